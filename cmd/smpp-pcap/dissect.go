@@ -0,0 +1,338 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"nessmpp/pkg/pcap"
+	"nessmpp/pkg/pdu"
+)
+
+// responseBit is set in the command_id of every response
+const responseBit uint32 = 0x80000000
+
+// frame is an SMPP frame seen in the capture
+type frame struct {
+	ts     time.Time
+	flow   pcap.Flow
+	header pdu.Header
+	data   []byte    // Kept for replay only
+	answer *exchange // Exchange of a request frame
+}
+
+// exchange is a request and its response
+type exchange struct {
+	command  uint32
+	sent     time.Time
+	answered bool
+	status   uint32
+	latency  time.Duration
+}
+
+// connection holds the frames of both directions of a TCP connection
+type connection struct {
+	id     int
+	first  pcap.Flow // Direction seen first
+	esme   pcap.Flow // Direction of the ESME, if known
+	known  bool      // Whether esme is set
+	frames []*frame
+}
+
+// direction is the framing state of one flow
+type direction struct {
+	buf    []byte
+	synced bool
+	conn   *connection
+}
+
+// pendingKey identifies an outstanding request
+type pendingKey struct {
+	flow pcap.Flow
+	seq  uint32
+}
+
+// dissector splits reassembled TCP flows into SMPP frames, prints them and
+// pairs requests with responses by sequence number
+type dissector struct {
+	out    io.Writer
+	json   bool
+	port   uint16
+	record bool
+
+	directions map[pcap.Flow]*direction
+	conns      []*connection
+	pending    map[pendingKey]*exchange
+	exchanges  []*exchange
+}
+
+// newDissector creates a dissector printing to out
+func newDissector(out io.Writer) *dissector {
+	return &dissector{
+		out:        out,
+		directions: make(map[pcap.Flow]*direction),
+		pending:    make(map[pendingKey]*exchange),
+	}
+}
+
+// direction returns the framing state of a flow, creating its connection
+// on first use
+func (d *dissector) direction(flow pcap.Flow) *direction {
+	if dir, ok := d.directions[flow]; ok {
+		return dir
+	}
+	dir := &direction{synced: true}
+	if rev, ok := d.directions[flow.Reverse()]; ok {
+		dir.conn = rev.conn
+	} else {
+		dir.conn = &connection{id: len(d.conns) + 1, first: flow}
+		d.conns = append(d.conns, dir.conn)
+	}
+	d.directions[flow] = dir
+	return dir
+}
+
+// accepts reports whether a flow passes the port filter
+func (d *dissector) accepts(flow pcap.Flow) bool {
+	return d.port == 0 || flow.Src.Port() == d.port || flow.Dst.Port() == d.port
+}
+
+// Data splits the reassembled data of a flow into frames. After a gap or
+// when the capture starts in the middle of a frame, data is skipped one
+// octet at a time until a plausible header is found.
+func (d *dissector) Data(flow pcap.Flow, ts time.Time, data []byte) {
+	if !d.accepts(flow) {
+		return
+	}
+	dir := d.direction(flow)
+	dir.buf = append(dir.buf, data...)
+
+	buf := dir.buf
+	for len(buf) >= pdu.HeaderLength {
+		length := binary.BigEndian.Uint32(buf[0:4])
+		if !plausibleHeader(buf) {
+			if dir.synced {
+				d.note(flow, ts, "lost SMPP framing, resynchronizing")
+				dir.synced = false
+			}
+			buf = buf[1:]
+			continue
+		}
+		if len(buf) < int(length) {
+			break
+		}
+		dir.synced = true
+		d.frame(dir.conn, flow, ts, buf[:length])
+		buf = buf[length:]
+	}
+	dir.buf = append(dir.buf[:0:0], buf...)
+}
+
+// Gap drops the partial frame of a flow that lost data
+func (d *dissector) Gap(flow pcap.Flow, ts time.Time, n int) {
+	if !d.accepts(flow) {
+		return
+	}
+	dir := d.direction(flow)
+	dir.buf = nil
+	dir.synced = false
+	d.note(flow, ts, fmt.Sprintf("%d octets missing from the capture", n))
+}
+
+// Close reports data left over at the end of a flow
+func (d *dissector) Close(flow pcap.Flow) {
+	if dir, ok := d.directions[flow]; ok && len(dir.buf) > 0 {
+		d.note(flow, time.Time{}, fmt.Sprintf("%d octets of an incomplete frame at the end of the flow", len(dir.buf)))
+		dir.buf = nil
+	}
+}
+
+// plausibleHeader checks that buf starts with the header of a known command
+func plausibleHeader(buf []byte) bool {
+	length := binary.BigEndian.Uint32(buf[0:4])
+	if length < pdu.HeaderLength || length > pdu.DefaultMaxCommandLength {
+		return false
+	}
+	_, ok := pdu.DefaultRegistry.Lookup(binary.BigEndian.Uint32(buf[4:8]))
+	return ok
+}
+
+// frame decodes, correlates and prints one frame
+func (d *dissector) frame(conn *connection, flow pcap.Flow, ts time.Time, data []byte) {
+	f := &frame{ts: ts, flow: flow}
+	f.header.Unmarshal(data)
+	if d.record {
+		f.data = append([]byte(nil), data...)
+	}
+	conn.frames = append(conn.frames, f)
+
+	// The frame is printed before the next one is read, so the PDU may
+	// share memory with it
+	p, err := pdu.DecodeAliased(data)
+
+	var latency *time.Duration
+	var note string
+	if f.header.CommandID&responseBit != 0 {
+		latency, note = d.response(f)
+	} else {
+		d.request(conn, f)
+	}
+
+	if d.json {
+		d.printJSON(f, p, err, data, latency, note)
+	} else {
+		d.printText(f, p, err, data, latency, note)
+	}
+}
+
+// request records an outstanding request
+func (d *dissector) request(conn *connection, f *frame) {
+	switch f.header.CommandID {
+	case pdu.BIND_RECEIVER, pdu.BIND_TRANSMITTER, pdu.BIND_TRANSCEIVER:
+		conn.esme, conn.known = f.flow, true
+	}
+
+	ex := &exchange{command: f.header.CommandID, sent: f.ts}
+	f.answer = ex
+	d.exchanges = append(d.exchanges, ex)
+	d.pending[pendingKey{f.flow, f.header.SequenceNumber}] = ex
+}
+
+// response pairs a response with its request and returns the latency
+func (d *dissector) response(f *frame) (*time.Duration, string) {
+	key := pendingKey{f.flow.Reverse(), f.header.SequenceNumber}
+	ex, ok := d.pending[key]
+	if !ok {
+		return nil, "no matching request"
+	}
+	if f.header.CommandID != ex.command|responseBit && f.header.CommandID != pdu.GENERIC_NACK {
+		return nil, fmt.Sprintf("request was %s", pdu.CommandName(ex.command))
+	}
+
+	delete(d.pending, key)
+	ex.answered = true
+	ex.status = f.header.CommandStatus
+	ex.latency = f.ts.Sub(ex.sent)
+	return &ex.latency, ""
+}
+
+// printText prints a frame as one line
+func (d *dissector) printText(f *frame, p pdu.PDU, err error, data []byte, latency *time.Duration, note string) {
+	line := fmt.Sprintf("%s %s ", f.ts.Format("2006-01-02 15:04:05.000000"), f.flow)
+	if err != nil {
+		line += fmt.Sprintf("%s error=%q raw=%s", f.header.String(), err.Error(), hex.EncodeToString(data))
+	} else {
+		line += pdu.FormatPDU(p)
+	}
+	if latency != nil {
+		line += fmt.Sprintf(" latency=%s", *latency)
+	}
+	if note != "" {
+		line += fmt.Sprintf(" (%s)", note)
+	}
+	fmt.Fprintln(d.out, line)
+}
+
+// frameJSON is the JSON form of a frame
+type frameJSON struct {
+	Time      time.Time       `json:"time"`
+	Src       string          `json:"src"`
+	Dst       string          `json:"dst"`
+	PDU       json.RawMessage `json:"pdu,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Raw       string          `json:"raw,omitempty"`
+	LatencyMS *float64        `json:"latency_ms,omitempty"`
+	Note      string          `json:"note,omitempty"`
+}
+
+// printJSON prints a frame as one JSON object
+func (d *dissector) printJSON(f *frame, p pdu.PDU, err error, data []byte, latency *time.Duration, note string) {
+	v := frameJSON{Time: f.ts, Src: f.flow.Src.String(), Dst: f.flow.Dst.String(), Note: note}
+	if err == nil {
+		v.PDU, err = json.Marshal(p)
+	}
+	if err != nil {
+		v.Error = err.Error()
+		v.Raw = hex.EncodeToString(data)
+	}
+	if latency != nil {
+		ms := float64(*latency) / float64(time.Millisecond)
+		v.LatencyMS = &ms
+	}
+	line, _ := json.Marshal(v)
+	fmt.Fprintln(d.out, string(line))
+}
+
+// note prints a remark about a flow
+func (d *dissector) note(flow pcap.Flow, ts time.Time, msg string) {
+	if d.json {
+		line, _ := json.Marshal(frameJSON{Time: ts, Src: flow.Src.String(), Dst: flow.Dst.String(), Note: msg})
+		fmt.Fprintln(d.out, string(line))
+		return
+	}
+	when := "-"
+	if !ts.IsZero() {
+		when = ts.Format("2006-01-02 15:04:05.000000")
+	}
+	fmt.Fprintf(d.out, "%s %s # %s\n", when, flow, msg)
+}
+
+// summary prints the number of requests per command, how many were
+// answered and with an error, and the latency distribution
+func (d *dissector) summary(w io.Writer) {
+	byCommand := make(map[uint32][]*exchange)
+	for _, ex := range d.exchanges {
+		byCommand[ex.command] = append(byCommand[ex.command], ex)
+	}
+	commands := make([]uint32, 0, len(byCommand))
+	for id := range byCommand {
+		commands = append(commands, id)
+	}
+	sort.Slice(commands, func(i, j int) bool {
+		return pdu.CommandName(commands[i]) < pdu.CommandName(commands[j])
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "command\trequests\tanswered\terrors\tmin\tavg\tp50\tp95\tmax\t")
+	for _, id := range commands {
+		var latencies []time.Duration
+		var errors int
+		var total time.Duration
+		for _, ex := range byCommand[id] {
+			if !ex.answered {
+				continue
+			}
+			if ex.status != pdu.ESME_ROK {
+				errors++
+			}
+			latencies = append(latencies, ex.latency)
+			total += ex.latency
+		}
+
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t", pdu.CommandName(id), len(byCommand[id]), len(latencies), errors)
+		if len(latencies) == 0 {
+			fmt.Fprintln(tw, "-\t-\t-\t-\t-\t")
+			continue
+		}
+		slices.Sort(latencies)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t\n",
+			latencies[0],
+			total/time.Duration(len(latencies)),
+			percentile(latencies, 50),
+			percentile(latencies, 95),
+			latencies[len(latencies)-1])
+	}
+	tw.Flush()
+}
+
+// percentile returns the p-th percentile of sorted latencies
+func percentile(sorted []time.Duration, p int) time.Duration {
+	i := (len(sorted)*p+99)/100 - 1
+	return sorted[max(i, 0)]
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"nessmpp/pkg/pcap"
+	"nessmpp/pkg/pdu"
+)
+
+// The captures are described in pkg/pcap/reader_test.go: a bind split
+// across segments, submit_sm 3 ahead of submit_sm 2 and a retransmission
+// of the latter, and enquire_link 4 missing from the capture
+var captureFiles = []string{"smpp.pcap", "smpp.pcapng"}
+
+// dissect runs a dissector over a capture of pkg/pcap/testdata
+func dissect(t *testing.T, name string, asJSON bool) (*dissector, string) {
+	t.Helper()
+	var out bytes.Buffer
+	d := newDissector(&out)
+	d.json = asJSON
+	d.record = true
+	assembler := pcap.NewAssembler(d)
+	if err := readCapture(filepath.Join("..", "..", "pkg", "pcap", "testdata", name), assembler); err != nil {
+		t.Fatalf("readCapture: %v", err)
+	}
+	assembler.Flush()
+	return d, out.String()
+}
+
+func TestDissectFrames(t *testing.T) {
+	type seen struct {
+		command uint32
+		seq     uint32
+		ms      int
+	}
+	want := []seen{
+		{pdu.BIND_TRANSMITTER, 1, 11},
+		{pdu.BIND_TRANSMITTER_RESP, 1, 15},
+		{pdu.SUBMIT_SM, 2, 21},
+		{pdu.SUBMIT_SM, 3, 21},
+		{pdu.SUBMIT_SM_RESP, 2, 30},
+		{pdu.SUBMIT_SM_RESP, 3, 41},
+		{pdu.ENQUIRE_LINK, 5, 50},
+		{pdu.UNBIND, 6, 51},
+		{pdu.ENQUIRE_LINK_RESP, 4, 59},
+		{pdu.ENQUIRE_LINK_RESP, 5, 60},
+		{pdu.UNBIND_RESP, 6, 61},
+	}
+	start := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	for _, name := range captureFiles {
+		t.Run(name, func(t *testing.T) {
+			d, _ := dissect(t, name, false)
+			if len(d.conns) != 1 {
+				t.Fatalf("%d connections, want 1", len(d.conns))
+			}
+			conn := d.conns[0]
+			if !conn.known || conn.esme.Dst.Port() != 2775 {
+				t.Errorf("ESME side %v known %v, want the side that bound", conn.esme, conn.known)
+			}
+
+			var got []seen
+			for _, f := range conn.frames {
+				got = append(got, seen{f.header.CommandID, f.header.SequenceNumber, int(f.ts.Sub(start) / time.Millisecond)})
+				if _, err := pdu.Decode(f.data); err != nil {
+					t.Errorf("%s %d: %v", pdu.CommandName(f.header.CommandID), f.header.SequenceNumber, err)
+				}
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("frames:\n%v\nwant:\n%v", got, want)
+			}
+
+			// The split bind is reassembled
+			bind, err := pdu.Decode(conn.frames[0].data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b := bind.(*pdu.BindTransmitter); b.SystemID != "esme" || b.Password != "secret" {
+				t.Errorf("bind_transmitter %+v", b)
+			}
+		})
+	}
+}
+
+func TestDissectLatency(t *testing.T) {
+	type paired struct {
+		command  uint32
+		answered bool
+		latency  time.Duration
+	}
+	want := []paired{
+		{pdu.BIND_TRANSMITTER, true, 4 * time.Millisecond},
+		{pdu.SUBMIT_SM, true, 9 * time.Millisecond},
+		{pdu.SUBMIT_SM, true, 20 * time.Millisecond},
+		{pdu.ENQUIRE_LINK, true, 10 * time.Millisecond},
+		{pdu.UNBIND, true, 10 * time.Millisecond},
+	}
+
+	for _, name := range captureFiles {
+		t.Run(name, func(t *testing.T) {
+			d, out := dissect(t, name, false)
+			var got []paired
+			for _, ex := range d.exchanges {
+				got = append(got, paired{ex.command, ex.answered, ex.latency})
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("exchanges:\n%v\nwant:\n%v", got, want)
+			}
+			if len(d.pending) != 0 {
+				t.Errorf("%d requests still pending", len(d.pending))
+			}
+
+			// One line per frame, with the latency on the responses and
+			// notes for the gap and the response to the missing request
+			lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+			wantLines := []string{
+				"submit_sm_resp",
+				"latency=9ms",
+				"latency=20ms",
+				"# 16 octets missing from the capture",
+				"enquire_link_resp",
+				"(no matching request)",
+			}
+			for _, s := range wantLines {
+				if !strings.Contains(out, s) {
+					t.Errorf("output does not contain %q:\n%s", s, out)
+				}
+			}
+			if len(lines) != 12 {
+				t.Errorf("%d lines of output, want 12:\n%s", len(lines), out)
+			}
+			for _, line := range lines {
+				if strings.Contains(line, "bind_transmitter_resp") && !strings.HasSuffix(line, "latency=4ms") {
+					t.Errorf("bind_transmitter_resp line %q", line)
+				}
+			}
+
+			var summary bytes.Buffer
+			d.summary(&summary)
+			for _, row := range []string{
+				"submit_sm         2         2       0  9ms  14.5ms  9ms  20ms  20ms",
+				"enquire_link         1         1       0  10ms    10ms  10ms  10ms  10ms",
+			} {
+				if !strings.Contains(strings.Join(strings.Fields(summary.String()), " "), strings.Join(strings.Fields(row), " ")) {
+					t.Errorf("summary does not contain %q:\n%s", row, summary.String())
+				}
+			}
+		})
+	}
+}
+
+func TestDissectJSON(t *testing.T) {
+	_, out := dissect(t, "smpp.pcapng", true)
+
+	var frames []frameJSON
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		var f frameJSON
+		if err := json.Unmarshal(scanner.Bytes(), &f); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		frames = append(frames, f)
+	}
+	if len(frames) != 12 {
+		t.Fatalf("%d JSON lines, want 12", len(frames))
+	}
+
+	var latencies []float64
+	for _, f := range frames {
+		if f.Error != "" {
+			t.Errorf("frame at %v: %s", f.Time, f.Error)
+		}
+		if f.LatencyMS != nil {
+			latencies = append(latencies, *f.LatencyMS)
+		}
+	}
+	if want := []float64{4, 9, 20, 10, 10}; !slices.Equal(latencies, want) {
+		t.Errorf("latencies %v, want %v", latencies, want)
+	}
+	if f := frames[0]; f.Src != "10.0.0.1:40000" || f.Dst != "10.0.0.2:2775" || len(f.PDU) == 0 {
+		t.Errorf("first frame %+v", f)
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"nessmpp/pkg/pdu"
+)
+
+// replayer sends the requests of the ESME side of captured connections to
+// a live server, answers the requests of the server, and compares the
+// responses with those in the capture
+type replayer struct {
+	addr  string
+	speed float64       // Replay speed relative to the capture, 0 for no delays
+	wait  time.Duration // Time allowed for outstanding responses at the end
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// sentRequest is a request sent to the server
+type sentRequest struct {
+	at       time.Time
+	captured *frame
+}
+
+// replayResult counts the outcome of replaying a connection
+type replayResult struct {
+	sent       int
+	answered   int
+	mismatched int
+}
+
+// run replays every connection whose ESME side is known, concurrently and
+// with the timing of the capture
+func (r *replayer) run(conns []*connection) {
+	var origin time.Time
+	for _, c := range conns {
+		if len(c.frames) > 0 && (origin.IsZero() || c.frames[0].ts.Before(origin)) {
+			origin = c.frames[0].ts
+		}
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, c := range conns {
+		if !c.known {
+			r.printf(c, "skipped: the ESME side is unknown")
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := r.replay(c, start, origin)
+			if err != nil {
+				r.printf(c, "replay failed: %v", err)
+			}
+			r.printf(c, "sent %d requests, %d answered, %d with a different status than captured",
+				res.sent, res.answered, res.mismatched)
+		}()
+	}
+	wg.Wait()
+}
+
+// replay replays one connection
+func (r *replayer) replay(c *connection, start time.Time, origin time.Time) (replayResult, error) {
+	var res replayResult
+	conn, err := net.Dial("tcp", r.addr)
+	if err != nil {
+		return res, err
+	}
+	defer conn.Close()
+
+	var mu sync.Mutex
+	sent := make(map[uint32]sentRequest)
+	done := make(chan struct{})
+	writer := pdu.NewWriter(conn)
+
+	go func() {
+		defer close(done)
+		reader := pdu.NewReader(conn)
+		for {
+			data, err := reader.ReadFrame()
+			if err != nil {
+				return
+			}
+			var h pdu.Header
+			h.Unmarshal(data)
+
+			if h.CommandID&responseBit == 0 {
+				r.answer(c, writer, data, &h)
+				continue
+			}
+
+			mu.Lock()
+			req, ok := sent[h.SequenceNumber]
+			delete(sent, h.SequenceNumber)
+			if ok {
+				res.answered++
+			}
+			mu.Unlock()
+			if !ok {
+				r.printf(c, "< %s (no matching request)", h.String())
+				continue
+			}
+			if !r.compare(c, &h, req) {
+				mu.Lock()
+				res.mismatched++
+				mu.Unlock()
+			}
+		}
+	}()
+
+	for _, f := range c.frames {
+		if f.flow != c.esme || f.header.CommandID&responseBit != 0 {
+			continue
+		}
+		if r.speed > 0 {
+			due := start.Add(time.Duration(float64(f.ts.Sub(origin)) / r.speed))
+			time.Sleep(time.Until(due))
+		}
+
+		mu.Lock()
+		sent[f.header.SequenceNumber] = sentRequest{at: time.Now(), captured: f}
+		res.sent++
+		mu.Unlock()
+		r.printf(c, "> %s", f.header.String())
+		if err := writer.WriteFrame(f.data); err != nil {
+			return res, err
+		}
+	}
+
+	// Give the server time to answer what is outstanding
+	deadline := time.Now().Add(r.wait)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		outstanding := len(sent)
+		mu.Unlock()
+		if outstanding == 0 {
+			break
+		}
+		select {
+		case <-done:
+			deadline = time.Now()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	conn.Close()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, seq := range slices.Sorted(maps.Keys(sent)) {
+		r.printf(c, "no response to %s seq=%d", pdu.CommandName(sent[seq].captured.header.CommandID), seq)
+	}
+	return res, nil
+}
+
+// compare prints a response next to the one in the capture and reports
+// whether their status matches
+func (r *replayer) compare(c *connection, h *pdu.Header, req sentRequest) bool {
+	line := fmt.Sprintf("< %s latency=%s", h.String(), time.Since(req.at))
+	match := true
+	if ex := req.captured.answer; ex != nil && ex.answered {
+		line += fmt.Sprintf(" captured_status=%s captured_latency=%s", pdu.StatusName(ex.status), ex.latency)
+		if ex.status != h.CommandStatus {
+			line += " MISMATCH"
+			match = false
+		}
+	} else {
+		line += " (unanswered in the capture)"
+	}
+	r.printf(c, "%s", line)
+	return match
+}
+
+// answer responds to a request of the server, e.g. deliver_sm or
+// enquire_link, with a successful response
+func (r *replayer) answer(c *connection, w *pdu.Writer, data []byte, h *pdu.Header) {
+	r.printf(c, "< %s", h.String())
+
+	var resp pdu.PDU
+	if p, err := pdu.Decode(data); err == nil {
+		resp = p.GetResponse()
+	}
+	if resp == nil {
+		nack := pdu.NewGenericNack()
+		nack.SetErrorCode(pdu.ESME_RINVCMDID)
+		resp = nack
+	}
+	resp.GetHeader().SequenceNumber = h.SequenceNumber
+	if err := w.WritePDU(resp); err == nil {
+		r.printf(c, "> %s (answered by the replayer)", resp.GetHeader().String())
+	}
+}
+
+// printf prints a line about a connection
+func (r *replayer) printf(c *connection, format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	flow := c.first
+	if c.known {
+		flow = c.esme
+	}
+	fmt.Fprintf(r.out, "[conn %d %s] %s\n", c.id, flow, fmt.Sprintf(format, args...))
+}
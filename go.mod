@@ -2,4 +2,7 @@ module nessmpp
 
 go 1.24.0
 
-require github.com/streadway/amqp v1.1.0 // indirect
+require (
+	github.com/streadway/amqp v1.1.0 // indirect
+	golang.org/x/text v0.34.0
+)
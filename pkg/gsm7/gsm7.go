@@ -0,0 +1,166 @@
+// Package gsm7 implements the GSM 03.38 / 3GPP TS 23.038 7-bit default
+// alphabet, its extension table, the national language locking and single
+// shift tables, and septet packing.
+package gsm7
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrUnknownLanguage = errors.New("gsm7: unknown national language")
+	ErrInvalidSeptet   = errors.New("gsm7: invalid septet")
+)
+
+// UnencodableError reports a character that is not in the selected tables
+type UnencodableError struct {
+	Rune   rune
+	Offset int
+}
+
+// Error returns the error message
+func (e *UnencodableError) Error() string {
+	return fmt.Sprintf("gsm7: character %q at offset %d cannot be encoded", e.Rune, e.Offset)
+}
+
+// Encoding selects the locking and single shift tables by national
+// language identifier. The zero value is the default alphabet with the
+// default extension table.
+type Encoding struct {
+	LockingShift uint8
+	SingleShift  uint8
+}
+
+// Default is the default alphabet with the default extension table
+var Default = Encoding{}
+
+// tables resolves the locking and single shift tables
+func (e Encoding) tables() (*Table, *Table, error) {
+	locking, ok := LockingShift(e.LockingShift)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: locking shift 0x%02X", ErrUnknownLanguage, e.LockingShift)
+	}
+	single, ok := SingleShift(e.SingleShift)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: single shift 0x%02X", ErrUnknownLanguage, e.SingleShift)
+	}
+	return locking, single, nil
+}
+
+// Encode converts text into unpacked septets, one per octet. Characters of
+// the single shift table are encoded as an escape followed by their septet.
+func (e Encoding) Encode(text string) ([]byte, error) {
+	locking, single, err := e.tables()
+	if err != nil {
+		return nil, err
+	}
+
+	septets := make([]byte, 0, len(text))
+	for offset, r := range text {
+		if septet, ok := locking.Septet(r); ok {
+			septets = append(septets, septet)
+			continue
+		}
+		if septet, ok := single.Septet(r); ok {
+			septets = append(septets, ESCAPE, septet)
+			continue
+		}
+		return nil, &UnencodableError{Rune: r, Offset: offset}
+	}
+	return septets, nil
+}
+
+// Decode converts unpacked septets into text. An escaped septet that is
+// not in the single shift table is shown as its locking shift character,
+// as required by 3GPP TS 23.038.
+func (e Encoding) Decode(septets []byte) (string, error) {
+	locking, single, err := e.tables()
+	if err != nil {
+		return "", err
+	}
+
+	runes := make([]rune, 0, len(septets))
+	for i := 0; i < len(septets); i++ {
+		septet := septets[i]
+		if septet > 0x7F {
+			return "", fmt.Errorf("%w: 0x%02X at offset %d", ErrInvalidSeptet, septet, i)
+		}
+
+		if septet == ESCAPE {
+			// A trailing escape has nothing to modify
+			if i+1 == len(septets) {
+				break
+			}
+			i++
+			septet = septets[i]
+			if septet > 0x7F {
+				return "", fmt.Errorf("%w: 0x%02X at offset %d", ErrInvalidSeptet, septet, i)
+			}
+			if r, ok := single.Rune(septet); ok {
+				runes = append(runes, r)
+				continue
+			}
+			// A second escape is reserved for future extension tables
+			if septet == ESCAPE {
+				runes = append(runes, ' ')
+				continue
+			}
+		}
+
+		if r, ok := locking.Rune(septet); ok {
+			runes = append(runes, r)
+		}
+	}
+	return string(runes), nil
+}
+
+// SeptetLen returns the number of septets text occupies, counting the
+// escape of single shift characters. It reports false if a character
+// cannot be encoded.
+func (e Encoding) SeptetLen(text string) (int, bool) {
+	locking, single, err := e.tables()
+	if err != nil {
+		return 0, false
+	}
+
+	n := 0
+	for _, r := range text {
+		if _, ok := locking.Septet(r); ok {
+			n++
+			continue
+		}
+		if _, ok := single.Septet(r); ok {
+			n += 2
+			continue
+		}
+		return 0, false
+	}
+	return n, true
+}
+
+// CanEncode checks if every character of text is in the selected tables
+func (e Encoding) CanEncode(text string) bool {
+	_, ok := e.SeptetLen(text)
+	return ok
+}
+
+// Encode converts text into unpacked septets using the default alphabet
+func Encode(text string) ([]byte, error) {
+	return Default.Encode(text)
+}
+
+// Decode converts unpacked septets into text using the default alphabet
+func Decode(septets []byte) (string, error) {
+	return Default.Decode(septets)
+}
+
+// SeptetLen returns the number of septets text occupies in the default alphabet
+func SeptetLen(text string) (int, bool) {
+	return Default.SeptetLen(text)
+}
+
+// CanEncode checks if text can be encoded in the default alphabet
+func CanEncode(text string) bool {
+	return Default.CanEncode(text)
+}
@@ -0,0 +1,216 @@
+package gsm7
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		enc     Encoding
+		text    string
+		septets []byte
+	}{
+		{"default", Default, "Hi @£", []byte{0x48, 0x69, 0x20, 0x00, 0x01}},
+		{"extension", Default, "{€}", []byte{ESCAPE, 0x28, ESCAPE, 0x65, ESCAPE, 0x29}},
+		{"turkish locking", Encoding{LockingShift: NLI_TURKISH}, "ğİ€", []byte{0x0C, 0x40, 0x04}},
+		{"turkish single", Encoding{SingleShift: NLI_TURKISH}, "aş", []byte{0x61, ESCAPE, 0x73}},
+		{"spanish single", Encoding{SingleShift: NLI_SPANISH}, "Ú", []byte{ESCAPE, 0x55}},
+		{"portuguese locking", Encoding{LockingShift: NLI_PORTUGUESE}, "ãõ", []byte{0x7B, 0x7C}},
+		{"hindi locking", Encoding{LockingShift: NLI_HINDI}, "नमस्ते", []byte{0x2F, 0x42, 0x4C, 0x5F, 0x27, 0x59}},
+		{"hindi single", Encoding{LockingShift: NLI_HINDI, SingleShift: NLI_HINDI}, "न।", []byte{0x2F, ESCAPE, 0x19}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			septets, err := tt.enc.Encode(tt.text)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			if !bytes.Equal(septets, tt.septets) {
+				t.Fatalf("Encode = % X, want % X", septets, tt.septets)
+			}
+			if n, ok := tt.enc.SeptetLen(tt.text); !ok || n != len(tt.septets) {
+				t.Errorf("SeptetLen = %d, %v, want %d", n, ok, len(tt.septets))
+			}
+			text, err := tt.enc.Decode(septets)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if text != tt.text {
+				t.Errorf("Decode = %q, want %q", text, tt.text)
+			}
+		})
+	}
+}
+
+func TestEncodeUnencodable(t *testing.T) {
+	tests := []struct {
+		name   string
+		enc    Encoding
+		text   string
+		r      rune
+		offset int
+	}{
+		{"cjk", Default, "abc中", '中', 3},
+		{"cyrillic after extension", Default, "€Ж", 'Ж', 3},
+		{"turkish only in single shift", Default, "ş", 'ş', 0},
+		{"hindi without its table", Encoding{LockingShift: NLI_BENGALI}, "न", 'न', 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.enc.Encode(tt.text)
+			var uerr *UnencodableError
+			if !errors.As(err, &uerr) {
+				t.Fatalf("Encode error = %v, want UnencodableError", err)
+			}
+			if uerr.Rune != tt.r || uerr.Offset != tt.offset {
+				t.Errorf("UnencodableError = %q at %d, want %q at %d", uerr.Rune, uerr.Offset, tt.r, tt.offset)
+			}
+			if tt.enc.CanEncode(tt.text) {
+				t.Errorf("CanEncode = true")
+			}
+		})
+	}
+}
+
+func TestTableDuplicateRune(t *testing.T) {
+	// '¡' and '*' appear twice in the Indian single shift tables
+	for _, tt := range []struct {
+		r       rune
+		septets []byte
+	}{{'¡', []byte{0x13, 0x15}}, {'*', []byte{0x0B, 0x18}}} {
+		if septet, ok := BengaliSingleShift.Septet(tt.r); !ok || septet != tt.septets[0] {
+			t.Errorf("Septet(%q) = 0x%02X, %v, want 0x%02X", tt.r, septet, ok, tt.septets[0])
+		}
+		for _, septet := range tt.septets {
+			if r, ok := BengaliSingleShift.Rune(septet); !ok || r != tt.r {
+				t.Errorf("Rune(0x%02X) = %q, %v, want %q", septet, r, ok, tt.r)
+			}
+		}
+	}
+}
+
+func TestUnknownLanguage(t *testing.T) {
+	for _, enc := range []Encoding{{LockingShift: 0x42}, {SingleShift: 0x42}, {LockingShift: NLI_SPANISH}} {
+		if _, err := enc.Encode("a"); !errors.Is(err, ErrUnknownLanguage) {
+			t.Errorf("%+v: Encode error = %v, want ErrUnknownLanguage", enc, err)
+		}
+	}
+}
+
+func TestDecodeEscapes(t *testing.T) {
+	tests := []struct {
+		name    string
+		septets []byte
+		text    string
+	}{
+		{"undefined extension falls back to locking", []byte{ESCAPE, 0x41}, "A"},
+		{"trailing escape", []byte{0x41, ESCAPE}, "A"},
+		{"double escape", []byte{ESCAPE, ESCAPE, 0x41}, " A"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, err := Decode(tt.septets)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if text != tt.text {
+				t.Errorf("Decode = %q, want %q", text, tt.text)
+			}
+		})
+	}
+
+	if _, err := Decode([]byte{0x41, 0x80}); !errors.Is(err, ErrInvalidSeptet) {
+		t.Errorf("Decode error = %v, want ErrInvalidSeptet", err)
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	got := Languages()
+	if len(got) != int(NLI_URDU) {
+		t.Fatalf("Languages = %v, want NLI 0x01-0x0D", got)
+	}
+	for i, nli := range got {
+		if nli != uint8(i+1) {
+			t.Fatalf("Languages = %v, want NLI 0x01-0x0D", got)
+		}
+	}
+	for nli := NLI_BENGALI; nli <= NLI_URDU; nli++ {
+		if _, ok := LockingShift(nli); !ok {
+			t.Errorf("no locking shift for NLI 0x%02X", nli)
+		}
+		if _, ok := SingleShift(nli); !ok {
+			t.Errorf("no single shift for NLI 0x%02X", nli)
+		}
+	}
+}
+
+func TestPack(t *testing.T) {
+	tests := []struct {
+		name     string
+		septets  []byte
+		fillBits int
+		packed   []byte
+	}{
+		{"hellohello", []byte("hellohello"), 0, []byte{0xE8, 0x32, 0x9B, 0xFD, 0x46, 0x97, 0xD9, 0xEC, 0x37}},
+		// 7 septets leave 7 spare bits, filled with CR
+		{"seven septets", []byte("1234567"), 0, []byte{0x31, 0xD9, 0x8C, 0x56, 0xB3, 0xDD, 0x1A}},
+		// A CR ending on an octet boundary is followed by a second CR
+		{"eight septets ending in CR", []byte("ABCDEFG\r"), 0, []byte{0x41, 0xE1, 0x90, 0x58, 0x34, 0x1E, 0x1B, 0x0D}},
+		{"fill bits", []byte("A"), 1, []byte{0x82}},
+		{"empty", nil, 0, []byte{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packed := Pack(tt.septets, tt.fillBits)
+			if !bytes.Equal(packed, tt.packed) {
+				t.Errorf("Pack = % X, want % X", packed, tt.packed)
+			}
+		})
+	}
+}
+
+func TestUnpackPadding(t *testing.T) {
+	// The CR padding seven spare bits is dropped
+	septets := Unpack(Pack([]byte("1234567"), 0), -1, 0)
+	if string(septets) != "1234567" {
+		t.Errorf("Unpack = %q, want %q", septets, "1234567")
+	}
+
+	// The wanted CR is kept and the receiver sees it twice
+	septets = Unpack(Pack([]byte("ABCDEFG\r"), 0), -1, 0)
+	if string(septets) != "ABCDEFG\r\r" {
+		t.Errorf("Unpack = %q, want %q", septets, "ABCDEFG\r\r")
+	}
+
+	// An explicit count stops before the padding
+	septets = Unpack(Pack([]byte("1234567"), 0), 7, 0)
+	if string(septets) != "1234567" {
+		t.Errorf("Unpack = %q, want %q", septets, "1234567")
+	}
+}
+
+func TestPackRoundTrip(t *testing.T) {
+	for fillBits := 0; fillBits < 7; fillBits++ {
+		for n := 0; n <= 20; n++ {
+			septets := make([]byte, n)
+			for i := range septets {
+				septets[i] = byte(0x20 + i*5%0x5F)
+			}
+			got := Unpack(Pack(septets, fillBits), n, fillBits)
+			if !bytes.Equal(got, septets) {
+				t.Errorf("fill %d, %d septets: Unpack = % X, want % X", fillBits, n, got, septets)
+			}
+		}
+	}
+}
+
+func TestFillBits(t *testing.T) {
+	for udhLen, want := range map[int]int{0: 0, 6: 1, 7: 0, 5: 2, 12: 2} {
+		if got := FillBits(udhLen); got != want {
+			t.Errorf("FillBits(%d) = %d, want %d", udhLen, got, want)
+		}
+	}
+}
@@ -0,0 +1,2068 @@
+package gsm7
+
+// BengaliLockingShift is the Bengali national language locking shift table
+// (3GPP TS 23.038 A.3.4)
+var BengaliLockingShift = NewTable(map[byte]rune{
+	0x00: '\u0981',
+	0x01: '\u0982',
+	0x02: '\u0983',
+	0x03: '\u0985',
+	0x04: '\u0986',
+	0x05: '\u0987',
+	0x06: '\u0988',
+	0x07: '\u0989',
+	0x08: '\u098A',
+	0x09: '\u098B',
+	0x0A: '\n',
+	0x0B: '\u098C',
+	0x0D: '\r',
+	0x0F: '\u098F',
+	0x10: '\u0990',
+	0x13: '\u0993',
+	0x14: '\u0994',
+	0x15: '\u0995',
+	0x16: '\u0996',
+	0x17: '\u0997',
+	0x18: '\u0998',
+	0x19: '\u0999',
+	0x1A: '\u099A',
+	0x1C: '\u099B',
+	0x1D: '\u099C',
+	0x1E: '\u099D',
+	0x1F: '\u099E',
+	0x20: ' ',
+	0x21: '!',
+	0x22: '\u099F',
+	0x23: '\u09A0',
+	0x24: '\u09A1',
+	0x25: '\u09A2',
+	0x26: '\u09A3',
+	0x27: '\u09A4',
+	0x28: ')',
+	0x29: '(',
+	0x2A: '\u09A5',
+	0x2B: '\u09A6',
+	0x2C: ',',
+	0x2D: '\u09A7',
+	0x2E: '.',
+	0x2F: '\u09A8',
+	0x30: '0',
+	0x31: '1',
+	0x32: '2',
+	0x33: '3',
+	0x34: '4',
+	0x35: '5',
+	0x36: '6',
+	0x37: '7',
+	0x38: '8',
+	0x39: '9',
+	0x3A: ':',
+	0x3B: ';',
+	0x3D: '\u09AA',
+	0x3E: '\u09AB',
+	0x3F: '?',
+	0x40: '\u09AC',
+	0x41: '\u09AD',
+	0x42: '\u09AE',
+	0x43: '\u09AF',
+	0x44: '\u09B0',
+	0x46: '\u09B2',
+	0x4A: '\u09B6',
+	0x4B: '\u09B7',
+	0x4C: '\u09B8',
+	0x4D: '\u09B9',
+	0x4E: '\u09BC',
+	0x4F: '\u09BD',
+	0x50: '\u09BE',
+	0x51: '\u09BF',
+	0x52: '\u09C0',
+	0x53: '\u09C1',
+	0x54: '\u09C2',
+	0x55: '\u09C3',
+	0x56: '\u09C4',
+	0x59: '\u09C7',
+	0x5A: '\u09C8',
+	0x5D: '\u09CB',
+	0x5E: '\u09CC',
+	0x5F: '\u09CD',
+	0x60: '\u09CE',
+	0x61: 'a',
+	0x62: 'b',
+	0x63: 'c',
+	0x64: 'd',
+	0x65: 'e',
+	0x66: 'f',
+	0x67: 'g',
+	0x68: 'h',
+	0x69: 'i',
+	0x6A: 'j',
+	0x6B: 'k',
+	0x6C: 'l',
+	0x6D: 'm',
+	0x6E: 'n',
+	0x6F: 'o',
+	0x70: 'p',
+	0x71: 'q',
+	0x72: 'r',
+	0x73: 's',
+	0x74: 't',
+	0x75: 'u',
+	0x76: 'v',
+	0x77: 'w',
+	0x78: 'x',
+	0x79: 'y',
+	0x7A: 'z',
+	0x7B: '\u09D7',
+	0x7C: '\u09DC',
+	0x7D: '\u09DD',
+	0x7E: '\u09F0',
+	0x7F: '\u09F1',
+})
+
+// BengaliSingleShift is the Bengali national language single shift table
+// (3GPP TS 23.038 A.2.4)
+var BengaliSingleShift = NewTable(map[byte]rune{
+	0x00: '@',
+	0x01: '\u00A3',
+	0x02: '$',
+	0x03: '\u00A5',
+	0x04: '\u00BF',
+	0x05: '"',
+	0x06: '\u00A4',
+	0x07: '%',
+	0x08: '&',
+	0x09: '\'',
+	0x0A: '\f',
+	0x0B: '*',
+	0x0C: '+',
+	0x0E: '-',
+	0x0F: '/',
+	0x10: '<',
+	0x11: '=',
+	0x12: '>',
+	0x13: '\u00A1',
+	0x14: '^',
+	0x15: '\u00A1',
+	0x16: '_',
+	0x17: '#',
+	0x18: '*',
+	0x19: '\u09E6',
+	0x1A: '\u09E7',
+	0x1C: '\u09E8',
+	0x1D: '\u09E9',
+	0x1E: '\u09EA',
+	0x1F: '\u09EB',
+	0x20: '\u09EC',
+	0x21: '\u09ED',
+	0x22: '\u09EE',
+	0x23: '\u09EF',
+	0x24: '\u09DF',
+	0x25: '\u09E0',
+	0x26: '\u09E1',
+	0x27: '\u09E2',
+	0x28: '{',
+	0x29: '}',
+	0x2A: '\u09E3',
+	0x2B: '\u09F2',
+	0x2C: '\u09F3',
+	0x2D: '\u09F4',
+	0x2E: '\u09F5',
+	0x2F: '\\',
+	0x30: '\u09F6',
+	0x31: '\u09F7',
+	0x32: '\u09F8',
+	0x33: '\u09F9',
+	0x34: '\u09FA',
+	0x3C: '[',
+	0x3D: '~',
+	0x3E: ']',
+	0x40: '|',
+	0x41: 'A',
+	0x42: 'B',
+	0x43: 'C',
+	0x44: 'D',
+	0x45: 'E',
+	0x46: 'F',
+	0x47: 'G',
+	0x48: 'H',
+	0x49: 'I',
+	0x4A: 'J',
+	0x4B: 'K',
+	0x4C: 'L',
+	0x4D: 'M',
+	0x4E: 'N',
+	0x4F: 'O',
+	0x50: 'P',
+	0x51: 'Q',
+	0x52: 'R',
+	0x53: 'S',
+	0x54: 'T',
+	0x55: 'U',
+	0x56: 'V',
+	0x57: 'W',
+	0x58: 'X',
+	0x59: 'Y',
+	0x5A: 'Z',
+	0x65: '\u20AC',
+})
+
+// GujaratiLockingShift is the Gujarati national language locking shift table
+// (3GPP TS 23.038 A.3.5)
+var GujaratiLockingShift = NewTable(map[byte]rune{
+	0x00: '\u0A81',
+	0x01: '\u0A82',
+	0x02: '\u0A83',
+	0x03: '\u0A85',
+	0x04: '\u0A86',
+	0x05: '\u0A87',
+	0x06: '\u0A88',
+	0x07: '\u0A89',
+	0x08: '\u0A8A',
+	0x09: '\u0A8B',
+	0x0A: '\n',
+	0x0B: '\u0A8C',
+	0x0C: '\u0A8D',
+	0x0D: '\r',
+	0x0F: '\u0A8F',
+	0x10: '\u0A90',
+	0x11: '\u0A91',
+	0x13: '\u0A93',
+	0x14: '\u0A94',
+	0x15: '\u0A95',
+	0x16: '\u0A96',
+	0x17: '\u0A97',
+	0x18: '\u0A98',
+	0x19: '\u0A99',
+	0x1A: '\u0A9A',
+	0x1C: '\u0A9B',
+	0x1D: '\u0A9C',
+	0x1E: '\u0A9D',
+	0x1F: '\u0A9E',
+	0x20: ' ',
+	0x21: '!',
+	0x22: '\u0A9F',
+	0x23: '\u0AA0',
+	0x24: '\u0AA1',
+	0x25: '\u0AA2',
+	0x26: '\u0AA3',
+	0x27: '\u0AA4',
+	0x28: ')',
+	0x29: '(',
+	0x2A: '\u0AA5',
+	0x2B: '\u0AA6',
+	0x2C: ',',
+	0x2D: '\u0AA7',
+	0x2E: '.',
+	0x2F: '\u0AA8',
+	0x30: '0',
+	0x31: '1',
+	0x32: '2',
+	0x33: '3',
+	0x34: '4',
+	0x35: '5',
+	0x36: '6',
+	0x37: '7',
+	0x38: '8',
+	0x39: '9',
+	0x3A: ':',
+	0x3B: ';',
+	0x3D: '\u0AAA',
+	0x3E: '\u0AAB',
+	0x3F: '?',
+	0x40: '\u0AAC',
+	0x41: '\u0AAD',
+	0x42: '\u0AAE',
+	0x43: '\u0AAF',
+	0x44: '\u0AB0',
+	0x46: '\u0AB2',
+	0x47: '\u0AB3',
+	0x49: '\u0AB5',
+	0x4A: '\u0AB6',
+	0x4B: '\u0AB7',
+	0x4C: '\u0AB8',
+	0x4D: '\u0AB9',
+	0x4E: '\u0ABC',
+	0x4F: '\u0ABD',
+	0x50: '\u0ABE',
+	0x51: '\u0ABF',
+	0x52: '\u0AC0',
+	0x53: '\u0AC1',
+	0x54: '\u0AC2',
+	0x55: '\u0AC3',
+	0x56: '\u0AC4',
+	0x57: '\u0AC5',
+	0x59: '\u0AC7',
+	0x5A: '\u0AC8',
+	0x5B: '\u0AC9',
+	0x5D: '\u0ACB',
+	0x5E: '\u0ACC',
+	0x5F: '\u0ACD',
+	0x60: '\u0AD0',
+	0x61: 'a',
+	0x62: 'b',
+	0x63: 'c',
+	0x64: 'd',
+	0x65: 'e',
+	0x66: 'f',
+	0x67: 'g',
+	0x68: 'h',
+	0x69: 'i',
+	0x6A: 'j',
+	0x6B: 'k',
+	0x6C: 'l',
+	0x6D: 'm',
+	0x6E: 'n',
+	0x6F: 'o',
+	0x70: 'p',
+	0x71: 'q',
+	0x72: 'r',
+	0x73: 's',
+	0x74: 't',
+	0x75: 'u',
+	0x76: 'v',
+	0x77: 'w',
+	0x78: 'x',
+	0x79: 'y',
+	0x7A: 'z',
+	0x7B: '\u0AE0',
+	0x7C: '\u0AE1',
+	0x7D: '\u0AE2',
+	0x7E: '\u0AE3',
+	0x7F: '\u0AF1',
+})
+
+// GujaratiSingleShift is the Gujarati national language single shift table
+// (3GPP TS 23.038 A.2.5)
+var GujaratiSingleShift = NewTable(map[byte]rune{
+	0x00: '@',
+	0x01: '\u00A3',
+	0x02: '$',
+	0x03: '\u00A5',
+	0x04: '\u00BF',
+	0x05: '"',
+	0x06: '\u00A4',
+	0x07: '%',
+	0x08: '&',
+	0x09: '\'',
+	0x0A: '\f',
+	0x0B: '*',
+	0x0C: '+',
+	0x0E: '-',
+	0x0F: '/',
+	0x10: '<',
+	0x11: '=',
+	0x12: '>',
+	0x13: '\u00A1',
+	0x14: '^',
+	0x15: '\u00A1',
+	0x16: '_',
+	0x17: '#',
+	0x18: '*',
+	0x19: '\u0964',
+	0x1A: '\u0965',
+	0x1C: '\u0AE6',
+	0x1D: '\u0AE7',
+	0x1E: '\u0AE8',
+	0x1F: '\u0AE9',
+	0x20: '\u0AEA',
+	0x21: '\u0AEB',
+	0x22: '\u0AEC',
+	0x23: '\u0AED',
+	0x24: '\u0AEE',
+	0x25: '\u0AEF',
+	0x28: '{',
+	0x29: '}',
+	0x2F: '\\',
+	0x3C: '[',
+	0x3D: '~',
+	0x3E: ']',
+	0x40: '|',
+	0x41: 'A',
+	0x42: 'B',
+	0x43: 'C',
+	0x44: 'D',
+	0x45: 'E',
+	0x46: 'F',
+	0x47: 'G',
+	0x48: 'H',
+	0x49: 'I',
+	0x4A: 'J',
+	0x4B: 'K',
+	0x4C: 'L',
+	0x4D: 'M',
+	0x4E: 'N',
+	0x4F: 'O',
+	0x50: 'P',
+	0x51: 'Q',
+	0x52: 'R',
+	0x53: 'S',
+	0x54: 'T',
+	0x55: 'U',
+	0x56: 'V',
+	0x57: 'W',
+	0x58: 'X',
+	0x59: 'Y',
+	0x5A: 'Z',
+	0x65: '\u20AC',
+})
+
+// HindiLockingShift is the Hindi national language locking shift table
+// (3GPP TS 23.038 A.3.6)
+var HindiLockingShift = NewTable(map[byte]rune{
+	0x00: '\u0981',
+	0x01: '\u0982',
+	0x02: '\u0983',
+	0x03: '\u0905',
+	0x04: '\u0906',
+	0x05: '\u0907',
+	0x06: '\u0908',
+	0x07: '\u0909',
+	0x08: '\u090A',
+	0x09: '\u090B',
+	0x0A: '\n',
+	0x0B: '\u090C',
+	0x0C: '\u090D',
+	0x0D: '\r',
+	0x0E: '\u090E',
+	0x0F: '\u090F',
+	0x10: '\u0910',
+	0x11: '\u0911',
+	0x12: '\u0912',
+	0x13: '\u0913',
+	0x14: '\u0914',
+	0x15: '\u0915',
+	0x16: '\u0916',
+	0x17: '\u0917',
+	0x18: '\u0918',
+	0x19: '\u0919',
+	0x1A: '\u091A',
+	0x1C: '\u091B',
+	0x1D: '\u091C',
+	0x1E: '\u091D',
+	0x1F: '\u091E',
+	0x20: ' ',
+	0x21: '!',
+	0x22: '\u091F',
+	0x23: '\u0920',
+	0x24: '\u0921',
+	0x25: '\u0922',
+	0x26: '\u0923',
+	0x27: '\u0924',
+	0x28: ')',
+	0x29: '(',
+	0x2A: '\u0925',
+	0x2B: '\u0926',
+	0x2C: ',',
+	0x2D: '\u0927',
+	0x2E: '.',
+	0x2F: '\u0928',
+	0x30: '0',
+	0x31: '1',
+	0x32: '2',
+	0x33: '3',
+	0x34: '4',
+	0x35: '5',
+	0x36: '6',
+	0x37: '7',
+	0x38: '8',
+	0x39: '9',
+	0x3A: ':',
+	0x3B: ';',
+	0x3C: '\u0929',
+	0x3D: '\u092A',
+	0x3E: '\u092B',
+	0x3F: '?',
+	0x40: '\u092C',
+	0x41: '\u092D',
+	0x42: '\u092E',
+	0x43: '\u092F',
+	0x44: '\u0930',
+	0x45: '\u0931',
+	0x46: '\u0932',
+	0x47: '\u0933',
+	0x48: '\u0934',
+	0x49: '\u0935',
+	0x4A: '\u0936',
+	0x4B: '\u0937',
+	0x4C: '\u0938',
+	0x4D: '\u0939',
+	0x4E: '\u093C',
+	0x4F: '\u093D',
+	0x50: '\u093E',
+	0x51: '\u093F',
+	0x52: '\u0940',
+	0x53: '\u0941',
+	0x54: '\u0942',
+	0x55: '\u0943',
+	0x56: '\u0944',
+	0x57: '\u0945',
+	0x58: '\u0946',
+	0x59: '\u0947',
+	0x5A: '\u0948',
+	0x5B: '\u0949',
+	0x5C: '\u094A',
+	0x5D: '\u094B',
+	0x5E: '\u094C',
+	0x5F: '\u094D',
+	0x60: '\u0950',
+	0x61: 'a',
+	0x62: 'b',
+	0x63: 'c',
+	0x64: 'd',
+	0x65: 'e',
+	0x66: 'f',
+	0x67: 'g',
+	0x68: 'h',
+	0x69: 'i',
+	0x6A: 'j',
+	0x6B: 'k',
+	0x6C: 'l',
+	0x6D: 'm',
+	0x6E: 'n',
+	0x6F: 'o',
+	0x70: 'p',
+	0x71: 'q',
+	0x72: 'r',
+	0x73: 's',
+	0x74: 't',
+	0x75: 'u',
+	0x76: 'v',
+	0x77: 'w',
+	0x78: 'x',
+	0x79: 'y',
+	0x7A: 'z',
+	0x7B: '\u0972',
+	0x7C: '\u097B',
+	0x7D: '\u097C',
+	0x7E: '\u097E',
+	0x7F: '\u097F',
+})
+
+// HindiSingleShift is the Hindi national language single shift table
+// (3GPP TS 23.038 A.2.6)
+var HindiSingleShift = NewTable(map[byte]rune{
+	0x00: '@',
+	0x01: '\u00A3',
+	0x02: '$',
+	0x03: '\u00A5',
+	0x04: '\u00BF',
+	0x05: '"',
+	0x06: '\u00A4',
+	0x07: '%',
+	0x08: '&',
+	0x09: '\'',
+	0x0A: '\f',
+	0x0B: '*',
+	0x0C: '+',
+	0x0E: '-',
+	0x0F: '/',
+	0x10: '<',
+	0x11: '=',
+	0x12: '>',
+	0x13: '\u00A1',
+	0x14: '^',
+	0x15: '\u00A1',
+	0x16: '_',
+	0x17: '#',
+	0x18: '*',
+	0x19: '\u0964',
+	0x1A: '\u0965',
+	0x1C: '\u0966',
+	0x1D: '\u0967',
+	0x1E: '\u0968',
+	0x1F: '\u0969',
+	0x20: '\u096A',
+	0x21: '\u096B',
+	0x22: '\u096C',
+	0x23: '\u096D',
+	0x24: '\u096E',
+	0x25: '\u096F',
+	0x26: '\u0951',
+	0x27: '\u0952',
+	0x28: '{',
+	0x29: '}',
+	0x2A: '\u0953',
+	0x2B: '\u0954',
+	0x2C: '\u0958',
+	0x2D: '\u0959',
+	0x2E: '\u095A',
+	0x2F: '\\',
+	0x30: '\u095B',
+	0x31: '\u095C',
+	0x32: '\u095D',
+	0x33: '\u095E',
+	0x34: '\u095F',
+	0x35: '\u0960',
+	0x36: '\u0961',
+	0x37: '\u0962',
+	0x38: '\u0963',
+	0x39: '\u0970',
+	0x3A: '\u0971',
+	0x3C: '[',
+	0x3D: '~',
+	0x3E: ']',
+	0x40: '|',
+	0x41: 'A',
+	0x42: 'B',
+	0x43: 'C',
+	0x44: 'D',
+	0x45: 'E',
+	0x46: 'F',
+	0x47: 'G',
+	0x48: 'H',
+	0x49: 'I',
+	0x4A: 'J',
+	0x4B: 'K',
+	0x4C: 'L',
+	0x4D: 'M',
+	0x4E: 'N',
+	0x4F: 'O',
+	0x50: 'P',
+	0x51: 'Q',
+	0x52: 'R',
+	0x53: 'S',
+	0x54: 'T',
+	0x55: 'U',
+	0x56: 'V',
+	0x57: 'W',
+	0x58: 'X',
+	0x59: 'Y',
+	0x5A: 'Z',
+	0x65: '\u20AC',
+})
+
+// KannadaLockingShift is the Kannada national language locking shift table
+// (3GPP TS 23.038 A.3.7)
+var KannadaLockingShift = NewTable(map[byte]rune{
+	0x01: '\u0C82',
+	0x02: '\u0C83',
+	0x03: '\u0C85',
+	0x04: '\u0C86',
+	0x05: '\u0C87',
+	0x06: '\u0C88',
+	0x07: '\u0C89',
+	0x08: '\u0C8A',
+	0x09: '\u0C8B',
+	0x0A: '\n',
+	0x0B: '\u0C8C',
+	0x0D: '\r',
+	0x0E: '\u0C8E',
+	0x0F: '\u0C8F',
+	0x10: '\u0C90',
+	0x12: '\u0C92',
+	0x13: '\u0C93',
+	0x14: '\u0C94',
+	0x15: '\u0C95',
+	0x16: '\u0C96',
+	0x17: '\u0C97',
+	0x18: '\u0C98',
+	0x19: '\u0C99',
+	0x1A: '\u0C9A',
+	0x1C: '\u0C9B',
+	0x1D: '\u0C9C',
+	0x1E: '\u0C9D',
+	0x1F: '\u0C9E',
+	0x20: ' ',
+	0x21: '!',
+	0x22: '\u0C9F',
+	0x23: '\u0CA0',
+	0x24: '\u0CA1',
+	0x25: '\u0CA2',
+	0x26: '\u0CA3',
+	0x27: '\u0CA4',
+	0x28: ')',
+	0x29: '(',
+	0x2A: '\u0CA5',
+	0x2B: '\u0CA6',
+	0x2C: ',',
+	0x2D: '\u0CA7',
+	0x2E: '.',
+	0x2F: '\u0CA8',
+	0x30: '0',
+	0x31: '1',
+	0x32: '2',
+	0x33: '3',
+	0x34: '4',
+	0x35: '5',
+	0x36: '6',
+	0x37: '7',
+	0x38: '8',
+	0x39: '9',
+	0x3A: ':',
+	0x3B: ';',
+	0x3D: '\u0CAA',
+	0x3E: '\u0CAB',
+	0x3F: '?',
+	0x40: '\u0CAC',
+	0x41: '\u0CAD',
+	0x42: '\u0CAE',
+	0x43: '\u0CAF',
+	0x44: '\u0CB0',
+	0x45: '\u0CB1',
+	0x46: '\u0CB2',
+	0x47: '\u0CB3',
+	0x49: '\u0CB5',
+	0x4A: '\u0CB6',
+	0x4B: '\u0CB7',
+	0x4C: '\u0CB8',
+	0x4D: '\u0CB9',
+	0x4E: '\u0CBC',
+	0x4F: '\u0CBD',
+	0x50: '\u0CBE',
+	0x51: '\u0CBF',
+	0x52: '\u0CC0',
+	0x53: '\u0CC1',
+	0x54: '\u0CC2',
+	0x55: '\u0CC3',
+	0x56: '\u0CC4',
+	0x58: '\u0CC6',
+	0x59: '\u0CC7',
+	0x5A: '\u0CC8',
+	0x5C: '\u0CCA',
+	0x5D: '\u0CCB',
+	0x5E: '\u0CCC',
+	0x5F: '\u0CCD',
+	0x60: '\u0CD5',
+	0x61: 'a',
+	0x62: 'b',
+	0x63: 'c',
+	0x64: 'd',
+	0x65: 'e',
+	0x66: 'f',
+	0x67: 'g',
+	0x68: 'h',
+	0x69: 'i',
+	0x6A: 'j',
+	0x6B: 'k',
+	0x6C: 'l',
+	0x6D: 'm',
+	0x6E: 'n',
+	0x6F: 'o',
+	0x70: 'p',
+	0x71: 'q',
+	0x72: 'r',
+	0x73: 's',
+	0x74: 't',
+	0x75: 'u',
+	0x76: 'v',
+	0x77: 'w',
+	0x78: 'x',
+	0x79: 'y',
+	0x7A: 'z',
+	0x7B: '\u0CD6',
+	0x7C: '\u0CE0',
+	0x7D: '\u0CE1',
+	0x7E: '\u0CE2',
+	0x7F: '\u0CE3',
+})
+
+// KannadaSingleShift is the Kannada national language single shift table
+// (3GPP TS 23.038 A.2.7)
+var KannadaSingleShift = NewTable(map[byte]rune{
+	0x00: '@',
+	0x01: '\u00A3',
+	0x02: '$',
+	0x03: '\u00A5',
+	0x04: '\u00BF',
+	0x05: '"',
+	0x06: '\u00A4',
+	0x07: '%',
+	0x08: '&',
+	0x09: '\'',
+	0x0A: '\f',
+	0x0B: '*',
+	0x0C: '+',
+	0x0E: '-',
+	0x0F: '/',
+	0x10: '<',
+	0x11: '=',
+	0x12: '>',
+	0x13: '\u00A1',
+	0x14: '^',
+	0x15: '\u00A1',
+	0x16: '_',
+	0x17: '#',
+	0x18: '*',
+	0x19: '\u0964',
+	0x1A: '\u0965',
+	0x1C: '\u0CE6',
+	0x1D: '\u0CE7',
+	0x1E: '\u0CE8',
+	0x1F: '\u0CE9',
+	0x20: '\u0CEA',
+	0x21: '\u0CEB',
+	0x22: '\u0CEC',
+	0x23: '\u0CED',
+	0x24: '\u0CEE',
+	0x25: '\u0CEF',
+	0x26: '\u0CDE',
+	0x27: '\u0CF1',
+	0x28: '{',
+	0x29: '}',
+	0x2A: '\u0CF2',
+	0x2F: '\\',
+	0x3C: '[',
+	0x3D: '~',
+	0x3E: ']',
+	0x40: '|',
+	0x41: 'A',
+	0x42: 'B',
+	0x43: 'C',
+	0x44: 'D',
+	0x45: 'E',
+	0x46: 'F',
+	0x47: 'G',
+	0x48: 'H',
+	0x49: 'I',
+	0x4A: 'J',
+	0x4B: 'K',
+	0x4C: 'L',
+	0x4D: 'M',
+	0x4E: 'N',
+	0x4F: 'O',
+	0x50: 'P',
+	0x51: 'Q',
+	0x52: 'R',
+	0x53: 'S',
+	0x54: 'T',
+	0x55: 'U',
+	0x56: 'V',
+	0x57: 'W',
+	0x58: 'X',
+	0x59: 'Y',
+	0x5A: 'Z',
+	0x65: '\u20AC',
+})
+
+// MalayalamLockingShift is the Malayalam national language locking shift table
+// (3GPP TS 23.038 A.3.8)
+var MalayalamLockingShift = NewTable(map[byte]rune{
+	0x01: '\u0D02',
+	0x02: '\u0D03',
+	0x03: '\u0D05',
+	0x04: '\u0D06',
+	0x05: '\u0D07',
+	0x06: '\u0D08',
+	0x07: '\u0D09',
+	0x08: '\u0D0A',
+	0x09: '\u0D0B',
+	0x0A: '\n',
+	0x0B: '\u0D0C',
+	0x0D: '\r',
+	0x0E: '\u0D0E',
+	0x0F: '\u0D0F',
+	0x10: '\u0D10',
+	0x12: '\u0D12',
+	0x13: '\u0D13',
+	0x14: '\u0D14',
+	0x15: '\u0D15',
+	0x16: '\u0D16',
+	0x17: '\u0D17',
+	0x18: '\u0D18',
+	0x19: '\u0D19',
+	0x1A: '\u0D1A',
+	0x1C: '\u0D1B',
+	0x1D: '\u0D1C',
+	0x1E: '\u0D1D',
+	0x1F: '\u0D1E',
+	0x20: ' ',
+	0x21: '!',
+	0x22: '\u0D1F',
+	0x23: '\u0D20',
+	0x24: '\u0D21',
+	0x25: '\u0D22',
+	0x26: '\u0D23',
+	0x27: '\u0D24',
+	0x28: ')',
+	0x29: '(',
+	0x2A: '\u0D25',
+	0x2B: '\u0D26',
+	0x2C: ',',
+	0x2D: '\u0D27',
+	0x2E: '.',
+	0x2F: '\u0D28',
+	0x30: '0',
+	0x31: '1',
+	0x32: '2',
+	0x33: '3',
+	0x34: '4',
+	0x35: '5',
+	0x36: '6',
+	0x37: '7',
+	0x38: '8',
+	0x39: '9',
+	0x3A: ':',
+	0x3B: ';',
+	0x3D: '\u0D2A',
+	0x3E: '\u0D2B',
+	0x3F: '?',
+	0x40: '\u0D2C',
+	0x41: '\u0D2D',
+	0x42: '\u0D2E',
+	0x43: '\u0D2F',
+	0x44: '\u0D30',
+	0x45: '\u0D31',
+	0x46: '\u0D32',
+	0x47: '\u0D33',
+	0x48: '\u0D34',
+	0x49: '\u0D35',
+	0x4A: '\u0D36',
+	0x4B: '\u0D37',
+	0x4C: '\u0D38',
+	0x4D: '\u0D39',
+	0x4F: '\u0D3D',
+	0x50: '\u0D3E',
+	0x51: '\u0D3F',
+	0x52: '\u0D40',
+	0x53: '\u0D41',
+	0x54: '\u0D42',
+	0x55: '\u0D43',
+	0x56: '\u0D44',
+	0x58: '\u0D46',
+	0x59: '\u0D47',
+	0x5A: '\u0D48',
+	0x5C: '\u0D4A',
+	0x5D: '\u0D4B',
+	0x5E: '\u0D4C',
+	0x5F: '\u0D4D',
+	0x60: '\u0D57',
+	0x61: 'a',
+	0x62: 'b',
+	0x63: 'c',
+	0x64: 'd',
+	0x65: 'e',
+	0x66: 'f',
+	0x67: 'g',
+	0x68: 'h',
+	0x69: 'i',
+	0x6A: 'j',
+	0x6B: 'k',
+	0x6C: 'l',
+	0x6D: 'm',
+	0x6E: 'n',
+	0x6F: 'o',
+	0x70: 'p',
+	0x71: 'q',
+	0x72: 'r',
+	0x73: 's',
+	0x74: 't',
+	0x75: 'u',
+	0x76: 'v',
+	0x77: 'w',
+	0x78: 'x',
+	0x79: 'y',
+	0x7A: 'z',
+	0x7B: '\u0D60',
+	0x7C: '\u0D61',
+	0x7D: '\u0D62',
+	0x7E: '\u0D63',
+	0x7F: '\u0D79',
+})
+
+// MalayalamSingleShift is the Malayalam national language single shift table
+// (3GPP TS 23.038 A.2.8)
+var MalayalamSingleShift = NewTable(map[byte]rune{
+	0x00: '@',
+	0x01: '\u00A3',
+	0x02: '$',
+	0x03: '\u00A5',
+	0x04: '\u00BF',
+	0x05: '"',
+	0x06: '\u00A4',
+	0x07: '%',
+	0x08: '&',
+	0x09: '\'',
+	0x0A: '\f',
+	0x0B: '*',
+	0x0C: '+',
+	0x0E: '-',
+	0x0F: '/',
+	0x10: '<',
+	0x11: '=',
+	0x12: '>',
+	0x13: '\u00A1',
+	0x14: '^',
+	0x15: '\u00A1',
+	0x16: '_',
+	0x17: '#',
+	0x18: '*',
+	0x19: '\u0964',
+	0x1A: '\u0965',
+	0x1C: '\u0D66',
+	0x1D: '\u0D67',
+	0x1E: '\u0D68',
+	0x1F: '\u0D69',
+	0x20: '\u0D6A',
+	0x21: '\u0D6B',
+	0x22: '\u0D6C',
+	0x23: '\u0D6D',
+	0x24: '\u0D6E',
+	0x25: '\u0D6F',
+	0x26: '\u0D70',
+	0x27: '\u0D71',
+	0x28: '{',
+	0x29: '}',
+	0x2A: '\u0D72',
+	0x2B: '\u0D73',
+	0x2C: '\u0D74',
+	0x2D: '\u0D75',
+	0x2E: '\u0D7A',
+	0x2F: '\\',
+	0x30: '\u0D7B',
+	0x31: '\u0D7C',
+	0x32: '\u0D7D',
+	0x33: '\u0D7E',
+	0x34: '\u0D7F',
+	0x3C: '[',
+	0x3D: '~',
+	0x3E: ']',
+	0x40: '|',
+	0x41: 'A',
+	0x42: 'B',
+	0x43: 'C',
+	0x44: 'D',
+	0x45: 'E',
+	0x46: 'F',
+	0x47: 'G',
+	0x48: 'H',
+	0x49: 'I',
+	0x4A: 'J',
+	0x4B: 'K',
+	0x4C: 'L',
+	0x4D: 'M',
+	0x4E: 'N',
+	0x4F: 'O',
+	0x50: 'P',
+	0x51: 'Q',
+	0x52: 'R',
+	0x53: 'S',
+	0x54: 'T',
+	0x55: 'U',
+	0x56: 'V',
+	0x57: 'W',
+	0x58: 'X',
+	0x59: 'Y',
+	0x5A: 'Z',
+	0x65: '\u20AC',
+})
+
+// OriyaLockingShift is the Oriya national language locking shift table
+// (3GPP TS 23.038 A.3.9)
+var OriyaLockingShift = NewTable(map[byte]rune{
+	0x00: '\u0B01',
+	0x01: '\u0B02',
+	0x02: '\u0B03',
+	0x03: '\u0B05',
+	0x04: '\u0B06',
+	0x05: '\u0B07',
+	0x06: '\u0B08',
+	0x07: '\u0B09',
+	0x08: '\u0B0A',
+	0x09: '\u0B0B',
+	0x0A: '\n',
+	0x0B: '\u0B0C',
+	0x0D: '\r',
+	0x0F: '\u0B0F',
+	0x10: '\u0B10',
+	0x13: '\u0B13',
+	0x14: '\u0B14',
+	0x15: '\u0B15',
+	0x16: '\u0B16',
+	0x17: '\u0B17',
+	0x18: '\u0B18',
+	0x19: '\u0B19',
+	0x1A: '\u0B1A',
+	0x1C: '\u0B1B',
+	0x1D: '\u0B1C',
+	0x1E: '\u0B1D',
+	0x1F: '\u0B1E',
+	0x20: ' ',
+	0x21: '!',
+	0x22: '\u0B1F',
+	0x23: '\u0B20',
+	0x24: '\u0B21',
+	0x25: '\u0B22',
+	0x26: '\u0B23',
+	0x27: '\u0B24',
+	0x28: ')',
+	0x29: '(',
+	0x2A: '\u0B25',
+	0x2B: '\u0B26',
+	0x2C: ',',
+	0x2D: '\u0B27',
+	0x2E: '.',
+	0x2F: '\u0B28',
+	0x30: '0',
+	0x31: '1',
+	0x32: '2',
+	0x33: '3',
+	0x34: '4',
+	0x35: '5',
+	0x36: '6',
+	0x37: '7',
+	0x38: '8',
+	0x39: '9',
+	0x3A: ':',
+	0x3B: ';',
+	0x3D: '\u0B2A',
+	0x3E: '\u0B2B',
+	0x3F: '?',
+	0x40: '\u0B2C',
+	0x41: '\u0B2D',
+	0x42: '\u0B2E',
+	0x43: '\u0B2F',
+	0x44: '\u0B30',
+	0x46: '\u0B32',
+	0x47: '\u0B33',
+	0x49: '\u0B35',
+	0x4A: '\u0B36',
+	0x4B: '\u0B37',
+	0x4C: '\u0B38',
+	0x4D: '\u0B39',
+	0x4E: '\u0B3C',
+	0x4F: '\u0B3D',
+	0x50: '\u0B3E',
+	0x51: '\u0B3F',
+	0x52: '\u0B40',
+	0x53: '\u0B41',
+	0x54: '\u0B42',
+	0x55: '\u0B43',
+	0x56: '\u0B44',
+	0x59: '\u0B47',
+	0x5A: '\u0B48',
+	0x5D: '\u0B4B',
+	0x5E: '\u0B4C',
+	0x5F: '\u0B4D',
+	0x60: '\u0B56',
+	0x61: 'a',
+	0x62: 'b',
+	0x63: 'c',
+	0x64: 'd',
+	0x65: 'e',
+	0x66: 'f',
+	0x67: 'g',
+	0x68: 'h',
+	0x69: 'i',
+	0x6A: 'j',
+	0x6B: 'k',
+	0x6C: 'l',
+	0x6D: 'm',
+	0x6E: 'n',
+	0x6F: 'o',
+	0x70: 'p',
+	0x71: 'q',
+	0x72: 'r',
+	0x73: 's',
+	0x74: 't',
+	0x75: 'u',
+	0x76: 'v',
+	0x77: 'w',
+	0x78: 'x',
+	0x79: 'y',
+	0x7A: 'z',
+	0x7B: '\u0B57',
+	0x7C: '\u0B60',
+	0x7D: '\u0B61',
+	0x7E: '\u0B62',
+	0x7F: '\u0B63',
+})
+
+// OriyaSingleShift is the Oriya national language single shift table
+// (3GPP TS 23.038 A.2.9)
+var OriyaSingleShift = NewTable(map[byte]rune{
+	0x00: '@',
+	0x01: '\u00A3',
+	0x02: '$',
+	0x03: '\u00A5',
+	0x04: '\u00BF',
+	0x05: '"',
+	0x06: '\u00A4',
+	0x07: '%',
+	0x08: '&',
+	0x09: '\'',
+	0x0A: '\f',
+	0x0B: '*',
+	0x0C: '+',
+	0x0E: '-',
+	0x0F: '/',
+	0x10: '<',
+	0x11: '=',
+	0x12: '>',
+	0x13: '\u00A1',
+	0x14: '^',
+	0x15: '\u00A1',
+	0x16: '_',
+	0x17: '#',
+	0x18: '*',
+	0x19: '\u0964',
+	0x1A: '\u0965',
+	0x1C: '\u0B66',
+	0x1D: '\u0B67',
+	0x1E: '\u0B68',
+	0x1F: '\u0B69',
+	0x20: '\u0B6A',
+	0x21: '\u0B6B',
+	0x22: '\u0B6C',
+	0x23: '\u0B6D',
+	0x24: '\u0B6E',
+	0x25: '\u0B6F',
+	0x26: '\u0B5C',
+	0x27: '\u0B5D',
+	0x28: '{',
+	0x29: '}',
+	0x2A: '\u0B5F',
+	0x2B: '\u0B70',
+	0x2C: '\u0B71',
+	0x2F: '\\',
+	0x3C: '[',
+	0x3D: '~',
+	0x3E: ']',
+	0x40: '|',
+	0x41: 'A',
+	0x42: 'B',
+	0x43: 'C',
+	0x44: 'D',
+	0x45: 'E',
+	0x46: 'F',
+	0x47: 'G',
+	0x48: 'H',
+	0x49: 'I',
+	0x4A: 'J',
+	0x4B: 'K',
+	0x4C: 'L',
+	0x4D: 'M',
+	0x4E: 'N',
+	0x4F: 'O',
+	0x50: 'P',
+	0x51: 'Q',
+	0x52: 'R',
+	0x53: 'S',
+	0x54: 'T',
+	0x55: 'U',
+	0x56: 'V',
+	0x57: 'W',
+	0x58: 'X',
+	0x59: 'Y',
+	0x5A: 'Z',
+	0x65: '\u20AC',
+})
+
+// PunjabiLockingShift is the Punjabi national language locking shift table
+// (3GPP TS 23.038 A.3.10)
+var PunjabiLockingShift = NewTable(map[byte]rune{
+	0x00: '\u0A01',
+	0x01: '\u0A02',
+	0x02: '\u0A03',
+	0x03: '\u0A05',
+	0x04: '\u0A06',
+	0x05: '\u0A07',
+	0x06: '\u0A08',
+	0x07: '\u0A09',
+	0x08: '\u0A0A',
+	0x0A: '\n',
+	0x0D: '\r',
+	0x0F: '\u0A0F',
+	0x10: '\u0A10',
+	0x13: '\u0A13',
+	0x14: '\u0A14',
+	0x15: '\u0A15',
+	0x16: '\u0A16',
+	0x17: '\u0A17',
+	0x18: '\u0A18',
+	0x19: '\u0A19',
+	0x1A: '\u0A1A',
+	0x1C: '\u0A1B',
+	0x1D: '\u0A1C',
+	0x1E: '\u0A1D',
+	0x1F: '\u0A1E',
+	0x20: ' ',
+	0x21: '!',
+	0x22: '\u0A1F',
+	0x23: '\u0A20',
+	0x24: '\u0A21',
+	0x25: '\u0A22',
+	0x26: '\u0A23',
+	0x27: '\u0A24',
+	0x28: ')',
+	0x29: '(',
+	0x2A: '\u0A25',
+	0x2B: '\u0A26',
+	0x2C: ',',
+	0x2D: '\u0A27',
+	0x2E: '.',
+	0x2F: '\u0A28',
+	0x30: '0',
+	0x31: '1',
+	0x32: '2',
+	0x33: '3',
+	0x34: '4',
+	0x35: '5',
+	0x36: '6',
+	0x37: '7',
+	0x38: '8',
+	0x39: '9',
+	0x3A: ':',
+	0x3B: ';',
+	0x3D: '\u0A2A',
+	0x3E: '\u0A2B',
+	0x3F: '?',
+	0x40: '\u0A2C',
+	0x41: '\u0A2D',
+	0x42: '\u0A2E',
+	0x43: '\u0A2F',
+	0x44: '\u0A30',
+	0x46: '\u0A32',
+	0x47: '\u0A33',
+	0x49: '\u0A35',
+	0x4A: '\u0A36',
+	0x4C: '\u0A38',
+	0x4D: '\u0A39',
+	0x4E: '\u0A3C',
+	0x50: '\u0A3E',
+	0x51: '\u0A3F',
+	0x52: '\u0A40',
+	0x53: '\u0A41',
+	0x54: '\u0A42',
+	0x59: '\u0A47',
+	0x5A: '\u0A48',
+	0x5D: '\u0A4B',
+	0x5E: '\u0A4C',
+	0x5F: '\u0A4D',
+	0x60: '\u0A51',
+	0x61: 'a',
+	0x62: 'b',
+	0x63: 'c',
+	0x64: 'd',
+	0x65: 'e',
+	0x66: 'f',
+	0x67: 'g',
+	0x68: 'h',
+	0x69: 'i',
+	0x6A: 'j',
+	0x6B: 'k',
+	0x6C: 'l',
+	0x6D: 'm',
+	0x6E: 'n',
+	0x6F: 'o',
+	0x70: 'p',
+	0x71: 'q',
+	0x72: 'r',
+	0x73: 's',
+	0x74: 't',
+	0x75: 'u',
+	0x76: 'v',
+	0x77: 'w',
+	0x78: 'x',
+	0x79: 'y',
+	0x7A: 'z',
+	0x7B: '\u0A70',
+	0x7C: '\u0A71',
+	0x7D: '\u0A72',
+	0x7E: '\u0A73',
+	0x7F: '\u0A74',
+})
+
+// PunjabiSingleShift is the Punjabi national language single shift table
+// (3GPP TS 23.038 A.2.10)
+var PunjabiSingleShift = NewTable(map[byte]rune{
+	0x00: '@',
+	0x01: '\u00A3',
+	0x02: '$',
+	0x03: '\u00A5',
+	0x04: '\u00BF',
+	0x05: '"',
+	0x06: '\u00A4',
+	0x07: '%',
+	0x08: '&',
+	0x09: '\'',
+	0x0A: '\f',
+	0x0B: '*',
+	0x0C: '+',
+	0x0E: '-',
+	0x0F: '/',
+	0x10: '<',
+	0x11: '=',
+	0x12: '>',
+	0x13: '\u00A1',
+	0x14: '^',
+	0x15: '\u00A1',
+	0x16: '_',
+	0x17: '#',
+	0x18: '*',
+	0x19: '\u0964',
+	0x1A: '\u0965',
+	0x1C: '\u0A66',
+	0x1D: '\u0A67',
+	0x1E: '\u0A68',
+	0x1F: '\u0A69',
+	0x20: '\u0A6A',
+	0x21: '\u0A6B',
+	0x22: '\u0A6C',
+	0x23: '\u0A6D',
+	0x24: '\u0A6E',
+	0x25: '\u0A6F',
+	0x26: '\u0A59',
+	0x27: '\u0A5A',
+	0x28: '{',
+	0x29: '}',
+	0x2A: '\u0A5B',
+	0x2B: '\u0A5C',
+	0x2C: '\u0A5E',
+	0x2D: '\u0A75',
+	0x2F: '\\',
+	0x3C: '[',
+	0x3D: '~',
+	0x3E: ']',
+	0x40: '|',
+	0x41: 'A',
+	0x42: 'B',
+	0x43: 'C',
+	0x44: 'D',
+	0x45: 'E',
+	0x46: 'F',
+	0x47: 'G',
+	0x48: 'H',
+	0x49: 'I',
+	0x4A: 'J',
+	0x4B: 'K',
+	0x4C: 'L',
+	0x4D: 'M',
+	0x4E: 'N',
+	0x4F: 'O',
+	0x50: 'P',
+	0x51: 'Q',
+	0x52: 'R',
+	0x53: 'S',
+	0x54: 'T',
+	0x55: 'U',
+	0x56: 'V',
+	0x57: 'W',
+	0x58: 'X',
+	0x59: 'Y',
+	0x5A: 'Z',
+	0x65: '\u20AC',
+})
+
+// TamilLockingShift is the Tamil national language locking shift table
+// (3GPP TS 23.038 A.3.11)
+var TamilLockingShift = NewTable(map[byte]rune{
+	0x01: '\u0B82',
+	0x02: '\u0B83',
+	0x03: '\u0B85',
+	0x04: '\u0B86',
+	0x05: '\u0B87',
+	0x06: '\u0B88',
+	0x07: '\u0B89',
+	0x08: '\u0B8A',
+	0x0A: '\n',
+	0x0D: '\r',
+	0x0E: '\u0B8E',
+	0x0F: '\u0B8F',
+	0x10: '\u0B90',
+	0x12: '\u0B92',
+	0x13: '\u0B93',
+	0x14: '\u0B94',
+	0x15: '\u0B95',
+	0x19: '\u0B99',
+	0x1A: '\u0B9A',
+	0x1D: '\u0B9C',
+	0x1F: '\u0B9E',
+	0x20: ' ',
+	0x21: '!',
+	0x22: '\u0B9F',
+	0x26: '\u0BA3',
+	0x27: '\u0BA4',
+	0x28: ')',
+	0x29: '(',
+	0x2C: ',',
+	0x2E: '.',
+	0x2F: '\u0BA8',
+	0x30: '0',
+	0x31: '1',
+	0x32: '2',
+	0x33: '3',
+	0x34: '4',
+	0x35: '5',
+	0x36: '6',
+	0x37: '7',
+	0x38: '8',
+	0x39: '9',
+	0x3A: ':',
+	0x3B: ';',
+	0x3C: '\u0BA9',
+	0x3D: '\u0BAA',
+	0x3F: '?',
+	0x42: '\u0BAE',
+	0x43: '\u0BAF',
+	0x44: '\u0BB0',
+	0x45: '\u0BB1',
+	0x46: '\u0BB2',
+	0x47: '\u0BB3',
+	0x48: '\u0BB4',
+	0x49: '\u0BB5',
+	0x4A: '\u0BB6',
+	0x4B: '\u0BB7',
+	0x4C: '\u0BB8',
+	0x4D: '\u0BB9',
+	0x50: '\u0BBE',
+	0x51: '\u0BBF',
+	0x52: '\u0BC0',
+	0x53: '\u0BC1',
+	0x54: '\u0BC2',
+	0x58: '\u0BC6',
+	0x59: '\u0BC7',
+	0x5A: '\u0BC8',
+	0x5C: '\u0BCA',
+	0x5D: '\u0BCB',
+	0x5E: '\u0BCC',
+	0x5F: '\u0BCD',
+	0x60: '\u0BD0',
+	0x61: 'a',
+	0x62: 'b',
+	0x63: 'c',
+	0x64: 'd',
+	0x65: 'e',
+	0x66: 'f',
+	0x67: 'g',
+	0x68: 'h',
+	0x69: 'i',
+	0x6A: 'j',
+	0x6B: 'k',
+	0x6C: 'l',
+	0x6D: 'm',
+	0x6E: 'n',
+	0x6F: 'o',
+	0x70: 'p',
+	0x71: 'q',
+	0x72: 'r',
+	0x73: 's',
+	0x74: 't',
+	0x75: 'u',
+	0x76: 'v',
+	0x77: 'w',
+	0x78: 'x',
+	0x79: 'y',
+	0x7A: 'z',
+	0x7B: '\u0BD7',
+	0x7C: '\u0BF0',
+	0x7D: '\u0BF1',
+	0x7E: '\u0BF2',
+	0x7F: '\u0BF9',
+})
+
+// TamilSingleShift is the Tamil national language single shift table
+// (3GPP TS 23.038 A.2.11)
+var TamilSingleShift = NewTable(map[byte]rune{
+	0x00: '@',
+	0x01: '\u00A3',
+	0x02: '$',
+	0x03: '\u00A5',
+	0x04: '\u00BF',
+	0x05: '"',
+	0x06: '\u00A4',
+	0x07: '%',
+	0x08: '&',
+	0x09: '\'',
+	0x0A: '\f',
+	0x0B: '*',
+	0x0C: '+',
+	0x0E: '-',
+	0x0F: '/',
+	0x10: '<',
+	0x11: '=',
+	0x12: '>',
+	0x13: '\u00A1',
+	0x14: '^',
+	0x15: '\u00A1',
+	0x16: '_',
+	0x17: '#',
+	0x18: '*',
+	0x19: '\u0964',
+	0x1A: '\u0965',
+	0x1C: '\u0BE6',
+	0x1D: '\u0BE7',
+	0x1E: '\u0BE8',
+	0x1F: '\u0BE9',
+	0x20: '\u0BEA',
+	0x21: '\u0BEB',
+	0x22: '\u0BEC',
+	0x23: '\u0BED',
+	0x24: '\u0BEE',
+	0x25: '\u0BEF',
+	0x26: '\u0BF3',
+	0x27: '\u0BF4',
+	0x28: '{',
+	0x29: '}',
+	0x2A: '\u0BF5',
+	0x2B: '\u0BF6',
+	0x2C: '\u0BF7',
+	0x2D: '\u0BF8',
+	0x2E: '\u0BFA',
+	0x2F: '\\',
+	0x3C: '[',
+	0x3D: '~',
+	0x3E: ']',
+	0x40: '|',
+	0x41: 'A',
+	0x42: 'B',
+	0x43: 'C',
+	0x44: 'D',
+	0x45: 'E',
+	0x46: 'F',
+	0x47: 'G',
+	0x48: 'H',
+	0x49: 'I',
+	0x4A: 'J',
+	0x4B: 'K',
+	0x4C: 'L',
+	0x4D: 'M',
+	0x4E: 'N',
+	0x4F: 'O',
+	0x50: 'P',
+	0x51: 'Q',
+	0x52: 'R',
+	0x53: 'S',
+	0x54: 'T',
+	0x55: 'U',
+	0x56: 'V',
+	0x57: 'W',
+	0x58: 'X',
+	0x59: 'Y',
+	0x5A: 'Z',
+	0x65: '\u20AC',
+})
+
+// TeluguLockingShift is the Telugu national language locking shift table
+// (3GPP TS 23.038 A.3.12)
+var TeluguLockingShift = NewTable(map[byte]rune{
+	0x00: '\u0C01',
+	0x01: '\u0C02',
+	0x02: '\u0C03',
+	0x03: '\u0C05',
+	0x04: '\u0C06',
+	0x05: '\u0C07',
+	0x06: '\u0C08',
+	0x07: '\u0C09',
+	0x08: '\u0C0A',
+	0x09: '\u0C0B',
+	0x0A: '\n',
+	0x0B: '\u0C0C',
+	0x0D: '\r',
+	0x0E: '\u0C0E',
+	0x0F: '\u0C0F',
+	0x10: '\u0C10',
+	0x12: '\u0C12',
+	0x13: '\u0C13',
+	0x14: '\u0C14',
+	0x15: '\u0C15',
+	0x16: '\u0C16',
+	0x17: '\u0C17',
+	0x18: '\u0C18',
+	0x19: '\u0C19',
+	0x1A: '\u0C1A',
+	0x1C: '\u0C1B',
+	0x1D: '\u0C1C',
+	0x1E: '\u0C1D',
+	0x1F: '\u0C1E',
+	0x20: ' ',
+	0x21: '!',
+	0x22: '\u0C1F',
+	0x23: '\u0C20',
+	0x24: '\u0C21',
+	0x25: '\u0C22',
+	0x26: '\u0C23',
+	0x27: '\u0C24',
+	0x28: ')',
+	0x29: '(',
+	0x2A: '\u0C25',
+	0x2B: '\u0C26',
+	0x2C: ',',
+	0x2D: '\u0C27',
+	0x2E: '.',
+	0x2F: '\u0C28',
+	0x30: '0',
+	0x31: '1',
+	0x32: '2',
+	0x33: '3',
+	0x34: '4',
+	0x35: '5',
+	0x36: '6',
+	0x37: '7',
+	0x38: '8',
+	0x39: '9',
+	0x3A: ':',
+	0x3B: ';',
+	0x3D: '\u0C2A',
+	0x3E: '\u0C2B',
+	0x3F: '?',
+	0x40: '\u0C2C',
+	0x41: '\u0C2D',
+	0x42: '\u0C2E',
+	0x43: '\u0C2F',
+	0x44: '\u0C30',
+	0x45: '\u0C31',
+	0x46: '\u0C32',
+	0x47: '\u0C33',
+	0x49: '\u0C35',
+	0x4A: '\u0C36',
+	0x4B: '\u0C37',
+	0x4C: '\u0C38',
+	0x4D: '\u0C39',
+	0x4F: '\u0C3D',
+	0x50: '\u0C3E',
+	0x51: '\u0C3F',
+	0x52: '\u0C40',
+	0x53: '\u0C41',
+	0x54: '\u0C42',
+	0x55: '\u0C43',
+	0x56: '\u0C44',
+	0x58: '\u0C46',
+	0x59: '\u0C47',
+	0x5A: '\u0C48',
+	0x5C: '\u0C4A',
+	0x5D: '\u0C4B',
+	0x5E: '\u0C4C',
+	0x5F: '\u0C4D',
+	0x60: '\u0C55',
+	0x61: 'a',
+	0x62: 'b',
+	0x63: 'c',
+	0x64: 'd',
+	0x65: 'e',
+	0x66: 'f',
+	0x67: 'g',
+	0x68: 'h',
+	0x69: 'i',
+	0x6A: 'j',
+	0x6B: 'k',
+	0x6C: 'l',
+	0x6D: 'm',
+	0x6E: 'n',
+	0x6F: 'o',
+	0x70: 'p',
+	0x71: 'q',
+	0x72: 'r',
+	0x73: 's',
+	0x74: 't',
+	0x75: 'u',
+	0x76: 'v',
+	0x77: 'w',
+	0x78: 'x',
+	0x79: 'y',
+	0x7A: 'z',
+	0x7B: '\u0C56',
+	0x7C: '\u0C60',
+	0x7D: '\u0C61',
+	0x7E: '\u0C62',
+	0x7F: '\u0C63',
+})
+
+// TeluguSingleShift is the Telugu national language single shift table
+// (3GPP TS 23.038 A.2.12)
+var TeluguSingleShift = NewTable(map[byte]rune{
+	0x00: '@',
+	0x01: '\u00A3',
+	0x02: '$',
+	0x03: '\u00A5',
+	0x04: '\u00BF',
+	0x05: '"',
+	0x06: '\u00A4',
+	0x07: '%',
+	0x08: '&',
+	0x09: '\'',
+	0x0A: '\f',
+	0x0B: '*',
+	0x0C: '+',
+	0x0E: '-',
+	0x0F: '/',
+	0x10: '<',
+	0x11: '=',
+	0x12: '>',
+	0x13: '\u00A1',
+	0x14: '^',
+	0x15: '\u00A1',
+	0x16: '_',
+	0x17: '#',
+	0x18: '*',
+	0x1C: '\u0C66',
+	0x1D: '\u0C67',
+	0x1E: '\u0C68',
+	0x1F: '\u0C69',
+	0x20: '\u0C6A',
+	0x21: '\u0C6B',
+	0x22: '\u0C6C',
+	0x23: '\u0C6D',
+	0x24: '\u0C6E',
+	0x25: '\u0C6F',
+	0x26: '\u0C58',
+	0x27: '\u0C59',
+	0x28: '{',
+	0x29: '}',
+	0x2A: '\u0C78',
+	0x2B: '\u0C79',
+	0x2C: '\u0C7A',
+	0x2D: '\u0C7B',
+	0x2E: '\u0C7C',
+	0x2F: '\\',
+	0x30: '\u0C7D',
+	0x31: '\u0C7E',
+	0x32: '\u0C7F',
+	0x3C: '[',
+	0x3D: '~',
+	0x3E: ']',
+	0x40: '|',
+	0x41: 'A',
+	0x42: 'B',
+	0x43: 'C',
+	0x44: 'D',
+	0x45: 'E',
+	0x46: 'F',
+	0x47: 'G',
+	0x48: 'H',
+	0x49: 'I',
+	0x4A: 'J',
+	0x4B: 'K',
+	0x4C: 'L',
+	0x4D: 'M',
+	0x4E: 'N',
+	0x4F: 'O',
+	0x50: 'P',
+	0x51: 'Q',
+	0x52: 'R',
+	0x53: 'S',
+	0x54: 'T',
+	0x55: 'U',
+	0x56: 'V',
+	0x57: 'W',
+	0x58: 'X',
+	0x59: 'Y',
+	0x5A: 'Z',
+	0x65: '\u20AC',
+})
+
+// UrduLockingShift is the Urdu national language locking shift table
+// (3GPP TS 23.038 A.3.13)
+var UrduLockingShift = NewTable(map[byte]rune{
+	0x00: '\u0627',
+	0x01: '\u0622',
+	0x02: '\u0628',
+	0x03: '\u067B',
+	0x04: '\u0680',
+	0x05: '\u067E',
+	0x06: '\u06A6',
+	0x07: '\u062A',
+	0x08: '\u06C2',
+	0x09: '\u067F',
+	0x0A: '\n',
+	0x0B: '\u0679',
+	0x0C: '\u067D',
+	0x0D: '\r',
+	0x0E: '\u067A',
+	0x0F: '\u067C',
+	0x10: '\u062B',
+	0x11: '\u062C',
+	0x12: '\u0681',
+	0x13: '\u0684',
+	0x14: '\u0683',
+	0x15: '\u0685',
+	0x16: '\u0686',
+	0x17: '\u0687',
+	0x18: '\u062D',
+	0x19: '\u062E',
+	0x1A: '\u062F',
+	0x1C: '\u068C',
+	0x1D: '\u0688',
+	0x1E: '\u0689',
+	0x1F: '\u068A',
+	0x20: ' ',
+	0x21: '!',
+	0x22: '\u068F',
+	0x23: '\u068D',
+	0x24: '\u0630',
+	0x25: '\u0631',
+	0x26: '\u0691',
+	0x27: '\u0693',
+	0x28: ')',
+	0x29: '(',
+	0x2A: '\u0699',
+	0x2B: '\u0632',
+	0x2C: ',',
+	0x2D: '\u0696',
+	0x2E: '.',
+	0x2F: '\u0698',
+	0x30: '0',
+	0x31: '1',
+	0x32: '2',
+	0x33: '3',
+	0x34: '4',
+	0x35: '5',
+	0x36: '6',
+	0x37: '7',
+	0x38: '8',
+	0x39: '9',
+	0x3A: ':',
+	0x3B: ';',
+	0x3C: '\u069A',
+	0x3D: '\u0633',
+	0x3E: '\u0634',
+	0x3F: '?',
+	0x40: '\u0635',
+	0x41: '\u0636',
+	0x42: '\u0637',
+	0x43: '\u0638',
+	0x44: '\u0639',
+	0x45: '\u0641',
+	0x46: '\u0642',
+	0x47: '\u06A9',
+	0x48: '\u06AA',
+	0x49: '\u06AB',
+	0x4A: '\u06AF',
+	0x4B: '\u06B3',
+	0x4C: '\u06B1',
+	0x4D: '\u0644',
+	0x4E: '\u0645',
+	0x4F: '\u0646',
+	0x50: '\u06BA',
+	0x51: '\u06BB',
+	0x52: '\u06BC',
+	0x53: '\u0648',
+	0x54: '\u06C4',
+	0x55: '\u06D5',
+	0x56: '\u06C1',
+	0x57: '\u06BE',
+	0x58: '\u0621',
+	0x59: '\u06CC',
+	0x5A: '\u06D0',
+	0x5B: '\u06D2',
+	0x5C: '\u064D',
+	0x5D: '\u0650',
+	0x5E: '\u064F',
+	0x5F: '\u0657',
+	0x60: '\u0654',
+	0x61: 'a',
+	0x62: 'b',
+	0x63: 'c',
+	0x64: 'd',
+	0x65: 'e',
+	0x66: 'f',
+	0x67: 'g',
+	0x68: 'h',
+	0x69: 'i',
+	0x6A: 'j',
+	0x6B: 'k',
+	0x6C: 'l',
+	0x6D: 'm',
+	0x6E: 'n',
+	0x6F: 'o',
+	0x70: 'p',
+	0x71: 'q',
+	0x72: 'r',
+	0x73: 's',
+	0x74: 't',
+	0x75: 'u',
+	0x76: 'v',
+	0x77: 'w',
+	0x78: 'x',
+	0x79: 'y',
+	0x7A: 'z',
+	0x7B: '\u0655',
+	0x7C: '\u0651',
+	0x7D: '\u0653',
+	0x7E: '\u0656',
+	0x7F: '\u0670',
+})
+
+// UrduSingleShift is the Urdu national language single shift table
+// (3GPP TS 23.038 A.2.13)
+var UrduSingleShift = NewTable(map[byte]rune{
+	0x00: '@',
+	0x01: '\u00A3',
+	0x02: '$',
+	0x03: '\u00A5',
+	0x04: '\u00BF',
+	0x05: '"',
+	0x06: '\u00A4',
+	0x07: '%',
+	0x08: '&',
+	0x09: '\'',
+	0x0A: '\f',
+	0x0B: '*',
+	0x0C: '+',
+	0x0E: '-',
+	0x0F: '/',
+	0x10: '<',
+	0x11: '=',
+	0x12: '>',
+	0x13: '\u00A1',
+	0x14: '^',
+	0x15: '\u00A1',
+	0x16: '_',
+	0x17: '#',
+	0x18: '*',
+	0x19: '\u0600',
+	0x1A: '\u0601',
+	0x1C: '\u06F0',
+	0x1D: '\u06F1',
+	0x1E: '\u06F2',
+	0x1F: '\u06F3',
+	0x20: '\u06F4',
+	0x21: '\u06F5',
+	0x22: '\u06F6',
+	0x23: '\u06F7',
+	0x24: '\u06F8',
+	0x25: '\u06F9',
+	0x26: '\u060C',
+	0x27: '\u060D',
+	0x28: '{',
+	0x29: '}',
+	0x2A: '\u060E',
+	0x2B: '\u060F',
+	0x2C: '\u0610',
+	0x2D: '\u0611',
+	0x2E: '\u0612',
+	0x2F: '\\',
+	0x30: '\u0613',
+	0x31: '\u0614',
+	0x32: '\u061B',
+	0x33: '\u061F',
+	0x34: '\u0640',
+	0x35: '\u0652',
+	0x36: '\u0658',
+	0x37: '\u066B',
+	0x38: '\u066C',
+	0x39: '\u0672',
+	0x3A: '\u0673',
+	0x3B: '\u06CD',
+	0x3C: '[',
+	0x3D: '~',
+	0x3E: ']',
+	0x3F: '\u06D4',
+	0x40: '|',
+	0x41: 'A',
+	0x42: 'B',
+	0x43: 'C',
+	0x44: 'D',
+	0x45: 'E',
+	0x46: 'F',
+	0x47: 'G',
+	0x48: 'H',
+	0x49: 'I',
+	0x4A: 'J',
+	0x4B: 'K',
+	0x4C: 'L',
+	0x4D: 'M',
+	0x4E: 'N',
+	0x4F: 'O',
+	0x50: 'P',
+	0x51: 'Q',
+	0x52: 'R',
+	0x53: 'S',
+	0x54: 'T',
+	0x55: 'U',
+	0x56: 'V',
+	0x57: 'W',
+	0x58: 'X',
+	0x59: 'Y',
+	0x5A: 'Z',
+	0x65: '\u20AC',
+})
@@ -0,0 +1,246 @@
+package gsm7
+
+import "sync"
+
+// National language identifiers as used in the UDH national language shift
+// IEs and the SMPP language_indicator. The values match the pdu.NLI_*
+// constants.
+const (
+	NLI_DEFAULT    uint8 = 0x00
+	NLI_TURKISH    uint8 = 0x01
+	NLI_SPANISH    uint8 = 0x02
+	NLI_PORTUGUESE uint8 = 0x03
+	NLI_BENGALI    uint8 = 0x04
+	NLI_GUJARATI   uint8 = 0x05
+	NLI_HINDI      uint8 = 0x06
+	NLI_KANNADA    uint8 = 0x07
+	NLI_MALAYALAM  uint8 = 0x08
+	NLI_ORIYA      uint8 = 0x09
+	NLI_PUNJABI    uint8 = 0x0A
+	NLI_TAMIL      uint8 = 0x0B
+	NLI_TELUGU     uint8 = 0x0C
+	NLI_URDU       uint8 = 0x0D
+)
+
+// DefaultAlphabet is the GSM 7-bit default alphabet (3GPP TS 23.038 6.2.1)
+var DefaultAlphabet = newFullTable(
+	"@£$¥èéùìòÇ\nØø\rÅå" +
+		"Δ_ΦΓΛΩΠΨΣΘΞ\x1bÆæßÉ" +
+		" !\"#¤%&'()*+,-./" +
+		"0123456789:;<=>?" +
+		"¡ABCDEFGHIJKLMNO" +
+		"PQRSTUVWXYZÄÖÑÜ§" +
+		"¿abcdefghijklmno" +
+		"pqrstuvwxyzäöñüà")
+
+// DefaultExtension is the default alphabet extension table reached through
+// the escape septet (3GPP TS 23.038 6.2.1.1)
+var DefaultExtension = NewTable(map[byte]rune{
+	0x0A: '\f',
+	0x14: '^',
+	0x28: '{',
+	0x29: '}',
+	0x2F: '\\',
+	0x3C: '[',
+	0x3D: '~',
+	0x3E: ']',
+	0x40: '|',
+	0x65: '€',
+})
+
+// TurkishLockingShift is the Turkish national language locking shift table
+// (3GPP TS 23.038 A.3.1)
+var TurkishLockingShift = newFullTable(
+	"@£$¥€éùıòÇ\nĞğ\rÅå" +
+		"Δ_ΦΓΛΩΠΨΣΘΞ\x1bŞşßÉ" +
+		" !\"#¤%&'()*+,-./" +
+		"0123456789:;<=>?" +
+		"İABCDEFGHIJKLMNO" +
+		"PQRSTUVWXYZÄÖÑÜ§" +
+		"çabcdefghijklmno" +
+		"pqrstuvwxyzäöñüà")
+
+// TurkishSingleShift is the Turkish national language single shift table
+// (3GPP TS 23.038 A.2.1)
+var TurkishSingleShift = NewTable(map[byte]rune{
+	0x0A: '\f',
+	0x14: '^',
+	0x28: '{',
+	0x29: '}',
+	0x2F: '\\',
+	0x3C: '[',
+	0x3D: '~',
+	0x3E: ']',
+	0x40: '|',
+	0x47: 'Ğ',
+	0x49: 'İ',
+	0x53: 'Ş',
+	0x63: 'ç',
+	0x65: '€',
+	0x67: 'ğ',
+	0x69: 'ı',
+	0x73: 'ş',
+})
+
+// SpanishSingleShift is the Spanish national language single shift table
+// (3GPP TS 23.038 A.2.2). Spanish has no locking shift table.
+var SpanishSingleShift = NewTable(map[byte]rune{
+	0x09: 'ç',
+	0x0A: '\f',
+	0x14: '^',
+	0x28: '{',
+	0x29: '}',
+	0x2F: '\\',
+	0x3C: '[',
+	0x3D: '~',
+	0x3E: ']',
+	0x40: '|',
+	0x41: 'Á',
+	0x49: 'Í',
+	0x4F: 'Ó',
+	0x55: 'Ú',
+	0x61: 'á',
+	0x65: '€',
+	0x69: 'í',
+	0x6F: 'ó',
+	0x75: 'ú',
+})
+
+// PortugueseLockingShift is the Portuguese national language locking shift
+// table (3GPP TS 23.038 A.3.3)
+var PortugueseLockingShift = newFullTable(
+	"@£$¥êéúíóç\nÔô\rÁá" +
+		"Δ_ªÇÀ∞^\\€Ó|\x1bÂâÊÉ" +
+		" !\"#º%&'()*+,-./" +
+		"0123456789:;<=>?" +
+		"ÍABCDEFGHIJKLMNO" +
+		"PQRSTUVWXYZÃÕÚÜ§" +
+		"~abcdefghijklmno" +
+		"pqrstuvwxyzãõ`üà")
+
+// PortugueseSingleShift is the Portuguese national language single shift
+// table (3GPP TS 23.038 A.2.3)
+var PortugueseSingleShift = NewTable(map[byte]rune{
+	0x05: 'ê',
+	0x09: 'ç',
+	0x0A: '\f',
+	0x0B: 'Ô',
+	0x0C: 'ô',
+	0x0E: 'Á',
+	0x0F: 'á',
+	0x12: 'Φ',
+	0x13: 'Γ',
+	0x14: '^',
+	0x15: 'Ω',
+	0x16: 'Π',
+	0x17: 'Ψ',
+	0x18: 'Σ',
+	0x19: 'Θ',
+	0x1F: 'Ê',
+	0x28: '{',
+	0x29: '}',
+	0x2F: '\\',
+	0x3C: '[',
+	0x3D: '~',
+	0x3E: ']',
+	0x40: '|',
+	0x41: 'À',
+	0x49: 'Í',
+	0x4F: 'Ó',
+	0x55: 'Ú',
+	0x5B: 'Ã',
+	0x5C: 'Õ',
+	0x61: 'Â',
+	0x65: '€',
+	0x69: 'í',
+	0x6F: 'ó',
+	0x75: 'ú',
+	0x7B: 'ã',
+	0x7C: 'õ',
+	0x7F: 'â',
+})
+
+var (
+	languagesMu   sync.RWMutex
+	lockingShifts = map[uint8]*Table{
+		NLI_DEFAULT:    DefaultAlphabet,
+		NLI_TURKISH:    TurkishLockingShift,
+		NLI_PORTUGUESE: PortugueseLockingShift,
+		NLI_BENGALI:    BengaliLockingShift,
+		NLI_GUJARATI:   GujaratiLockingShift,
+		NLI_HINDI:      HindiLockingShift,
+		NLI_KANNADA:    KannadaLockingShift,
+		NLI_MALAYALAM:  MalayalamLockingShift,
+		NLI_ORIYA:      OriyaLockingShift,
+		NLI_PUNJABI:    PunjabiLockingShift,
+		NLI_TAMIL:      TamilLockingShift,
+		NLI_TELUGU:     TeluguLockingShift,
+		NLI_URDU:       UrduLockingShift,
+	}
+	singleShifts = map[uint8]*Table{
+		NLI_DEFAULT:    DefaultExtension,
+		NLI_TURKISH:    TurkishSingleShift,
+		NLI_SPANISH:    SpanishSingleShift,
+		NLI_PORTUGUESE: PortugueseSingleShift,
+		NLI_BENGALI:    BengaliSingleShift,
+		NLI_GUJARATI:   GujaratiSingleShift,
+		NLI_HINDI:      HindiSingleShift,
+		NLI_KANNADA:    KannadaSingleShift,
+		NLI_MALAYALAM:  MalayalamSingleShift,
+		NLI_ORIYA:      OriyaSingleShift,
+		NLI_PUNJABI:    PunjabiSingleShift,
+		NLI_TAMIL:      TamilSingleShift,
+		NLI_TELUGU:     TeluguSingleShift,
+		NLI_URDU:       UrduSingleShift,
+	}
+)
+
+// RegisterLanguage adds or replaces the shift tables of a national language,
+// e.g. a handset vendor's variant of a standard table. Either table may be
+// nil when the language does not define it.
+func RegisterLanguage(nli uint8, locking, single *Table) {
+	languagesMu.Lock()
+	defer languagesMu.Unlock()
+	if locking != nil {
+		lockingShifts[nli] = locking
+	} else {
+		delete(lockingShifts, nli)
+	}
+	if single != nil {
+		singleShifts[nli] = single
+	} else {
+		delete(singleShifts, nli)
+	}
+}
+
+// LockingShift returns the locking shift table of a national language
+func LockingShift(nli uint8) (*Table, bool) {
+	languagesMu.RLock()
+	defer languagesMu.RUnlock()
+	t, ok := lockingShifts[nli]
+	return t, ok
+}
+
+// SingleShift returns the single shift table of a national language
+func SingleShift(nli uint8) (*Table, bool) {
+	languagesMu.RLock()
+	defer languagesMu.RUnlock()
+	t, ok := singleShifts[nli]
+	return t, ok
+}
+
+// Languages returns the identifiers of every language with a locking or
+// single shift table, excluding the default alphabet
+func Languages() []uint8 {
+	languagesMu.RLock()
+	defer languagesMu.RUnlock()
+	var nlis []uint8
+	for nli := 1; nli <= 0xFF; nli++ {
+		_, locking := lockingShifts[uint8(nli)]
+		_, single := singleShifts[uint8(nli)]
+		if locking || single {
+			nlis = append(nlis, uint8(nli))
+		}
+	}
+	return nlis
+}
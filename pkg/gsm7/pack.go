@@ -0,0 +1,74 @@
+package gsm7
+
+// FillBits returns the number of fill bits needed after a user data header
+// of udhLen octets (including the UDHL octet) so that the first septet of
+// the message starts on a septet boundary
+func FillBits(udhLen int) int {
+	return (7 - (udhLen*8)%7) % 7
+}
+
+// Pack packs septets into octets, least significant bit first, after
+// fillBits zero bits. When the last octet would have 7 spare bits they are
+// filled with CR so that the receiver does not read an extra '@', and a
+// message ending in CR on an octet boundary gets a second CR so that its
+// own CR is not taken for padding.
+func Pack(septets []byte, fillBits int) []byte {
+	if n := len(septets); n > 0 && septets[n-1] == CR && (fillBits+n*7)%8 == 0 {
+		septets = append(septets[:n:n], CR)
+	}
+
+	nbits := fillBits + len(septets)*7
+	packed := make([]byte, (nbits+7)/8)
+
+	bit := fillBits
+	for _, septet := range septets {
+		septet &= 0x7F
+		idx, shift := bit/8, uint(bit%8)
+		packed[idx] |= septet << shift
+		if shift > 1 {
+			packed[idx+1] |= septet >> (8 - shift)
+		}
+		bit += 7
+	}
+
+	if nbits%8 == 1 {
+		packed[len(packed)-1] |= CR << 1
+	}
+
+	return packed
+}
+
+// Unpack extracts n septets from packed octets, skipping fillBits leading
+// bits. If n is negative, every complete septet is extracted and a CR
+// padding the last octet is dropped.
+func Unpack(packed []byte, n int, fillBits int) []byte {
+	available := (len(packed)*8 - fillBits) / 7
+	if available < 0 {
+		available = 0
+	}
+
+	padded := false
+	if n < 0 || n > available {
+		padded = n < 0
+		n = available
+	}
+
+	septets := make([]byte, n)
+	bit := fillBits
+	for i := 0; i < n; i++ {
+		idx, shift := bit/8, uint(bit%8)
+		septet := packed[idx] >> shift
+		if shift > 1 && idx+1 < len(packed) {
+			septet |= packed[idx+1] << (8 - shift)
+		}
+		septets[i] = septet & 0x7F
+		bit += 7
+	}
+
+	// A CR filling the last 7 bits of the final octet is padding
+	if padded && n > 0 && (fillBits+n*7)%8 == 0 && septets[n-1] == CR {
+		septets = septets[:n-1]
+	}
+
+	return septets
+}
@@ -0,0 +1,68 @@
+package gsm7
+
+import "unicode/utf8"
+
+const (
+	// ESCAPE switches the next septet to the single shift (extension) table
+	ESCAPE byte = 0x1B
+
+	// CR is the carriage return septet used to pad a packed message whose
+	// last octet would otherwise hold 7 zero bits, i.e. an '@'
+	CR byte = 0x0D
+)
+
+// Table maps the 128 septet values of one GSM 03.38 table to characters.
+// Septets without a character, such as the escape position or unused
+// extension codes, are left undefined.
+type Table struct {
+	runes [128]rune
+	index map[rune]byte
+}
+
+// NewTable creates a table from a septet to rune mapping. A character
+// mapped by several septets, as '¡' and '*' are in the Indian single shift
+// tables, decodes from each and encodes to the lowest.
+func NewTable(chars map[byte]rune) *Table {
+	t := &Table{index: make(map[rune]byte, len(chars))}
+	for septet := byte(0); septet <= 0x7F; septet++ {
+		r, ok := chars[septet]
+		if !ok || septet == ESCAPE || r == 0 {
+			continue
+		}
+		t.runes[septet] = r
+		if _, dup := t.index[r]; !dup {
+			t.index[r] = septet
+		}
+	}
+	return t
+}
+
+// newFullTable creates a table from a string holding all 128 characters in
+// septet order. The character at the escape position is ignored.
+func newFullTable(s string) *Table {
+	if utf8.RuneCountInString(s) != 128 {
+		panic("gsm7: table must contain 128 characters")
+	}
+	chars := make(map[byte]rune, 128)
+	septet := byte(0)
+	for _, r := range s {
+		chars[septet] = r
+		septet++
+	}
+	return NewTable(chars)
+}
+
+// Rune returns the character of a septet
+func (t *Table) Rune(septet byte) (rune, bool) {
+	if septet > 0x7F {
+		return 0, false
+	}
+	r := t.runes[septet]
+	return r, r != 0
+}
+
+// Septet returns the septet of a character
+func (t *Table) Septet(r rune) (byte, bool) {
+	septet, ok := t.index[r]
+	return septet, ok
+}
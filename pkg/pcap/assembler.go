@@ -0,0 +1,176 @@
+package pcap
+
+import (
+	"time"
+)
+
+// DefaultMaxBuffered is the amount of out of order data an Assembler holds
+// per flow before it gives up on the missing segment
+const DefaultMaxBuffered = 1024 * 1024
+
+// StreamHandler receives the reassembled data of TCP flows
+type StreamHandler interface {
+	// Data is called with the next payload of a flow in sequence order
+	Data(flow Flow, ts time.Time, data []byte)
+	// Gap is called when n octets of a flow were not captured and have been
+	// skipped; ts is the time of the data that follows
+	Gap(flow Flow, ts time.Time, n int)
+	// Close is called once a flow has ended or the capture is over
+	Close(flow Flow)
+}
+
+// pendingSegment is a segment that arrived ahead of the data before it
+type pendingSegment struct {
+	ts      time.Time
+	payload []byte
+}
+
+// stream is the reassembly state of one flow
+type stream struct {
+	next     uint32
+	started  bool
+	pending  map[uint32]pendingSegment
+	buffered int
+	closed   bool
+}
+
+// Assembler reassembles TCP flows from their segments. Retransmitted and
+// overlapping data is delivered once; data that arrives out of order is
+// held until the missing segment is seen or MaxBuffered is exceeded.
+type Assembler struct {
+	MaxBuffered int
+
+	handler   StreamHandler
+	streams   map[Flow]*stream
+	initiator map[Flow]bool
+}
+
+// NewAssembler creates an Assembler delivering to handler
+func NewAssembler(handler StreamHandler) *Assembler {
+	return &Assembler{
+		MaxBuffered: DefaultMaxBuffered,
+		handler:     handler,
+		streams:     make(map[Flow]*stream),
+		initiator:   make(map[Flow]bool),
+	}
+}
+
+// Initiator reports whether a flow was seen opening its connection with a
+// SYN, i.e. it is the client side
+func (a *Assembler) Initiator(flow Flow) bool {
+	return a.initiator[flow]
+}
+
+// Add passes a segment to the assembler
+func (a *Assembler) Add(seg *Segment) {
+	s := a.streams[seg.Flow]
+	if s == nil || (s.closed && seg.Flags&TCP_SYN != 0) {
+		// A new connection, possibly reusing the ports of a closed one
+		s = &stream{pending: make(map[uint32]pendingSegment)}
+		a.streams[seg.Flow] = s
+	}
+	if s.closed {
+		return
+	}
+
+	seq := seg.Seq
+	if seg.Flags&TCP_SYN != 0 {
+		if seg.Flags&TCP_ACK == 0 {
+			a.initiator[seg.Flow] = true
+		}
+		seq++
+		s.next, s.started = seq, true
+	} else if !s.started {
+		// The capture started in the middle of the connection
+		s.next, s.started = seq, true
+	}
+
+	if len(seg.Payload) > 0 {
+		if int32(seq-s.next) > 0 {
+			if _, ok := s.pending[seq]; !ok {
+				s.pending[seq] = pendingSegment{ts: seg.Timestamp, payload: append([]byte(nil), seg.Payload...)}
+				s.buffered += len(seg.Payload)
+			}
+			for s.buffered > a.MaxBuffered {
+				a.skipGap(seg.Flow, s)
+			}
+		} else {
+			a.deliver(seg.Flow, s, seq, seg.Timestamp, seg.Payload)
+		}
+	}
+
+	if seg.Flags&(TCP_FIN|TCP_RST) != 0 {
+		a.close(seg.Flow, s)
+	}
+}
+
+// Flush delivers the data still held for every open flow, skipping what
+// is missing, and closes them
+func (a *Assembler) Flush() {
+	for flow, s := range a.streams {
+		a.close(flow, s)
+	}
+}
+
+// deliver passes a segment starting at or before the next expected octet
+// to the handler, followed by the pending segments it makes contiguous
+func (a *Assembler) deliver(flow Flow, s *stream, seq uint32, ts time.Time, payload []byte) {
+	for {
+		if overlap := int(s.next - seq); overlap < len(payload) {
+			payload = payload[overlap:]
+			s.next += uint32(len(payload))
+			a.handler.Data(flow, ts, payload)
+		}
+
+		found := false
+		for pseq, p := range s.pending {
+			if int32(pseq-s.next) <= 0 {
+				delete(s.pending, pseq)
+				s.buffered -= len(p.payload)
+				// Data held back becomes available with the segment
+				// that completes it
+				seq, payload = pseq, p.payload
+				if p.ts.After(ts) {
+					ts = p.ts
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return
+		}
+	}
+}
+
+// skipGap gives up on the missing data before the earliest pending segment
+func (a *Assembler) skipGap(flow Flow, s *stream) {
+	first, gap := uint32(0), -1
+	for pseq := range s.pending {
+		if d := int(int32(pseq - s.next)); gap == -1 || d < gap {
+			first, gap = pseq, d
+		}
+	}
+	if gap == -1 {
+		return
+	}
+
+	p := s.pending[first]
+	a.handler.Gap(flow, p.ts, gap)
+	delete(s.pending, first)
+	s.buffered -= len(p.payload)
+	s.next = first
+	a.deliver(flow, s, first, p.ts, p.payload)
+}
+
+// close flushes and ends a flow
+func (a *Assembler) close(flow Flow, s *stream) {
+	if s.closed {
+		return
+	}
+	for len(s.pending) > 0 {
+		a.skipGap(flow, s)
+	}
+	s.closed = true
+	a.handler.Close(flow)
+}
@@ -0,0 +1,187 @@
+package pcap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+// recorder is a StreamHandler recording what it is called with
+type recorder struct {
+	events  []string
+	streams map[Flow][]byte
+}
+
+func newRecorder() *recorder {
+	return &recorder{streams: make(map[Flow][]byte)}
+}
+
+func (r *recorder) Data(flow Flow, ts time.Time, data []byte) {
+	r.events = append(r.events, fmt.Sprintf("%s data %d at %v", r.side(flow), len(data), ts.Sub(captureStart)))
+	r.streams[flow] = append(r.streams[flow], data...)
+}
+
+func (r *recorder) Gap(flow Flow, ts time.Time, n int) {
+	r.events = append(r.events, fmt.Sprintf("%s gap %d at %v", r.side(flow), n, ts.Sub(captureStart)))
+}
+
+func (r *recorder) Close(flow Flow) {
+	r.events = append(r.events, fmt.Sprintf("%s close", r.side(flow)))
+}
+
+// side names the direction of a flow of the fixtures
+func (r *recorder) side(flow Flow) string {
+	if flow == esmeFlow {
+		return "esme"
+	}
+	return "smsc"
+}
+
+// sequenceNumbers splits a stream of SMPP frames and returns their
+// sequence numbers, or nil if the stream does not end on a frame boundary
+func sequenceNumbers(stream []byte) []uint32 {
+	var seqs []uint32
+	for len(stream) >= 16 {
+		length := int(binary.BigEndian.Uint32(stream))
+		if length < 16 || length > len(stream) {
+			return nil
+		}
+		seqs = append(seqs, binary.BigEndian.Uint32(stream[12:16]))
+		stream = stream[length:]
+	}
+	if len(stream) > 0 {
+		return nil
+	}
+	return seqs
+}
+
+func TestAssemblerCapture(t *testing.T) {
+	for _, name := range captureFiles {
+		t.Run(name, func(t *testing.T) {
+			rec := newRecorder()
+			a := NewAssembler(rec)
+			for _, p := range readPackets(t, name) {
+				if seg, ok := DecodeTCP(p); ok {
+					a.Add(seg)
+				}
+			}
+			a.Flush()
+
+			if !a.Initiator(esmeFlow) || a.Initiator(esmeFlow.Reverse()) {
+				t.Errorf("Initiator = %v, %v, want the ESME", a.Initiator(esmeFlow), a.Initiator(esmeFlow.Reverse()))
+			}
+
+			// The bind is delivered in two parts, submit_sm 3 waits for
+			// submit_sm 2 and takes its time, the retransmission is dropped
+			// and the FIN skips the missing enquire_link
+			want := []string{
+				"esme data 10 at 10ms",
+				"esme data 23 at 11ms",
+				"smsc data 21 at 15ms",
+				"esme data 55 at 21ms",
+				"esme data 56 at 21ms",
+				"smsc data 18 at 30ms",
+				"smsc data 18 at 41ms",
+				"esme gap 16 at 50ms",
+				"esme data 16 at 50ms",
+				"esme data 16 at 51ms",
+				"esme close",
+				"smsc data 16 at 59ms",
+				"smsc data 16 at 60ms",
+				"smsc data 16 at 61ms",
+				"smsc close",
+			}
+			if !slices.Equal(rec.events, want) {
+				t.Errorf("events:\n%q\nwant:\n%q", rec.events, want)
+			}
+
+			esme := sequenceNumbers(rec.streams[esmeFlow])
+			if want := []uint32{1, 2, 3, 5, 6}; !slices.Equal(esme, want) {
+				t.Errorf("ESME frames %v, want %v", esme, want)
+			}
+			smsc := sequenceNumbers(rec.streams[esmeFlow.Reverse()])
+			if want := []uint32{1, 2, 3, 4, 5, 6}; !slices.Equal(smsc, want) {
+				t.Errorf("SMSC frames %v, want %v", smsc, want)
+			}
+		})
+	}
+}
+
+// segment creates a segment of the ESME flow at ms milliseconds into the
+// capture
+func segment(ms int, seq uint32, flags uint8, payload string) *Segment {
+	return &Segment{
+		Timestamp: captureStart.Add(time.Duration(ms) * time.Millisecond),
+		Flow:      esmeFlow,
+		Seq:       seq,
+		Flags:     flags,
+		Payload:   []byte(payload),
+	}
+}
+
+func TestAssemblerOverlap(t *testing.T) {
+	rec := newRecorder()
+	a := NewAssembler(rec)
+	a.Add(segment(0, 99, TCP_SYN, ""))
+	a.Add(segment(1, 100, TCP_ACK, "abcd"))
+	a.Add(segment(2, 102, TCP_ACK, "cdef"))     // Partly retransmitted
+	a.Add(segment(3, 110, TCP_ACK, "klmn"))     // Ahead of the next octet
+	a.Add(segment(4, 110, TCP_ACK, "klmn"))     // Held twice
+	a.Add(segment(5, 106, TCP_ACK, "ghijkl"))   // Fills the hole and overlaps
+	a.Add(segment(6, 100, TCP_ACK, "abcdefgh")) // Old data
+	a.Add(segment(7, 114, TCP_FIN, ""))
+	a.Add(segment(8, 114, TCP_ACK, "late")) // After the FIN
+
+	want := []string{
+		"esme data 4 at 1ms",
+		"esme data 2 at 2ms",
+		"esme data 6 at 5ms",
+		"esme data 2 at 5ms",
+		"esme close",
+	}
+	if !slices.Equal(rec.events, want) {
+		t.Errorf("events:\n%q\nwant:\n%q", rec.events, want)
+	}
+	if got := string(rec.streams[esmeFlow]); got != "abcdefghijklmn" {
+		t.Errorf("stream %q", got)
+	}
+}
+
+func TestAssemblerMaxBuffered(t *testing.T) {
+	rec := newRecorder()
+	a := NewAssembler(rec)
+	a.MaxBuffered = 8
+	a.Add(segment(0, 1000, TCP_ACK, "abcd"))
+	a.Add(segment(1, 1010, TCP_ACK, "klmn"))
+	a.Add(segment(2, 1020, TCP_ACK, "uvwx"))
+	a.Add(segment(3, 1014, TCP_ACK, "opqrst"))
+
+	// The capture starts mid-connection; the last segment takes the held
+	// data over MaxBuffered and the assembler gives up on the first hole
+	want := []string{
+		"esme data 4 at 0s",
+		"esme gap 6 at 1ms",
+		"esme data 4 at 1ms",
+		"esme data 6 at 3ms",
+		"esme data 4 at 3ms",
+	}
+	if !slices.Equal(rec.events, want) {
+		t.Errorf("events:\n%q\nwant:\n%q", rec.events, want)
+	}
+	if got := string(rec.streams[esmeFlow]); got != "abcdklmnopqrstuvwx" {
+		t.Errorf("stream %q", got)
+	}
+
+	// A new connection on the same ports after the first one closed
+	a.Flush()
+	a.Add(segment(4, 5000, TCP_SYN, ""))
+	a.Add(segment(5, 5001, TCP_ACK, "new"))
+	if got := rec.events[len(rec.events)-1]; got != "esme data 3 at 5ms" {
+		t.Errorf("after reuse: %s", got)
+	}
+	if !a.Initiator(esmeFlow) {
+		t.Error("the SYN of the new connection does not make the ESME its initiator")
+	}
+}
@@ -0,0 +1,183 @@
+package pcap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+	"time"
+)
+
+// TCP flags
+const (
+	TCP_FIN uint8 = 0x01
+	TCP_SYN uint8 = 0x02
+	TCP_RST uint8 = 0x04
+	TCP_PSH uint8 = 0x08
+	TCP_ACK uint8 = 0x10
+)
+
+const (
+	etherTypeIPv4  = 0x0800
+	etherTypeIPv6  = 0x86DD
+	etherTypeVLAN  = 0x8100
+	etherTypeQinQ  = 0x88A8
+	etherTypeQinQ2 = 0x9100
+
+	protocolTCP = 6
+)
+
+// Flow is one direction of a TCP connection
+type Flow struct {
+	Src netip.AddrPort
+	Dst netip.AddrPort
+}
+
+// Reverse returns the opposite direction of the connection
+func (f Flow) Reverse() Flow {
+	return Flow{Src: f.Dst, Dst: f.Src}
+}
+
+// String returns the flow as "src > dst"
+func (f Flow) String() string {
+	return fmt.Sprintf("%s > %s", f.Src, f.Dst)
+}
+
+// Segment is a TCP segment taken from a packet
+type Segment struct {
+	Timestamp time.Time
+	Flow      Flow
+	Seq       uint32
+	Flags     uint8
+	Payload   []byte
+}
+
+// DecodeTCP returns the TCP segment carried by a packet. It reports false
+// for packets that are not TCP over IPv4 or IPv6, for IP fragments and for
+// link types it does not know.
+func DecodeTCP(p *Packet) (*Segment, bool) {
+	ip, ok := linkPayload(p.LinkType, p.Data)
+	if !ok {
+		return nil, false
+	}
+
+	src, dst, tcp, ok := ipPayload(ip)
+	if !ok || len(tcp) < 20 {
+		return nil, false
+	}
+
+	offset := int(tcp[12]>>4) * 4
+	if offset < 20 || offset > len(tcp) {
+		return nil, false
+	}
+
+	return &Segment{
+		Timestamp: p.Timestamp,
+		Flow: Flow{
+			Src: netip.AddrPortFrom(src, binary.BigEndian.Uint16(tcp[0:2])),
+			Dst: netip.AddrPortFrom(dst, binary.BigEndian.Uint16(tcp[2:4])),
+		},
+		Seq:     binary.BigEndian.Uint32(tcp[4:8]),
+		Flags:   tcp[13],
+		Payload: tcp[offset:],
+	}, true
+}
+
+// linkPayload strips the link layer header and returns the IP packet
+func linkPayload(linkType uint16, data []byte) ([]byte, bool) {
+	switch linkType {
+	case LINKTYPE_ETHERNET:
+		if len(data) < 14 {
+			return nil, false
+		}
+		etherType := binary.BigEndian.Uint16(data[12:14])
+		data = data[14:]
+		for etherType == etherTypeVLAN || etherType == etherTypeQinQ || etherType == etherTypeQinQ2 {
+			if len(data) < 4 {
+				return nil, false
+			}
+			etherType = binary.BigEndian.Uint16(data[2:4])
+			data = data[4:]
+		}
+		return data, etherType == etherTypeIPv4 || etherType == etherTypeIPv6
+	case LINKTYPE_NULL, LINKTYPE_LOOP:
+		// The address family is 2 for IPv4 and one of several values for
+		// IPv6; checking the IP version is simpler and covers both byte orders
+		if len(data) < 4 {
+			return nil, false
+		}
+		return data[4:], true
+	case LINKTYPE_RAW, LINKTYPE_DLT_RAW1, LINKTYPE_DLT_RAW2, LINKTYPE_IPV4, LINKTYPE_IPV6:
+		return data, true
+	case LINKTYPE_LINUX_SLL:
+		if len(data) < 16 {
+			return nil, false
+		}
+		protocol := binary.BigEndian.Uint16(data[14:16])
+		return data[16:], protocol == etherTypeIPv4 || protocol == etherTypeIPv6
+	case LINKTYPE_LINUX_SLL2:
+		if len(data) < 20 {
+			return nil, false
+		}
+		protocol := binary.BigEndian.Uint16(data[0:2])
+		return data[20:], protocol == etherTypeIPv4 || protocol == etherTypeIPv6
+	}
+	return nil, false
+}
+
+// ipPayload returns the addresses and the TCP segment of an IP packet
+func ipPayload(data []byte) (netip.Addr, netip.Addr, []byte, bool) {
+	var none netip.Addr
+	if len(data) < 1 {
+		return none, none, nil, false
+	}
+
+	switch data[0] >> 4 {
+	case 4:
+		if len(data) < 20 {
+			return none, none, nil, false
+		}
+		headerLen := int(data[0]&0x0F) * 4
+		totalLen := int(binary.BigEndian.Uint16(data[2:4]))
+		fragment := binary.BigEndian.Uint16(data[6:8])
+		if headerLen < 20 || totalLen < headerLen || data[9] != protocolTCP || fragment&0x3FFF != 0 {
+			return none, none, nil, false
+		}
+		// Ethernet pads short frames; the capture may also be truncated
+		end := min(totalLen, len(data))
+		if headerLen > end {
+			return none, none, nil, false
+		}
+		src := netip.AddrFrom4([4]byte(data[12:16]))
+		dst := netip.AddrFrom4([4]byte(data[16:20]))
+		return src, dst, data[headerLen:end], true
+
+	case 6:
+		if len(data) < 40 {
+			return none, none, nil, false
+		}
+		end := min(40+int(binary.BigEndian.Uint16(data[4:6])), len(data))
+		src := netip.AddrFrom16([16]byte(data[8:24]))
+		dst := netip.AddrFrom16([16]byte(data[24:40]))
+		next := data[6]
+		payload := data[40:end]
+		for {
+			switch next {
+			case protocolTCP:
+				return src, dst, payload, true
+			case 0, 43, 60: // Hop-by-hop, routing and destination options
+				if len(payload) < 8 || len(payload) < (int(payload[1])+1)*8 {
+					return none, none, nil, false
+				}
+				next, payload = payload[0], payload[(int(payload[1])+1)*8:]
+			case 51: // Authentication header
+				if len(payload) < 8 || len(payload) < (int(payload[1])+2)*4 {
+					return none, none, nil, false
+				}
+				next, payload = payload[0], payload[(int(payload[1])+2)*4:]
+			default: // Fragments and anything else
+				return none, none, nil, false
+			}
+		}
+	}
+	return none, none, nil, false
+}
@@ -0,0 +1,331 @@
+// Package pcap reads packet captures in the pcap and pcapng file formats
+// and reassembles the TCP streams they contain. It has no dependency on
+// libpcap.
+package pcap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"time"
+)
+
+var (
+	ErrUnknownFormat = errors.New("pcap: unknown file format")
+	ErrInvalidBlock  = errors.New("pcap: invalid block")
+	ErrInvalidRecord = errors.New("pcap: invalid packet record")
+)
+
+// Link types of the captured packets
+const (
+	LINKTYPE_NULL       uint16 = 0   // BSD loopback, host byte order family
+	LINKTYPE_ETHERNET   uint16 = 1   // Ethernet II
+	LINKTYPE_DLT_RAW1   uint16 = 12  // Raw IP on some BSDs
+	LINKTYPE_DLT_RAW2   uint16 = 14  // Raw IP on OpenBSD
+	LINKTYPE_RAW        uint16 = 101 // Raw IPv4 or IPv6
+	LINKTYPE_LOOP       uint16 = 108 // OpenBSD loopback, network byte order family
+	LINKTYPE_LINUX_SLL  uint16 = 113 // Linux cooked capture
+	LINKTYPE_IPV4       uint16 = 228 // Raw IPv4
+	LINKTYPE_IPV6       uint16 = 229 // Raw IPv6
+	LINKTYPE_LINUX_SLL2 uint16 = 276 // Linux cooked capture v2
+)
+
+const (
+	pcapMagicMicros = 0xA1B2C3D4 // Classic pcap, microsecond timestamps
+	pcapMagicNanos  = 0xA1B23C4D // Classic pcap, nanosecond timestamps
+	pcapngMagic     = 0x1A2B3C4D // pcapng section byte order magic
+
+	// pcapng block types
+	blockSectionHeader    = 0x0A0D0D0A
+	blockInterface        = 0x00000001
+	blockPacket           = 0x00000002 // Obsolete packet block
+	blockSimplePacket     = 0x00000003
+	blockEnhancedPacket   = 0x00000006
+	optionEnd             = 0
+	optionIfTsresol       = 9
+	maxBlockLength        = 64 * 1024 * 1024
+	defaultTicksPerSecond = 1000000
+)
+
+// Packet is a captured packet
+type Packet struct {
+	Timestamp time.Time // Zero for pcapng simple packet blocks
+	LinkType  uint16
+	Data      []byte // Captured octets, possibly truncated to the snap length
+	Length    int    // Length of the packet on the wire
+}
+
+// ngInterface describes a pcapng capture interface
+type ngInterface struct {
+	linkType       uint16
+	snapLen        uint32
+	ticksPerSecond uint64 // 0 for binary fractions given by tsShift
+	tsShift        uint8
+}
+
+// Reader reads the packets of a pcap or pcapng file in order
+type Reader struct {
+	r     *bufio.Reader
+	order binary.ByteOrder
+	ng    bool
+
+	// Classic pcap
+	linkType uint16
+	nanos    bool
+
+	// pcapng
+	interfaces []ngInterface
+}
+
+// NewReader creates a Reader, detecting the file format from its header
+func NewReader(r io.Reader) (*Reader, error) {
+	pr := &Reader{r: bufio.NewReaderSize(r, 64*1024)}
+
+	head, err := pr.r.Peek(4)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownFormat, err)
+	}
+
+	switch {
+	case binary.BigEndian.Uint32(head) == blockSectionHeader:
+		pr.ng = true
+		return pr, nil
+	case binary.LittleEndian.Uint32(head) == pcapMagicMicros || binary.LittleEndian.Uint32(head) == pcapMagicNanos:
+		pr.order = binary.LittleEndian
+	case binary.BigEndian.Uint32(head) == pcapMagicMicros || binary.BigEndian.Uint32(head) == pcapMagicNanos:
+		pr.order = binary.BigEndian
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	var header [24]byte
+	if _, err := io.ReadFull(pr.r, header[:]); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownFormat, err)
+	}
+	pr.nanos = pr.order.Uint32(header[0:4]) == pcapMagicNanos
+	pr.linkType = uint16(pr.order.Uint32(header[20:24]))
+	return pr, nil
+}
+
+// ReadPacket returns the next packet, or io.EOF at the end of the file
+func (r *Reader) ReadPacket() (*Packet, error) {
+	if r.ng {
+		return r.readBlockPacket()
+	}
+	return r.readRecord()
+}
+
+// readRecord reads a classic pcap packet record
+func (r *Reader) readRecord() (*Packet, error) {
+	var header [16]byte
+	if _, err := io.ReadFull(r.r, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("%w: truncated header", ErrInvalidRecord)
+		}
+		return nil, err
+	}
+
+	sec := r.order.Uint32(header[0:4])
+	frac := r.order.Uint32(header[4:8])
+	capLen := r.order.Uint32(header[8:12])
+	origLen := r.order.Uint32(header[12:16])
+	if capLen > maxBlockLength {
+		return nil, fmt.Errorf("%w: captured length %d", ErrInvalidRecord, capLen)
+	}
+
+	data := make([]byte, capLen)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return nil, fmt.Errorf("%w: truncated data", ErrInvalidRecord)
+	}
+
+	nsec := int64(frac)
+	if !r.nanos {
+		nsec *= 1000
+	}
+	return &Packet{
+		Timestamp: time.Unix(int64(sec), nsec).UTC(),
+		LinkType:  r.linkType,
+		Data:      data,
+		Length:    int(origLen),
+	}, nil
+}
+
+// readBlockPacket reads pcapng blocks until one that holds a packet
+func (r *Reader) readBlockPacket() (*Packet, error) {
+	for {
+		blockType, body, err := r.readBlock()
+		if err != nil {
+			return nil, err
+		}
+
+		switch blockType {
+		case blockInterface:
+			if err := r.addInterface(body); err != nil {
+				return nil, err
+			}
+		case blockEnhancedPacket:
+			if len(body) < 20 {
+				return nil, fmt.Errorf("%w: short enhanced packet block", ErrInvalidBlock)
+			}
+			return r.newPacket(r.order.Uint32(body[0:4]), body[4:12], r.order.Uint32(body[12:16]), r.order.Uint32(body[16:20]), body[20:])
+		case blockPacket:
+			if len(body) < 20 {
+				return nil, fmt.Errorf("%w: short packet block", ErrInvalidBlock)
+			}
+			return r.newPacket(uint32(r.order.Uint16(body[0:2])), body[4:12], r.order.Uint32(body[12:16]), r.order.Uint32(body[16:20]), body[20:])
+		case blockSimplePacket:
+			if len(body) < 4 || len(r.interfaces) == 0 {
+				return nil, fmt.Errorf("%w: simple packet block", ErrInvalidBlock)
+			}
+			origLen := r.order.Uint32(body[0:4])
+			capLen := uint32(len(body) - 4)
+			if iface := r.interfaces[0]; iface.snapLen != 0 && iface.snapLen < capLen {
+				capLen = iface.snapLen
+			}
+			capLen = min(capLen, origLen)
+			return &Packet{
+				LinkType: r.interfaces[0].linkType,
+				Data:     body[4 : 4+capLen],
+				Length:   int(origLen),
+			}, nil
+		}
+	}
+}
+
+// newPacket creates a packet from the fields of a pcapng packet block
+func (r *Reader) newPacket(ifaceID uint32, ts []byte, capLen, origLen uint32, data []byte) (*Packet, error) {
+	if int(ifaceID) >= len(r.interfaces) {
+		return nil, fmt.Errorf("%w: unknown interface %d", ErrInvalidBlock, ifaceID)
+	}
+	if uint32(len(data)) < capLen {
+		return nil, fmt.Errorf("%w: captured length %d exceeds block", ErrInvalidBlock, capLen)
+	}
+
+	iface := r.interfaces[ifaceID]
+	ticks := uint64(r.order.Uint32(ts[0:4]))<<32 | uint64(r.order.Uint32(ts[4:8]))
+	return &Packet{
+		Timestamp: iface.timestamp(ticks),
+		LinkType:  iface.linkType,
+		Data:      data[:capLen],
+		Length:    int(origLen),
+	}, nil
+}
+
+// timestamp converts a pcapng timestamp in the resolution of the interface
+func (i ngInterface) timestamp(ticks uint64) time.Time {
+	if i.ticksPerSecond == 0 {
+		sec := ticks >> i.tsShift
+		frac := ticks & (1<<i.tsShift - 1)
+		hi, lo := bits.Mul64(frac, uint64(time.Second))
+		nsec, _ := bits.Div64(hi, lo, 1<<i.tsShift)
+		return time.Unix(int64(sec), int64(nsec)).UTC()
+	}
+	sec := ticks / i.ticksPerSecond
+	hi, lo := bits.Mul64(ticks%i.ticksPerSecond, uint64(time.Second))
+	nsec, _ := bits.Div64(hi, lo, i.ticksPerSecond)
+	return time.Unix(int64(sec), int64(nsec)).UTC()
+}
+
+// addInterface records an interface description block
+func (r *Reader) addInterface(body []byte) error {
+	if len(body) < 8 {
+		return fmt.Errorf("%w: short interface description block", ErrInvalidBlock)
+	}
+	iface := ngInterface{
+		linkType:       r.order.Uint16(body[0:2]),
+		snapLen:        r.order.Uint32(body[4:8]),
+		ticksPerSecond: defaultTicksPerSecond,
+	}
+
+	options := body[8:]
+	for len(options) >= 4 {
+		code := r.order.Uint16(options[0:2])
+		length := int(r.order.Uint16(options[2:4]))
+		if code == optionEnd || 4+length > len(options) {
+			break
+		}
+		if code == optionIfTsresol && length >= 1 {
+			if err := iface.setResolution(options[4]); err != nil {
+				return err
+			}
+		}
+		options = options[min(len(options), 4+(length+3)&^3):]
+	}
+
+	r.interfaces = append(r.interfaces, iface)
+	return nil
+}
+
+// setResolution applies an if_tsresol option: a negative power of 10, or
+// of 2 when the high bit is set
+func (i *ngInterface) setResolution(v uint8) error {
+	if v&0x80 != 0 {
+		if v&0x7F > 63 {
+			return fmt.Errorf("%w: timestamp resolution 2^-%d", ErrInvalidBlock, v&0x7F)
+		}
+		i.ticksPerSecond, i.tsShift = 0, v&0x7F
+		return nil
+	}
+	if v > 19 {
+		return fmt.Errorf("%w: timestamp resolution 10^-%d", ErrInvalidBlock, v)
+	}
+	i.ticksPerSecond = 1
+	for range v {
+		i.ticksPerSecond *= 10
+	}
+	return nil
+}
+
+// readBlock reads a pcapng block and returns its type and body. A section
+// header block sets the byte order of the blocks that follow and starts
+// a new list of interfaces.
+func (r *Reader) readBlock() (uint32, []byte, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r.r, header[:8]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return 0, nil, fmt.Errorf("%w: truncated header", ErrInvalidBlock)
+		}
+		return 0, nil, err
+	}
+
+	if binary.BigEndian.Uint32(header[0:4]) == blockSectionHeader {
+		if _, err := io.ReadFull(r.r, header[8:12]); err != nil {
+			return 0, nil, fmt.Errorf("%w: truncated section header", ErrInvalidBlock)
+		}
+		switch {
+		case binary.LittleEndian.Uint32(header[8:12]) == pcapngMagic:
+			r.order = binary.LittleEndian
+		case binary.BigEndian.Uint32(header[8:12]) == pcapngMagic:
+			r.order = binary.BigEndian
+		default:
+			return 0, nil, fmt.Errorf("%w: byte order magic", ErrInvalidBlock)
+		}
+		r.interfaces = nil
+	}
+
+	blockType := r.order.Uint32(header[0:4])
+	length := r.order.Uint32(header[4:8])
+	if length < 12 || length%4 != 0 || length > maxBlockLength {
+		return 0, nil, fmt.Errorf("%w: length %d", ErrInvalidBlock, length)
+	}
+
+	read := uint32(8)
+	if blockType == blockSectionHeader {
+		if length < 16 {
+			return 0, nil, fmt.Errorf("%w: length %d", ErrInvalidBlock, length)
+		}
+		read = 12
+	}
+
+	rest := make([]byte, length-read)
+	if _, err := io.ReadFull(r.r, rest); err != nil {
+		return 0, nil, fmt.Errorf("%w: truncated body", ErrInvalidBlock)
+	}
+	if r.order.Uint32(rest[len(rest)-4:]) != length {
+		return 0, nil, fmt.Errorf("%w: trailing length does not match", ErrInvalidBlock)
+	}
+	return blockType, rest[:len(rest)-4], nil
+}
@@ -0,0 +1,153 @@
+package pcap
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// The fixtures hold the same SMPP session between 10.0.0.1:40000 (the
+// ESME) and 10.0.0.2:2775, captured over Ethernet starting at captureStart:
+//
+//	 0ms  SYN, SYN/ACK and ACK
+//	10ms  bind_transmitter split across two segments
+//	15ms  bind_transmitter_resp
+//	20ms  submit_sm 3, ahead of submit_sm 2 at 21ms, retransmitted at 22ms
+//	30ms  submit_sm_resp 2, 41ms submit_sm_resp 3
+//	      enquire_link 4 missing from the capture
+//	50ms  enquire_link 5, 51ms unbind 6, 52ms FIN
+//	59ms  enquire_link_resp 4, 60ms enquire_link_resp 5, 61ms unbind_resp 6
+//	62ms  FIN
+//
+// smpp.pcap is little endian with microsecond timestamps, smpp.pcapng big
+// endian with nanosecond timestamps.
+var (
+	captureFiles = []string{"smpp.pcap", "smpp.pcapng"}
+	captureStart = time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	esmeFlow     = Flow{
+		Src: netip.MustParseAddrPort("10.0.0.1:40000"),
+		Dst: netip.MustParseAddrPort("10.0.0.2:2775"),
+	}
+)
+
+// readPackets reads every packet of a capture in testdata
+func readPackets(t *testing.T, name string) []*Packet {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	r, err := NewReader(f)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	var packets []*Packet
+	for {
+		p, err := r.ReadPacket()
+		if errors.Is(err, io.EOF) {
+			return packets
+		}
+		if err != nil {
+			t.Fatalf("ReadPacket %d: %v", len(packets), err)
+		}
+		packets = append(packets, p)
+	}
+}
+
+func TestReaderFormats(t *testing.T) {
+	offsets := []int{0, 1, 2, 10, 11, 15, 20, 21, 22, 30, 41, 50, 51, 52, 59, 60, 61, 62}
+	for _, name := range captureFiles {
+		t.Run(name, func(t *testing.T) {
+			packets := readPackets(t, name)
+			if len(packets) != len(offsets) {
+				t.Fatalf("read %d packets, want %d", len(packets), len(offsets))
+			}
+			for i, p := range packets {
+				if want := captureStart.Add(time.Duration(offsets[i]) * time.Millisecond); !p.Timestamp.Equal(want) {
+					t.Errorf("packet %d at %v, want %v", i, p.Timestamp, want)
+				}
+				if p.LinkType != LINKTYPE_ETHERNET || p.Length != len(p.Data) {
+					t.Errorf("packet %d: link type %d, length %d of %d", i, p.LinkType, p.Length, len(p.Data))
+				}
+			}
+
+			seg, ok := DecodeTCP(packets[0])
+			if !ok {
+				t.Fatal("DecodeTCP of the SYN failed")
+			}
+			if seg.Flow != esmeFlow || seg.Seq != 1000 || seg.Flags != TCP_SYN || len(seg.Payload) != 0 {
+				t.Errorf("SYN decoded as %s seq %d flags 0x%02X payload %d", seg.Flow, seg.Seq, seg.Flags, len(seg.Payload))
+			}
+			seg, ok = DecodeTCP(packets[3])
+			if !ok || seg.Flow != esmeFlow || len(seg.Payload) != 10 {
+				t.Errorf("first bind segment decoded as %v, %v", seg, ok)
+			}
+		})
+	}
+}
+
+func TestReaderInvalid(t *testing.T) {
+	if _, err := NewReader(bytes.NewReader([]byte("not a capture"))); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("NewReader error = %v, want ErrUnknownFormat", err)
+	}
+	if _, err := NewReader(bytes.NewReader(nil)); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("NewReader of an empty file error = %v, want ErrUnknownFormat", err)
+	}
+
+	for _, name := range captureFiles {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Cut in the middle of the last packet
+		r, err := NewReader(bytes.NewReader(data[:len(data)-10]))
+		if err != nil {
+			t.Fatalf("%s: NewReader: %v", name, err)
+		}
+		for {
+			_, err = r.ReadPacket()
+			if err != nil {
+				break
+			}
+		}
+		if !errors.Is(err, ErrInvalidRecord) && !errors.Is(err, ErrInvalidBlock) {
+			t.Errorf("%s: truncated file error = %v", name, err)
+		}
+	}
+}
+
+func TestInterfaceTimestamp(t *testing.T) {
+	tests := []struct {
+		tsresol uint8
+		ticks   uint64
+		want    time.Time
+	}{
+		{6, 1_500_000, time.Unix(1, 500_000_000)},
+		{9, 1_000_000_001, time.Unix(1, 1)},
+		{0, 3, time.Unix(3, 0)},
+		{0x80 | 10, 3<<10 | 512, time.Unix(3, 500_000_000)},
+	}
+	for _, tt := range tests {
+		var iface ngInterface
+		if err := iface.setResolution(tt.tsresol); err != nil {
+			t.Fatalf("setResolution(0x%02X): %v", tt.tsresol, err)
+		}
+		if got := iface.timestamp(tt.ticks); !got.Equal(tt.want) {
+			t.Errorf("tsresol 0x%02X: timestamp(%d) = %v, want %v", tt.tsresol, tt.ticks, got, tt.want.UTC())
+		}
+	}
+
+	var iface ngInterface
+	for _, v := range []uint8{20, 0x80 | 64} {
+		if err := iface.setResolution(v); !errors.Is(err, ErrInvalidBlock) {
+			t.Errorf("setResolution(0x%02X) error = %v", v, err)
+		}
+	}
+}
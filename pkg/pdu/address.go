@@ -0,0 +1,313 @@
+package pdu
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"nessmpp/pkg/gsm7"
+)
+
+var (
+	ErrInvalidAddress = errors.New("invalid address")
+	ErrNoCountryCode  = errors.New("national number without a country code to normalize it")
+)
+
+// Address limits
+const (
+	ALPHANUMERIC_ADDR_MAX_SEPTETS int = 11 // GSM 03.40 alphanumeric originator, 10 octets of packed septets
+	E164_MAX_DIGITS               int = 15 // ITU-T E.164 international number
+	SHORT_CODE_MAX_DIGITS         int = 8  // Longest number treated as a short code
+)
+
+// Address is an SME address with its type of number and numbering plan
+type Address struct {
+	TON  uint8  `json:"ton"`
+	NPI  uint8  `json:"npi"`
+	Addr string `json:"addr"`
+}
+
+// NewAddress creates an Address
+func NewAddress(addr string, ton, npi uint8) Address {
+	return Address{TON: ton, NPI: npi, Addr: addr}
+}
+
+// InternationalAddress creates an international E.164 Address from a
+// number with or without a leading "+"
+func InternationalAddress(number string) Address {
+	return Address{TON: TON_INTERNATIONAL, NPI: NPI_ISDN, Addr: strings.TrimPrefix(number, "+")}
+}
+
+// AlphanumericAddress creates an alphanumeric sender Address
+func AlphanumericAddress(name string) Address {
+	return Address{TON: TON_ALPHANUMERIC, NPI: NPI_UNKNOWN, Addr: name}
+}
+
+// IsZero reports whether the address is empty, which lets the SMSC use its
+// default address
+func (a Address) IsZero() bool {
+	return a == Address{}
+}
+
+// IsAlphanumeric reports whether the address is an alphanumeric sender
+func (a Address) IsAlphanumeric() bool {
+	return a.TON == TON_ALPHANUMERIC
+}
+
+// IsShortCode reports whether the address is a numeric short code
+func (a Address) IsShortCode() bool {
+	switch a.TON {
+	case TON_UNKNOWN, TON_NETWORK_SPECIFIC, TON_ABBREVIATED:
+		return isDigits(a.Addr) && len(a.Addr) <= SHORT_CODE_MAX_DIGITS
+	}
+	return false
+}
+
+// E164 returns an international number with a leading "+", or "" for
+// other addresses
+func (a Address) E164() string {
+	if a.TON != TON_INTERNATIONAL || !isDigits(a.Addr) {
+		return ""
+	}
+	return "+" + a.Addr
+}
+
+// String returns the address with its TON and NPI, e.g. "447700900123
+// (ton=1 npi=1)"
+func (a Address) String() string {
+	return fmt.Sprintf("%s (ton=%d npi=%d)", a.Addr, a.TON, a.NPI)
+}
+
+// Validate checks the address against the rules of its type of number:
+// alphanumeric senders hold at most 11 GSM characters, international
+// numbers up to 15 digits without a leading zero, abbreviated numbers up
+// to SHORT_CODE_MAX_DIGITS digits and other numeric types digits only. An
+// empty address is only valid with TON and NPI unknown.
+func (a Address) Validate() error {
+	if a.Addr == "" {
+		if a.TON != TON_UNKNOWN || a.NPI != NPI_UNKNOWN {
+			return a.invalid("empty address with ton=%d npi=%d", a.TON, a.NPI)
+		}
+		return nil
+	}
+
+	switch a.TON {
+	case TON_ALPHANUMERIC:
+		n, ok := gsm7.SeptetLen(a.Addr)
+		if !ok {
+			return a.invalid("alphanumeric address is not in the GSM 7-bit alphabet")
+		}
+		if n > ALPHANUMERIC_ADDR_MAX_SEPTETS {
+			return a.invalid("alphanumeric address of %d characters exceeds %d", n, ALPHANUMERIC_ADDR_MAX_SEPTETS)
+		}
+		return nil
+	case TON_INTERNATIONAL:
+		if !isDigits(a.Addr) {
+			return a.invalid("international number must be digits only")
+		}
+		if len(a.Addr) > E164_MAX_DIGITS {
+			return a.invalid("international number of %d digits exceeds %d", len(a.Addr), E164_MAX_DIGITS)
+		}
+		if a.Addr[0] == '0' {
+			return a.invalid("international number starts with a zero")
+		}
+		return nil
+	case TON_ABBREVIATED:
+		if !isDigits(a.Addr) || len(a.Addr) > SHORT_CODE_MAX_DIGITS {
+			return a.invalid("short code must be up to %d digits", SHORT_CODE_MAX_DIGITS)
+		}
+		return nil
+	case TON_NATIONAL, TON_SUBSCRIBER_NUMBER:
+		if !isDigits(a.Addr) || len(a.Addr) > E164_MAX_DIGITS {
+			return a.invalid("number must be up to %d digits", E164_MAX_DIGITS)
+		}
+		return nil
+	}
+
+	if a.NPI == NPI_ISDN && !isDigits(a.Addr) {
+		return a.invalid("ISDN number must be digits only")
+	}
+	return nil
+}
+
+// invalid returns an ErrInvalidAddress with the reason
+func (a Address) invalid(format string, args ...any) error {
+	return fmt.Errorf("%w %q: %s", ErrInvalidAddress, a.Addr, fmt.Sprintf(format, args...))
+}
+
+// SourceAddressed is implemented by PDUs carrying a source_addr
+type SourceAddressed interface {
+	Source() Address
+	SetSource(a Address)
+}
+
+// DestinationAddressed is implemented by PDUs carrying a destination_addr
+type DestinationAddressed interface {
+	Destination() Address
+	SetDestination(a Address)
+}
+
+// AddressNormalizer brings numeric addresses into their canonical
+// international form so that routing and billing see every number the
+// same way, whichever notation the peer used
+type AddressNormalizer struct {
+	CountryCode         string // Country of national numbers, e.g. "44"
+	TrunkPrefix         string // National trunk prefix, e.g. "0"
+	InternationalPrefix string // International call prefix, "00" by default
+}
+
+// NewAddressNormalizer creates an AddressNormalizer for national numbers of
+// a country
+func NewAddressNormalizer(countryCode, trunkPrefix string) *AddressNormalizer {
+	return &AddressNormalizer{
+		CountryCode:         countryCode,
+		TrunkPrefix:         trunkPrefix,
+		InternationalPrefix: "00",
+	}
+}
+
+// Normalize returns the canonical form of an address:
+//
+//   - numbers written with "+" or the international prefix, international
+//     numbers and national numbers are stripped of prefixes and
+//     separators and become TON international, NPI ISDN
+//   - numbers of at most SHORT_CODE_MAX_DIGITS digits become TON
+//     abbreviated, NPI unknown
+//   - text that is not a number becomes an alphanumeric sender
+//
+// A number of unknown type that starts with the trunk prefix is national,
+// however short; without a trunk prefix configured, one that does not
+// start with the country code is. The result is validated.
+func (n *AddressNormalizer) Normalize(a Address) (Address, error) {
+	if a.Addr == "" || a.TON == TON_ALPHANUMERIC {
+		return a, a.Validate()
+	}
+
+	number, plus := stripSeparators(a.Addr)
+	if !isDigits(number) {
+		if a.TON != TON_UNKNOWN || plus {
+			return a, a.invalid("not a number")
+		}
+		alnum := AlphanumericAddress(a.Addr)
+		return alnum, alnum.Validate()
+	}
+
+	ton := a.TON
+	intlPrefix := n.InternationalPrefix
+	if intlPrefix == "" {
+		intlPrefix = "00"
+	}
+	switch {
+	case plus:
+		ton = TON_INTERNATIONAL
+	case ton != TON_NATIONAL && strings.HasPrefix(number, intlPrefix):
+		number = number[len(intlPrefix):]
+		ton = TON_INTERNATIONAL
+	case ton == TON_ABBREVIATED || ton == TON_NETWORK_SPECIFIC:
+	case ton == TON_UNKNOWN && n.TrunkPrefix != "" && strings.HasPrefix(number, n.TrunkPrefix):
+		ton = TON_NATIONAL
+	case ton == TON_UNKNOWN && len(number) <= SHORT_CODE_MAX_DIGITS:
+		ton = TON_ABBREVIATED
+	case ton == TON_UNKNOWN && n.TrunkPrefix == "" && n.CountryCode != "" && !strings.HasPrefix(number, n.CountryCode):
+		ton = TON_NATIONAL
+	case ton == TON_UNKNOWN:
+		ton = TON_INTERNATIONAL
+	}
+
+	switch ton {
+	case TON_NATIONAL:
+		if n.CountryCode == "" {
+			return a, ErrNoCountryCode
+		}
+		number = n.CountryCode + strings.TrimPrefix(number, n.TrunkPrefix)
+		ton = TON_INTERNATIONAL
+	case TON_SUBSCRIBER_NUMBER:
+		// Needs an area code to become international
+		return a, a.Validate()
+	}
+
+	var out Address
+	switch ton {
+	case TON_INTERNATIONAL:
+		out = Address{TON: TON_INTERNATIONAL, NPI: NPI_ISDN, Addr: number}
+	case TON_ABBREVIATED:
+		out = Address{TON: TON_ABBREVIATED, NPI: NPI_UNKNOWN, Addr: number}
+	default:
+		out = Address{TON: ton, NPI: a.NPI, Addr: number}
+	}
+	return out, out.Validate()
+}
+
+// NormalizePDU normalizes the source and destination addresses of a PDU,
+// including every SME address of a submit_multi. The PDU is only changed
+// if all of them are valid.
+func (n *AddressNormalizer) NormalizePDU(p PDU) error {
+	var src, dst Address
+	var err error
+	if sa, ok := p.(SourceAddressed); ok {
+		if src, err = n.Normalize(sa.Source()); err != nil {
+			return err
+		}
+	}
+	if da, ok := p.(DestinationAddressed); ok {
+		if dst, err = n.Normalize(da.Destination()); err != nil {
+			return err
+		}
+	}
+
+	var dests []Address
+	multi, _ := p.(*SubmitMulti)
+	if multi != nil {
+		dests = make([]Address, len(multi.DestAddresses))
+		for i := range multi.DestAddresses {
+			if multi.DestAddresses[i].IsDistributionList() {
+				continue
+			}
+			if dests[i], err = n.Normalize(multi.DestAddresses[i].Address()); err != nil {
+				return err
+			}
+		}
+	}
+
+	if sa, ok := p.(SourceAddressed); ok {
+		sa.SetSource(src)
+	}
+	if da, ok := p.(DestinationAddressed); ok {
+		da.SetDestination(dst)
+	}
+	for i := range dests {
+		if !multi.DestAddresses[i].IsDistributionList() {
+			multi.DestAddresses[i].SetAddress(dests[i])
+		}
+	}
+	return nil
+}
+
+// stripSeparators removes the blanks, dashes, dots and brackets people put
+// into numbers and a leading "+", reporting whether there was one
+func stripSeparators(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	plus := strings.HasPrefix(s, "+")
+	s = strings.TrimPrefix(s, "+")
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')', '/':
+			return -1
+		}
+		return r
+	}, s)
+	return s, plus
+}
+
+// isDigits reports whether s is a non-empty string of decimal digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+package pdu
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAddressNormalize(t *testing.T) {
+	uk := NewAddressNormalizer("44", "0")
+	us := NewAddressNormalizer("1", "")
+	tests := []struct {
+		name string
+		n    *AddressNormalizer
+		in   Address
+		want Address
+		err  error
+	}{
+		{"plus", uk, NewAddress("+44 7700 900123", TON_UNKNOWN, NPI_UNKNOWN), InternationalAddress("447700900123"), nil},
+		{"plus short", uk, NewAddress("+4412", TON_UNKNOWN, NPI_UNKNOWN), InternationalAddress("4412"), nil},
+		{"international prefix", uk, NewAddress("00447700900123", TON_UNKNOWN, NPI_UNKNOWN), InternationalAddress("447700900123"), nil},
+		{"international", uk, NewAddress("447700900123", TON_INTERNATIONAL, NPI_ISDN), InternationalAddress("447700900123"), nil},
+		{"trunk prefix", uk, NewAddress("(07700) 900-123", TON_UNKNOWN, NPI_UNKNOWN), InternationalAddress("447700900123"), nil},
+		{"short trunk prefix", uk, NewAddress("0800123", TON_UNKNOWN, NPI_UNKNOWN), InternationalAddress("44800123"), nil},
+		{"national", uk, NewAddress("7700900123", TON_NATIONAL, NPI_ISDN), InternationalAddress("447700900123"), nil},
+		{"national with trunk prefix", uk, NewAddress("07700900123", TON_NATIONAL, NPI_ISDN), InternationalAddress("447700900123"), nil},
+		{"unknown without prefix", uk, NewAddress("447700900123", TON_UNKNOWN, NPI_UNKNOWN), InternationalAddress("447700900123"), nil},
+		{"no trunk prefix national", us, NewAddress("2025550123", TON_UNKNOWN, NPI_UNKNOWN), InternationalAddress("12025550123"), nil},
+		{"no trunk prefix international", us, NewAddress("12025550123", TON_UNKNOWN, NPI_UNKNOWN), InternationalAddress("12025550123"), nil},
+		{"short code", uk, NewAddress("81234", TON_UNKNOWN, NPI_UNKNOWN), NewAddress("81234", TON_ABBREVIATED, NPI_UNKNOWN), nil},
+		{"short code of 8 digits", uk, NewAddress("12345678", TON_UNKNOWN, NPI_UNKNOWN), NewAddress("12345678", TON_ABBREVIATED, NPI_UNKNOWN), nil},
+		{"abbreviated", uk, NewAddress("81234", TON_ABBREVIATED, NPI_ISDN), NewAddress("81234", TON_ABBREVIATED, NPI_UNKNOWN), nil},
+		{"network specific", uk, NewAddress("0123", TON_NETWORK_SPECIFIC, NPI_PRIVATE), NewAddress("0123", TON_NETWORK_SPECIFIC, NPI_PRIVATE), nil},
+		{"subscriber number", uk, NewAddress("900123", TON_SUBSCRIBER_NUMBER, NPI_ISDN), NewAddress("900123", TON_SUBSCRIBER_NUMBER, NPI_ISDN), nil},
+		{"alphanumeric", uk, NewAddress("Shop", TON_UNKNOWN, NPI_UNKNOWN), AlphanumericAddress("Shop"), nil},
+		{"alphanumeric kept", uk, AlphanumericAddress("Shop 24"), AlphanumericAddress("Shop 24"), nil},
+		{"empty", uk, Address{}, Address{}, nil},
+		{"text as international", uk, NewAddress("Shop", TON_INTERNATIONAL, NPI_ISDN), Address{}, ErrInvalidAddress},
+		{"plus text", uk, NewAddress("+Shop", TON_UNKNOWN, NPI_UNKNOWN), Address{}, ErrInvalidAddress},
+		{"alphanumeric too long", uk, NewAddress("ShopOnline24", TON_UNKNOWN, NPI_UNKNOWN), Address{}, ErrInvalidAddress},
+		{"international zero", uk, NewAddress("+0447700900123", TON_UNKNOWN, NPI_UNKNOWN), Address{}, ErrInvalidAddress},
+		{"international too long", uk, NewAddress("+4477009001234567", TON_UNKNOWN, NPI_UNKNOWN), Address{}, ErrInvalidAddress},
+		{"no country code", NewAddressNormalizer("", "0"), NewAddress("07700900123", TON_UNKNOWN, NPI_UNKNOWN), Address{}, ErrNoCountryCode},
+	}
+	for _, tt := range tests {
+		got, err := tt.n.Normalize(tt.in)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: Normalize(%v) error = %v, want %v", tt.name, tt.in, err, tt.err)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("%s: Normalize(%v) = %v, want %v", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAddressValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		a     Address
+		valid bool
+	}{
+		{"empty", Address{}, true},
+		{"empty with ton", Address{TON: TON_INTERNATIONAL}, false},
+		{"alphanumeric 11 characters", AlphanumericAddress("ShopOnline1"), true},
+		{"alphanumeric 12 characters", AlphanumericAddress("ShopOnline12"), false},
+		{"alphanumeric escaped", AlphanumericAddress("Shop€€€€€€"), false},
+		{"alphanumeric not gsm", AlphanumericAddress("Магазин"), false},
+		{"international 15 digits", InternationalAddress("123456789012345"), true},
+		{"international 16 digits", InternationalAddress("1234567890123456"), false},
+		{"international leading zero", InternationalAddress("0447700900123"), false},
+		{"international not digits", InternationalAddress("44-7700"), false},
+		{"abbreviated 8 digits", NewAddress("12345678", TON_ABBREVIATED, NPI_UNKNOWN), true},
+		{"abbreviated 9 digits", NewAddress("123456789", TON_ABBREVIATED, NPI_UNKNOWN), false},
+		{"national", NewAddress("07700900123", TON_NATIONAL, NPI_ISDN), true},
+		{"national too long", NewAddress(strings.Repeat("1", 16), TON_NATIONAL, NPI_ISDN), false},
+		{"isdn not digits", NewAddress("12AB", TON_UNKNOWN, NPI_ISDN), false},
+		{"unknown text", NewAddress("12AB", TON_UNKNOWN, NPI_UNKNOWN), true},
+	}
+	for _, tt := range tests {
+		err := tt.a.Validate()
+		if tt.valid && err != nil {
+			t.Errorf("%s: Validate(%v) = %v", tt.name, tt.a, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidAddress) {
+			t.Errorf("%s: Validate(%v) = %v, want ErrInvalidAddress", tt.name, tt.a, err)
+		}
+	}
+}
+
+func TestNormalizePDU(t *testing.T) {
+	n := NewAddressNormalizer("44", "0")
+	multi := NewSubmitMulti()
+	multi.SetSource(NewAddress("Shop", TON_UNKNOWN, NPI_UNKNOWN))
+	multi.AddDestAddr("07700900123", TON_UNKNOWN, NPI_UNKNOWN)
+	multi.AddDistributionList("staff")
+	multi.AddDestAddr("81234", TON_UNKNOWN, NPI_UNKNOWN)
+	if err := n.NormalizePDU(multi); err != nil {
+		t.Fatalf("NormalizePDU: %v", err)
+	}
+	if got := multi.Source(); got != AlphanumericAddress("Shop") {
+		t.Errorf("source %v", got)
+	}
+	want := []Address{InternationalAddress("447700900123"), {}, NewAddress("81234", TON_ABBREVIATED, NPI_UNKNOWN)}
+	for i, da := range multi.DestAddresses {
+		if da.IsDistributionList() {
+			if da.DLName != "staff" {
+				t.Errorf("dest %d: distribution list %q", i, da.DLName)
+			}
+			continue
+		}
+		if got := da.Address(); got != want[i] {
+			t.Errorf("dest %d: %v, want %v", i, got, want[i])
+		}
+	}
+
+	// An invalid destination leaves the PDU as it was
+	sm := NewSubmitSM()
+	sm.SetSource(NewAddress("07700900123", TON_UNKNOWN, NPI_UNKNOWN))
+	sm.SetDestination(NewAddress("+0123", TON_UNKNOWN, NPI_UNKNOWN))
+	if err := n.NormalizePDU(sm); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("NormalizePDU error = %v", err)
+	}
+	if sm.Source().Addr != "07700900123" {
+		t.Errorf("source changed to %v", sm.Source())
+	}
+}
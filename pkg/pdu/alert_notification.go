@@ -2,24 +2,43 @@ package pdu
 
 // AlertNotification represents an SMPP Alert Notification PDU
 type AlertNotification struct {
-	Header         *Header
-	SourceAddr     string
-	SourceAddrTON  uint8
-	SourceAddrNPI  uint8
-	EsmeAddr       string
-	EsmeAddrTON    uint8
-	EsmeAddrNPI    uint8
-	OptionalParams map[string]interface{}
+	Header        *Header `json:"header"`
+	SourceAddr    string  `json:"source_addr"`
+	SourceAddrTON uint8   `json:"source_addr_ton"`
+	SourceAddrNPI uint8   `json:"source_addr_npi"`
+	EsmeAddr      string  `json:"esme_addr"`
+	EsmeAddrTON   uint8   `json:"esme_addr_ton"`
+	EsmeAddrNPI   uint8   `json:"esme_addr_npi"`
+	TLVParams     TLVList `json:"tlvs,omitempty"`
 }
 
 // NewAlertNotification creates a new Alert Notification PDU
 func NewAlertNotification() *AlertNotification {
 	return &AlertNotification{
-		Header:         NewHeader(),
-		OptionalParams: make(map[string]interface{}),
+		Header: NewHeader(),
 	}
 }
 
+// Source returns source_addr with its TON and NPI
+func (an *AlertNotification) Source() Address {
+	return Address{TON: an.SourceAddrTON, NPI: an.SourceAddrNPI, Addr: an.SourceAddr}
+}
+
+// SetSource sets source_addr with its TON and NPI
+func (an *AlertNotification) SetSource(a Address) {
+	an.SetSourceAddr(a.Addr, a.TON, a.NPI)
+}
+
+// Esme returns esme_addr with its TON and NPI
+func (an *AlertNotification) Esme() Address {
+	return Address{TON: an.EsmeAddrTON, NPI: an.EsmeAddrNPI, Addr: an.EsmeAddr}
+}
+
+// SetEsme sets esme_addr with its TON and NPI
+func (an *AlertNotification) SetEsme(a Address) {
+	an.SetEsmeAddr(a.Addr, a.TON, a.NPI)
+}
+
 // SetSourceAddr sets the source address parameters
 func (an *AlertNotification) SetSourceAddr(addr string, ton, npi uint8) {
 	an.SourceAddr = addr
@@ -34,75 +53,100 @@ func (an *AlertNotification) SetEsmeAddr(addr string, ton, npi uint8) {
 	an.EsmeAddrNPI = npi
 }
 
-// Marshal serializes the PDU into bytes
-func (an *AlertNotification) Marshal() ([]byte, error) {
-	// Calculate the total length
-	length := 16                             // Header length
-	length += 1 + 1 + len(an.SourceAddr) + 1 // Source address params
-	length += 1 + 1 + len(an.EsmeAddr) + 1   // ESME address params
-
-	// Set the command length in the header
-	an.Header.CommandLength = uint32(length)
-	an.Header.CommandID = ALERT_NOTIFICATION
+// checkFields checks the mandatory fields against their specified sizes
+func (an *AlertNotification) checkFields() error {
+	return firstError(
+		checkCString("source_addr", an.SourceAddr, LONG_ADDR_MAX_LEN, ESME_RINVSRCADR),
+		checkCString("esme_addr", an.EsmeAddr, LONG_ADDR_MAX_LEN, ESME_RINVDSTADR),
+	)
+}
 
-	// Create the byte slice
-	buf := make([]byte, length)
+// EncodedLen returns the size of the encoded PDU in bytes
+func (an *AlertNotification) EncodedLen() int {
+	length := HeaderLength
+	length += 2 // source_addr_ton, source_addr_npi
+	length += len(an.SourceAddr) + 1
+	length += 2 // esme_addr_ton, esme_addr_npi
+	length += len(an.EsmeAddr) + 1
+	length += an.TLVParams.EncodedLen()
+	return length
+}
 
-	// Marshal header
-	headerBytes, err := an.Header.Marshal()
-	if err != nil {
-		return nil, err
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (an *AlertNotification) AppendTo(dst []byte) ([]byte, error) {
+	if err := an.checkFields(); err != nil {
+		return dst, err
 	}
-	copy(buf[0:], headerBytes)
-
-	offset := 16
 
-	// Write source address parameters
-	buf[offset] = an.SourceAddrTON
-	buf[offset+1] = an.SourceAddrNPI
-	copy(buf[offset+2:], []byte(an.SourceAddr))
-	offset += 2 + len(an.SourceAddr) + 1
+	an.Header.CommandLength = uint32(an.EncodedLen())
+	an.Header.CommandID = ALERT_NOTIFICATION
 
-	// Write ESME address parameters
-	buf[offset] = an.EsmeAddrTON
-	buf[offset+1] = an.EsmeAddrNPI
-	copy(buf[offset+2:], []byte(an.EsmeAddr))
+	dst = an.Header.AppendTo(dst)
+	dst = append(dst, an.SourceAddrTON, an.SourceAddrNPI)
+	dst = appendCString(dst, an.SourceAddr)
+	dst = append(dst, an.EsmeAddrTON, an.EsmeAddrNPI)
+	dst = appendCString(dst, an.EsmeAddr)
+	dst = an.TLVParams.AppendTo(dst)
+	return dst, nil
+}
 
-	return buf, nil
+// Marshal serializes the PDU into bytes
+func (an *AlertNotification) Marshal() ([]byte, error) {
+	return an.AppendTo(make([]byte, 0, an.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (an *AlertNotification) Unmarshal(data []byte) error {
-	// Unmarshal header
+	return an.decode(newDecoder(data, false))
+}
+
+// decode reads the PDU fields from dec
+func (an *AlertNotification) decode(dec *decoder) error {
 	an.Header = &Header{}
-	if err := an.Header.Unmarshal(data[:16]); err != nil {
-		return err
-	}
+	dec.header(an.Header)
 
-	offset := 16
+	an.SourceAddrTON = dec.uint8("source_addr_ton")
+	an.SourceAddrNPI = dec.uint8("source_addr_npi")
+	an.SourceAddr = dec.cstring("source_addr", LONG_ADDR_MAX_LEN, ESME_RINVSRCADR)
+	an.EsmeAddrTON = dec.uint8("esme_addr_ton")
+	an.EsmeAddrNPI = dec.uint8("esme_addr_npi")
+	an.EsmeAddr = dec.cstring("esme_addr", LONG_ADDR_MAX_LEN, ESME_RINVDSTADR)
 
-	// Read source address parameters
-	an.SourceAddrTON = data[offset]
-	an.SourceAddrNPI = data[offset+1]
-	offset += 2
+	dec.tlvs(&an.TLVParams)
+	return dec.Err()
+}
 
-	// Read source address
-	i := offset
-	for ; data[i] != 0; i++ {
-	}
-	an.SourceAddr = string(data[offset:i])
-	offset = i + 1
+// CommandID returns the command_id of the PDU
+func (an *AlertNotification) CommandID() uint32 {
+	return ALERT_NOTIFICATION
+}
 
-	// Read ESME address parameters
-	an.EsmeAddrTON = data[offset]
-	an.EsmeAddrNPI = data[offset+1]
-	offset += 2
+// GetHeader returns the PDU header
+func (an *AlertNotification) GetHeader() *Header {
+	return an.Header
+}
 
-	// Read ESME address
-	i = offset
-	for ; data[i] != 0; i++ {
-	}
-	an.EsmeAddr = string(data[offset:i])
+// String returns a one-line description of the PDU for trace logs
+func (an *AlertNotification) String() string {
+	return FormatPDU(an)
+}
+
+// MarshalJSON encodes the PDU as JSON
+func (an *AlertNotification) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(an)
+}
+
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (an *AlertNotification) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, an)
+}
+
+// GetTLVParams returns the optional parameters of the PDU
+func (an *AlertNotification) GetTLVParams() *TLVList {
+	return &an.TLVParams
+}
 
+// GetResponse returns nil as alert_notification has no response PDU
+func (an *AlertNotification) GetResponse() PDU {
 	return nil
 }
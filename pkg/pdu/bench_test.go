@@ -0,0 +1,95 @@
+package pdu
+
+import "testing"
+
+func benchSubmitSM() *SubmitSM {
+	s := NewSubmitSM()
+	s.SourceAddrTON = 5
+	s.SourceAddr = "SENDER"
+	s.DestAddrTON = 1
+	s.DestAddrNPI = 1
+	s.DestinationAddr = "447700900123"
+	s.RegisteredDelivery = 1
+	s.SetMessageText("Your verification code is 123456. It expires in 10 minutes.", DATA_CODING_DEFAULT)
+	s.TLVParams.SetUserMessageReference(42)
+	return s
+}
+
+func benchDeliverSM() *DeliverSM {
+	d := NewDeliverSM()
+	d.SourceAddrTON = 1
+	d.SourceAddrNPI = 1
+	d.SourceAddr = "447700900123"
+	d.DestinationAddr = "SENDER"
+	d.ESMClass = ESM_CLASS_TYPE_DELIVERY_RECEIPT
+	d.ShortMessage = []byte("id:0123456789 sub:001 dlvrd:001 submit date:2501011200 done date:2501011201 stat:DELIVRD err:000 text:Your verification")
+	d.TLVParams.SetReceiptedMessageID("0123456789")
+	d.TLVParams.SetMessageState(uint8(SMPP_34_MESSAGE_STATE_DELIVERED))
+	return d
+}
+
+func benchMarshal(b *testing.B, p PDU) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.Marshal(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchAppendTo(b *testing.B, p Appender) {
+	buf := make([]byte, 0, 512)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var err error
+		if buf, err = p.AppendTo(buf[:0]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchDecode(b *testing.B, p PDU, decode func([]byte) (PDU, error)) {
+	data, err := p.Marshal()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		if _, err := decode(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSubmitSMMarshal(b *testing.B) {
+	benchMarshal(b, benchSubmitSM())
+}
+
+func BenchmarkSubmitSMAppendTo(b *testing.B) {
+	benchAppendTo(b, benchSubmitSM())
+}
+
+func BenchmarkSubmitSMDecode(b *testing.B) {
+	benchDecode(b, benchSubmitSM(), Decode)
+}
+
+func BenchmarkSubmitSMDecodeAliased(b *testing.B) {
+	benchDecode(b, benchSubmitSM(), DecodeAliased)
+}
+
+func BenchmarkDeliverSMMarshal(b *testing.B) {
+	benchMarshal(b, benchDeliverSM())
+}
+
+func BenchmarkDeliverSMAppendTo(b *testing.B) {
+	benchAppendTo(b, benchDeliverSM())
+}
+
+func BenchmarkDeliverSMDecode(b *testing.B) {
+	benchDecode(b, benchDeliverSM(), Decode)
+}
+
+func BenchmarkDeliverSMDecodeAliased(b *testing.B) {
+	benchDecode(b, benchDeliverSM(), DecodeAliased)
+}
@@ -0,0 +1,241 @@
+package pdu
+
+import (
+	"errors"
+)
+
+var (
+	ErrEmptyBinary = errors.New("binary message has no data")
+)
+
+// Well known application ports
+const (
+	PORT_WAP_PUSH            uint16 = 2948  // WAP Push connectionless session service
+	PORT_WAP_PUSH_SECURE     uint16 = 2949  // WAP Push secure connectionless session service
+	PORT_WAP_WSP             uint16 = 9200  // WAP connectionless session service, source of pushes
+	PORT_WAP_WSP_SECURE      uint16 = 9202  // WAP secure connectionless session service
+	PORT_VCARD               uint16 = 9204  // vCard
+	PORT_VCALENDAR           uint16 = 9205  // vCalendar
+	PORT_NOKIA_RINGTONE      uint16 = 5505  // Nokia Smart Messaging ringing tone
+	PORT_NOKIA_OPERATOR_LOGO uint16 = 5506  // Nokia Smart Messaging operator logo
+	PORT_NOKIA_OTA_SETTINGS  uint16 = 49999 // Nokia OTA browser settings
+)
+
+// SIM data download (3GPP TS 31.115)
+const (
+	PROTOCOL_ID_SIM_DATA_DOWNLOAD uint8 = 0x7F // TP-PID of messages for the (U)SIM
+	DATA_CODING_SIM_8BIT          uint8 = 0xF6 // 8-bit data, message class 2 (SIM specific)
+)
+
+// NewPortIE creates an application port addressing element, with 16-bit
+// ports unless both fit into 8 bits
+func NewPortIE(destPort, sourcePort uint16) *PortIE {
+	return &PortIE{
+		DestPort:   destPort,
+		SourcePort: sourcePort,
+		Port16Bit:  destPort > 0xFF || sourcePort > 0xFF,
+	}
+}
+
+// BinaryMessage is 8-bit data for an application on the handset or the
+// SIM, such as a WAP Push, a vCard or an OTA command packet
+type BinaryMessage struct {
+	Data       []byte
+	DataCoding uint8   // DATA_CODING_BINARY unless set
+	ProtocolID uint8   // protocol_id of every part
+	Ports      *PortIE // Application ports, nil for none
+
+	// Elements are added to the UDH of every part, FirstElements only to
+	// that of the first
+	Elements      []UDHElement
+	FirstElements []UDHElement
+}
+
+// NewPortMessage creates a binary message for an application port
+func NewPortMessage(data []byte, destPort, sourcePort uint16) *BinaryMessage {
+	return &BinaryMessage{
+		Data:       data,
+		DataCoding: DATA_CODING_BINARY,
+		Ports:      NewPortIE(destPort, sourcePort),
+	}
+}
+
+// NewVCardMessage creates a message carrying a vCard to the address book
+func NewVCardMessage(vcard string) *BinaryMessage {
+	return NewPortMessage([]byte(vcard), PORT_VCARD, PORT_VCARD)
+}
+
+// NewVCalendarMessage creates a message carrying a vCalendar entry
+func NewVCalendarMessage(vcalendar string) *BinaryMessage {
+	return NewPortMessage([]byte(vcalendar), PORT_VCALENDAR, PORT_VCALENDAR)
+}
+
+// NewSIMDataDownload creates a message carrying a secured command packet
+// to the SIM. The command packet element is only in the first part.
+func NewSIMDataDownload(commandPacket []byte) *BinaryMessage {
+	return &BinaryMessage{
+		Data:          commandPacket,
+		DataCoding:    DATA_CODING_SIM_8BIT,
+		ProtocolID:    PROTOCOL_ID_SIM_DATA_DOWNLOAD,
+		FirstElements: []UDHElement{&RawIE{IEI: UDH_IE_SIM_COMMAND_PACKET}},
+	}
+}
+
+// udh returns the header of a part: the concatenation element, the ports
+// when they go into the UDH, and the further elements
+func (m *BinaryMessage) udh(concat *ConcatIE, ports bool, first bool) *UDH {
+	udh := NewUDH()
+	if concat != nil {
+		udh.Add(concat)
+	}
+	if ports && m.Ports != nil {
+		udh.Add(m.Ports)
+	}
+	if first {
+		for _, e := range m.FirstElements {
+			udh.Add(e)
+		}
+	}
+	for _, e := range m.Elements {
+		udh.Add(e)
+	}
+	return udh
+}
+
+// udhSize returns the octets a header takes in the user data, none when it
+// has no elements
+func udhSize(u *UDH) int {
+	if len(u.Elements) == 0 {
+		return 0
+	}
+	return u.Len()
+}
+
+// SplitBinary splits a binary message into segments for dest. The UDH
+// strategies put the ports into the UDH of every part next to the
+// concatenation element; SAR and payload put them into the source_port
+// and destination_port TLVs. Either way every part leaves room for the
+// elements the handset receives.
+func (s *Segmenter) SplitBinary(dest string, m *BinaryMessage) ([]Segment, error) {
+	if len(m.Data) == 0 {
+		return nil, ErrEmptyBinary
+	}
+	dataCoding := m.DataCoding
+	if dataCoding == 0 {
+		dataCoding = DATA_CODING_BINARY
+	}
+	portsInUDH := s.Strategy == SEGMENT_UDH_8BIT || s.Strategy == SEGMENT_UDH_16BIT
+
+	var portParams TLVList
+	if !portsInUDH && m.Ports != nil {
+		portParams.SetSourcePort(m.Ports.SourcePort)
+		portParams.SetDestinationPort(m.Ports.DestPort)
+	}
+
+	// segment builds one part from its header and data
+	segment := func(udh *UDH, data []byte) (Segment, error) {
+		seg := Segment{DataCoding: dataCoding, TLVParams: portParams.Clone()}
+		if len(udh.Elements) == 0 {
+			seg.ShortMessage = data
+			return seg, nil
+		}
+		seg.ESMClass = ESM_CLASS_UDHI
+		sm, err := udh.Prepend(data)
+		seg.ShortMessage = sm
+		return seg, err
+	}
+
+	// Parts are sized for the UDH on the air interface. The SMSC turns the
+	// port and SAR TLVs into UDH elements, which take the same room as if
+	// they had been sent in the UDH.
+	single := m.udh(nil, portsInUDH, true)
+	if udhSize(m.udh(nil, true, true))+len(m.Data) <= SM_MAX_USER_DATA {
+		seg, err := segment(single, m.Data)
+		return []Segment{seg}, err
+	}
+
+	if s.Strategy == SEGMENT_PAYLOAD {
+		seg, err := segment(single, m.Data)
+		if err != nil {
+			return nil, err
+		}
+		if len(seg.ShortMessage) > MESSAGE_PAYLOAD_MAX_LENGTH {
+			return nil, ErrMessageTooLong
+		}
+		seg.TLVParams.SetMessagePayload(seg.ShortMessage)
+		seg.ShortMessage = nil
+		return []Segment{seg}, nil
+	}
+
+	// The concatenation element is the same size in every part, 8-bit for
+	// the one the SMSC builds from the SAR TLVs
+	concat := &ConcatIE{Ref16Bit: s.Strategy == SEGMENT_UDH_16BIT}
+	firstLimit := SM_MAX_USER_DATA - udhSize(m.udh(concat, true, true))
+	limit := SM_MAX_USER_DATA - udhSize(m.udh(concat, true, false))
+	if firstLimit <= 0 || limit <= 0 {
+		return nil, ErrInvalidUDH
+	}
+
+	var parts [][]byte
+	for data, n := m.Data, firstLimit; len(data) > 0; n = limit {
+		n = min(n, len(data))
+		parts = append(parts, data[:n])
+		data = data[n:]
+	}
+	if len(parts) > SEGMENT_MAX_PARTS {
+		return nil, ErrTooManySegments
+	}
+
+	refs := s.Refs
+	if refs == nil {
+		refs = DefaultReferenceAllocator
+	}
+	ref := refs.Next(dest)
+	total := uint8(len(parts))
+
+	segments := make([]Segment, len(parts))
+	for i, part := range parts {
+		seq := uint8(i + 1)
+		var c *ConcatIE
+		if s.Strategy != SEGMENT_SAR {
+			c = &ConcatIE{Ref: ref, Total: total, SeqNum: seq, Ref16Bit: concat.Ref16Bit}
+			if !c.Ref16Bit {
+				c.Ref &= 0xFF
+			}
+		}
+		seg, err := segment(m.udh(c, portsInUDH, i == 0), part)
+		if err != nil {
+			return nil, err
+		}
+		if s.Strategy == SEGMENT_SAR {
+			seg.TLVParams.SetSAR(SARParams{RefNum: ref, Total: total, SeqNum: seq})
+		}
+		segments[i] = seg
+	}
+	return segments, nil
+}
+
+// SubmitSMBinary splits a binary message into submit_sm PDUs copied from
+// tmpl, which holds the addresses and other fields common to every part
+func (s *Segmenter) SubmitSMBinary(tmpl *SubmitSM, m *BinaryMessage) ([]*SubmitSM, error) {
+	segments, err := s.SplitBinary(tmpl.DestinationAddr, m)
+	if err != nil {
+		return nil, err
+	}
+
+	pdus := make([]*SubmitSM, len(segments))
+	for i, seg := range segments {
+		sm := *tmpl
+		sm.Header = NewHeader()
+		sm.DataCoding = seg.DataCoding
+		if m.ProtocolID != 0 {
+			sm.ProtocolID = m.ProtocolID
+		}
+		sm.ESMClass = tmpl.ESMClass&^ESM_CLASS_UDHI | seg.ESMClass
+		sm.ShortMessage = seg.ShortMessage
+		sm.SMLength = uint8(len(seg.ShortMessage))
+		sm.TLVParams = mergeSegmentParams(tmpl.TLVParams, seg.TLVParams)
+		pdus[i] = &sm
+	}
+	return pdus, nil
+}
@@ -1,174 +1,126 @@
 package pdu
 
-import (
-	"encoding/binary"
-	"errors"
-)
-
 // BindReceiver represents an SMPP bind_receiver PDU
 type BindReceiver struct {
-	Header           *Header
-	SystemID         string
-	Password         string
-	SystemType       string
-	InterfaceVersion uint8
-	AddrTON          uint8
-	AddrNPI          uint8
-	AddressRange     string
-	TLVParams        map[uint16]*TLVParam
+	Header           *Header `json:"header"`
+	SystemID         string  `json:"system_id"`
+	Password         string  `json:"password"`
+	SystemType       string  `json:"system_type"`
+	InterfaceVersion uint8   `json:"interface_version"`
+	AddrTON          uint8   `json:"addr_ton"`
+	AddrNPI          uint8   `json:"addr_npi"`
+	AddressRange     string  `json:"address_range"`
+	TLVParams        TLVList `json:"tlvs,omitempty"`
 }
 
 // NewBindReceiver creates a new BindReceiver PDU
 func NewBindReceiver() *BindReceiver {
 	return &BindReceiver{
-		Header:    NewHeader(),
-		TLVParams: make(map[uint16]*TLVParam),
+		Header: NewHeader(),
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (br *BindReceiver) Marshal() ([]byte, error) {
-	// Calculate the total length
-	length := 16 // Header length
+// checkFields checks the mandatory fields against their specified sizes
+func (br *BindReceiver) checkFields() error {
+	return firstError(
+		checkCString("system_id", br.SystemID, SYSTEM_ID_MAX_LEN, ESME_RINVSYSID),
+		checkCString("password", br.Password, PASSWORD_MAX_LEN, ESME_RINVPASWD),
+		checkCString("system_type", br.SystemType, SYSTEM_TYPE_MAX_LEN, ESME_RINVSYSTYP),
+		checkCString("address_range", br.AddressRange, ADDRESS_RANGE_MAX_LEN, ESME_RBINDFAIL),
+	)
+}
+
+// EncodedLen returns the size of the encoded PDU in bytes
+func (br *BindReceiver) EncodedLen() int {
+	length := HeaderLength
 	length += len(br.SystemID) + 1
 	length += len(br.Password) + 1
 	length += len(br.SystemType) + 1
-	length += 3 // Interface version + addr_ton + addr_npi
+	length += 3 // interface_version, addr_ton, addr_npi
 	length += len(br.AddressRange) + 1
+	length += br.TLVParams.EncodedLen()
+	return length
+}
 
-	// Add TLV parameters length
-	for _, tlv := range br.TLVParams {
-		length += 4 + len(tlv.Value) // Tag(2) + Length(2) + Value(n)
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (br *BindReceiver) AppendTo(dst []byte) ([]byte, error) {
+	if err := br.checkFields(); err != nil {
+		return dst, err
 	}
 
-	// Set command length in header
-	br.Header.CommandLength = uint32(length)
+	br.Header.CommandLength = uint32(br.EncodedLen())
 	br.Header.CommandID = BIND_RECEIVER
 
-	// Create the byte slice
-	buf := make([]byte, length)
-
-	// Marshal header
-	headerBytes, err := br.Header.Marshal()
-	if err != nil {
-		return nil, err
-	}
-	copy(buf[0:], headerBytes)
-
-	offset := 16
-
-	// Write system_id
-	copy(buf[offset:], br.SystemID)
-	offset += len(br.SystemID) + 1
-
-	// Write password
-	copy(buf[offset:], br.Password)
-	offset += len(br.Password) + 1
-
-	// Write system_type
-	copy(buf[offset:], br.SystemType)
-	offset += len(br.SystemType) + 1
-
-	// Write interface_version
-	buf[offset] = br.InterfaceVersion
-	offset++
-
-	// Write addr_ton
-	buf[offset] = br.AddrTON
-	offset++
-
-	// Write addr_npi
-	buf[offset] = br.AddrNPI
-	offset++
-
-	// Write address_range
-	copy(buf[offset:], br.AddressRange)
-	offset += len(br.AddressRange) + 1
-
-	// Write TLV parameters
-	for _, tlv := range br.TLVParams {
-		tlvBytes, err := tlv.Marshal()
-		if err != nil {
-			return nil, err
-		}
-		copy(buf[offset:], tlvBytes)
-		offset += 4 + len(tlv.Value)
-	}
+	dst = br.Header.AppendTo(dst)
+	dst = appendCString(dst, br.SystemID)
+	dst = appendCString(dst, br.Password)
+	dst = appendCString(dst, br.SystemType)
+	dst = append(dst, br.InterfaceVersion, br.AddrTON, br.AddrNPI)
+	dst = appendCString(dst, br.AddressRange)
+	dst = br.TLVParams.AppendTo(dst)
+	return dst, nil
+}
 
-	return buf, nil
+// Marshal serializes the PDU into bytes
+func (br *BindReceiver) Marshal() ([]byte, error) {
+	return br.AppendTo(make([]byte, 0, br.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (br *BindReceiver) Unmarshal(data []byte) error {
-	var err error
+	return br.decode(newDecoder(data, false))
+}
 
-	// Unmarshal header
+// decode reads the PDU fields from dec
+func (br *BindReceiver) decode(dec *decoder) error {
 	br.Header = &Header{}
-	if err = br.Header.Unmarshal(data[:16]); err != nil {
-		return err
-	}
-
-	offset := 16
-
-	// Read system_id
-	if br.SystemID, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
-
-	// Read password
-	if br.Password, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
-
-	// Read system_type
-	if br.SystemType, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
-
-	// Read interface_version
-	br.InterfaceVersion = data[offset]
-	offset++
-
-	// Read addr_ton
-	br.AddrTON = data[offset]
-	offset++
-
-	// Read addr_npi
-	br.AddrNPI = data[offset]
-	offset++
-
-	// Read address_range
-	if br.AddressRange, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+	dec.header(br.Header)
+
+	br.SystemID = dec.cstring("system_id", SYSTEM_ID_MAX_LEN, ESME_RINVSYSID)
+	br.Password = dec.cstring("password", PASSWORD_MAX_LEN, ESME_RINVPASWD)
+	br.SystemType = dec.cstring("system_type", SYSTEM_TYPE_MAX_LEN, ESME_RINVSYSTYP)
+	br.InterfaceVersion = dec.uint8("interface_version")
+	br.AddrTON = dec.uint8("addr_ton")
+	br.AddrNPI = dec.uint8("addr_npi")
+	br.AddressRange = dec.cstring("address_range", ADDRESS_RANGE_MAX_LEN, ESME_RBINDFAIL)
+
+	dec.tlvs(&br.TLVParams)
+	return dec.Err()
+}
 
-	// Read TLV parameters if any remain
-	remaining := int(br.Header.CommandLength) - offset
-	currentOffset := offset
+// CommandID returns the command_id of the PDU
+func (br *BindReceiver) CommandID() uint32 {
+	return BIND_RECEIVER
+}
 
-	for remaining > 0 {
-		if remaining < 4 {
-			return errors.New("invalid TLV parameter: insufficient data")
-		}
+// GetHeader returns the PDU header
+func (br *BindReceiver) GetHeader() *Header {
+	return br.Header
+}
 
-		tag := binary.BigEndian.Uint16(data[currentOffset:])
-		length := binary.BigEndian.Uint16(data[currentOffset+2:])
+// String returns a one-line description of the PDU for trace logs
+func (br *BindReceiver) String() string {
+	return FormatPDU(br)
+}
 
-		if remaining < int(4+length) {
-			return errors.New("invalid TLV parameter: insufficient data for value")
-		}
+// MarshalJSON encodes the PDU as JSON
+func (br *BindReceiver) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(br)
+}
 
-		tlv := &TLVParam{
-			Tag:    tag,
-			Length: length,
-			Value:  make([]byte, length),
-		}
-		copy(tlv.Value, data[currentOffset+4:currentOffset+4+int(length)])
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (br *BindReceiver) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, br)
+}
 
-		br.TLVParams[tag] = tlv
-		currentOffset += 4 + int(length)
-		remaining -= 4 + int(length)
-	}
+// GetTLVParams returns the optional parameters of the PDU
+func (br *BindReceiver) GetTLVParams() *TLVList {
+	return &br.TLVParams
+}
 
-	return nil
+// GetResponse creates the bind_receiver_resp answering this PDU
+func (br *BindReceiver) GetResponse() PDU {
+	resp := NewBindReceiverResp()
+	resp.Header = responseHeader(br.Header, BIND_RECEIVER_RESP)
+	return resp
 }
@@ -1,101 +1,101 @@
 package pdu
 
-import "errors"
-
 // BindReceiverResp represents an SMPP bind_receiver_resp PDU
 type BindReceiverResp struct {
-	Header    *Header
-	SystemID  string
-	TLVParams map[uint16]*TLVParam
+	Header    *Header `json:"header"`
+	SystemID  string  `json:"system_id"`
+	TLVParams TLVList `json:"tlvs,omitempty"`
 }
 
 // NewBindReceiverResp creates a new BindReceiverResp PDU
 func NewBindReceiverResp() *BindReceiverResp {
 	return &BindReceiverResp{
-		Header:    NewHeader(),
-		TLVParams: make(map[uint16]*TLVParam),
+		Header: NewHeader(),
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (brr *BindReceiverResp) Marshal() ([]byte, error) {
-	// Calculate the total length
-	length := 16 // Header length
+// checkFields checks the mandatory fields against their specified sizes
+func (brr *BindReceiverResp) checkFields() error {
+	return firstError(
+		checkCString("system_id", brr.SystemID, SYSTEM_ID_MAX_LEN, ESME_RINVSYSID),
+	)
+}
+
+// EncodedLen returns the size of the encoded PDU in bytes
+func (brr *BindReceiverResp) EncodedLen() int {
+	length := HeaderLength
 	length += len(brr.SystemID) + 1
+	length += brr.TLVParams.EncodedLen()
+	return length
+}
 
-	// Add TLV parameters length
-	for _, tlv := range brr.TLVParams {
-		length += 4 + len(tlv.Value) // Tag(2) + Length(2) + Value(n)
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (brr *BindReceiverResp) AppendTo(dst []byte) ([]byte, error) {
+	if err := brr.checkFields(); err != nil {
+		return dst, err
 	}
 
-	// Set command length in header
-	brr.Header.CommandLength = uint32(length)
+	brr.Header.CommandLength = uint32(brr.EncodedLen())
 	brr.Header.CommandID = BIND_RECEIVER_RESP
 
-	// Create the byte slice
-	buf := make([]byte, length)
-
-	// Marshal header
-	headerBytes, err := brr.Header.Marshal()
-	if err != nil {
-		return nil, err
-	}
-	copy(buf[0:], headerBytes)
-
-	offset := 16
-
-	// Write system_id
-	copy(buf[offset:], brr.SystemID)
-	offset += len(brr.SystemID) + 1
-
-	// Write TLV parameters
-	for _, tlv := range brr.TLVParams {
-		tlvBytes, err := tlv.Marshal()
-		if err != nil {
-			return nil, err
-		}
-		copy(buf[offset:], tlvBytes)
-		offset += 4 + len(tlv.Value)
-	}
+	dst = brr.Header.AppendTo(dst)
+	dst = appendCString(dst, brr.SystemID)
+	dst = brr.TLVParams.AppendTo(dst)
+	return dst, nil
+}
 
-	return buf, nil
+// Marshal serializes the PDU into bytes
+func (brr *BindReceiverResp) Marshal() ([]byte, error) {
+	return brr.AppendTo(make([]byte, 0, brr.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (brr *BindReceiverResp) Unmarshal(data []byte) error {
-	var err error
+	return brr.decode(newDecoder(data, false))
+}
 
-	// Unmarshal header
+// decode reads the PDU fields from dec
+func (brr *BindReceiverResp) decode(dec *decoder) error {
 	brr.Header = &Header{}
-	if err = brr.Header.Unmarshal(data[:16]); err != nil {
-		return err
-	}
+	dec.header(brr.Header)
 
-	offset := 16
+	brr.SystemID = dec.cstring("system_id", SYSTEM_ID_MAX_LEN, ESME_RINVSYSID)
 
-	// Read system_id
-	if brr.SystemID, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+	dec.tlvs(&brr.TLVParams)
+	return dec.Err()
+}
+
+// CommandID returns the command_id of the PDU
+func (brr *BindReceiverResp) CommandID() uint32 {
+	return BIND_RECEIVER_RESP
+}
+
+// GetHeader returns the PDU header
+func (brr *BindReceiverResp) GetHeader() *Header {
+	return brr.Header
+}
 
-	// Read TLV parameters if any remain
-	remaining := int(brr.Header.CommandLength) - offset
-	currentOffset := offset
+// String returns a one-line description of the PDU for trace logs
+func (brr *BindReceiverResp) String() string {
+	return FormatPDU(brr)
+}
 
-	for remaining > 0 {
-		if remaining < 4 {
-			return errors.New("invalid TLV parameter: insufficient data")
-		}
+// MarshalJSON encodes the PDU as JSON
+func (brr *BindReceiverResp) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(brr)
+}
 
-		tlv := &TLVParam{}
-		if err := tlv.Unmarshal(data[currentOffset:]); err != nil {
-			return err
-		}
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (brr *BindReceiverResp) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, brr)
+}
 
-		brr.TLVParams[tlv.Tag] = tlv
-		currentOffset += 4 + int(tlv.Length)
-		remaining -= 4 + int(tlv.Length)
-	}
+// GetTLVParams returns the optional parameters of the PDU
+func (brr *BindReceiverResp) GetTLVParams() *TLVList {
+	return &brr.TLVParams
+}
 
+// GetResponse returns nil as bind_receiver_resp has no response PDU
+func (brr *BindReceiverResp) GetResponse() PDU {
 	return nil
 }
@@ -1,101 +1,101 @@
 package pdu
 
-import "errors"
-
 // BindTransceiverResp represents an SMPP bind_transceiver_resp PDU
 type BindTransceiverResp struct {
-	Header    *Header
-	SystemID  string
-	TLVParams map[uint16]*TLVParam
+	Header    *Header `json:"header"`
+	SystemID  string  `json:"system_id"`
+	TLVParams TLVList `json:"tlvs,omitempty"`
 }
 
 // NewBindTransceiverResp creates a new BindTransceiverResp PDU
 func NewBindTransceiverResp() *BindTransceiverResp {
 	return &BindTransceiverResp{
-		Header:    NewHeader(),
-		TLVParams: make(map[uint16]*TLVParam),
+		Header: NewHeader(),
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (btr *BindTransceiverResp) Marshal() ([]byte, error) {
-	// Calculate the total length
-	length := 16 // Header length
+// checkFields checks the mandatory fields against their specified sizes
+func (btr *BindTransceiverResp) checkFields() error {
+	return firstError(
+		checkCString("system_id", btr.SystemID, SYSTEM_ID_MAX_LEN, ESME_RINVSYSID),
+	)
+}
+
+// EncodedLen returns the size of the encoded PDU in bytes
+func (btr *BindTransceiverResp) EncodedLen() int {
+	length := HeaderLength
 	length += len(btr.SystemID) + 1
+	length += btr.TLVParams.EncodedLen()
+	return length
+}
 
-	// Add TLV parameters length
-	for _, tlv := range btr.TLVParams {
-		length += 4 + len(tlv.Value) // Tag(2) + Length(2) + Value(n)
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (btr *BindTransceiverResp) AppendTo(dst []byte) ([]byte, error) {
+	if err := btr.checkFields(); err != nil {
+		return dst, err
 	}
 
-	// Set command length in header
-	btr.Header.CommandLength = uint32(length)
+	btr.Header.CommandLength = uint32(btr.EncodedLen())
 	btr.Header.CommandID = BIND_TRANSCEIVER_RESP
 
-	// Create the byte slice
-	buf := make([]byte, length)
-
-	// Marshal header
-	headerBytes, err := btr.Header.Marshal()
-	if err != nil {
-		return nil, err
-	}
-	copy(buf[0:], headerBytes)
-
-	offset := 16
-
-	// Write system_id
-	copy(buf[offset:], btr.SystemID)
-	offset += len(btr.SystemID) + 1
-
-	// Write TLV parameters
-	for _, tlv := range btr.TLVParams {
-		tlvBytes, err := tlv.Marshal()
-		if err != nil {
-			return nil, err
-		}
-		copy(buf[offset:], tlvBytes)
-		offset += 4 + len(tlv.Value)
-	}
+	dst = btr.Header.AppendTo(dst)
+	dst = appendCString(dst, btr.SystemID)
+	dst = btr.TLVParams.AppendTo(dst)
+	return dst, nil
+}
 
-	return buf, nil
+// Marshal serializes the PDU into bytes
+func (btr *BindTransceiverResp) Marshal() ([]byte, error) {
+	return btr.AppendTo(make([]byte, 0, btr.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (btr *BindTransceiverResp) Unmarshal(data []byte) error {
-	var err error
+	return btr.decode(newDecoder(data, false))
+}
 
-	// Unmarshal header
+// decode reads the PDU fields from dec
+func (btr *BindTransceiverResp) decode(dec *decoder) error {
 	btr.Header = &Header{}
-	if err = btr.Header.Unmarshal(data[:16]); err != nil {
-		return err
-	}
+	dec.header(btr.Header)
 
-	offset := 16
+	btr.SystemID = dec.cstring("system_id", SYSTEM_ID_MAX_LEN, ESME_RINVSYSID)
 
-	// Read system_id
-	if btr.SystemID, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+	dec.tlvs(&btr.TLVParams)
+	return dec.Err()
+}
+
+// CommandID returns the command_id of the PDU
+func (btr *BindTransceiverResp) CommandID() uint32 {
+	return BIND_TRANSCEIVER_RESP
+}
+
+// GetHeader returns the PDU header
+func (btr *BindTransceiverResp) GetHeader() *Header {
+	return btr.Header
+}
 
-	// Read TLV parameters if any remain
-	remaining := int(btr.Header.CommandLength) - offset
-	currentOffset := offset
+// String returns a one-line description of the PDU for trace logs
+func (btr *BindTransceiverResp) String() string {
+	return FormatPDU(btr)
+}
 
-	for remaining > 0 {
-		if remaining < 4 {
-			return errors.New("invalid TLV parameter: insufficient data")
-		}
+// MarshalJSON encodes the PDU as JSON
+func (btr *BindTransceiverResp) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(btr)
+}
 
-		tlv := &TLVParam{}
-		if err := tlv.Unmarshal(data[currentOffset:]); err != nil {
-			return err
-		}
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (btr *BindTransceiverResp) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, btr)
+}
 
-		btr.TLVParams[tlv.Tag] = tlv
-		currentOffset += 4 + int(tlv.Length)
-		remaining -= 4 + int(tlv.Length)
-	}
+// GetTLVParams returns the optional parameters of the PDU
+func (btr *BindTransceiverResp) GetTLVParams() *TLVList {
+	return &btr.TLVParams
+}
 
+// GetResponse returns nil as bind_transceiver_resp has no response PDU
+func (btr *BindTransceiverResp) GetResponse() PDU {
 	return nil
 }
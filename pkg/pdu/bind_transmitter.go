@@ -1,174 +1,126 @@
 package pdu
 
-import (
-	"encoding/binary"
-	"errors"
-)
-
 // BindTransmitter represents an SMPP bind_transmitter PDU
 type BindTransmitter struct {
-	Header           *Header
-	SystemID         string
-	Password         string
-	SystemType       string
-	InterfaceVersion uint8
-	AddrTON          uint8
-	AddrNPI          uint8
-	AddressRange     string
-	TLVParams        map[uint16]*TLVParam
+	Header           *Header `json:"header"`
+	SystemID         string  `json:"system_id"`
+	Password         string  `json:"password"`
+	SystemType       string  `json:"system_type"`
+	InterfaceVersion uint8   `json:"interface_version"`
+	AddrTON          uint8   `json:"addr_ton"`
+	AddrNPI          uint8   `json:"addr_npi"`
+	AddressRange     string  `json:"address_range"`
+	TLVParams        TLVList `json:"tlvs,omitempty"`
 }
 
 // NewBindTransmitter creates a new BindTransmitter PDU
 func NewBindTransmitter() *BindTransmitter {
 	return &BindTransmitter{
-		Header:    NewHeader(),
-		TLVParams: make(map[uint16]*TLVParam),
+		Header: NewHeader(),
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (bt *BindTransmitter) Marshal() ([]byte, error) {
-	// Calculate the total length
-	length := 16 // Header length
+// checkFields checks the mandatory fields against their specified sizes
+func (bt *BindTransmitter) checkFields() error {
+	return firstError(
+		checkCString("system_id", bt.SystemID, SYSTEM_ID_MAX_LEN, ESME_RINVSYSID),
+		checkCString("password", bt.Password, PASSWORD_MAX_LEN, ESME_RINVPASWD),
+		checkCString("system_type", bt.SystemType, SYSTEM_TYPE_MAX_LEN, ESME_RINVSYSTYP),
+		checkCString("address_range", bt.AddressRange, ADDRESS_RANGE_MAX_LEN, ESME_RBINDFAIL),
+	)
+}
+
+// EncodedLen returns the size of the encoded PDU in bytes
+func (bt *BindTransmitter) EncodedLen() int {
+	length := HeaderLength
 	length += len(bt.SystemID) + 1
 	length += len(bt.Password) + 1
 	length += len(bt.SystemType) + 1
-	length += 3 // Interface version + addr_ton + addr_npi
+	length += 3 // interface_version, addr_ton, addr_npi
 	length += len(bt.AddressRange) + 1
+	length += bt.TLVParams.EncodedLen()
+	return length
+}
 
-	// Add TLV parameters length
-	for _, tlv := range bt.TLVParams {
-		length += 4 + len(tlv.Value) // Tag(2) + Length(2) + Value(n)
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (bt *BindTransmitter) AppendTo(dst []byte) ([]byte, error) {
+	if err := bt.checkFields(); err != nil {
+		return dst, err
 	}
 
-	// Set command length in header
-	bt.Header.CommandLength = uint32(length)
+	bt.Header.CommandLength = uint32(bt.EncodedLen())
 	bt.Header.CommandID = BIND_TRANSMITTER
 
-	// Create the byte slice
-	buf := make([]byte, length)
-
-	// Marshal header
-	headerBytes, err := bt.Header.Marshal()
-	if err != nil {
-		return nil, err
-	}
-	copy(buf[0:], headerBytes)
-
-	offset := 16
-
-	// Write system_id
-	copy(buf[offset:], bt.SystemID)
-	offset += len(bt.SystemID) + 1
-
-	// Write password
-	copy(buf[offset:], bt.Password)
-	offset += len(bt.Password) + 1
-
-	// Write system_type
-	copy(buf[offset:], bt.SystemType)
-	offset += len(bt.SystemType) + 1
-
-	// Write interface_version
-	buf[offset] = bt.InterfaceVersion
-	offset++
-
-	// Write addr_ton
-	buf[offset] = bt.AddrTON
-	offset++
-
-	// Write addr_npi
-	buf[offset] = bt.AddrNPI
-	offset++
-
-	// Write address_range
-	copy(buf[offset:], bt.AddressRange)
-	offset += len(bt.AddressRange) + 1
-
-	// Write TLV parameters
-	for _, tlv := range bt.TLVParams {
-		tlvBytes, err := tlv.Marshal()
-		if err != nil {
-			return nil, err
-		}
-		copy(buf[offset:], tlvBytes)
-		offset += 4 + len(tlv.Value)
-	}
+	dst = bt.Header.AppendTo(dst)
+	dst = appendCString(dst, bt.SystemID)
+	dst = appendCString(dst, bt.Password)
+	dst = appendCString(dst, bt.SystemType)
+	dst = append(dst, bt.InterfaceVersion, bt.AddrTON, bt.AddrNPI)
+	dst = appendCString(dst, bt.AddressRange)
+	dst = bt.TLVParams.AppendTo(dst)
+	return dst, nil
+}
 
-	return buf, nil
+// Marshal serializes the PDU into bytes
+func (bt *BindTransmitter) Marshal() ([]byte, error) {
+	return bt.AppendTo(make([]byte, 0, bt.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (bt *BindTransmitter) Unmarshal(data []byte) error {
-	var err error
+	return bt.decode(newDecoder(data, false))
+}
 
-	// Unmarshal header
+// decode reads the PDU fields from dec
+func (bt *BindTransmitter) decode(dec *decoder) error {
 	bt.Header = &Header{}
-	if err = bt.Header.Unmarshal(data[:16]); err != nil {
-		return err
-	}
-
-	offset := 16
-
-	// Read system_id
-	if bt.SystemID, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
-
-	// Read password
-	if bt.Password, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
-
-	// Read system_type
-	if bt.SystemType, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
-
-	// Read interface_version
-	bt.InterfaceVersion = data[offset]
-	offset++
-
-	// Read addr_ton
-	bt.AddrTON = data[offset]
-	offset++
-
-	// Read addr_npi
-	bt.AddrNPI = data[offset]
-	offset++
-
-	// Read address_range
-	if bt.AddressRange, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+	dec.header(bt.Header)
+
+	bt.SystemID = dec.cstring("system_id", SYSTEM_ID_MAX_LEN, ESME_RINVSYSID)
+	bt.Password = dec.cstring("password", PASSWORD_MAX_LEN, ESME_RINVPASWD)
+	bt.SystemType = dec.cstring("system_type", SYSTEM_TYPE_MAX_LEN, ESME_RINVSYSTYP)
+	bt.InterfaceVersion = dec.uint8("interface_version")
+	bt.AddrTON = dec.uint8("addr_ton")
+	bt.AddrNPI = dec.uint8("addr_npi")
+	bt.AddressRange = dec.cstring("address_range", ADDRESS_RANGE_MAX_LEN, ESME_RBINDFAIL)
+
+	dec.tlvs(&bt.TLVParams)
+	return dec.Err()
+}
 
-	// Read TLV parameters if any remain
-	remaining := int(bt.Header.CommandLength) - offset
-	currentOffset := offset
+// CommandID returns the command_id of the PDU
+func (bt *BindTransmitter) CommandID() uint32 {
+	return BIND_TRANSMITTER
+}
 
-	for remaining > 0 {
-		if remaining < 4 {
-			return errors.New("invalid TLV parameter: insufficient data")
-		}
+// GetHeader returns the PDU header
+func (bt *BindTransmitter) GetHeader() *Header {
+	return bt.Header
+}
 
-		tag := binary.BigEndian.Uint16(data[currentOffset:])
-		length := binary.BigEndian.Uint16(data[currentOffset+2:])
+// String returns a one-line description of the PDU for trace logs
+func (bt *BindTransmitter) String() string {
+	return FormatPDU(bt)
+}
 
-		if remaining < int(4+length) {
-			return errors.New("invalid TLV parameter: insufficient data for value")
-		}
+// MarshalJSON encodes the PDU as JSON
+func (bt *BindTransmitter) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(bt)
+}
 
-		tlv := &TLVParam{
-			Tag:    tag,
-			Length: length,
-			Value:  make([]byte, length),
-		}
-		copy(tlv.Value, data[currentOffset+4:currentOffset+4+int(length)])
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (bt *BindTransmitter) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, bt)
+}
 
-		bt.TLVParams[tag] = tlv
-		currentOffset += 4 + int(length)
-		remaining -= 4 + int(length)
-	}
+// GetTLVParams returns the optional parameters of the PDU
+func (bt *BindTransmitter) GetTLVParams() *TLVList {
+	return &bt.TLVParams
+}
 
-	return nil
+// GetResponse creates the bind_transmitter_resp answering this PDU
+func (bt *BindTransmitter) GetResponse() PDU {
+	resp := NewBindTransmitterResp()
+	resp.Header = responseHeader(bt.Header, BIND_TRANSMITTER_RESP)
+	return resp
 }
@@ -0,0 +1,461 @@
+package pdu
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidBroadcastArea    = NewStatusError(ESME_RBCAST_AREA_FORMAT_INVALID, "invalid broadcast area")
+	ErrInvalidBroadcastChannel = NewStatusError(ESME_RBCAST_CHANNEL_INVALID, "invalid broadcast channel indicator")
+	ErrInvalidBroadcastValue   = NewStatusError(ESME_RINVOPTPARAMVAL, "invalid broadcast parameter")
+)
+
+// Broadcast area limits
+const (
+	BCAST_AREA_MAX_DETAILS int = 100 // Area details after the format octet
+	BCAST_AREA_LAI_LEN     int = 5   // MCC/MNC and LAC
+	BCAST_AREA_CGI_LEN     int = 7   // MCC/MNC, LAC and cell identity
+)
+
+// broadcastMandatoryTLVs are the TLVs every broadcast_sm must carry
+var broadcastMandatoryTLVs = []uint16{
+	TLV_BROADCAST_AREA_IDENTIFIER,
+	TLV_BROADCAST_CONTENT_TYPE,
+	TLV_BROADCAST_REP_NUM,
+	TLV_BROADCAST_FREQUENCY_INTERVAL,
+}
+
+// broadcastValueTLVs are the broadcast TLVs whose values are checked
+// before a broadcast_sm is sent
+var broadcastValueTLVs = map[uint16]bool{
+	TLV_BROADCAST_AREA_IDENTIFIER:    true,
+	TLV_BROADCAST_CONTENT_TYPE:       true,
+	TLV_BROADCAST_FREQUENCY_INTERVAL: true,
+	TLV_BROADCAST_CHANNEL_INDICATOR:  true,
+}
+
+// BroadcastArea is a broadcast_area_identifier value: an area format
+// followed by its details
+type BroadcastArea struct {
+	Format  uint8
+	Details []byte
+}
+
+// CellID locates a location area, or a cell within one, of a mobile network
+type CellID struct {
+	MCC string // Mobile country code, 3 digits
+	MNC string // Mobile network code, 2 or 3 digits
+	LAC uint16 // Location area code
+	CI  uint16 // Cell identity, unused for location areas
+}
+
+// BroadcastAreaName creates an area given by its name
+func BroadcastAreaName(name string) (BroadcastArea, error) {
+	a := BroadcastArea{Format: SMPP_50_BCAST_AREA_FORMAT_NAME, Details: []byte(name)}
+	return a, a.Validate()
+}
+
+// BroadcastAreaAlias creates an area given by an alias provisioned on the
+// broadcast centre
+func BroadcastAreaAlias(alias string) (BroadcastArea, error) {
+	a := BroadcastArea{Format: SMPP_50_BCAST_AREA_FORMAT_ALIAS, Details: []byte(alias)}
+	return a, a.Validate()
+}
+
+// BroadcastAreaMSC creates the area served by an MSC, given by its E.164
+// address
+func BroadcastAreaMSC(address string) (BroadcastArea, error) {
+	a := BroadcastArea{Format: SMPP_50_BCAST_AREA_FORMAT_MSC, Details: []byte(address)}
+	return a, a.Validate()
+}
+
+// BroadcastAreaHLR creates the area of the subscribers of an HLR, given by
+// its E.164 address
+func BroadcastAreaHLR(address string) (BroadcastArea, error) {
+	a := BroadcastArea{Format: SMPP_50_BCAST_AREA_FORMAT_HLR, Details: []byte(address)}
+	return a, a.Validate()
+}
+
+// BroadcastAreaLocation creates a location area. The CI of id is ignored.
+func BroadcastAreaLocation(id CellID) (BroadcastArea, error) {
+	plmn, err := encodePLMN(id.MCC, id.MNC)
+	if err != nil {
+		return BroadcastArea{}, err
+	}
+	details := binary.BigEndian.AppendUint16(plmn, id.LAC)
+	return BroadcastArea{Format: SMPP_50_BCAST_AREA_FORMAT_LAC, Details: details}, nil
+}
+
+// BroadcastAreaCell creates a single cell area
+func BroadcastAreaCell(id CellID) (BroadcastArea, error) {
+	plmn, err := encodePLMN(id.MCC, id.MNC)
+	if err != nil {
+		return BroadcastArea{}, err
+	}
+	details := binary.BigEndian.AppendUint16(plmn, id.LAC)
+	details = binary.BigEndian.AppendUint16(details, id.CI)
+	return BroadcastArea{Format: SMPP_50_BCAST_AREA_FORMAT_CELL, Details: details}, nil
+}
+
+// BroadcastAreaAll creates the area of the whole network
+func BroadcastAreaAll() BroadcastArea {
+	return BroadcastArea{Format: SMPP_50_BCAST_AREA_FORMAT_ALL}
+}
+
+// ParseBroadcastArea parses and validates a broadcast_area_identifier value
+func ParseBroadcastArea(value []byte) (BroadcastArea, error) {
+	if len(value) == 0 {
+		return BroadcastArea{}, fmt.Errorf("%w: empty", ErrInvalidBroadcastArea)
+	}
+	a := BroadcastArea{Format: value[0], Details: value[1:]}
+	return a, a.Validate()
+}
+
+// Marshal encodes the area as a broadcast_area_identifier value
+func (a BroadcastArea) Marshal() []byte {
+	return append([]byte{a.Format}, a.Details...)
+}
+
+// Validate checks the details against the area format. Formats from 0x80
+// on are SMSC specific and only checked for length.
+func (a BroadcastArea) Validate() error {
+	if len(a.Details) > BCAST_AREA_MAX_DETAILS {
+		return fmt.Errorf("%w: %d octets of details exceed %d", ErrInvalidBroadcastArea, len(a.Details), BCAST_AREA_MAX_DETAILS)
+	}
+
+	switch a.Format {
+	case SMPP_50_BCAST_AREA_FORMAT_NAME, SMPP_50_BCAST_AREA_FORMAT_ALIAS:
+		if len(a.Details) == 0 {
+			return fmt.Errorf("%w: empty name", ErrInvalidBroadcastArea)
+		}
+	case SMPP_50_BCAST_AREA_FORMAT_MSC, SMPP_50_BCAST_AREA_FORMAT_HLR:
+		if !isDigits(string(a.Details)) || len(a.Details) > E164_MAX_DIGITS {
+			return fmt.Errorf("%w: address must be up to %d digits", ErrInvalidBroadcastArea, E164_MAX_DIGITS)
+		}
+	case SMPP_50_BCAST_AREA_FORMAT_LAC:
+		if len(a.Details) != BCAST_AREA_LAI_LEN {
+			return fmt.Errorf("%w: location area of %d octets, want %d", ErrInvalidBroadcastArea, len(a.Details), BCAST_AREA_LAI_LEN)
+		}
+		if _, _, err := decodePLMN(a.Details); err != nil {
+			return err
+		}
+	case SMPP_50_BCAST_AREA_FORMAT_CELL:
+		if len(a.Details) != BCAST_AREA_CGI_LEN {
+			return fmt.Errorf("%w: cell of %d octets, want %d", ErrInvalidBroadcastArea, len(a.Details), BCAST_AREA_CGI_LEN)
+		}
+		if _, _, err := decodePLMN(a.Details); err != nil {
+			return err
+		}
+	case SMPP_50_BCAST_AREA_FORMAT_ALL:
+		if len(a.Details) != 0 {
+			return fmt.Errorf("%w: whole network area has details", ErrInvalidBroadcastArea)
+		}
+	default:
+		if a.Format < 0x80 {
+			return fmt.Errorf("%w: unknown format 0x%02X", ErrInvalidBroadcastArea, a.Format)
+		}
+	}
+	return nil
+}
+
+// Text returns the name, alias or E.164 address of the area
+func (a BroadcastArea) Text() (string, bool) {
+	switch a.Format {
+	case SMPP_50_BCAST_AREA_FORMAT_NAME, SMPP_50_BCAST_AREA_FORMAT_ALIAS,
+		SMPP_50_BCAST_AREA_FORMAT_MSC, SMPP_50_BCAST_AREA_FORMAT_HLR:
+		return string(a.Details), true
+	}
+	return "", false
+}
+
+// CellID returns the location area or cell of the area
+func (a BroadcastArea) CellID() (CellID, bool) {
+	var id CellID
+	var err error
+	switch {
+	case a.Format == SMPP_50_BCAST_AREA_FORMAT_LAC && len(a.Details) == BCAST_AREA_LAI_LEN:
+	case a.Format == SMPP_50_BCAST_AREA_FORMAT_CELL && len(a.Details) == BCAST_AREA_CGI_LEN:
+		id.CI = binary.BigEndian.Uint16(a.Details[5:7])
+	default:
+		return id, false
+	}
+	if id.MCC, id.MNC, err = decodePLMN(a.Details); err != nil {
+		return id, false
+	}
+	id.LAC = binary.BigEndian.Uint16(a.Details[3:5])
+	return id, true
+}
+
+// encodePLMN encodes MCC and MNC as the 3 BCD octets of 3GPP TS 24.008,
+// a 2 digit MNC being padded with 0xF
+func encodePLMN(mcc, mnc string) ([]byte, error) {
+	if len(mcc) != 3 || !isDigits(mcc) || (len(mnc) != 2 && len(mnc) != 3) || !isDigits(mnc) {
+		return nil, fmt.Errorf("%w: MCC %q and MNC %q must be 3 and 2 or 3 digits", ErrInvalidBroadcastArea, mcc, mnc)
+	}
+	mnc3 := byte(0xF)
+	if len(mnc) == 3 {
+		mnc3 = mnc[2] - '0'
+	}
+	return []byte{
+		(mcc[1]-'0')<<4 | (mcc[0] - '0'),
+		mnc3<<4 | (mcc[2] - '0'),
+		(mnc[1]-'0')<<4 | (mnc[0] - '0'),
+	}, nil
+}
+
+// decodePLMN decodes the MCC and MNC at the start of data
+func decodePLMN(data []byte) (string, string, error) {
+	digits := []byte{data[0] & 0xF, data[0] >> 4, data[1] & 0xF, data[2] & 0xF, data[2] >> 4, data[1] >> 4}
+	n := len(digits)
+	if digits[5] == 0xF {
+		n--
+	}
+	for _, d := range digits[:n] {
+		if d > 9 {
+			return "", "", fmt.Errorf("%w: MCC/MNC is not BCD", ErrInvalidBroadcastArea)
+		}
+	}
+	for i := range digits[:n] {
+		digits[i] += '0'
+	}
+	return string(digits[:3]), string(digits[3:n]), nil
+}
+
+// BroadcastContentType is a broadcast_content_type value: the network type
+// and the service the content belongs to
+type BroadcastContentType struct {
+	Network uint8
+	Service uint16
+}
+
+// Marshal encodes the content type as a TLV value
+func (c BroadcastContentType) Marshal() []byte {
+	return binary.BigEndian.AppendUint16([]byte{c.Network}, c.Service)
+}
+
+// ParseBroadcastContentType parses a broadcast_content_type value
+func ParseBroadcastContentType(value []byte) (BroadcastContentType, error) {
+	if len(value) != 3 {
+		return BroadcastContentType{}, fmt.Errorf("%w: content type of %d octets", ErrInvalidBroadcastValue, len(value))
+	}
+	c := BroadcastContentType{Network: value[0], Service: binary.BigEndian.Uint16(value[1:3])}
+	if c.Network > SMPP_50_BCAST_NETWORK_CDMA {
+		return c, fmt.Errorf("%w: unknown network type %d", ErrInvalidBroadcastValue, c.Network)
+	}
+	return c, nil
+}
+
+// BroadcastFrequency is a broadcast_frequency_interval value: how often a
+// message is repeated, as a number of time units
+type BroadcastFrequency struct {
+	Unit  uint8
+	Count uint16
+}
+
+// broadcastFrequencyUnits are the exact time units, largest first
+var broadcastFrequencyUnits = []struct {
+	unit     uint8
+	duration time.Duration
+}{
+	{SMPP_50_BCAST_FREQ_WEEKS, 7 * 24 * time.Hour},
+	{SMPP_50_BCAST_FREQ_DAYS, 24 * time.Hour},
+	{SMPP_50_BCAST_FREQ_HOURS, time.Hour},
+	{SMPP_50_BCAST_FREQ_MINUTES, time.Minute},
+	{SMPP_50_BCAST_FREQ_SECONDS, time.Second},
+}
+
+// NewBroadcastFrequency expresses an interval in the largest unit that
+// represents it exactly. A zero interval means as frequently as possible.
+func NewBroadcastFrequency(interval time.Duration) (BroadcastFrequency, error) {
+	if interval == 0 {
+		return BroadcastFrequency{Unit: SMPP_50_BCAST_FREQ_ASAP}, nil
+	}
+	for _, u := range broadcastFrequencyUnits {
+		if interval%u.duration == 0 && interval/u.duration <= 0xFFFF && interval > 0 {
+			return BroadcastFrequency{Unit: u.unit, Count: uint16(interval / u.duration)}, nil
+		}
+	}
+	return BroadcastFrequency{}, fmt.Errorf("%w: interval %s is not a whole number of seconds up to 65535 units", ErrInvalidBroadcastValue, interval)
+}
+
+// Marshal encodes the frequency as a TLV value
+func (f BroadcastFrequency) Marshal() []byte {
+	return binary.BigEndian.AppendUint16([]byte{f.Unit}, f.Count)
+}
+
+// Interval returns the frequency as a duration. Months and years have no
+// fixed length and as frequently as possible has none at all.
+func (f BroadcastFrequency) Interval() (time.Duration, bool) {
+	for _, u := range broadcastFrequencyUnits {
+		if u.unit == f.Unit {
+			return time.Duration(f.Count) * u.duration, true
+		}
+	}
+	return 0, false
+}
+
+// ParseBroadcastFrequency parses a broadcast_frequency_interval value
+func ParseBroadcastFrequency(value []byte) (BroadcastFrequency, error) {
+	if len(value) != 3 {
+		return BroadcastFrequency{}, fmt.Errorf("%w: frequency interval of %d octets", ErrInvalidBroadcastValue, len(value))
+	}
+	f := BroadcastFrequency{Unit: value[0], Count: binary.BigEndian.Uint16(value[1:3])}
+	switch f.Unit {
+	case SMPP_50_BCAST_FREQ_ASAP, SMPP_50_BCAST_FREQ_MONTHS, SMPP_50_BCAST_FREQ_YEARS:
+	default:
+		if _, ok := f.Interval(); !ok {
+			return f, fmt.Errorf("%w: unknown frequency unit 0x%02X", ErrInvalidBroadcastValue, f.Unit)
+		}
+	}
+	return f, nil
+}
+
+// validateBroadcastArea is the schema validator of broadcast_area_identifier
+func validateBroadcastArea(value []byte) error {
+	_, err := ParseBroadcastArea(value)
+	return err
+}
+
+// validateBroadcastContentType is the schema validator of
+// broadcast_content_type
+func validateBroadcastContentType(value []byte) error {
+	_, err := ParseBroadcastContentType(value)
+	return err
+}
+
+// validateBroadcastFrequency is the schema validator of
+// broadcast_frequency_interval
+func validateBroadcastFrequency(value []byte) error {
+	_, err := ParseBroadcastFrequency(value)
+	return err
+}
+
+// validateBroadcastChannel is the schema validator of
+// broadcast_channel_indicator
+func validateBroadcastChannel(value []byte) error {
+	if value[0] > SMPP_50_BCAST_CHANNEL_EXTENDED {
+		return fmt.Errorf("%w: %d", ErrInvalidBroadcastChannel, value[0])
+	}
+	return nil
+}
+
+// validateBroadcastAreaSuccess is the schema validator of
+// broadcast_area_success, a percentage or SMPP_50_BCAST_AREA_SUCCESS_UNKNOWN
+func validateBroadcastAreaSuccess(value []byte) error {
+	if value[0] > 100 && value[0] != SMPP_50_BCAST_AREA_SUCCESS_UNKNOWN {
+		return fmt.Errorf("%w: area success rate %d", ErrInvalidBroadcastValue, value[0])
+	}
+	return nil
+}
+
+// checkBroadcastParams checks that a broadcast_sm carries the mandatory
+// broadcast TLVs and that their values are valid
+func checkBroadcastParams(params TLVList) error {
+	for _, tag := range broadcastMandatoryTLVs {
+		if !params.Has(tag) {
+			return &TLVError{Tag: tag, Name: DefaultTLVSchema.Name(tag), Status: ESME_RMISSINGOPTPARAM,
+				Reason: "mandatory in broadcast_sm"}
+		}
+	}
+	for _, tlv := range params {
+		if !broadcastValueTLVs[tlv.Tag] {
+			continue
+		}
+		if def, ok := DefaultTLVSchema.Lookup(tlv.Tag); ok {
+			if err := def.CheckValue(tlv.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// BroadcastAreas returns the broadcast_area_identifier TLVs
+func (l TLVList) BroadcastAreas() ([]BroadcastArea, error) {
+	var areas []BroadcastArea
+	for _, tlv := range l.GetAll(TLV_BROADCAST_AREA_IDENTIFIER) {
+		a, err := ParseBroadcastArea(tlv.Value)
+		if err != nil {
+			return areas, err
+		}
+		areas = append(areas, a)
+	}
+	return areas, nil
+}
+
+// AddBroadcastArea adds a broadcast_area_identifier TLV, which may be
+// repeated
+func (l *TLVList) AddBroadcastArea(a BroadcastArea) {
+	l.Add(TLV_BROADCAST_AREA_IDENTIFIER, a.Marshal())
+}
+
+// BroadcastContentType returns the broadcast_content_type TLV
+func (l TLVList) BroadcastContentType() (BroadcastContentType, bool) {
+	value, ok := l.GetOctets(TLV_BROADCAST_CONTENT_TYPE)
+	if !ok {
+		return BroadcastContentType{}, false
+	}
+	c, err := ParseBroadcastContentType(value)
+	return c, err == nil
+}
+
+// SetBroadcastContentType sets the broadcast_content_type TLV
+func (l *TLVList) SetBroadcastContentType(c BroadcastContentType) {
+	l.Set(TLV_BROADCAST_CONTENT_TYPE, c.Marshal())
+}
+
+// BroadcastRepNum returns the broadcast_rep_num TLV
+func (l TLVList) BroadcastRepNum() (uint16, bool) {
+	return l.GetUint16(TLV_BROADCAST_REP_NUM)
+}
+
+// SetBroadcastRepNum sets the broadcast_rep_num TLV
+func (l *TLVList) SetBroadcastRepNum(n uint16) {
+	l.SetUint16(TLV_BROADCAST_REP_NUM, n)
+}
+
+// BroadcastFrequency returns the broadcast_frequency_interval TLV
+func (l TLVList) BroadcastFrequency() (BroadcastFrequency, bool) {
+	value, ok := l.GetOctets(TLV_BROADCAST_FREQUENCY_INTERVAL)
+	if !ok {
+		return BroadcastFrequency{}, false
+	}
+	f, err := ParseBroadcastFrequency(value)
+	return f, err == nil
+}
+
+// SetBroadcastFrequency sets the broadcast_frequency_interval TLV
+func (l *TLVList) SetBroadcastFrequency(f BroadcastFrequency) {
+	l.Set(TLV_BROADCAST_FREQUENCY_INTERVAL, f.Marshal())
+}
+
+// BroadcastChannelIndicator returns the broadcast_channel_indicator TLV
+func (l TLVList) BroadcastChannelIndicator() (uint8, bool) {
+	return l.GetUint8(TLV_BROADCAST_CHANNEL_INDICATOR)
+}
+
+// SetBroadcastChannelIndicator sets the broadcast_channel_indicator TLV
+func (l *TLVList) SetBroadcastChannelIndicator(channel uint8) error {
+	if err := validateBroadcastChannel([]byte{channel}); err != nil {
+		return err
+	}
+	l.SetUint8(TLV_BROADCAST_CHANNEL_INDICATOR, channel)
+	return nil
+}
+
+// BroadcastAreaSuccess returns the broadcast_area_success TLV, a
+// percentage or SMPP_50_BCAST_AREA_SUCCESS_UNKNOWN
+func (l TLVList) BroadcastAreaSuccess() (uint8, bool) {
+	return l.GetUint8(TLV_BROADCAST_AREA_SUCCESS)
+}
+
+// SetBroadcastAreaSuccess sets the broadcast_area_success TLV
+func (l *TLVList) SetBroadcastAreaSuccess(percent uint8) error {
+	if err := validateBroadcastAreaSuccess([]byte{percent}); err != nil {
+		return err
+	}
+	l.SetUint8(TLV_BROADCAST_AREA_SUCCESS, percent)
+	return nil
+}
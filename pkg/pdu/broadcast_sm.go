@@ -1,214 +1,209 @@
 package pdu
 
 import (
-	"encoding/binary"
-	"errors"
+	"fmt"
+	"time"
 )
 
 // BroadcastSM represents an SMPP broadcast_sm PDU (SMPP v5.0)
 type BroadcastSM struct {
-	Header               *Header
-	ServiceType          string
-	SourceAddrTON        uint8
-	SourceAddrNPI        uint8
-	SourceAddr           string
-	MessageID            string
-	PriorityFlag         uint8
-	ScheduleDeliveryTime string
-	ValidityPeriod       string
-	ReplaceIfPresent     uint8
-	DataCoding           uint8
-	SMDefaultMsgID       uint8
-	TLVParams            map[uint16]*TLVParam
+	Header               *Header `json:"header"`
+	ServiceType          string  `json:"service_type"`
+	SourceAddrTON        uint8   `json:"source_addr_ton"`
+	SourceAddrNPI        uint8   `json:"source_addr_npi"`
+	SourceAddr           string  `json:"source_addr"`
+	MessageID            string  `json:"message_id"`
+	PriorityFlag         uint8   `json:"priority_flag"`
+	ScheduleDeliveryTime string  `json:"schedule_delivery_time"`
+	ValidityPeriod       string  `json:"validity_period"`
+	ReplaceIfPresent     uint8   `json:"replace_if_present_flag"`
+	DataCoding           uint8   `json:"data_coding"`
+	SMDefaultMsgID       uint8   `json:"sm_default_msg_id"`
+	TLVParams            TLVList `json:"tlvs,omitempty"`
 }
 
 // NewBroadcastSM creates a new BroadcastSM PDU
 func NewBroadcastSM() *BroadcastSM {
 	return &BroadcastSM{
-		Header:    NewHeader(),
-		TLVParams: make(map[uint16]*TLVParam),
+		Header: NewHeader(),
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (b *BroadcastSM) Marshal() ([]byte, error) {
-	// Calculate the total length
-	length := 16 // Header length
-	length += len(b.ServiceType) + 1
-	length += 2 // Source addr ton and npi
-	length += len(b.SourceAddr) + 1
-	length += len(b.MessageID) + 1
-	length += 1 // Priority flag
-	length += len(b.ScheduleDeliveryTime) + 1
-	length += len(b.ValidityPeriod) + 1
-	length += 3 // Replace if present, data coding, sm default msg id
+// Source returns source_addr with its TON and NPI
+func (b *BroadcastSM) Source() Address {
+	return Address{TON: b.SourceAddrTON, NPI: b.SourceAddrNPI, Addr: b.SourceAddr}
+}
 
-	// Add TLV parameters length
-	for _, tlv := range b.TLVParams {
-		length += 4 + len(tlv.Value) // Tag(2) + Length(2) + Value(n)
-	}
+// SetSource sets source_addr with its TON and NPI
+func (b *BroadcastSM) SetSource(a Address) {
+	b.SourceAddrTON, b.SourceAddrNPI, b.SourceAddr = a.TON, a.NPI, a.Addr
+}
 
-	// Set command length in header
-	b.Header.CommandLength = uint32(length)
-	b.Header.CommandID = BROADCAST_SM
+// SetScheduleDeliveryTime sets an absolute schedule_delivery_time, the zero
+// time clears it
+func (b *BroadcastSM) SetScheduleDeliveryTime(t time.Time) {
+	b.ScheduleDeliveryTime = FormatAbsoluteTime(t)
+}
 
-	// Create the byte slice
-	buf := make([]byte, length)
+// SetScheduleDeliveryDelay sets a relative schedule_delivery_time
+func (b *BroadcastSM) SetScheduleDeliveryDelay(delay time.Duration) {
+	b.ScheduleDeliveryTime = FormatRelativeTime(delay)
+}
 
-	// Marshal header
-	headerBytes, err := b.Header.Marshal()
+// ScheduledAt returns the schedule_delivery_time, resolving a relative time
+// against now. The zero time means immediate delivery.
+func (b *BroadcastSM) ScheduledAt(now time.Time) (time.Time, error) {
+	t, err := ParseSMPPTime(b.ScheduleDeliveryTime, now)
 	if err != nil {
-		return nil, err
-	}
-	copy(buf[0:], headerBytes)
-
-	offset := 16
-
-	// Write service_type
-	copy(buf[offset:], b.ServiceType)
-	offset += len(b.ServiceType) + 1
-
-	// Write source_addr_ton
-	buf[offset] = b.SourceAddrTON
-	offset++
-
-	// Write source_addr_npi
-	buf[offset] = b.SourceAddrNPI
-	offset++
-
-	// Write source_addr
-	copy(buf[offset:], b.SourceAddr)
-	offset += len(b.SourceAddr) + 1
-
-	// Write message_id
-	copy(buf[offset:], b.MessageID)
-	offset += len(b.MessageID) + 1
-
-	// Write priority_flag
-	buf[offset] = b.PriorityFlag
-	offset++
-
-	// Write schedule_delivery_time
-	copy(buf[offset:], b.ScheduleDeliveryTime)
-	offset += len(b.ScheduleDeliveryTime) + 1
-
-	// Write validity_period
-	copy(buf[offset:], b.ValidityPeriod)
-	offset += len(b.ValidityPeriod) + 1
-
-	// Write replace_if_present
-	buf[offset] = b.ReplaceIfPresent
-	offset++
-
-	// Write data_coding
-	buf[offset] = b.DataCoding
-	offset++
-
-	// Write sm_default_msg_id
-	buf[offset] = b.SMDefaultMsgID
-	offset++
-
-	// Write TLV parameters
-	for _, tlv := range b.TLVParams {
-		tlvBytes, err := tlv.Marshal()
-		if err != nil {
-			return nil, err
-		}
-		copy(buf[offset:], tlvBytes)
-		offset += 4 + len(tlv.Value)
+		return t, fmt.Errorf("%w: %v", ErrInvalidScheduleTime, err)
 	}
+	return t, nil
+}
 
-	return buf, nil
+// SetValidityPeriod sets an absolute validity_period, the zero time clears it
+func (b *BroadcastSM) SetValidityPeriod(t time.Time) {
+	b.ValidityPeriod = FormatAbsoluteTime(t)
 }
 
-// Unmarshal deserializes the PDU from bytes
-func (b *BroadcastSM) Unmarshal(data []byte) error {
-	var err error
+// SetValidityDuration sets a relative validity_period
+func (b *BroadcastSM) SetValidityDuration(validity time.Duration) {
+	b.ValidityPeriod = FormatRelativeTime(validity)
+}
 
-	// Unmarshal header
-	b.Header = &Header{}
-	if err = b.Header.Unmarshal(data[:16]); err != nil {
-		return err
+// ExpiresAt returns the validity_period, resolving a relative time against
+// now. The zero time means the SMSC default validity.
+func (b *BroadcastSM) ExpiresAt(now time.Time) (time.Time, error) {
+	t, err := ParseSMPPTime(b.ValidityPeriod, now)
+	if err != nil {
+		return t, fmt.Errorf("%w: %v", ErrInvalidValidityPeriod, err)
 	}
+	return t, nil
+}
 
-	offset := 16
-
-	// Read service_type
-	if b.ServiceType, offset, err = ReadCString(data[offset:]); err != nil {
+// Validate checks the schedule_delivery_time and validity_period, and that
+// the mandatory broadcast TLVs are present and valid
+func (b *BroadcastSM) Validate() error {
+	if err := validateMessageTimes(b.ScheduleDeliveryTime, b.ValidityPeriod); err != nil {
 		return err
 	}
+	return checkBroadcastParams(b.TLVParams)
+}
 
-	// Read source_addr_ton
-	b.SourceAddrTON = data[offset]
-	offset++
+// checkFields checks the mandatory fields against their specified sizes
+func (b *BroadcastSM) checkFields() error {
+	return firstError(
+		checkCString("service_type", b.ServiceType, SERVICE_TYPE_MAX_LEN, ESME_RINVSERTYP),
+		checkCString("source_addr", b.SourceAddr, ADDR_MAX_LEN, ESME_RINVSRCADR),
+		checkCString("message_id", b.MessageID, MESSAGE_ID_MAX_LEN, ESME_RINVMSGID),
+		checkCString("schedule_delivery_time", b.ScheduleDeliveryTime, TIME_MAX_LEN, ESME_RINVSCHED),
+		checkCString("validity_period", b.ValidityPeriod, TIME_MAX_LEN, ESME_RINVEXPIRY),
+	)
+}
 
-	// Read source_addr_npi
-	b.SourceAddrNPI = data[offset]
-	offset++
+// EncodedLen returns the size of the encoded PDU in bytes
+func (b *BroadcastSM) EncodedLen() int {
+	length := HeaderLength
+	length += len(b.ServiceType) + 1
+	length += 2 // source_addr_ton, source_addr_npi
+	length += len(b.SourceAddr) + 1
+	length += len(b.MessageID) + 1
+	length++ // priority_flag
+	length += len(b.ScheduleDeliveryTime) + 1
+	length += len(b.ValidityPeriod) + 1
+	length += 3 // replace_if_present, data_coding, sm_default_msg_id
+	length += b.TLVParams.EncodedLen()
+	return length
+}
 
-	// Read source_addr
-	if b.SourceAddr, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (b *BroadcastSM) AppendTo(dst []byte) ([]byte, error) {
+	if err := b.checkFields(); err != nil {
+		return dst, err
 	}
-
-	// Read message_id
-	if b.MessageID, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
+	if err := checkBroadcastParams(b.TLVParams); err != nil {
+		return dst, err
 	}
 
-	// Read priority_flag
-	b.PriorityFlag = data[offset]
-	offset++
-
-	// Read schedule_delivery_time
-	if b.ScheduleDeliveryTime, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+	b.Header.CommandLength = uint32(b.EncodedLen())
+	b.Header.CommandID = BROADCAST_SM
 
-	// Read validity_period
-	if b.ValidityPeriod, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+	dst = b.Header.AppendTo(dst)
+	dst = appendCString(dst, b.ServiceType)
+	dst = append(dst, b.SourceAddrTON, b.SourceAddrNPI)
+	dst = appendCString(dst, b.SourceAddr)
+	dst = appendCString(dst, b.MessageID)
+	dst = append(dst, b.PriorityFlag)
+	dst = appendCString(dst, b.ScheduleDeliveryTime)
+	dst = appendCString(dst, b.ValidityPeriod)
+	dst = append(dst, b.ReplaceIfPresent, b.DataCoding, b.SMDefaultMsgID)
+	dst = b.TLVParams.AppendTo(dst)
+	return dst, nil
+}
 
-	// Read replace_if_present
-	b.ReplaceIfPresent = data[offset]
-	offset++
+// Marshal serializes the PDU into bytes
+func (b *BroadcastSM) Marshal() ([]byte, error) {
+	return b.AppendTo(make([]byte, 0, b.EncodedLen()))
+}
 
-	// Read data_coding
-	b.DataCoding = data[offset]
-	offset++
+// Unmarshal deserializes the PDU from bytes
+func (b *BroadcastSM) Unmarshal(data []byte) error {
+	return b.decode(newDecoder(data, false))
+}
 
-	// Read sm_default_msg_id
-	b.SMDefaultMsgID = data[offset]
-	offset++
+// decode reads the PDU fields from dec
+func (b *BroadcastSM) decode(dec *decoder) error {
+	b.Header = &Header{}
+	dec.header(b.Header)
+
+	b.ServiceType = dec.cstring("service_type", SERVICE_TYPE_MAX_LEN, ESME_RINVSERTYP)
+	b.SourceAddrTON = dec.uint8("source_addr_ton")
+	b.SourceAddrNPI = dec.uint8("source_addr_npi")
+	b.SourceAddr = dec.cstring("source_addr", ADDR_MAX_LEN, ESME_RINVSRCADR)
+	b.MessageID = dec.cstring("message_id", MESSAGE_ID_MAX_LEN, ESME_RINVMSGID)
+	b.PriorityFlag = dec.uint8("priority_flag")
+	b.ScheduleDeliveryTime = dec.cstring("schedule_delivery_time", TIME_MAX_LEN, ESME_RINVSCHED)
+	b.ValidityPeriod = dec.cstring("validity_period", TIME_MAX_LEN, ESME_RINVEXPIRY)
+	b.ReplaceIfPresent = dec.uint8("replace_if_present")
+	b.DataCoding = dec.uint8("data_coding")
+	b.SMDefaultMsgID = dec.uint8("sm_default_msg_id")
+
+	dec.tlvs(&b.TLVParams)
+	return dec.Err()
+}
 
-	// Read TLV parameters if any remain
-	remaining := int(b.Header.CommandLength) - offset
-	currentOffset := offset
+// CommandID returns the command_id of the PDU
+func (b *BroadcastSM) CommandID() uint32 {
+	return BROADCAST_SM
+}
 
-	for remaining > 0 {
-		if remaining < 4 {
-			return errors.New("invalid TLV parameter: insufficient data")
-		}
+// GetHeader returns the PDU header
+func (b *BroadcastSM) GetHeader() *Header {
+	return b.Header
+}
 
-		tag := binary.BigEndian.Uint16(data[currentOffset:])
-		length := binary.BigEndian.Uint16(data[currentOffset+2:])
+// String returns a one-line description of the PDU for trace logs
+func (b *BroadcastSM) String() string {
+	return FormatPDU(b)
+}
 
-		if remaining < int(4+length) {
-			return errors.New("invalid TLV parameter: insufficient data for value")
-		}
+// MarshalJSON encodes the PDU as JSON
+func (b *BroadcastSM) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(b)
+}
 
-		tlv := &TLVParam{
-			Tag:    tag,
-			Length: length,
-			Value:  make([]byte, length),
-		}
-		copy(tlv.Value, data[currentOffset+4:currentOffset+4+int(length)])
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (b *BroadcastSM) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, b)
+}
 
-		b.TLVParams[tag] = tlv
-		currentOffset += 4 + int(length)
-		remaining -= 4 + int(length)
-	}
+// GetTLVParams returns the optional parameters of the PDU
+func (b *BroadcastSM) GetTLVParams() *TLVList {
+	return &b.TLVParams
+}
 
-	return nil
+// GetResponse creates the broadcast_sm_resp answering this PDU
+func (b *BroadcastSM) GetResponse() PDU {
+	resp := NewBroadcastSMResp()
+	resp.Header = responseHeader(b.Header, BROADCAST_SM_RESP)
+	return resp
 }
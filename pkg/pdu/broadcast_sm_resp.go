@@ -1,113 +1,101 @@
 package pdu
 
-import (
-	"encoding/binary"
-	"errors"
-)
-
 // BroadcastSMResp represents an SMPP broadcast_sm_resp PDU (SMPP v5.0)
 type BroadcastSMResp struct {
-	Header    *Header
-	MessageID string
-	TLVParams map[uint16]*TLVParam
+	Header    *Header `json:"header"`
+	MessageID string  `json:"message_id"`
+	TLVParams TLVList `json:"tlvs,omitempty"`
 }
 
 // NewBroadcastSMResp creates a new BroadcastSMResp PDU
 func NewBroadcastSMResp() *BroadcastSMResp {
 	return &BroadcastSMResp{
-		Header:    NewHeader(),
-		TLVParams: make(map[uint16]*TLVParam),
+		Header: NewHeader(),
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (b *BroadcastSMResp) Marshal() ([]byte, error) {
-	// Calculate the total length
-	length := 16 // Header length
+// checkFields checks the mandatory fields against their specified sizes
+func (b *BroadcastSMResp) checkFields() error {
+	return firstError(
+		checkCString("message_id", b.MessageID, MESSAGE_ID_MAX_LEN, ESME_RINVMSGID),
+	)
+}
+
+// EncodedLen returns the size of the encoded PDU in bytes
+func (b *BroadcastSMResp) EncodedLen() int {
+	length := HeaderLength
 	length += len(b.MessageID) + 1
+	length += b.TLVParams.EncodedLen()
+	return length
+}
 
-	// Add TLV parameters length
-	for _, tlv := range b.TLVParams {
-		length += 4 + len(tlv.Value) // Tag(2) + Length(2) + Value(n)
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (b *BroadcastSMResp) AppendTo(dst []byte) ([]byte, error) {
+	if err := b.checkFields(); err != nil {
+		return dst, err
 	}
 
-	// Set command length in header
-	b.Header.CommandLength = uint32(length)
+	b.Header.CommandLength = uint32(b.EncodedLen())
 	b.Header.CommandID = BROADCAST_SM_RESP
 
-	// Create the byte slice
-	buf := make([]byte, length)
-
-	// Marshal header
-	headerBytes, err := b.Header.Marshal()
-	if err != nil {
-		return nil, err
-	}
-	copy(buf[0:], headerBytes)
-
-	offset := 16
-
-	// Write message_id
-	copy(buf[offset:], b.MessageID)
-	offset += len(b.MessageID) + 1
-
-	// Write TLV parameters
-	for _, tlv := range b.TLVParams {
-		tlvBytes, err := tlv.Marshal()
-		if err != nil {
-			return nil, err
-		}
-		copy(buf[offset:], tlvBytes)
-		offset += 4 + len(tlv.Value)
-	}
+	dst = b.Header.AppendTo(dst)
+	dst = appendCString(dst, b.MessageID)
+	dst = b.TLVParams.AppendTo(dst)
+	return dst, nil
+}
 
-	return buf, nil
+// Marshal serializes the PDU into bytes
+func (b *BroadcastSMResp) Marshal() ([]byte, error) {
+	return b.AppendTo(make([]byte, 0, b.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (b *BroadcastSMResp) Unmarshal(data []byte) error {
-	var err error
+	return b.decode(newDecoder(data, false))
+}
 
-	// Unmarshal header
+// decode reads the PDU fields from dec
+func (b *BroadcastSMResp) decode(dec *decoder) error {
 	b.Header = &Header{}
-	if err = b.Header.Unmarshal(data[:16]); err != nil {
-		return err
-	}
+	dec.header(b.Header)
 
-	offset := 16
+	b.MessageID = dec.cstring("message_id", MESSAGE_ID_MAX_LEN, ESME_RINVMSGID)
 
-	// Read message_id
-	if b.MessageID, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+	dec.tlvs(&b.TLVParams)
+	return dec.Err()
+}
 
-	// Read TLV parameters if any remain
-	remaining := int(b.Header.CommandLength) - offset
-	currentOffset := offset
+// CommandID returns the command_id of the PDU
+func (b *BroadcastSMResp) CommandID() uint32 {
+	return BROADCAST_SM_RESP
+}
 
-	for remaining > 0 {
-		if remaining < 4 {
-			return errors.New("invalid TLV parameter: insufficient data")
-		}
+// GetHeader returns the PDU header
+func (b *BroadcastSMResp) GetHeader() *Header {
+	return b.Header
+}
 
-		tag := binary.BigEndian.Uint16(data[currentOffset:])
-		length := binary.BigEndian.Uint16(data[currentOffset+2:])
+// String returns a one-line description of the PDU for trace logs
+func (b *BroadcastSMResp) String() string {
+	return FormatPDU(b)
+}
 
-		if remaining < int(4+length) {
-			return errors.New("invalid TLV parameter: insufficient data for value")
-		}
+// MarshalJSON encodes the PDU as JSON
+func (b *BroadcastSMResp) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(b)
+}
 
-		tlv := &TLVParam{
-			Tag:    tag,
-			Length: length,
-			Value:  make([]byte, length),
-		}
-		copy(tlv.Value, data[currentOffset+4:currentOffset+4+int(length)])
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (b *BroadcastSMResp) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, b)
+}
 
-		b.TLVParams[tag] = tlv
-		currentOffset += 4 + int(length)
-		remaining -= 4 + int(length)
-	}
+// GetTLVParams returns the optional parameters of the PDU
+func (b *BroadcastSMResp) GetTLVParams() *TLVList {
+	return &b.TLVParams
+}
 
+// GetResponse returns nil as broadcast_sm_resp has no response PDU
+func (b *BroadcastSMResp) GetResponse() PDU {
 	return nil
 }
@@ -0,0 +1,256 @@
+package pdu
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testBroadcastParams returns the mandatory broadcast_sm TLVs
+func testBroadcastParams() TLVList {
+	var l TLVList
+	l.AddBroadcastArea(BroadcastAreaAll())
+	l.SetBroadcastContentType(BroadcastContentType{Network: SMPP_50_BCAST_NETWORK_GSM, Service: SMPP_50_BCAST_CONTENT_EMERGENCY})
+	l.SetBroadcastRepNum(3)
+	l.SetBroadcastFrequency(BroadcastFrequency{Unit: SMPP_50_BCAST_FREQ_MINUTES, Count: 5})
+	return l
+}
+
+func TestBroadcastAreaFormats(t *testing.T) {
+	area := func(a BroadcastArea, err error) BroadcastArea {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+	tests := []struct {
+		name  string
+		area  BroadcastArea
+		value []byte
+		text  string
+		cell  *CellID
+	}{
+		{"name", area(BroadcastAreaName("London")), []byte("\x00London"), "London", nil},
+		{"alias", area(BroadcastAreaAlias("zone-7")), []byte("\x01zone-7"), "zone-7", nil},
+		{"msc", area(BroadcastAreaMSC("447700900000")), []byte("\x02447700900000"), "447700900000", nil},
+		{"hlr", area(BroadcastAreaHLR("447700900001")), []byte("\x05447700900001"), "447700900001", nil},
+		{
+			"location area",
+			area(BroadcastAreaLocation(CellID{MCC: "234", MNC: "15", LAC: 0x1234, CI: 9})),
+			[]byte{0x03, 0x32, 0xF4, 0x51, 0x12, 0x34}, "",
+			&CellID{MCC: "234", MNC: "15", LAC: 0x1234},
+		},
+		{
+			"cell",
+			area(BroadcastAreaCell(CellID{MCC: "310", MNC: "260", LAC: 0x00FF, CI: 0xABCD})),
+			[]byte{0x04, 0x13, 0x00, 0x62, 0x00, 0xFF, 0xAB, 0xCD}, "",
+			&CellID{MCC: "310", MNC: "260", LAC: 0x00FF, CI: 0xABCD},
+		},
+		{"all", BroadcastAreaAll(), []byte{0x06}, "", nil},
+	}
+	for _, tt := range tests {
+		value := tt.area.Marshal()
+		if !bytes.Equal(value, tt.value) {
+			t.Errorf("%s: Marshal = %X, want %X", tt.name, value, tt.value)
+		}
+		got, err := ParseBroadcastArea(value)
+		if err != nil {
+			t.Errorf("%s: ParseBroadcastArea: %v", tt.name, err)
+			continue
+		}
+		if got.Format != tt.area.Format || !bytes.Equal(got.Details, tt.area.Details) {
+			t.Errorf("%s: parsed %+v, want %+v", tt.name, got, tt.area)
+		}
+		if text, ok := got.Text(); text != tt.text || ok != (tt.text != "") {
+			t.Errorf("%s: Text = %q, %v", tt.name, text, ok)
+		}
+		id, ok := got.CellID()
+		if ok != (tt.cell != nil) || (ok && id != *tt.cell) {
+			t.Errorf("%s: CellID = %+v, %v, want %+v", tt.name, id, ok, tt.cell)
+		}
+	}
+}
+
+func TestBroadcastPLMN(t *testing.T) {
+	tests := []struct {
+		mcc, mnc string
+		bcd      []byte
+	}{
+		{"234", "15", []byte{0x32, 0xF4, 0x51}},
+		{"001", "01", []byte{0x00, 0xF1, 0x10}},
+		{"310", "260", []byte{0x13, 0x00, 0x62}},
+		{"001", "001", []byte{0x00, 0x11, 0x00}},
+	}
+	for _, tt := range tests {
+		bcd, err := encodePLMN(tt.mcc, tt.mnc)
+		if err != nil || !bytes.Equal(bcd, tt.bcd) {
+			t.Errorf("encodePLMN(%q, %q) = %X, %v, want %X", tt.mcc, tt.mnc, bcd, err, tt.bcd)
+			continue
+		}
+		mcc, mnc, err := decodePLMN(bcd)
+		if err != nil || mcc != tt.mcc || mnc != tt.mnc {
+			t.Errorf("decodePLMN(%X) = %q, %q, %v", bcd, mcc, mnc, err)
+		}
+	}
+
+	for _, id := range []CellID{
+		{MCC: "23", MNC: "15"},
+		{MCC: "2345", MNC: "15"},
+		{MCC: "234", MNC: "1"},
+		{MCC: "234", MNC: "1234"},
+		{MCC: "23a", MNC: "15"},
+	} {
+		if _, err := BroadcastAreaCell(id); !errors.Is(err, ErrInvalidBroadcastArea) {
+			t.Errorf("BroadcastAreaCell(%+v) error = %v", id, err)
+		}
+	}
+}
+
+func TestBroadcastAreaInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value []byte
+	}{
+		{"empty", nil},
+		{"empty name", []byte{SMPP_50_BCAST_AREA_FORMAT_NAME}},
+		{"empty alias", []byte{SMPP_50_BCAST_AREA_FORMAT_ALIAS}},
+		{"msc not digits", []byte("\x02+4477")},
+		{"hlr too long", append([]byte{SMPP_50_BCAST_AREA_FORMAT_HLR}, strings.Repeat("1", E164_MAX_DIGITS+1)...)},
+		{"short location area", []byte{0x03, 0x32, 0xF4, 0x51, 0x12}},
+		{"long cell", []byte{0x04, 0x32, 0xF4, 0x51, 0x12, 0x34, 0x00, 0x01, 0x02}},
+		{"not bcd", []byte{0x03, 0x3A, 0xF4, 0x51, 0x12, 0x34}},
+		{"all with details", []byte{0x06, 0x00}},
+		{"unknown format", []byte{0x07, 'x'}},
+		{"too many details", append([]byte{0x80}, make([]byte, BCAST_AREA_MAX_DETAILS+1)...)},
+	}
+	for _, tt := range tests {
+		_, err := ParseBroadcastArea(tt.value)
+		if !errors.Is(err, ErrInvalidBroadcastArea) {
+			t.Errorf("%s: error = %v, want ErrInvalidBroadcastArea", tt.name, err)
+		} else if status, _ := ErrorStatus(err); status != ESME_RBCAST_AREA_FORMAT_INVALID {
+			t.Errorf("%s: status 0x%08X", tt.name, status)
+		}
+	}
+
+	// SMSC specific formats are only checked for length
+	if _, err := ParseBroadcastArea([]byte{0x80, 'x'}); err != nil {
+		t.Errorf("SMSC specific format: %v", err)
+	}
+}
+
+func TestBroadcastValues(t *testing.T) {
+	f, err := NewBroadcastFrequency(90 * time.Minute)
+	if err != nil || f != (BroadcastFrequency{Unit: SMPP_50_BCAST_FREQ_MINUTES, Count: 90}) {
+		t.Errorf("NewBroadcastFrequency(90m) = %+v, %v", f, err)
+	}
+	if f, err := ParseBroadcastFrequency(f.Marshal()); err != nil || f.Count != 90 {
+		t.Errorf("ParseBroadcastFrequency = %+v, %v", f, err)
+	}
+	if _, err := NewBroadcastFrequency(1500 * time.Millisecond); !errors.Is(err, ErrInvalidBroadcastValue) {
+		t.Errorf("NewBroadcastFrequency(1.5s) error = %v", err)
+	}
+	if _, err := ParseBroadcastFrequency([]byte{0x07, 0, 1}); !errors.Is(err, ErrInvalidBroadcastValue) {
+		t.Errorf("unknown frequency unit error = %v", err)
+	}
+	if _, err := ParseBroadcastContentType([]byte{0x04, 0, 1}); !errors.Is(err, ErrInvalidBroadcastValue) {
+		t.Errorf("unknown network type error = %v", err)
+	}
+
+	var l TLVList
+	if err := l.SetBroadcastChannelIndicator(2); !errors.Is(err, ErrInvalidBroadcastChannel) || l.Has(TLV_BROADCAST_CHANNEL_INDICATOR) {
+		t.Errorf("SetBroadcastChannelIndicator(2) error = %v", err)
+	}
+	if err := l.SetBroadcastAreaSuccess(101); !errors.Is(err, ErrInvalidBroadcastValue) {
+		t.Errorf("SetBroadcastAreaSuccess(101) error = %v", err)
+	}
+}
+
+func TestBroadcastSMRoundTrip(t *testing.T) {
+	b := NewBroadcastSM()
+	b.Header.SequenceNumber = 9
+	b.MessageID = "b1"
+	b.TLVParams = testBroadcastParams()
+	london, err := BroadcastAreaName("London")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.TLVParams.AddBroadcastArea(london)
+	if err := b.TLVParams.SetBroadcastChannelIndicator(SMPP_50_BCAST_CHANNEL_EXTENDED); err != nil {
+		t.Fatal(err)
+	}
+	b.TLVParams.SetMessagePayload([]byte("Storm warning"))
+
+	frame, err := b.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	p, err := Decode(frame)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	got := p.(*BroadcastSM)
+	if err := got.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+	areas, err := got.TLVParams.BroadcastAreas()
+	if err != nil || len(areas) != 2 || areas[1].Format != SMPP_50_BCAST_AREA_FORMAT_NAME {
+		t.Errorf("BroadcastAreas = %+v, %v", areas, err)
+	}
+	if c, ok := got.TLVParams.BroadcastContentType(); !ok || c.Service != SMPP_50_BCAST_CONTENT_EMERGENCY {
+		t.Errorf("BroadcastContentType = %+v, %v", c, ok)
+	}
+	if n, ok := got.TLVParams.BroadcastRepNum(); !ok || n != 3 {
+		t.Errorf("BroadcastRepNum = %d, %v", n, ok)
+	}
+	if f, ok := got.TLVParams.BroadcastFrequency(); !ok || f.Count != 5 {
+		t.Errorf("BroadcastFrequency = %+v, %v", f, ok)
+	}
+	if c, ok := got.TLVParams.BroadcastChannelIndicator(); !ok || c != SMPP_50_BCAST_CHANNEL_EXTENDED {
+		t.Errorf("BroadcastChannelIndicator = %d, %v", c, ok)
+	}
+}
+
+func TestBroadcastSMInvalidParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*TLVList)
+		status uint32
+	}{
+		{"no area", func(l *TLVList) { l.Delete(TLV_BROADCAST_AREA_IDENTIFIER) }, ESME_RMISSINGOPTPARAM},
+		{"no content type", func(l *TLVList) { l.Delete(TLV_BROADCAST_CONTENT_TYPE) }, ESME_RMISSINGOPTPARAM},
+		{"no rep num", func(l *TLVList) { l.Delete(TLV_BROADCAST_REP_NUM) }, ESME_RMISSINGOPTPARAM},
+		{"no frequency", func(l *TLVList) { l.Delete(TLV_BROADCAST_FREQUENCY_INTERVAL) }, ESME_RMISSINGOPTPARAM},
+		{"bad area", func(l *TLVList) { l.Add(TLV_BROADCAST_AREA_IDENTIFIER, []byte{0x07}) }, ESME_RBCAST_AREA_FORMAT_INVALID},
+		{"bad channel", func(l *TLVList) { l.SetUint8(TLV_BROADCAST_CHANNEL_INDICATOR, 2) }, ESME_RBCAST_CHANNEL_INVALID},
+		{"bad frequency", func(l *TLVList) { l.Set(TLV_BROADCAST_FREQUENCY_INTERVAL, []byte{0x07, 0, 1}) }, ESME_RINVOPTPARAMVAL},
+	}
+	for _, tt := range tests {
+		b := NewBroadcastSM()
+		b.TLVParams = testBroadcastParams()
+		tt.modify(&b.TLVParams)
+
+		frame, err := b.Marshal()
+		var tlvErr *TLVError
+		if !errors.As(err, &tlvErr) || len(frame) != 0 {
+			t.Errorf("%s: Marshal = %X, %v, want a TLVError", tt.name, frame, err)
+			continue
+		}
+		if tlvErr.Status != tt.status {
+			t.Errorf("%s: status 0x%08X, want 0x%08X", tt.name, tlvErr.Status, tt.status)
+		}
+		if status, _ := ErrorStatus(b.Validate()); status != tt.status {
+			t.Errorf("%s: Validate status 0x%08X, want 0x%08X", tt.name, status, tt.status)
+		}
+	}
+
+	// Nothing is written to dst
+	b := NewBroadcastSM()
+	dst := []byte{0xAA}
+	if out, err := b.AppendTo(dst); err == nil || !slices.Equal(out, dst) {
+		t.Errorf("AppendTo without parameters = %X, %v", out, err)
+	}
+}
@@ -1,154 +1,129 @@
 package pdu
 
-import (
-	"encoding/binary"
-	"errors"
-)
-
 // CancelBroadcastSM represents an SMPP cancel_broadcast_sm PDU (SMPP v5.0)
 type CancelBroadcastSM struct {
-	Header        *Header
-	ServiceType   string
-	MessageID     string
-	SourceAddrTON uint8
-	SourceAddrNPI uint8
-	SourceAddr    string
-	TLVParams     map[uint16]*TLVParam
+	Header        *Header `json:"header"`
+	ServiceType   string  `json:"service_type"`
+	MessageID     string  `json:"message_id"`
+	SourceAddrTON uint8   `json:"source_addr_ton"`
+	SourceAddrNPI uint8   `json:"source_addr_npi"`
+	SourceAddr    string  `json:"source_addr"`
+	TLVParams     TLVList `json:"tlvs,omitempty"`
 }
 
 // NewCancelBroadcastSM creates a new CancelBroadcastSM PDU
 func NewCancelBroadcastSM() *CancelBroadcastSM {
 	return &CancelBroadcastSM{
-		Header:    NewHeader(),
-		TLVParams: make(map[uint16]*TLVParam),
+		Header: NewHeader(),
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (c *CancelBroadcastSM) Marshal() ([]byte, error) {
-	// Calculate the total length
-	length := 16 // Header length
+// Source returns source_addr with its TON and NPI
+func (c *CancelBroadcastSM) Source() Address {
+	return Address{TON: c.SourceAddrTON, NPI: c.SourceAddrNPI, Addr: c.SourceAddr}
+}
+
+// SetSource sets source_addr with its TON and NPI
+func (c *CancelBroadcastSM) SetSource(a Address) {
+	c.SourceAddrTON, c.SourceAddrNPI, c.SourceAddr = a.TON, a.NPI, a.Addr
+}
+
+// checkFields checks the mandatory fields against their specified sizes
+func (c *CancelBroadcastSM) checkFields() error {
+	return firstError(
+		checkCString("service_type", c.ServiceType, SERVICE_TYPE_MAX_LEN, ESME_RINVSERTYP),
+		checkCString("message_id", c.MessageID, MESSAGE_ID_MAX_LEN, ESME_RINVMSGID),
+		checkCString("source_addr", c.SourceAddr, ADDR_MAX_LEN, ESME_RINVSRCADR),
+	)
+}
+
+// EncodedLen returns the size of the encoded PDU in bytes
+func (c *CancelBroadcastSM) EncodedLen() int {
+	length := HeaderLength
 	length += len(c.ServiceType) + 1
 	length += len(c.MessageID) + 1
-	length += 2 // Source addr ton and npi
+	length += 2 // source_addr_ton, source_addr_npi
 	length += len(c.SourceAddr) + 1
+	length += c.TLVParams.EncodedLen()
+	return length
+}
 
-	// Add TLV parameters length
-	for _, tlv := range c.TLVParams {
-		length += 4 + len(tlv.Value) // Tag(2) + Length(2) + Value(n)
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (c *CancelBroadcastSM) AppendTo(dst []byte) ([]byte, error) {
+	if err := c.checkFields(); err != nil {
+		return dst, err
 	}
 
-	// Set command length in header
-	c.Header.CommandLength = uint32(length)
+	c.Header.CommandLength = uint32(c.EncodedLen())
 	c.Header.CommandID = CANCEL_BROADCAST_SM
 
-	// Create the byte slice
-	buf := make([]byte, length)
-
-	// Marshal header
-	headerBytes, err := c.Header.Marshal()
-	if err != nil {
-		return nil, err
-	}
-	copy(buf[0:], headerBytes)
-
-	offset := 16
-
-	// Write service_type
-	copy(buf[offset:], c.ServiceType)
-	offset += len(c.ServiceType) + 1
-
-	// Write message_id
-	copy(buf[offset:], c.MessageID)
-	offset += len(c.MessageID) + 1
-
-	// Write source_addr_ton
-	buf[offset] = c.SourceAddrTON
-	offset++
-
-	// Write source_addr_npi
-	buf[offset] = c.SourceAddrNPI
-	offset++
-
-	// Write source_addr
-	copy(buf[offset:], c.SourceAddr)
-	offset += len(c.SourceAddr) + 1
-
-	// Write TLV parameters
-	for _, tlv := range c.TLVParams {
-		tlvBytes, err := tlv.Marshal()
-		if err != nil {
-			return nil, err
-		}
-		copy(buf[offset:], tlvBytes)
-		offset += 4 + len(tlv.Value)
-	}
+	dst = c.Header.AppendTo(dst)
+	dst = appendCString(dst, c.ServiceType)
+	dst = appendCString(dst, c.MessageID)
+	dst = append(dst, c.SourceAddrTON, c.SourceAddrNPI)
+	dst = appendCString(dst, c.SourceAddr)
+	dst = c.TLVParams.AppendTo(dst)
+	return dst, nil
+}
 
-	return buf, nil
+// Marshal serializes the PDU into bytes
+func (c *CancelBroadcastSM) Marshal() ([]byte, error) {
+	return c.AppendTo(make([]byte, 0, c.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (c *CancelBroadcastSM) Unmarshal(data []byte) error {
-	var err error
+	return c.decode(newDecoder(data, false))
+}
 
-	// Unmarshal header
+// decode reads the PDU fields from dec
+func (c *CancelBroadcastSM) decode(dec *decoder) error {
 	c.Header = &Header{}
-	if err = c.Header.Unmarshal(data[:16]); err != nil {
-		return err
-	}
-
-	offset := 16
-
-	// Read service_type
-	if c.ServiceType, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+	dec.header(c.Header)
 
-	// Read message_id
-	if c.MessageID, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
-
-	// Read source_addr_ton
-	c.SourceAddrTON = data[offset]
-	offset++
+	c.ServiceType = dec.cstring("service_type", SERVICE_TYPE_MAX_LEN, ESME_RINVSERTYP)
+	c.MessageID = dec.cstring("message_id", MESSAGE_ID_MAX_LEN, ESME_RINVMSGID)
+	c.SourceAddrTON = dec.uint8("source_addr_ton")
+	c.SourceAddrNPI = dec.uint8("source_addr_npi")
+	c.SourceAddr = dec.cstring("source_addr", ADDR_MAX_LEN, ESME_RINVSRCADR)
 
-	// Read source_addr_npi
-	c.SourceAddrNPI = data[offset]
-	offset++
-
-	// Read source_addr
-	if c.SourceAddr, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+	dec.tlvs(&c.TLVParams)
+	return dec.Err()
+}
 
-	// Read TLV parameters if any remain
-	remaining := int(c.Header.CommandLength) - offset
-	currentOffset := offset
+// CommandID returns the command_id of the PDU
+func (c *CancelBroadcastSM) CommandID() uint32 {
+	return CANCEL_BROADCAST_SM
+}
 
-	for remaining > 0 {
-		if remaining < 4 {
-			return errors.New("invalid TLV parameter: insufficient data")
-		}
+// GetHeader returns the PDU header
+func (c *CancelBroadcastSM) GetHeader() *Header {
+	return c.Header
+}
 
-		tag := binary.BigEndian.Uint16(data[currentOffset:])
-		length := binary.BigEndian.Uint16(data[currentOffset+2:])
+// String returns a one-line description of the PDU for trace logs
+func (c *CancelBroadcastSM) String() string {
+	return FormatPDU(c)
+}
 
-		if remaining < int(4+length) {
-			return errors.New("invalid TLV parameter: insufficient data for value")
-		}
+// MarshalJSON encodes the PDU as JSON
+func (c *CancelBroadcastSM) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(c)
+}
 
-		tlv := &TLVParam{
-			Tag:    tag,
-			Length: length,
-			Value:  make([]byte, length),
-		}
-		copy(tlv.Value, data[currentOffset+4:currentOffset+4+int(length)])
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (c *CancelBroadcastSM) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, c)
+}
 
-		c.TLVParams[tag] = tlv
-		currentOffset += 4 + int(length)
-		remaining -= 4 + int(length)
-	}
+// GetTLVParams returns the optional parameters of the PDU
+func (c *CancelBroadcastSM) GetTLVParams() *TLVList {
+	return &c.TLVParams
+}
 
-	return nil
+// GetResponse creates the cancel_broadcast_sm_resp answering this PDU
+func (c *CancelBroadcastSM) GetResponse() PDU {
+	resp := NewCancelBroadcastSMResp()
+	resp.Header = responseHeader(c.Header, CANCEL_BROADCAST_SM_RESP)
+	return resp
 }
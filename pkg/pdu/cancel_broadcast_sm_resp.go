@@ -2,7 +2,7 @@ package pdu
 
 // CancelBroadcastSMResp represents an SMPP cancel_broadcast_sm_resp PDU (SMPP v5.0)
 type CancelBroadcastSMResp struct {
-	Header *Header
+	Header *Header `json:"header"`
 }
 
 // NewCancelBroadcastSMResp creates a new CancelBroadcastSMResp PDU
@@ -12,19 +12,64 @@ func NewCancelBroadcastSMResp() *CancelBroadcastSMResp {
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (c *CancelBroadcastSMResp) Marshal() ([]byte, error) {
-	// Set command length in header
-	c.Header.CommandLength = 16 // Only header, no body
+// EncodedLen returns the size of the encoded PDU in bytes
+func (c *CancelBroadcastSMResp) EncodedLen() int {
+	return HeaderLength
+}
+
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (c *CancelBroadcastSMResp) AppendTo(dst []byte) ([]byte, error) {
+	c.Header.CommandLength = uint32(c.EncodedLen())
 	c.Header.CommandID = CANCEL_BROADCAST_SM_RESP
 
-	// Marshal header
-	return c.Header.Marshal()
+	return c.Header.AppendTo(dst), nil
+}
+
+// Marshal serializes the PDU into bytes
+func (c *CancelBroadcastSMResp) Marshal() ([]byte, error) {
+	return c.AppendTo(make([]byte, 0, c.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (c *CancelBroadcastSMResp) Unmarshal(data []byte) error {
-	// Unmarshal header
+	return c.decode(newDecoder(data, false))
+}
+
+// decode reads the PDU fields from dec
+func (c *CancelBroadcastSMResp) decode(dec *decoder) error {
 	c.Header = &Header{}
-	return c.Header.Unmarshal(data[:16])
+	dec.header(c.Header)
+
+	dec.end()
+	return dec.Err()
+}
+
+// CommandID returns the command_id of the PDU
+func (c *CancelBroadcastSMResp) CommandID() uint32 {
+	return CANCEL_BROADCAST_SM_RESP
+}
+
+// GetHeader returns the PDU header
+func (c *CancelBroadcastSMResp) GetHeader() *Header {
+	return c.Header
+}
+
+// String returns a one-line description of the PDU for trace logs
+func (c *CancelBroadcastSMResp) String() string {
+	return FormatPDU(c)
+}
+
+// MarshalJSON encodes the PDU as JSON
+func (c *CancelBroadcastSMResp) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(c)
+}
+
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (c *CancelBroadcastSMResp) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, c)
+}
+
+// GetResponse returns nil as cancel_broadcast_sm_resp has no response PDU
+func (c *CancelBroadcastSMResp) GetResponse() PDU {
+	return nil
 }
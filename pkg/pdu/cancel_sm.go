@@ -1,18 +1,16 @@
 package pdu
 
-import "errors"
-
 // CancelSM represents an SMPP cancel_sm PDU
 type CancelSM struct {
-	Header          *Header
-	ServiceType     string
-	MessageID       string
-	SourceAddrTON   uint8
-	SourceAddrNPI   uint8
-	SourceAddr      string
-	DestAddrTON     uint8
-	DestAddrNPI     uint8
-	DestinationAddr string
+	Header          *Header `json:"header"`
+	ServiceType     string  `json:"service_type"`
+	MessageID       string  `json:"message_id"`
+	SourceAddrTON   uint8   `json:"source_addr_ton"`
+	SourceAddrNPI   uint8   `json:"source_addr_npi"`
+	SourceAddr      string  `json:"source_addr"`
+	DestAddrTON     uint8   `json:"dest_addr_ton"`
+	DestAddrNPI     uint8   `json:"dest_addr_npi"`
+	DestinationAddr string  `json:"destination_addr"`
 }
 
 // NewCancelSM creates a new CancelSM PDU
@@ -22,120 +20,123 @@ func NewCancelSM() *CancelSM {
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (c *CancelSM) Marshal() ([]byte, error) {
-	// Calculate the total length
-	length := 16 // Header length
-	length += len(c.ServiceType) + 1
-	length += len(c.MessageID) + 1
-	length += 2 // Source addr ton and npi
-	length += len(c.SourceAddr) + 1
-	length += 2 // Dest addr ton and npi
-	length += len(c.DestinationAddr) + 1
-
-	// Set command length in header
-	c.Header.CommandLength = uint32(length)
-	c.Header.CommandID = CANCEL_SM
-
-	// Create the byte slice
-	buf := make([]byte, length)
-
-	// Marshal header
-	headerBytes, err := c.Header.Marshal()
-	if err != nil {
-		return nil, err
-	}
-	copy(buf[0:], headerBytes)
-
-	offset := 16
+// Source returns source_addr with its TON and NPI
+func (c *CancelSM) Source() Address {
+	return Address{TON: c.SourceAddrTON, NPI: c.SourceAddrNPI, Addr: c.SourceAddr}
+}
 
-	// Write service_type
-	copy(buf[offset:], c.ServiceType)
-	offset += len(c.ServiceType) + 1
+// SetSource sets source_addr with its TON and NPI
+func (c *CancelSM) SetSource(a Address) {
+	c.SourceAddrTON, c.SourceAddrNPI, c.SourceAddr = a.TON, a.NPI, a.Addr
+}
 
-	// Write message_id
-	copy(buf[offset:], c.MessageID)
-	offset += len(c.MessageID) + 1
+// Destination returns destination_addr with its TON and NPI
+func (c *CancelSM) Destination() Address {
+	return Address{TON: c.DestAddrTON, NPI: c.DestAddrNPI, Addr: c.DestinationAddr}
+}
 
-	// Write source_addr_ton
-	buf[offset] = c.SourceAddrTON
-	offset++
+// SetDestination sets destination_addr with its TON and NPI
+func (c *CancelSM) SetDestination(a Address) {
+	c.DestAddrTON, c.DestAddrNPI, c.DestinationAddr = a.TON, a.NPI, a.Addr
+}
 
-	// Write source_addr_npi
-	buf[offset] = c.SourceAddrNPI
-	offset++
+// checkFields checks the mandatory fields against their specified sizes
+func (c *CancelSM) checkFields() error {
+	return firstError(
+		checkCString("service_type", c.ServiceType, SERVICE_TYPE_MAX_LEN, ESME_RINVSERTYP),
+		checkCString("message_id", c.MessageID, MESSAGE_ID_MAX_LEN, ESME_RINVMSGID),
+		checkCString("source_addr", c.SourceAddr, ADDR_MAX_LEN, ESME_RINVSRCADR),
+		checkCString("destination_addr", c.DestinationAddr, ADDR_MAX_LEN, ESME_RINVDSTADR),
+	)
+}
 
-	// Write source_addr
-	copy(buf[offset:], c.SourceAddr)
-	offset += len(c.SourceAddr) + 1
+// EncodedLen returns the size of the encoded PDU in bytes
+func (c *CancelSM) EncodedLen() int {
+	length := HeaderLength
+	length += len(c.ServiceType) + 1
+	length += len(c.MessageID) + 1
+	length += 2 // source_addr_ton, source_addr_npi
+	length += len(c.SourceAddr) + 1
+	length += 2 // dest_addr_ton, dest_addr_npi
+	length += len(c.DestinationAddr) + 1
+	return length
+}
 
-	// Write dest_addr_ton
-	buf[offset] = c.DestAddrTON
-	offset++
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (c *CancelSM) AppendTo(dst []byte) ([]byte, error) {
+	if err := c.checkFields(); err != nil {
+		return dst, err
+	}
 
-	// Write dest_addr_npi
-	buf[offset] = c.DestAddrNPI
-	offset++
+	c.Header.CommandLength = uint32(c.EncodedLen())
+	c.Header.CommandID = CANCEL_SM
 
-	// Write destination_addr
-	copy(buf[offset:], c.DestinationAddr)
-	offset += len(c.DestinationAddr) + 1
+	dst = c.Header.AppendTo(dst)
+	dst = appendCString(dst, c.ServiceType)
+	dst = appendCString(dst, c.MessageID)
+	dst = append(dst, c.SourceAddrTON, c.SourceAddrNPI)
+	dst = appendCString(dst, c.SourceAddr)
+	dst = append(dst, c.DestAddrTON, c.DestAddrNPI)
+	dst = appendCString(dst, c.DestinationAddr)
+	return dst, nil
+}
 
-	return buf, nil
+// Marshal serializes the PDU into bytes
+func (c *CancelSM) Marshal() ([]byte, error) {
+	return c.AppendTo(make([]byte, 0, c.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (c *CancelSM) Unmarshal(data []byte) error {
-	var err error
+	return c.decode(newDecoder(data, false))
+}
 
-	// Unmarshal header
+// decode reads the PDU fields from dec
+func (c *CancelSM) decode(dec *decoder) error {
 	c.Header = &Header{}
-	if err = c.Header.Unmarshal(data[:16]); err != nil {
-		return err
-	}
-
-	offset := 16
-
-	// Read service_type
-	if c.ServiceType, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
-
-	// Read message_id
-	if c.MessageID, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
-
-	// Read source_addr_ton
-	c.SourceAddrTON = data[offset]
-	offset++
-
-	// Read source_addr_npi
-	c.SourceAddrNPI = data[offset]
-	offset++
+	dec.header(c.Header)
+
+	c.ServiceType = dec.cstring("service_type", SERVICE_TYPE_MAX_LEN, ESME_RINVSERTYP)
+	c.MessageID = dec.cstring("message_id", MESSAGE_ID_MAX_LEN, ESME_RINVMSGID)
+	c.SourceAddrTON = dec.uint8("source_addr_ton")
+	c.SourceAddrNPI = dec.uint8("source_addr_npi")
+	c.SourceAddr = dec.cstring("source_addr", ADDR_MAX_LEN, ESME_RINVSRCADR)
+	c.DestAddrTON = dec.uint8("dest_addr_ton")
+	c.DestAddrNPI = dec.uint8("dest_addr_npi")
+	c.DestinationAddr = dec.cstring("destination_addr", ADDR_MAX_LEN, ESME_RINVDSTADR)
+
+	dec.end()
+	return dec.Err()
+}
 
-	// Read source_addr
-	if c.SourceAddr, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+// CommandID returns the command_id of the PDU
+func (c *CancelSM) CommandID() uint32 {
+	return CANCEL_SM
+}
 
-	// Read dest_addr_ton
-	c.DestAddrTON = data[offset]
-	offset++
+// GetHeader returns the PDU header
+func (c *CancelSM) GetHeader() *Header {
+	return c.Header
+}
 
-	// Read dest_addr_npi
-	c.DestAddrNPI = data[offset]
-	offset++
+// String returns a one-line description of the PDU for trace logs
+func (c *CancelSM) String() string {
+	return FormatPDU(c)
+}
 
-	// Read destination_addr
-	if c.DestinationAddr, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+// MarshalJSON encodes the PDU as JSON
+func (c *CancelSM) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(c)
+}
 
-	// Verify we've read all the data
-	if offset != int(c.Header.CommandLength) {
-		return errors.New("invalid PDU length")
-	}
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (c *CancelSM) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, c)
+}
 
-	return nil
+// GetResponse creates the cancel_sm_resp answering this PDU
+func (c *CancelSM) GetResponse() PDU {
+	resp := NewCancelSMResp()
+	resp.Header = responseHeader(c.Header, CANCEL_SM_RESP)
+	return resp
 }
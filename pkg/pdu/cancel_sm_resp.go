@@ -2,7 +2,7 @@ package pdu
 
 // CancelSMResp represents an SMPP cancel_sm_resp PDU
 type CancelSMResp struct {
-	Header *Header
+	Header *Header `json:"header"`
 }
 
 // NewCancelSMResp creates a new CancelSMResp PDU
@@ -12,19 +12,64 @@ func NewCancelSMResp() *CancelSMResp {
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (c *CancelSMResp) Marshal() ([]byte, error) {
-	// Set command length in header
-	c.Header.CommandLength = 16 // Only header, no body
+// EncodedLen returns the size of the encoded PDU in bytes
+func (c *CancelSMResp) EncodedLen() int {
+	return HeaderLength
+}
+
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (c *CancelSMResp) AppendTo(dst []byte) ([]byte, error) {
+	c.Header.CommandLength = uint32(c.EncodedLen())
 	c.Header.CommandID = CANCEL_SM_RESP
 
-	// Marshal header
-	return c.Header.Marshal()
+	return c.Header.AppendTo(dst), nil
+}
+
+// Marshal serializes the PDU into bytes
+func (c *CancelSMResp) Marshal() ([]byte, error) {
+	return c.AppendTo(make([]byte, 0, c.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (c *CancelSMResp) Unmarshal(data []byte) error {
-	// Unmarshal header
+	return c.decode(newDecoder(data, false))
+}
+
+// decode reads the PDU fields from dec
+func (c *CancelSMResp) decode(dec *decoder) error {
 	c.Header = &Header{}
-	return c.Header.Unmarshal(data[:16])
+	dec.header(c.Header)
+
+	dec.end()
+	return dec.Err()
+}
+
+// CommandID returns the command_id of the PDU
+func (c *CancelSMResp) CommandID() uint32 {
+	return CANCEL_SM_RESP
+}
+
+// GetHeader returns the PDU header
+func (c *CancelSMResp) GetHeader() *Header {
+	return c.Header
+}
+
+// String returns a one-line description of the PDU for trace logs
+func (c *CancelSMResp) String() string {
+	return FormatPDU(c)
+}
+
+// MarshalJSON encodes the PDU as JSON
+func (c *CancelSMResp) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(c)
+}
+
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (c *CancelSMResp) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, c)
+}
+
+// GetResponse returns nil as cancel_sm_resp has no response PDU
+func (c *CancelSMResp) GetResponse() PDU {
+	return nil
 }
@@ -1,7 +1,5 @@
 package pdu
 
-import "errors"
-
 // Data Coding Scheme Constants
 const (
 	// GSM 03.38 Coding Schemes
@@ -9,6 +7,10 @@ const (
 	DATA_CODING_IA5        uint8 = 0x01 // IA5 (CCITT T.50)/ASCII (ANSI X3.4)
 	DATA_CODING_BINARY     uint8 = 0x02 // 8-bit binary
 	DATA_CODING_ISO8859_1  uint8 = 0x03 // ISO-8859-1 (Latin-1)
+	DATA_CODING_OCTET      uint8 = 0x04 // Octet unspecified (8-bit binary)
+	DATA_CODING_JIS        uint8 = 0x05 // JIS (X 0208-1990)
+	DATA_CODING_CYRILLIC   uint8 = 0x06 // Cyrillic (ISO-8859-5)
+	DATA_CODING_HEBREW     uint8 = 0x07 // Latin/Hebrew (ISO-8859-8)
 	DATA_CODING_UCS2       uint8 = 0x08 // UCS2 (ISO/IEC-10646)
 	DATA_CODING_PICTOGRAM  uint8 = 0x09 // Pictogram Encoding
 	DATA_CODING_ISO2022_JP uint8 = 0x0A // ISO-2022-JP (Music Codes)
@@ -49,16 +51,35 @@ type SARParams struct {
 
 // UDH (User Data Header) Information Element Identifiers
 const (
-	UDH_IE_CONCAT_8BIT      uint8 = 0x00 // Concatenated messages, 8-bit reference
-	UDH_IE_SPECIAL_SMS      uint8 = 0x01 // Special SMS Message Indication
-	UDH_IE_PORT_8BIT        uint8 = 0x04 // Application port addressing scheme, 8 bit
-	UDH_IE_PORT_16BIT       uint8 = 0x05 // Application port addressing scheme, 16 bit
-	UDH_IE_CONCAT_16BIT     uint8 = 0x08 // Concatenated messages, 16-bit reference
-	UDH_IE_WIRELESS_CTRL    uint8 = 0x09 // Wireless Control Message Protocol
-	UDH_IE_TEXT_FORMAT      uint8 = 0x0A // Text Formatting
-	UDH_IE_PREDEFINED_SOUND uint8 = 0x0B // Predefined Sound
-	UDH_IE_USER_PROMPT      uint8 = 0x0C // User Prompt Indicator
-	UDH_IE_EMS_VAR_PIC      uint8 = 0x0D // Extended Object
+	UDH_IE_CONCAT_8BIT          uint8 = 0x00 // Concatenated messages, 8-bit reference
+	UDH_IE_SPECIAL_SMS          uint8 = 0x01 // Special SMS Message Indication
+	UDH_IE_PORT_8BIT            uint8 = 0x04 // Application port addressing scheme, 8 bit
+	UDH_IE_PORT_16BIT           uint8 = 0x05 // Application port addressing scheme, 16 bit
+	UDH_IE_CONCAT_16BIT         uint8 = 0x08 // Concatenated messages, 16-bit reference
+	UDH_IE_WIRELESS_CTRL        uint8 = 0x09 // Wireless Control Message Protocol
+	UDH_IE_TEXT_FORMAT          uint8 = 0x0A // Text Formatting
+	UDH_IE_PREDEFINED_SOUND     uint8 = 0x0B // Predefined Sound
+	UDH_IE_USER_DEFINED_SOUND   uint8 = 0x0C // User Defined Sound
+	UDH_IE_PREDEFINED_ANIMATION uint8 = 0x0D // Predefined Animation
+	UDH_IE_VARIABLE_PICTURE     uint8 = 0x12 // Variable Picture
+	UDH_IE_USER_PROMPT_IND      uint8 = 0x13 // User Prompt Indicator
+	UDH_IE_EXTENDED_OBJECT      uint8 = 0x14 // Extended Object
+	UDH_IE_NATIONAL_SINGLE      uint8 = 0x24 // National Language Single Shift
+	UDH_IE_NATIONAL_LOCKING     uint8 = 0x25 // National Language Locking Shift
+	UDH_IE_SIM_COMMAND_PACKET   uint8 = 0x70 // (U)SIM Toolkit Security Header, command packet
+	UDH_IE_SIM_RESPONSE_PACKET  uint8 = 0x71 // (U)SIM Toolkit Security Header, response packet
+)
+
+// UDH IE names with the values they had before they were corrected to TS
+// 23.040, kept so that existing code sends the same octets. 0x0C is the
+// User Defined Sound and 0x0D the Predefined Animation IE.
+//
+// Deprecated: use UDH_IE_USER_PROMPT_IND (0x13) and UDH_IE_VARIABLE_PICTURE
+// (0x12), or UDH_IE_USER_DEFINED_SOUND and UDH_IE_PREDEFINED_ANIMATION for
+// the old values.
+const (
+	UDH_IE_USER_PROMPT = UDH_IE_USER_DEFINED_SOUND
+	UDH_IE_EMS_VAR_PIC = UDH_IE_PREDEFINED_ANIMATION
 )
 
 // TLV (Tag Length Value) Tag Definitions
@@ -129,6 +150,8 @@ type TLVParam struct {
 	Tag    uint16
 	Length uint16
 	Value  []byte
+
+	def *TLVDef // Definition bound when decoded with a profile schema
 }
 
 // TLV Methods
@@ -141,32 +164,27 @@ func NewTLVParam(tag uint16, value []byte) *TLVParam {
 }
 
 func (tlv *TLVParam) Marshal() ([]byte, error) {
-	buf := make([]byte, 4+len(tlv.Value))
-
-	// Write Tag (2 bytes)
-	buf[0] = byte(tlv.Tag >> 8)
-	buf[1] = byte(tlv.Tag)
-
-	// Write Length (2 bytes)
-	buf[2] = byte(tlv.Length >> 8)
-	buf[3] = byte(tlv.Length)
-
-	// Write Value
-	copy(buf[4:], tlv.Value)
+	return tlv.AppendTo(make([]byte, 0, 4+len(tlv.Value))), nil
+}
 
-	return buf, nil
+// AppendTo appends the encoded parameter to dst
+func (tlv *TLVParam) AppendTo(dst []byte) []byte {
+	dst = append(dst, byte(tlv.Tag>>8), byte(tlv.Tag))
+	dst = append(dst, byte(tlv.Length>>8), byte(tlv.Length))
+	return append(dst, tlv.Value...)
 }
 
 func (tlv *TLVParam) Unmarshal(data []byte) error {
 	if len(data) < 4 {
-		return errors.New("invalid TLV data: too short")
+		return ErrTLVTooShort
 	}
 
 	tlv.Tag = uint16(data[0])<<8 | uint16(data[1])
+	tlv.def = nil
 	tlv.Length = uint16(data[2])<<8 | uint16(data[3])
 
-	if len(data) < int(4+tlv.Length) {
-		return errors.New("invalid TLV data: value length mismatch")
+	if len(data) < 4+int(tlv.Length) {
+		return ErrTLVLengthMismatch
 	}
 
 	tlv.Value = make([]byte, tlv.Length)
@@ -52,6 +52,14 @@ const (
 	CANCEL_BROADCAST_SM_RESP uint32 = 0x80000113 // v5.0
 )
 
+// Destination flags used by submit_multi
+const (
+	DEST_FLAG_SME_ADDRESS       uint8 = 0x01 // SME Address
+	DEST_FLAG_DISTRIBUTION_LIST uint8 = 0x02 // Distribution List Name
+
+	SUBMIT_MULTI_MAX_DESTS int = 254 // Maximum number of destinations in submit_multi
+)
+
 // Command Status
 const (
 	ESME_ROK              uint32 = 0x00000000 // No Error
@@ -184,30 +192,34 @@ const (
 	SMPP_34_MESSAGE_STATE_UNKNOWN       uint32 = 0x00000007
 	SMPP_34_MESSAGE_STATE_REJECTED      uint32 = 0x00000008
 
-	// TON (Type of Number) Values
-	SMPP_34_TON_UNKNOWN           uint8 = 0x00
-	SMPP_34_TON_INTERNATIONAL     uint8 = 0x01
-	SMPP_34_TON_NATIONAL          uint8 = 0x02
-	SMPP_34_TON_NETWORK_SPECIFIC  uint8 = 0x03
-	SMPP_34_TON_SUBSCRIBER_NUMBER uint8 = 0x04
-	SMPP_34_TON_ALPHANUMERIC      uint8 = 0x05
-	SMPP_34_TON_ABBREVIATED       uint8 = 0x06
-
-	// NPI (Numbering Plan Indicator) Values
-	SMPP_34_NPI_UNKNOWN       uint8 = 0x00
-	SMPP_34_NPI_ISDN          uint8 = 0x01
-	SMPP_34_NPI_DATA          uint8 = 0x03
-	SMPP_34_NPI_TELEX         uint8 = 0x04
-	SMPP_34_NPI_LAND_MOBILE   uint8 = 0x06
-	SMPP_34_NPI_NATIONAL      uint8 = 0x08
-	SMPP_34_NPI_PRIVATE       uint8 = 0x09
-	SMPP_34_NPI_ERMES         uint8 = 0x0A
-	SMPP_34_NPI_INTERNET      uint8 = 0x0E
-	SMPP_34_NPI_WAP_CLIENT_ID uint8 = 0x12
+	// TON (Type of Number) Values, aliases of TON_*
+	SMPP_34_TON_UNKNOWN           = TON_UNKNOWN
+	SMPP_34_TON_INTERNATIONAL     = TON_INTERNATIONAL
+	SMPP_34_TON_NATIONAL          = TON_NATIONAL
+	SMPP_34_TON_NETWORK_SPECIFIC  = TON_NETWORK_SPECIFIC
+	SMPP_34_TON_SUBSCRIBER_NUMBER = TON_SUBSCRIBER_NUMBER
+	SMPP_34_TON_ALPHANUMERIC      = TON_ALPHANUMERIC
+	SMPP_34_TON_ABBREVIATED       = TON_ABBREVIATED
+
+	// NPI (Numbering Plan Indicator) Values, aliases of NPI_*
+	SMPP_34_NPI_UNKNOWN       = NPI_UNKNOWN
+	SMPP_34_NPI_ISDN          = NPI_ISDN
+	SMPP_34_NPI_DATA          = NPI_DATA
+	SMPP_34_NPI_TELEX         = NPI_TELEX
+	SMPP_34_NPI_LAND_MOBILE   = NPI_LAND_MOBILE
+	SMPP_34_NPI_NATIONAL      = NPI_NATIONAL
+	SMPP_34_NPI_PRIVATE       = NPI_PRIVATE
+	SMPP_34_NPI_ERMES         = NPI_ERMES
+	SMPP_34_NPI_INTERNET      = NPI_INTERNET
+	SMPP_34_NPI_WAP_CLIENT_ID = NPI_WAP_CLIENT
 )
 
 // SMPP v5.0 specific status codes
 const (
+	// Message State
+	SMPP_50_MESSAGE_STATE_SCHEDULED uint32 = 0x00000000
+	SMPP_50_MESSAGE_STATE_SKIPPED   uint32 = 0x00000009
+
 	// Broadcast Message State
 	SMPP_50_BCAST_STATE_SCHEDULED  uint32 = 0x00000001
 	SMPP_50_BCAST_STATE_COMPLETE   uint32 = 0x00000002
@@ -223,6 +235,35 @@ const (
 	SMPP_50_BCAST_AREA_FORMAT_HLR   uint8 = 0x05
 	SMPP_50_BCAST_AREA_FORMAT_ALL   uint8 = 0x06
 
+	// Broadcast Content Type networks
+	SMPP_50_BCAST_NETWORK_GENERIC uint8 = 0x00
+	SMPP_50_BCAST_NETWORK_GSM     uint8 = 0x01 // GSM 03.41
+	SMPP_50_BCAST_NETWORK_TDMA    uint8 = 0x02 // IS-824
+	SMPP_50_BCAST_NETWORK_CDMA    uint8 = 0x03 // IS-637
+
+	// Broadcast Content Type services
+	SMPP_50_BCAST_CONTENT_INDEX         uint16 = 0x0000
+	SMPP_50_BCAST_CONTENT_EMERGENCY     uint16 = 0x0001
+	SMPP_50_BCAST_CONTENT_IRDB_DOWNLOAD uint16 = 0x0002
+	SMPP_50_BCAST_CONTENT_NEWS_FLASHES  uint16 = 0x0010
+
+	// Broadcast Frequency Interval units
+	SMPP_50_BCAST_FREQ_ASAP    uint8 = 0x00 // As frequently as possible
+	SMPP_50_BCAST_FREQ_SECONDS uint8 = 0x08
+	SMPP_50_BCAST_FREQ_MINUTES uint8 = 0x09
+	SMPP_50_BCAST_FREQ_HOURS   uint8 = 0x0A
+	SMPP_50_BCAST_FREQ_DAYS    uint8 = 0x0B
+	SMPP_50_BCAST_FREQ_WEEKS   uint8 = 0x0C
+	SMPP_50_BCAST_FREQ_MONTHS  uint8 = 0x0D
+	SMPP_50_BCAST_FREQ_YEARS   uint8 = 0x0E
+
+	// Broadcast Channel Indicator
+	SMPP_50_BCAST_CHANNEL_BASIC    uint8 = 0x00
+	SMPP_50_BCAST_CHANNEL_EXTENDED uint8 = 0x01
+
+	// Broadcast Area Success value when the rate is not known
+	SMPP_50_BCAST_AREA_SUCCESS_UNKNOWN uint8 = 0xFF
+
 	// Additional v5.0 Error Codes
 	ESME_RBCAST_QUERY_FAIL          uint32 = 0x00000425 // Broadcast query operation failed
 	ESME_RBCAST_CANCEL_FAIL         uint32 = 0x00000426 // Broadcast cancel operation failed
@@ -244,50 +285,106 @@ const (
 	SMPP_50_PAYLOAD_TYPE_BINARY  uint8 = 0x04
 )
 
-// Address TON (Type of Number) Constants
+// Address TON (Type of Number) Constants, used by every address field
+const (
+	TON_UNKNOWN           uint8 = 0x00 // Unknown
+	TON_INTERNATIONAL     uint8 = 0x01 // International
+	TON_NATIONAL          uint8 = 0x02 // National
+	TON_NETWORK_SPECIFIC  uint8 = 0x03 // Network Specific
+	TON_SUBSCRIBER_NUMBER uint8 = 0x04 // Subscriber Number
+	TON_ALPHANUMERIC      uint8 = 0x05 // Alphanumeric
+	TON_ABBREVIATED       uint8 = 0x06 // Abbreviated
+)
+
+// Address NPI (Numbering Plan Indicator) Constants, used by every address field
 const (
-	// Source TON
-	SRC_TON_UNKNOWN           uint8 = 0x00 // Unknown
-	SRC_TON_INTERNATIONAL     uint8 = 0x01 // International
-	SRC_TON_NATIONAL          uint8 = 0x02 // National
-	SRC_TON_NETWORK_SPECIFIC  uint8 = 0x03 // Network Specific
-	SRC_TON_SUBSCRIBER_NUMBER uint8 = 0x04 // Subscriber Number
-	SRC_TON_ALPHANUMERIC      uint8 = 0x05 // Alphanumeric
-	SRC_TON_ABBREVIATED       uint8 = 0x06 // Abbreviated
-
-	// Destination TON
-	DST_TON_UNKNOWN           uint8 = 0x00 // Unknown
-	DST_TON_INTERNATIONAL     uint8 = 0x01 // International
-	DST_TON_NATIONAL          uint8 = 0x02 // National
-	DST_TON_NETWORK_SPECIFIC  uint8 = 0x03 // Network Specific
-	DST_TON_SUBSCRIBER_NUMBER uint8 = 0x04 // Subscriber Number
-	DST_TON_ALPHANUMERIC      uint8 = 0x05 // Alphanumeric
-	DST_TON_ABBREVIATED       uint8 = 0x06 // Abbreviated
+	NPI_UNKNOWN     uint8 = 0x00 // Unknown
+	NPI_ISDN        uint8 = 0x01 // ISDN (E163/E164)
+	NPI_DATA        uint8 = 0x03 // Data (X.121)
+	NPI_TELEX       uint8 = 0x04 // Telex (F.69)
+	NPI_LAND_MOBILE uint8 = 0x06 // Land Mobile (E.212)
+	NPI_NATIONAL    uint8 = 0x08 // National
+	NPI_PRIVATE     uint8 = 0x09 // Private
+	NPI_ERMES       uint8 = 0x0A // ERMES
+	NPI_INTERNET    uint8 = 0x0E // Internet (IP)
+	NPI_WAP_CLIENT  uint8 = 0x12 // WAP Client ID
+)
+
+// Per field TON and NPI names kept for existing code.
+//
+// Deprecated: use the TON_* and NPI_* constants.
+const (
+	SRC_TON_UNKNOWN           = TON_UNKNOWN
+	SRC_TON_INTERNATIONAL     = TON_INTERNATIONAL
+	SRC_TON_NATIONAL          = TON_NATIONAL
+	SRC_TON_NETWORK_SPECIFIC  = TON_NETWORK_SPECIFIC
+	SRC_TON_SUBSCRIBER_NUMBER = TON_SUBSCRIBER_NUMBER
+	SRC_TON_ALPHANUMERIC      = TON_ALPHANUMERIC
+	SRC_TON_ABBREVIATED       = TON_ABBREVIATED
+
+	DST_TON_UNKNOWN           = TON_UNKNOWN
+	DST_TON_INTERNATIONAL     = TON_INTERNATIONAL
+	DST_TON_NATIONAL          = TON_NATIONAL
+	DST_TON_NETWORK_SPECIFIC  = TON_NETWORK_SPECIFIC
+	DST_TON_SUBSCRIBER_NUMBER = TON_SUBSCRIBER_NUMBER
+	DST_TON_ALPHANUMERIC      = TON_ALPHANUMERIC
+	DST_TON_ABBREVIATED       = TON_ABBREVIATED
+
+	SRC_NPI_UNKNOWN     = NPI_UNKNOWN
+	SRC_NPI_ISDN        = NPI_ISDN
+	SRC_NPI_DATA        = NPI_DATA
+	SRC_NPI_TELEX       = NPI_TELEX
+	SRC_NPI_LAND_MOBILE = NPI_LAND_MOBILE
+	SRC_NPI_NATIONAL    = NPI_NATIONAL
+	SRC_NPI_PRIVATE     = NPI_PRIVATE
+	SRC_NPI_ERMES       = NPI_ERMES
+	SRC_NPI_INTERNET    = NPI_INTERNET
+	SRC_NPI_WAP_CLIENT  = NPI_WAP_CLIENT
+
+	DST_NPI_UNKNOWN     = NPI_UNKNOWN
+	DST_NPI_ISDN        = NPI_ISDN
+	DST_NPI_DATA        = NPI_DATA
+	DST_NPI_TELEX       = NPI_TELEX
+	DST_NPI_LAND_MOBILE = NPI_LAND_MOBILE
+	DST_NPI_NATIONAL    = NPI_NATIONAL
+	DST_NPI_PRIVATE     = NPI_PRIVATE
+	DST_NPI_ERMES       = NPI_ERMES
+	DST_NPI_INTERNET    = NPI_INTERNET
+	DST_NPI_WAP_CLIENT  = NPI_WAP_CLIENT
+)
+
+// ESM Class Constants
+const (
+	// Messaging mode (bits 1-0)
+	ESM_CLASS_MODE_MASK          uint8 = 0x03 // Messaging mode mask
+	ESM_CLASS_MODE_DEFAULT       uint8 = 0x00 // Default SMSC mode
+	ESM_CLASS_MODE_DATAGRAM      uint8 = 0x01 // Datagram mode
+	ESM_CLASS_MODE_FORWARD       uint8 = 0x02 // Forward (transaction) mode
+	ESM_CLASS_MODE_STORE_FORWARD uint8 = 0x03 // Store and forward mode
+
+	// Message type (bits 5-2)
+	ESM_CLASS_TYPE_MASK                      uint8 = 0x3C // Message type mask
+	ESM_CLASS_TYPE_DEFAULT                   uint8 = 0x00 // Default message type
+	ESM_CLASS_TYPE_DELIVERY_RECEIPT          uint8 = 0x04 // SMSC delivery receipt
+	ESM_CLASS_TYPE_DELIVERY_ACK              uint8 = 0x08 // SME delivery acknowledgement
+	ESM_CLASS_TYPE_USER_ACK                  uint8 = 0x10 // SME manual/user acknowledgement
+	ESM_CLASS_TYPE_CONVERSATION_ABORT        uint8 = 0x18 // Conversation abort
+	ESM_CLASS_TYPE_INTERMEDIATE_NOTIFICATION uint8 = 0x20 // Intermediate delivery notification
+
+	// GSM network specific features (bits 7-6)
+	ESM_CLASS_UDHI       uint8 = 0x40 // UDH indicator
+	ESM_CLASS_REPLY_PATH uint8 = 0x80 // Reply path
 )
 
-// Address NPI (Numbering Plan Indicator) Constants
+// ussd_service_op values. 0x04-0x0F and 0x14-0x1F are reserved, 0x20-0xFF
+// are vendor specific.
 const (
-	// Source NPI
-	SRC_NPI_UNKNOWN     uint8 = 0x00 // Unknown
-	SRC_NPI_ISDN        uint8 = 0x01 // ISDN (E163/E164)
-	SRC_NPI_DATA        uint8 = 0x03 // Data (X.121)
-	SRC_NPI_TELEX       uint8 = 0x04 // Telex (F.69)
-	SRC_NPI_LAND_MOBILE uint8 = 0x06 // Land Mobile (E.212)
-	SRC_NPI_NATIONAL    uint8 = 0x08 // National
-	SRC_NPI_PRIVATE     uint8 = 0x09 // Private
-	SRC_NPI_ERMES       uint8 = 0x0A // ERMES
-	SRC_NPI_INTERNET    uint8 = 0x0E // Internet (IP)
-	SRC_NPI_WAP_CLIENT  uint8 = 0x12 // WAP Client ID
-
-	// Destination NPI
-	DST_NPI_UNKNOWN     uint8 = 0x00 // Unknown
-	DST_NPI_ISDN        uint8 = 0x01 // ISDN (E163/E164)
-	DST_NPI_DATA        uint8 = 0x03 // Data (X.121)
-	DST_NPI_TELEX       uint8 = 0x04 // Telex (F.69)
-	DST_NPI_LAND_MOBILE uint8 = 0x06 // Land Mobile (E.212)
-	DST_NPI_NATIONAL    uint8 = 0x08 // National
-	DST_NPI_PRIVATE     uint8 = 0x09 // Private
-	DST_NPI_ERMES       uint8 = 0x0A // ERMES
-	DST_NPI_INTERNET    uint8 = 0x0E // Internet (IP)
-	DST_NPI_WAP_CLIENT  uint8 = 0x12 // WAP Client ID
+	USSD_OP_PSSD_INDICATION uint8 = 0x00 // Process unstructured SS data indication
+	USSD_OP_PSSR_INDICATION uint8 = 0x01 // Process unstructured SS request indication
+	USSD_OP_USSR_REQUEST    uint8 = 0x02 // Unstructured SS request
+	USSD_OP_USSN_REQUEST    uint8 = 0x03 // Unstructured SS notify request
+	USSD_OP_PSSD_RESPONSE   uint8 = 0x10 // Process unstructured SS data response
+	USSD_OP_PSSR_RESPONSE   uint8 = 0x11 // Process unstructured SS request response
+	USSD_OP_USSR_CONFIRM    uint8 = 0x12 // Unstructured SS request confirm
+	USSD_OP_USSN_CONFIRM    uint8 = 0x13 // Unstructured SS notify confirm
 )
@@ -1,201 +1,158 @@
 package pdu
 
-import (
-	"encoding/binary"
-	"errors"
-)
-
 // DataSM represents an SMPP data_sm PDU
 type DataSM struct {
-	Header             *Header
-	ServiceType        string
-	SourceAddrTON      uint8
-	SourceAddrNPI      uint8
-	SourceAddr         string
-	DestAddrTON        uint8
-	DestAddrNPI        uint8
-	DestinationAddr    string
-	ESMClass           uint8
-	RegisteredDelivery uint8
-	DataCoding         uint8
-	TLVParams          map[uint16]*TLVParam
+	Header             *Header `json:"header"`
+	ServiceType        string  `json:"service_type"`
+	SourceAddrTON      uint8   `json:"source_addr_ton"`
+	SourceAddrNPI      uint8   `json:"source_addr_npi"`
+	SourceAddr         string  `json:"source_addr"`
+	DestAddrTON        uint8   `json:"dest_addr_ton"`
+	DestAddrNPI        uint8   `json:"dest_addr_npi"`
+	DestinationAddr    string  `json:"destination_addr"`
+	ESMClass           uint8   `json:"esm_class"`
+	RegisteredDelivery uint8   `json:"registered_delivery"`
+	DataCoding         uint8   `json:"data_coding"`
+	TLVParams          TLVList `json:"tlvs,omitempty"`
 }
 
 // NewDataSM creates a new DataSM PDU
 func NewDataSM() *DataSM {
 	return &DataSM{
-		Header:    NewHeader(),
-		TLVParams: make(map[uint16]*TLVParam),
+		Header: NewHeader(),
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (d *DataSM) Marshal() ([]byte, error) {
-	// Calculate the total length
-	length := 16 // Header length
+// Source returns source_addr with its TON and NPI
+func (d *DataSM) Source() Address {
+	return Address{TON: d.SourceAddrTON, NPI: d.SourceAddrNPI, Addr: d.SourceAddr}
+}
+
+// SetSource sets source_addr with its TON and NPI
+func (d *DataSM) SetSource(a Address) {
+	d.SourceAddrTON, d.SourceAddrNPI, d.SourceAddr = a.TON, a.NPI, a.Addr
+}
+
+// Destination returns destination_addr with its TON and NPI
+func (d *DataSM) Destination() Address {
+	return Address{TON: d.DestAddrTON, NPI: d.DestAddrNPI, Addr: d.DestinationAddr}
+}
+
+// SetDestination sets destination_addr with its TON and NPI
+func (d *DataSM) SetDestination(a Address) {
+	d.DestAddrTON, d.DestAddrNPI, d.DestinationAddr = a.TON, a.NPI, a.Addr
+}
+
+// MessageText decodes the message text from the message_payload TLV
+func (d *DataSM) MessageText() (string, error) {
+	return messageText(nil, d.ESMClass, d.DataCoding, d.TLVParams)
+}
+
+// checkFields checks the mandatory fields against their specified sizes
+func (d *DataSM) checkFields() error {
+	return firstError(
+		checkCString("service_type", d.ServiceType, SERVICE_TYPE_MAX_LEN, ESME_RINVSERTYP),
+		checkCString("source_addr", d.SourceAddr, LONG_ADDR_MAX_LEN, ESME_RINVSRCADR),
+		checkCString("destination_addr", d.DestinationAddr, LONG_ADDR_MAX_LEN, ESME_RINVDSTADR),
+	)
+}
+
+// EncodedLen returns the size of the encoded PDU in bytes
+func (d *DataSM) EncodedLen() int {
+	length := HeaderLength
 	length += len(d.ServiceType) + 1
-	length += 2 // Source addr ton and npi
+	length += 2 // source_addr_ton, source_addr_npi
 	length += len(d.SourceAddr) + 1
-	length += 2 // Dest addr ton and npi
+	length += 2 // dest_addr_ton, dest_addr_npi
 	length += len(d.DestinationAddr) + 1
-	length += 3 // ESM class, registered delivery, data coding
+	length += 3 // esm_class, registered_delivery, data_coding
+	length += d.TLVParams.EncodedLen()
+	return length
+}
 
-	// Add TLV parameters length
-	for _, tlv := range d.TLVParams {
-		length += 4 + len(tlv.Value) // Tag(2) + Length(2) + Value(n)
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (d *DataSM) AppendTo(dst []byte) ([]byte, error) {
+	if err := d.checkFields(); err != nil {
+		return dst, err
 	}
 
-	// Set command length in header
-	d.Header.CommandLength = uint32(length)
+	d.Header.CommandLength = uint32(d.EncodedLen())
 	d.Header.CommandID = DATA_SM
 
-	// Create the byte slice
-	buf := make([]byte, length)
-
-	// Marshal header
-	headerBytes, err := d.Header.Marshal()
-	if err != nil {
-		return nil, err
-	}
-	copy(buf[0:], headerBytes)
-
-	offset := 16
-
-	// Write service_type
-	copy(buf[offset:], d.ServiceType)
-	offset += len(d.ServiceType) + 1
-
-	// Write source_addr_ton
-	buf[offset] = d.SourceAddrTON
-	offset++
-
-	// Write source_addr_npi
-	buf[offset] = d.SourceAddrNPI
-	offset++
-
-	// Write source_addr
-	copy(buf[offset:], d.SourceAddr)
-	offset += len(d.SourceAddr) + 1
-
-	// Write dest_addr_ton
-	buf[offset] = d.DestAddrTON
-	offset++
-
-	// Write dest_addr_npi
-	buf[offset] = d.DestAddrNPI
-	offset++
-
-	// Write destination_addr
-	copy(buf[offset:], d.DestinationAddr)
-	offset += len(d.DestinationAddr) + 1
-
-	// Write esm_class
-	buf[offset] = d.ESMClass
-	offset++
-
-	// Write registered_delivery
-	buf[offset] = d.RegisteredDelivery
-	offset++
-
-	// Write data_coding
-	buf[offset] = d.DataCoding
-	offset++
-
-	// Write TLV parameters
-	for _, tlv := range d.TLVParams {
-		tlvBytes, err := tlv.Marshal()
-		if err != nil {
-			return nil, err
-		}
-		copy(buf[offset:], tlvBytes)
-		offset += 4 + len(tlv.Value)
-	}
+	dst = d.Header.AppendTo(dst)
+	dst = appendCString(dst, d.ServiceType)
+	dst = append(dst, d.SourceAddrTON, d.SourceAddrNPI)
+	dst = appendCString(dst, d.SourceAddr)
+	dst = append(dst, d.DestAddrTON, d.DestAddrNPI)
+	dst = appendCString(dst, d.DestinationAddr)
+	dst = append(dst, d.ESMClass, d.RegisteredDelivery, d.DataCoding)
+	dst = d.TLVParams.AppendTo(dst)
+	return dst, nil
+}
 
-	return buf, nil
+// Marshal serializes the PDU into bytes
+func (d *DataSM) Marshal() ([]byte, error) {
+	return d.AppendTo(make([]byte, 0, d.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (d *DataSM) Unmarshal(data []byte) error {
-	var err error
+	return d.decode(newDecoder(data, false))
+}
 
-	// Unmarshal header
+// decode reads the PDU fields from dec
+func (d *DataSM) decode(dec *decoder) error {
 	d.Header = &Header{}
-	if err = d.Header.Unmarshal(data[:16]); err != nil {
-		return err
-	}
-
-	offset := 16
-
-	// Read service_type
-	if d.ServiceType, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
-
-	// Read source_addr_ton
-	d.SourceAddrTON = data[offset]
-	offset++
-
-	// Read source_addr_npi
-	d.SourceAddrNPI = data[offset]
-	offset++
-
-	// Read source_addr
-	if d.SourceAddr, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
-
-	// Read dest_addr_ton
-	d.DestAddrTON = data[offset]
-	offset++
-
-	// Read dest_addr_npi
-	d.DestAddrNPI = data[offset]
-	offset++
-
-	// Read destination_addr
-	if d.DestinationAddr, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
-
-	// Read esm_class
-	d.ESMClass = data[offset]
-	offset++
-
-	// Read registered_delivery
-	d.RegisteredDelivery = data[offset]
-	offset++
-
-	// Read data_coding
-	d.DataCoding = data[offset]
-	offset++
+	dec.header(d.Header)
+
+	d.ServiceType = dec.cstring("service_type", SERVICE_TYPE_MAX_LEN, ESME_RINVSERTYP)
+	d.SourceAddrTON = dec.uint8("source_addr_ton")
+	d.SourceAddrNPI = dec.uint8("source_addr_npi")
+	d.SourceAddr = dec.cstring("source_addr", LONG_ADDR_MAX_LEN, ESME_RINVSRCADR)
+	d.DestAddrTON = dec.uint8("dest_addr_ton")
+	d.DestAddrNPI = dec.uint8("dest_addr_npi")
+	d.DestinationAddr = dec.cstring("destination_addr", LONG_ADDR_MAX_LEN, ESME_RINVDSTADR)
+	d.ESMClass = dec.uint8("esm_class")
+	d.RegisteredDelivery = dec.uint8("registered_delivery")
+	d.DataCoding = dec.uint8("data_coding")
+
+	dec.tlvs(&d.TLVParams)
+	return dec.Err()
+}
 
-	// Read TLV parameters if any remain
-	remaining := int(d.Header.CommandLength) - offset
-	currentOffset := offset
+// CommandID returns the command_id of the PDU
+func (d *DataSM) CommandID() uint32 {
+	return DATA_SM
+}
 
-	for remaining > 0 {
-		if remaining < 4 {
-			return errors.New("invalid TLV parameter: insufficient data")
-		}
+// GetHeader returns the PDU header
+func (d *DataSM) GetHeader() *Header {
+	return d.Header
+}
 
-		tag := binary.BigEndian.Uint16(data[currentOffset:])
-		length := binary.BigEndian.Uint16(data[currentOffset+2:])
+// String returns a one-line description of the PDU for trace logs
+func (d *DataSM) String() string {
+	return FormatPDU(d)
+}
 
-		if remaining < int(4+length) {
-			return errors.New("invalid TLV parameter: insufficient data for value")
-		}
+// MarshalJSON encodes the PDU as JSON
+func (d *DataSM) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(d)
+}
 
-		tlv := &TLVParam{
-			Tag:    tag,
-			Length: length,
-			Value:  make([]byte, length),
-		}
-		copy(tlv.Value, data[currentOffset+4:currentOffset+4+int(length)])
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (d *DataSM) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, d)
+}
 
-		d.TLVParams[tag] = tlv
-		currentOffset += 4 + int(length)
-		remaining -= 4 + int(length)
-	}
+// GetTLVParams returns the optional parameters of the PDU
+func (d *DataSM) GetTLVParams() *TLVList {
+	return &d.TLVParams
+}
 
-	return nil
+// GetResponse creates the data_sm_resp answering this PDU
+func (d *DataSM) GetResponse() PDU {
+	resp := NewDataSMResp()
+	resp.Header = responseHeader(d.Header, DATA_SM_RESP)
+	return resp
 }
@@ -1,113 +1,101 @@
 package pdu
 
-import (
-	"encoding/binary"
-	"errors"
-)
-
 // DataSMResp represents an SMPP data_sm_resp PDU
 type DataSMResp struct {
-	Header    *Header
-	MessageID string
-	TLVParams map[uint16]*TLVParam
+	Header    *Header `json:"header"`
+	MessageID string  `json:"message_id"`
+	TLVParams TLVList `json:"tlvs,omitempty"`
 }
 
 // NewDataSMResp creates a new DataSMResp PDU
 func NewDataSMResp() *DataSMResp {
 	return &DataSMResp{
-		Header:    NewHeader(),
-		TLVParams: make(map[uint16]*TLVParam),
+		Header: NewHeader(),
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (d *DataSMResp) Marshal() ([]byte, error) {
-	// Calculate the total length
-	length := 16 // Header length
+// checkFields checks the mandatory fields against their specified sizes
+func (d *DataSMResp) checkFields() error {
+	return firstError(
+		checkCString("message_id", d.MessageID, MESSAGE_ID_MAX_LEN, ESME_RINVMSGID),
+	)
+}
+
+// EncodedLen returns the size of the encoded PDU in bytes
+func (d *DataSMResp) EncodedLen() int {
+	length := HeaderLength
 	length += len(d.MessageID) + 1
+	length += d.TLVParams.EncodedLen()
+	return length
+}
 
-	// Add TLV parameters length
-	for _, tlv := range d.TLVParams {
-		length += 4 + len(tlv.Value) // Tag(2) + Length(2) + Value(n)
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (d *DataSMResp) AppendTo(dst []byte) ([]byte, error) {
+	if err := d.checkFields(); err != nil {
+		return dst, err
 	}
 
-	// Set command length in header
-	d.Header.CommandLength = uint32(length)
+	d.Header.CommandLength = uint32(d.EncodedLen())
 	d.Header.CommandID = DATA_SM_RESP
 
-	// Create the byte slice
-	buf := make([]byte, length)
-
-	// Marshal header
-	headerBytes, err := d.Header.Marshal()
-	if err != nil {
-		return nil, err
-	}
-	copy(buf[0:], headerBytes)
-
-	offset := 16
-
-	// Write message_id
-	copy(buf[offset:], d.MessageID)
-	offset += len(d.MessageID) + 1
-
-	// Write TLV parameters
-	for _, tlv := range d.TLVParams {
-		tlvBytes, err := tlv.Marshal()
-		if err != nil {
-			return nil, err
-		}
-		copy(buf[offset:], tlvBytes)
-		offset += 4 + len(tlv.Value)
-	}
+	dst = d.Header.AppendTo(dst)
+	dst = appendCString(dst, d.MessageID)
+	dst = d.TLVParams.AppendTo(dst)
+	return dst, nil
+}
 
-	return buf, nil
+// Marshal serializes the PDU into bytes
+func (d *DataSMResp) Marshal() ([]byte, error) {
+	return d.AppendTo(make([]byte, 0, d.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (d *DataSMResp) Unmarshal(data []byte) error {
-	var err error
+	return d.decode(newDecoder(data, false))
+}
 
-	// Unmarshal header
+// decode reads the PDU fields from dec
+func (d *DataSMResp) decode(dec *decoder) error {
 	d.Header = &Header{}
-	if err = d.Header.Unmarshal(data[:16]); err != nil {
-		return err
-	}
+	dec.header(d.Header)
 
-	offset := 16
+	d.MessageID = dec.cstring("message_id", MESSAGE_ID_MAX_LEN, ESME_RINVMSGID)
 
-	// Read message_id
-	if d.MessageID, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+	dec.tlvs(&d.TLVParams)
+	return dec.Err()
+}
 
-	// Read TLV parameters if any remain
-	remaining := int(d.Header.CommandLength) - offset
-	currentOffset := offset
+// CommandID returns the command_id of the PDU
+func (d *DataSMResp) CommandID() uint32 {
+	return DATA_SM_RESP
+}
 
-	for remaining > 0 {
-		if remaining < 4 {
-			return errors.New("invalid TLV parameter: insufficient data")
-		}
+// GetHeader returns the PDU header
+func (d *DataSMResp) GetHeader() *Header {
+	return d.Header
+}
 
-		tag := binary.BigEndian.Uint16(data[currentOffset:])
-		length := binary.BigEndian.Uint16(data[currentOffset+2:])
+// String returns a one-line description of the PDU for trace logs
+func (d *DataSMResp) String() string {
+	return FormatPDU(d)
+}
 
-		if remaining < int(4+length) {
-			return errors.New("invalid TLV parameter: insufficient data for value")
-		}
+// MarshalJSON encodes the PDU as JSON
+func (d *DataSMResp) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(d)
+}
 
-		tlv := &TLVParam{
-			Tag:    tag,
-			Length: length,
-			Value:  make([]byte, length),
-		}
-		copy(tlv.Value, data[currentOffset+4:currentOffset+4+int(length)])
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (d *DataSMResp) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, d)
+}
 
-		d.TLVParams[tag] = tlv
-		currentOffset += 4 + int(length)
-		remaining -= 4 + int(length)
-	}
+// GetTLVParams returns the optional parameters of the PDU
+func (d *DataSMResp) GetTLVParams() *TLVList {
+	return &d.TLVParams
+}
 
+// GetResponse returns nil as data_sm_resp has no response PDU
+func (d *DataSMResp) GetResponse() PDU {
 	return nil
 }
@@ -0,0 +1,213 @@
+package pdu
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrTruncated     = errors.New("pdu ends inside the field")
+	ErrTrailingData  = errors.New("unexpected data after the last field")
+	ErrInvalidLength = errors.New("length exceeds the specified maximum")
+)
+
+// DecodeError reports a field that could not be decoded. Offset is the
+// position of the field from the start of the PDU and Status the
+// command_status to answer with.
+type DecodeError struct {
+	Field  string
+	Offset int
+	Status uint32
+	Err    error
+}
+
+// Error returns the error message
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decode %s at offset %d: %v", e.Field, e.Offset, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// CommandStatus returns the command_status to answer with
+func (e *DecodeError) CommandStatus() uint32 {
+	return e.Status
+}
+
+// decodable is implemented by the PDUs of this package, which can decode
+// from an aliasing decoder
+type decodable interface {
+	decode(dec *decoder) error
+}
+
+// decoder reads the fields of a PDU frame in order. It never reads past the
+// end of the frame or its command_length; the first failure is kept and
+// every later read returns a zero value, so a decoder can be read to the
+// end and checked once. An aliasing decoder returns octet strings that
+// share memory with the frame instead of copies.
+type decoder struct {
+	data   []byte
+	offset int
+	alias  bool
+	err    error
+}
+
+// newDecoder creates a decoder for a frame
+func newDecoder(data []byte, alias bool) *decoder {
+	return &decoder{data: data, alias: alias}
+}
+
+// header decodes the PDU header into h and limits the decoder to its
+// command_length
+func (d *decoder) header(h *Header) {
+	if len(d.data) < HeaderLength {
+		d.fail("header", ESME_RINVCMDLEN, ErrTruncated)
+		return
+	}
+
+	h.Unmarshal(d.data[:HeaderLength])
+	switch {
+	case h.CommandLength < HeaderLength:
+		d.fail("command_length", ESME_RINVCMDLEN, ErrPDUTooShort)
+	case int(h.CommandLength) < len(d.data):
+		d.data = d.data[:h.CommandLength]
+	}
+	d.offset = HeaderLength
+}
+
+// fail records the first error
+func (d *decoder) fail(field string, status uint32, err error) {
+	if d.err == nil {
+		d.err = &DecodeError{Field: field, Offset: d.offset, Status: status, Err: err}
+	}
+}
+
+// reject records a field read from offset at whose value is invalid. The
+// status is taken from err.
+func (d *decoder) reject(field string, at int, err error) {
+	if d.err != nil {
+		return
+	}
+	status, ok := ErrorStatus(err)
+	if !ok {
+		status = ESME_RSYSERR
+	}
+	d.err = &DecodeError{Field: field, Offset: at, Status: status, Err: err}
+}
+
+// Err returns the first error met
+func (d *decoder) Err() error {
+	return d.err
+}
+
+// remaining returns the number of unread octets
+func (d *decoder) remaining() int {
+	if d.err != nil {
+		return 0
+	}
+	return len(d.data) - d.offset
+}
+
+// take returns the next n octets
+func (d *decoder) take(field string, n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n > len(d.data)-d.offset {
+		d.fail(field, ESME_RINVCMDLEN, ErrTruncated)
+		return nil
+	}
+	b := d.data[d.offset : d.offset+n : d.offset+n]
+	d.offset += n
+	return b
+}
+
+// uint8 reads a 1 octet integer
+func (d *decoder) uint8(field string) uint8 {
+	if b := d.take(field, 1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+// uint32 reads a 4 octet integer
+func (d *decoder) uint32(field string) uint32 {
+	if b := d.take(field, 4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+// cstring reads a C-Octet String of at most maxLen octets including the
+// NULL terminator. Status is reported when the string is too long.
+func (d *decoder) cstring(field string, maxLen int, status uint32) string {
+	if d.err != nil {
+		return ""
+	}
+	rest := d.data[d.offset:]
+	n := bytes.IndexByte(rest, 0)
+	switch {
+	case n == -1 && len(rest) < maxLen:
+		d.fail(field, ESME_RINVCMDLEN, ErrMissingNullByte)
+		return ""
+	case n == -1 || n+1 > maxLen:
+		d.fail(field, status, ErrCStringTooLong)
+		return ""
+	}
+	s := string(rest[:n])
+	d.offset += n + 1
+	return s
+}
+
+// octets reads an octet string of n octets, at most maxLen. Status is
+// reported when n is too large.
+func (d *decoder) octets(field string, n int, maxLen int, status uint32) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n > maxLen {
+		d.fail(field, status, ErrInvalidLength)
+		return nil
+	}
+	b := d.take(field, n)
+	if b == nil || d.alias {
+		return b
+	}
+	return append([]byte(nil), b...)
+}
+
+// tlvs reads the optional parameters up to the end of the PDU
+func (d *decoder) tlvs(l *TLVList) {
+	*l = nil
+	for d.remaining() > 0 {
+		if d.remaining() < 4 {
+			d.fail("optional parameter", ESME_RINVOPTPARSTREAM, ErrTLVTooShort)
+			return
+		}
+		start := d.offset
+		tag := binary.BigEndian.Uint16(d.data[start:])
+		length := int(binary.BigEndian.Uint16(d.data[start+2:]))
+		if length > len(d.data)-start-4 {
+			d.fail(fmt.Sprintf("tlv 0x%04X", tag), ESME_RINVPARLEN, ErrTLVLengthMismatch)
+			return
+		}
+		d.offset += 4
+		value := d.data[d.offset : d.offset+length : d.offset+length]
+		if !d.alias {
+			value = append([]byte(nil), value...)
+		}
+		d.offset += length
+		*l = append(*l, &TLVParam{Tag: tag, Length: uint16(length), Value: value})
+	}
+}
+
+// end checks that the whole PDU has been read
+func (d *decoder) end() {
+	if d.remaining() > 0 {
+		d.fail("command_length", ESME_RINVCMDLEN, ErrTrailingData)
+	}
+}
@@ -0,0 +1,253 @@
+package pdu
+
+import (
+	"encoding/binary"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testFrame returns a PDU frame with the given body and a matching
+// command_length
+func testFrame(commandID uint32, body ...[]byte) []byte {
+	frame := make([]byte, HeaderLength, 64)
+	binary.BigEndian.PutUint32(frame[4:], commandID)
+	binary.BigEndian.PutUint32(frame[12:], 1)
+	for _, b := range body {
+		frame = append(frame, b...)
+	}
+	binary.BigEndian.PutUint32(frame, uint32(len(frame)))
+	return frame
+}
+
+// cstr returns a C-Octet String
+func cstr(s string) []byte {
+	return append([]byte(s), 0)
+}
+
+// resize cuts or extends a frame and sets its command_length to match
+func resize(frame []byte, n int, extra ...byte) []byte {
+	out := append(slices.Clone(frame[:n]), extra...)
+	binary.BigEndian.PutUint32(out, uint32(len(out)))
+	return out
+}
+
+// decodeError decodes a frame and returns its DecodeError
+func decodeError(t *testing.T, frame []byte) *DecodeError {
+	t.Helper()
+	_, err := Decode(frame)
+	var derr *DecodeError
+	if !errors.As(err, &derr) {
+		t.Fatalf("Decode error = %v, want a DecodeError", err)
+	}
+	return derr
+}
+
+// standardPDUs returns an empty PDU of every standard command in the
+// default registry, with the least it needs to be marshalled
+func standardPDUs(t *testing.T) []PDU {
+	t.Helper()
+	var pdus []PDU
+	for id := range commandNames {
+		p, err := New(id)
+		if err != nil {
+			continue
+		}
+		switch p := p.(type) {
+		case *SubmitMulti:
+			p.AddDestAddr("447700900123", TON_INTERNATIONAL, NPI_ISDN)
+		case *BroadcastSM:
+			p.TLVParams = testBroadcastParams()
+		}
+		pdus = append(pdus, p)
+	}
+	slices.SortFunc(pdus, func(a, b PDU) int { return int(a.CommandID()) - int(b.CommandID()) })
+	return pdus
+}
+
+func TestDecodeErrorFields(t *testing.T) {
+	ton := []byte{0, 0}
+	tests := []struct {
+		name   string
+		frame  []byte
+		field  string
+		offset int
+		status uint32
+		err    error
+	}{
+		{
+			"service_type too long",
+			testFrame(SUBMIT_SM, cstr("LONGTYP")),
+			"service_type", 16, ESME_RINVSERTYP, ErrCStringTooLong,
+		},
+		{
+			"source_addr too long",
+			testFrame(SUBMIT_SM, cstr(""), ton, cstr(strings.Repeat("1", 21))),
+			"source_addr", 19, ESME_RINVSRCADR, ErrCStringTooLong,
+		},
+		{
+			"destination_addr without null",
+			testFrame(SUBMIT_SM, cstr(""), ton, cstr("1"), ton, []byte("4477")),
+			"destination_addr", 23, ESME_RINVCMDLEN, ErrMissingNullByte,
+		},
+		{
+			"schedule_delivery_time too long",
+			testFrame(SUBMIT_SM, cstr(""), ton, cstr(""), ton, cstr(""), []byte{0, 0, 0}, cstr(strings.Repeat("0", 17))),
+			"schedule_delivery_time", 26, ESME_RINVSCHED, ErrCStringTooLong,
+		},
+		{
+			"sm_length above 254",
+			testFrame(SUBMIT_SM, cstr(""), ton, cstr(""), ton, cstr(""), []byte{0, 0, 0}, cstr(""), cstr(""), []byte{0, 0, 0, 0, 255}),
+			"short_message", 33, ESME_RINVMSGLEN, ErrInvalidLength,
+		},
+		{
+			"short_message shorter than sm_length",
+			testFrame(SUBMIT_SM, cstr(""), ton, cstr(""), ton, cstr(""), []byte{0, 0, 0}, cstr(""), cstr(""), []byte{0, 0, 0, 0, 10}, []byte("hello")),
+			"short_message", 33, ESME_RINVCMDLEN, ErrTruncated,
+		},
+		{
+			"system_id too long",
+			testFrame(BIND_TRANSMITTER, cstr(strings.Repeat("s", 16))),
+			"system_id", 16, ESME_RINVSYSID, ErrCStringTooLong,
+		},
+		{
+			"password missing",
+			testFrame(BIND_TRANSMITTER, cstr("esme")),
+			"password", 21, ESME_RINVCMDLEN, ErrMissingNullByte,
+		},
+		{
+			"interface_version missing",
+			testFrame(BIND_TRANSCEIVER, cstr("esme"), cstr("pw"), cstr("")),
+			"interface_version", 25, ESME_RINVCMDLEN, ErrTruncated,
+		},
+		{
+			"message_id too long",
+			testFrame(SUBMIT_SM_RESP, cstr(strings.Repeat("f", 65))),
+			"message_id", 16, ESME_RINVMSGID, ErrCStringTooLong,
+		},
+		{
+			"unsuccess_sme truncated",
+			testFrame(SUBMIT_MULTI_RESP, cstr("1"), []byte{2, 1, 1}, cstr("447700900123"), []byte{0, 0, 0, 0x0B}, []byte{1, 1}),
+			"destination_addr", 40, ESME_RINVCMDLEN, ErrMissingNullByte,
+		},
+		{
+			"tlv header cut",
+			testFrame(SUBMIT_SM_RESP, cstr("1"), []byte{0x02, 0x04, 0x00}),
+			"optional parameter", 18, ESME_RINVOPTPARSTREAM, ErrTLVTooShort,
+		},
+		{
+			"tlv value beyond the pdu",
+			testFrame(DATA_SM_RESP, cstr("1"), []byte{0x04, 0x25, 0x00, 0x01, 0x00}, []byte{0x02, 0x04, 0x00, 0x02, 0x00}),
+			"tlv 0x0204", 23, ESME_RINVPARLEN, ErrTLVLengthMismatch,
+		},
+		{
+			"enquire_link with a body",
+			testFrame(ENQUIRE_LINK, []byte{0}),
+			"command_length", 16, ESME_RINVCMDLEN, ErrTrailingData,
+		},
+		{
+			"cancel_sm with trailing data",
+			testFrame(CANCEL_SM, cstr(""), cstr("1"), ton, cstr(""), ton, cstr(""), []byte{0xFF}),
+			"command_length", 25, ESME_RINVCMDLEN, ErrTrailingData,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			derr := decodeError(t, tt.frame)
+			if derr.Field != tt.field || derr.Offset != tt.offset || derr.Status != tt.status {
+				t.Errorf("DecodeError %s at %d status 0x%08X, want %s at %d status 0x%08X",
+					derr.Field, derr.Offset, derr.Status, tt.field, tt.offset, tt.status)
+			}
+			if !errors.Is(derr, tt.err) {
+				t.Errorf("DecodeError wraps %v, want %v", derr.Err, tt.err)
+			}
+			if status, ok := ErrorStatus(derr); !ok || status != tt.status {
+				t.Errorf("ErrorStatus = 0x%08X, %v", status, ok)
+			}
+		})
+	}
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	for _, p := range standardPDUs(t) {
+		name := CommandName(p.CommandID())
+		frame, err := p.Marshal()
+		if err != nil {
+			t.Fatalf("%s: Marshal: %v", name, err)
+		}
+		// Every cut inside the mandatory fields is reported at the field
+		// it falls in
+		end := len(frame)
+		if carrier, ok := p.(TLVCarrier); ok {
+			end -= carrier.GetTLVParams().EncodedLen()
+		}
+		for n := HeaderLength; n < end; n++ {
+			derr := decodeError(t, resize(frame, n))
+			if derr.Status != ESME_RINVCMDLEN || derr.Offset < HeaderLength || derr.Offset > n {
+				t.Errorf("%s cut to %d: %v, status 0x%08X", name, n, derr, derr.Status)
+			}
+			if !errors.Is(derr, ErrTruncated) && !errors.Is(derr, ErrMissingNullByte) {
+				t.Errorf("%s cut to %d: %v, want a truncated field", name, n, derr)
+			}
+		}
+	}
+}
+
+func TestDecodeOverlong(t *testing.T) {
+	for _, p := range standardPDUs(t) {
+		name := CommandName(p.CommandID())
+		frame, err := p.Marshal()
+		if err != nil {
+			t.Fatalf("%s: Marshal: %v", name, err)
+		}
+		_, hasTLVs := p.(TLVCarrier)
+
+		for extra := 1; extra <= 3; extra++ {
+			derr := decodeError(t, resize(frame, len(frame), make([]byte, extra)...))
+			want := DecodeError{Field: "command_length", Offset: len(frame), Status: ESME_RINVCMDLEN, Err: ErrTrailingData}
+			if hasTLVs {
+				want = DecodeError{Field: "optional parameter", Offset: len(frame), Status: ESME_RINVOPTPARSTREAM, Err: ErrTLVTooShort}
+			}
+			if derr.Field != want.Field || derr.Offset != want.Offset || derr.Status != want.Status || !errors.Is(derr, want.Err) {
+				t.Errorf("%s with %d extra octets: %v status 0x%08X, want %v status 0x%08X", name, extra, derr, derr.Status, &want, want.Status)
+			}
+		}
+
+		if hasTLVs {
+			// A parameter claiming more octets than are left
+			derr := decodeError(t, resize(frame, len(frame), 0x14, 0x00, 0x00, 0x08, 0x01))
+			if derr.Field != "tlv 0x1400" || derr.Offset != len(frame) || derr.Status != ESME_RINVPARLEN {
+				t.Errorf("%s with a long tlv: %v status 0x%08X", name, derr, derr.Status)
+			}
+		}
+	}
+}
+
+func TestDecodeCommandLength(t *testing.T) {
+	frame := testFrame(ENQUIRE_LINK)
+
+	// command_length must match the frame
+	long := append(slices.Clone(frame), 0)
+	if _, err := Decode(long); !errors.Is(err, ErrInvalidCommandLength) {
+		t.Errorf("Decode error = %v, want ErrInvalidCommandLength", err)
+	}
+
+	// The decoder itself never reads past command_length
+	dec := newDecoder(long, false)
+	h := &Header{}
+	dec.header(h)
+	dec.end()
+	if err := dec.Err(); err != nil {
+		t.Errorf("decoder read past command_length: %v", err)
+	}
+
+	short := slices.Clone(frame)
+	binary.BigEndian.PutUint32(short, 12)
+	dec = newDecoder(short, false)
+	dec.header(h)
+	var derr *DecodeError
+	if !errors.As(dec.Err(), &derr) || derr.Field != "command_length" || derr.Status != ESME_RINVCMDLEN {
+		t.Errorf("command_length 12: %v", dec.Err())
+	}
+}
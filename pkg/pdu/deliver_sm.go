@@ -1,289 +1,281 @@
 package pdu
 
 import (
-	"errors"
+	"fmt"
+	"time"
 )
 
 // DeliverSM represents an SMPP deliver_sm PDU
 type DeliverSM struct {
-	Header               *Header
-	ServiceType          string
-	SourceAddrTON        uint8
-	SourceAddrNPI        uint8
-	SourceAddr           string
-	DestAddrTON          uint8
-	DestAddrNPI          uint8
-	DestinationAddr      string
-	ESMClass             uint8
-	ProtocolID           uint8
-	PriorityFlag         uint8
-	ScheduleDeliveryTime string
-	ValidityPeriod       string
-	RegisteredDelivery   uint8
-	ReplaceIfPresent     uint8
-	DataCoding           uint8
-	SMDefaultMsgID       uint8
-	SMLength             uint8
-	ShortMessage         []byte
-	TLVParams            map[uint16]*TLVParam
+	Header               *Header `json:"header"`
+	ServiceType          string  `json:"service_type"`
+	SourceAddrTON        uint8   `json:"source_addr_ton"`
+	SourceAddrNPI        uint8   `json:"source_addr_npi"`
+	SourceAddr           string  `json:"source_addr"`
+	DestAddrTON          uint8   `json:"dest_addr_ton"`
+	DestAddrNPI          uint8   `json:"dest_addr_npi"`
+	DestinationAddr      string  `json:"destination_addr"`
+	ESMClass             uint8   `json:"esm_class"`
+	ProtocolID           uint8   `json:"protocol_id"`
+	PriorityFlag         uint8   `json:"priority_flag"`
+	ScheduleDeliveryTime string  `json:"schedule_delivery_time"`
+	ValidityPeriod       string  `json:"validity_period"`
+	RegisteredDelivery   uint8   `json:"registered_delivery"`
+	ReplaceIfPresent     uint8   `json:"replace_if_present_flag"`
+	DataCoding           uint8   `json:"data_coding"`
+	SMDefaultMsgID       uint8   `json:"sm_default_msg_id"`
+	SMLength             uint8   `json:"-"`
+	ShortMessage         []byte  `json:"short_message"`
+	TLVParams            TLVList `json:"tlvs,omitempty"`
 }
 
 // NewDeliverSM creates a new DeliverSM PDU
 func NewDeliverSM() *DeliverSM {
 	return &DeliverSM{
-		Header:    NewHeader(),
-		TLVParams: make(map[uint16]*TLVParam),
+		Header: NewHeader(),
 	}
 }
 
+// Source returns source_addr with its TON and NPI
+func (d *DeliverSM) Source() Address {
+	return Address{TON: d.SourceAddrTON, NPI: d.SourceAddrNPI, Addr: d.SourceAddr}
+}
+
+// SetSource sets source_addr with its TON and NPI
+func (d *DeliverSM) SetSource(a Address) {
+	d.SourceAddrTON, d.SourceAddrNPI, d.SourceAddr = a.TON, a.NPI, a.Addr
+}
+
+// Destination returns destination_addr with its TON and NPI
+func (d *DeliverSM) Destination() Address {
+	return Address{TON: d.DestAddrTON, NPI: d.DestAddrNPI, Addr: d.DestinationAddr}
+}
+
+// SetDestination sets destination_addr with its TON and NPI
+func (d *DeliverSM) SetDestination(a Address) {
+	d.DestAddrTON, d.DestAddrNPI, d.DestinationAddr = a.TON, a.NPI, a.Addr
+}
+
 // IsDeliveryReceipt checks if this PDU is a delivery receipt
 func (d *DeliverSM) IsDeliveryReceipt() bool {
-	return (d.ESMClass & 0x04) != 0
+	return d.ESMClass&ESM_CLASS_TYPE_MASK == ESM_CLASS_TYPE_DELIVERY_RECEIPT
 }
 
 // SetMessageText sets the message text with the specified data coding
 func (d *DeliverSM) SetMessageText(text string, coding uint8) error {
-	d.DataCoding = coding
-	d.ShortMessage = []byte(text)
-	if len(d.ShortMessage) > 255 {
-		return errors.New("message text too long")
+	data, err := TextEncoding{DataCoding: coding}.Encode(text)
+	if err != nil {
+		return err
 	}
-	d.SMLength = uint8(len(d.ShortMessage))
+	if len(data) > SM_MAX_LENGTH {
+		return ErrMessageTooLong
+	}
+	d.DataCoding = coding
+	d.ShortMessage = data
+	d.SMLength = uint8(len(data))
 	return nil
 }
 
-// Marshal serializes the PDU into bytes
-func (d *DeliverSM) Marshal() ([]byte, error) {
-	// Calculate the total length
-	length := 16 // Header length
-	length += len(d.ServiceType) + 1
-	length += 2 // Source addr ton and npi
-	length += len(d.SourceAddr) + 1
-	length += 2 // Dest addr ton and npi
-	length += len(d.DestinationAddr) + 1
-	length += 5 // ESM class, protocol id, priority flag, registered delivery, replace if present
-	length += len(d.ScheduleDeliveryTime) + 1
-	length += len(d.ValidityPeriod) + 1
-	length += 3 // Data coding, sm default msg id, sm length
-	length += len(d.ShortMessage)
-
-	// Add TLV parameters length
-	for _, tlv := range d.TLVParams {
-		length += 4 + len(tlv.Value)
-	}
-
-	// Set command length in header
-	d.Header.CommandLength = uint32(length)
-	d.Header.CommandID = DELIVER_SM
-
-	// Create the byte slice
-	buf := make([]byte, length)
-
-	// Marshal header
-	headerBytes, err := d.Header.Marshal()
-	if err != nil {
-		return nil, err
+// SetText sets the message text using the cheapest data coding, adding a
+// UDH when national language shift tables are needed
+func (d *DeliverSM) SetText(text string, opts *TextOptions) (*EncodedText, error) {
+	encoded := SelectTextEncoding(text, opts)
+	sm := encoded.ShortMessage()
+	if len(sm) > SM_MAX_LENGTH {
+		return encoded, ErrMessageTooLong
 	}
-	copy(buf[0:], headerBytes)
-
-	offset := 16
-
-	// Write service_type
-	copy(buf[offset:], d.ServiceType)
-	offset += len(d.ServiceType) + 1
-
-	// Write source_addr_ton
-	buf[offset] = d.SourceAddrTON
-	offset++
-
-	// Write source_addr_npi
-	buf[offset] = d.SourceAddrNPI
-	offset++
-
-	// Write source_addr
-	copy(buf[offset:], d.SourceAddr)
-	offset += len(d.SourceAddr) + 1
-
-	// Write dest_addr_ton
-	buf[offset] = d.DestAddrTON
-	offset++
-
-	// Write dest_addr_npi
-	buf[offset] = d.DestAddrNPI
-	offset++
-
-	// Write destination_addr
-	copy(buf[offset:], d.DestinationAddr)
-	offset += len(d.DestinationAddr) + 1
-
-	// Write esm_class
-	buf[offset] = d.ESMClass
-	offset++
-
-	// Write protocol_id
-	buf[offset] = d.ProtocolID
-	offset++
-
-	// Write priority_flag
-	buf[offset] = d.PriorityFlag
-	offset++
-
-	// Write schedule_delivery_time
-	copy(buf[offset:], d.ScheduleDeliveryTime)
-	offset += len(d.ScheduleDeliveryTime) + 1
-
-	// Write validity_period
-	copy(buf[offset:], d.ValidityPeriod)
-	offset += len(d.ValidityPeriod) + 1
-
-	// Write registered_delivery
-	buf[offset] = d.RegisteredDelivery
-	offset++
-
-	// Write replace_if_present
-	buf[offset] = d.ReplaceIfPresent
-	offset++
-
-	// Write data_coding
-	buf[offset] = d.DataCoding
-	offset++
-
-	// Write sm_default_msg_id
-	buf[offset] = d.SMDefaultMsgID
-	offset++
-
-	// Write sm_length
-	buf[offset] = d.SMLength
-	offset++
-
-	// Write short_message
-	copy(buf[offset:], d.ShortMessage)
-	offset += len(d.ShortMessage)
-
-	// Write TLV parameters
-	for _, tlv := range d.TLVParams {
-		tlvBytes, err := tlv.Marshal()
-		if err != nil {
-			return nil, err
-		}
-		copy(buf[offset:], tlvBytes)
-		offset += 4 + len(tlv.Value)
+	d.DataCoding = encoded.DataCoding
+	d.ShortMessage = sm
+	d.SMLength = uint8(len(sm))
+	if encoded.HasUDH() {
+		d.ESMClass |= ESM_CLASS_UDHI
 	}
+	return encoded, nil
+}
 
-	return buf, nil
+// MessageText decodes the message text from short_message, or from the
+// message_payload TLV when short_message is empty
+func (d *DeliverSM) MessageText() (string, error) {
+	return messageText(d.ShortMessage, d.ESMClass, d.DataCoding, d.TLVParams)
 }
 
-// Unmarshal deserializes the PDU from bytes
-func (d *DeliverSM) Unmarshal(data []byte) error {
-	var err error
+// UserData splits short_message into its UDH, nil when the UDHI flag is not
+// set in esm_class, and the user payload following it
+func (d *DeliverSM) UserData() (*UDH, []byte, error) {
+	return SplitUserData(d.ShortMessage, d.ESMClass)
+}
 
-	// Unmarshal header
-	d.Header = &Header{}
-	if err = d.Header.Unmarshal(data[:16]); err != nil {
-		return err
-	}
+// SetScheduleDeliveryTime sets an absolute schedule_delivery_time, the zero
+// time clears it
+func (d *DeliverSM) SetScheduleDeliveryTime(t time.Time) {
+	d.ScheduleDeliveryTime = FormatAbsoluteTime(t)
+}
 
-	offset := 16
+// SetScheduleDeliveryDelay sets a relative schedule_delivery_time
+func (d *DeliverSM) SetScheduleDeliveryDelay(delay time.Duration) {
+	d.ScheduleDeliveryTime = FormatRelativeTime(delay)
+}
 
-	// Read service_type
-	if d.ServiceType, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
+// ScheduledAt returns the schedule_delivery_time, resolving a relative time
+// against now. The zero time means immediate delivery.
+func (d *DeliverSM) ScheduledAt(now time.Time) (time.Time, error) {
+	t, err := ParseSMPPTime(d.ScheduleDeliveryTime, now)
+	if err != nil {
+		return t, fmt.Errorf("%w: %v", ErrInvalidScheduleTime, err)
 	}
+	return t, nil
+}
 
-	// Read source_addr_ton
-	d.SourceAddrTON = data[offset]
-	offset++
+// SetValidityPeriod sets an absolute validity_period, the zero time clears it
+func (d *DeliverSM) SetValidityPeriod(t time.Time) {
+	d.ValidityPeriod = FormatAbsoluteTime(t)
+}
 
-	// Read source_addr_npi
-	d.SourceAddrNPI = data[offset]
-	offset++
+// SetValidityDuration sets a relative validity_period
+func (d *DeliverSM) SetValidityDuration(validity time.Duration) {
+	d.ValidityPeriod = FormatRelativeTime(validity)
+}
 
-	// Read source_addr
-	if d.SourceAddr, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
+// ExpiresAt returns the validity_period, resolving a relative time against
+// now. The zero time means the SMSC default validity.
+func (d *DeliverSM) ExpiresAt(now time.Time) (time.Time, error) {
+	t, err := ParseSMPPTime(d.ValidityPeriod, now)
+	if err != nil {
+		return t, fmt.Errorf("%w: %v", ErrInvalidValidityPeriod, err)
 	}
+	return t, nil
+}
 
-	// Read dest_addr_ton
-	d.DestAddrTON = data[offset]
-	offset++
+// Validate checks the schedule_delivery_time and validity_period
+func (d *DeliverSM) Validate() error {
+	return validateMessageTimes(d.ScheduleDeliveryTime, d.ValidityPeriod)
+}
 
-	// Read dest_addr_npi
-	d.DestAddrNPI = data[offset]
-	offset++
+// checkFields checks the mandatory fields against their specified sizes
+func (d *DeliverSM) checkFields() error {
+	return firstError(
+		checkCString("service_type", d.ServiceType, SERVICE_TYPE_MAX_LEN, ESME_RINVSERTYP),
+		checkCString("source_addr", d.SourceAddr, ADDR_MAX_LEN, ESME_RINVSRCADR),
+		checkCString("destination_addr", d.DestinationAddr, ADDR_MAX_LEN, ESME_RINVDSTADR),
+		checkCString("schedule_delivery_time", d.ScheduleDeliveryTime, TIME_MAX_LEN, ESME_RINVSCHED),
+		checkCString("validity_period", d.ValidityPeriod, TIME_MAX_LEN, ESME_RINVEXPIRY),
+		checkOctets("short_message", d.ShortMessage, SM_MAX_LENGTH, ESME_RINVMSGLEN),
+	)
+}
 
-	// Read destination_addr
-	if d.DestinationAddr, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
+// EncodedLen returns the size of the encoded PDU in bytes
+func (d *DeliverSM) EncodedLen() int {
+	length := HeaderLength
+	length += len(d.ServiceType) + 1
+	length += 2 // source_addr_ton, source_addr_npi
+	length += len(d.SourceAddr) + 1
+	length += 2 // dest_addr_ton, dest_addr_npi
+	length += len(d.DestinationAddr) + 1
+	length += 3 // esm_class, protocol_id, priority_flag
+	length += len(d.ScheduleDeliveryTime) + 1
+	length += len(d.ValidityPeriod) + 1
+	length += 5 // registered_delivery, replace_if_present, data_coding, sm_default_msg_id, sm_length
+	length += len(d.ShortMessage)
+	length += d.TLVParams.EncodedLen()
+	return length
+}
+
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (d *DeliverSM) AppendTo(dst []byte) ([]byte, error) {
+	if err := d.checkFields(); err != nil {
+		return dst, err
 	}
 
-	// Read esm_class
-	d.ESMClass = data[offset]
-	offset++
+	d.SMLength = uint8(len(d.ShortMessage))
+	d.Header.CommandLength = uint32(d.EncodedLen())
+	d.Header.CommandID = DELIVER_SM
 
-	// Read protocol_id
-	d.ProtocolID = data[offset]
-	offset++
+	dst = d.Header.AppendTo(dst)
+	dst = appendCString(dst, d.ServiceType)
+	dst = append(dst, d.SourceAddrTON, d.SourceAddrNPI)
+	dst = appendCString(dst, d.SourceAddr)
+	dst = append(dst, d.DestAddrTON, d.DestAddrNPI)
+	dst = appendCString(dst, d.DestinationAddr)
+	dst = append(dst, d.ESMClass, d.ProtocolID, d.PriorityFlag)
+	dst = appendCString(dst, d.ScheduleDeliveryTime)
+	dst = appendCString(dst, d.ValidityPeriod)
+	dst = append(dst, d.RegisteredDelivery, d.ReplaceIfPresent, d.DataCoding, d.SMDefaultMsgID, d.SMLength)
+	dst = append(dst, d.ShortMessage...)
+	dst = d.TLVParams.AppendTo(dst)
+	return dst, nil
+}
 
-	// Read priority_flag
-	d.PriorityFlag = data[offset]
-	offset++
+// Marshal serializes the PDU into bytes
+func (d *DeliverSM) Marshal() ([]byte, error) {
+	return d.AppendTo(make([]byte, 0, d.EncodedLen()))
+}
 
-	// Read schedule_delivery_time
-	if d.ScheduleDeliveryTime, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+// Unmarshal deserializes the PDU from bytes
+func (d *DeliverSM) Unmarshal(data []byte) error {
+	return d.decode(newDecoder(data, false))
+}
 
-	// Read validity_period
-	if d.ValidityPeriod, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+// decode reads the PDU fields from dec
+func (d *DeliverSM) decode(dec *decoder) error {
+	d.Header = &Header{}
+	dec.header(d.Header)
+
+	d.ServiceType = dec.cstring("service_type", SERVICE_TYPE_MAX_LEN, ESME_RINVSERTYP)
+	d.SourceAddrTON = dec.uint8("source_addr_ton")
+	d.SourceAddrNPI = dec.uint8("source_addr_npi")
+	d.SourceAddr = dec.cstring("source_addr", ADDR_MAX_LEN, ESME_RINVSRCADR)
+	d.DestAddrTON = dec.uint8("dest_addr_ton")
+	d.DestAddrNPI = dec.uint8("dest_addr_npi")
+	d.DestinationAddr = dec.cstring("destination_addr", ADDR_MAX_LEN, ESME_RINVDSTADR)
+	d.ESMClass = dec.uint8("esm_class")
+	d.ProtocolID = dec.uint8("protocol_id")
+	d.PriorityFlag = dec.uint8("priority_flag")
+	d.ScheduleDeliveryTime = dec.cstring("schedule_delivery_time", TIME_MAX_LEN, ESME_RINVSCHED)
+	d.ValidityPeriod = dec.cstring("validity_period", TIME_MAX_LEN, ESME_RINVEXPIRY)
+	d.RegisteredDelivery = dec.uint8("registered_delivery")
+	d.ReplaceIfPresent = dec.uint8("replace_if_present")
+	d.DataCoding = dec.uint8("data_coding")
+	d.SMDefaultMsgID = dec.uint8("sm_default_msg_id")
+	d.SMLength = dec.uint8("sm_length")
+	d.ShortMessage = dec.octets("short_message", int(d.SMLength), SM_MAX_LENGTH, ESME_RINVMSGLEN)
+
+	dec.tlvs(&d.TLVParams)
+	return dec.Err()
+}
 
-	// Read registered_delivery
-	d.RegisteredDelivery = data[offset]
-	offset++
+// CommandID returns the command_id of the PDU
+func (d *DeliverSM) CommandID() uint32 {
+	return DELIVER_SM
+}
 
-	// Read replace_if_present
-	d.ReplaceIfPresent = data[offset]
-	offset++
+// GetHeader returns the PDU header
+func (d *DeliverSM) GetHeader() *Header {
+	return d.Header
+}
 
-	// Read data_coding
-	d.DataCoding = data[offset]
-	offset++
+// String returns a one-line description of the PDU for trace logs
+func (d *DeliverSM) String() string {
+	return FormatPDU(d)
+}
 
-	// Read sm_default_msg_id
-	d.SMDefaultMsgID = data[offset]
-	offset++
+// MarshalJSON encodes the PDU as JSON
+func (d *DeliverSM) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(d)
+}
 
-	// Read sm_length
-	d.SMLength = data[offset]
-	offset++
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (d *DeliverSM) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, d)
+}
 
-	// Read short_message
-	if int(d.SMLength) > len(data[offset:]) {
-		return errors.New("invalid short message length")
-	}
-	d.ShortMessage = make([]byte, d.SMLength)
-	copy(d.ShortMessage, data[offset:offset+int(d.SMLength)])
-	offset += int(d.SMLength)
-
-	// Read TLV parameters if any remain
-	remaining := int(d.Header.CommandLength) - offset
-	currentOffset := offset
-
-	for remaining > 0 {
-		if remaining < 4 {
-			return errors.New("invalid TLV parameter: insufficient data")
-		}
-
-		tlv := &TLVParam{}
-		if err := tlv.Unmarshal(data[currentOffset:]); err != nil {
-			return err
-		}
-
-		d.TLVParams[tlv.Tag] = tlv
-		currentOffset += 4 + int(tlv.Length)
-		remaining -= 4 + int(tlv.Length)
-	}
+// GetTLVParams returns the optional parameters of the PDU
+func (d *DeliverSM) GetTLVParams() *TLVList {
+	return &d.TLVParams
+}
 
-	return nil
+// GetResponse creates the deliver_sm_resp answering this PDU
+func (d *DeliverSM) GetResponse() PDU {
+	resp := NewDeliverSMResp()
+	resp.Header = responseHeader(d.Header, DELIVER_SM_RESP)
+	return resp
 }
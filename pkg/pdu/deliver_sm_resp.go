@@ -1,101 +1,101 @@
 package pdu
 
-import "errors"
-
 // DeliverSMResp represents an SMPP deliver_sm_resp PDU
 type DeliverSMResp struct {
-	Header    *Header
-	MessageID string
-	TLVParams map[uint16]*TLVParam
+	Header    *Header `json:"header"`
+	MessageID string  `json:"message_id"`
+	TLVParams TLVList `json:"tlvs,omitempty"`
 }
 
 // NewDeliverSMResp creates a new DeliverSMResp PDU
 func NewDeliverSMResp() *DeliverSMResp {
 	return &DeliverSMResp{
-		Header:    NewHeader(),
-		TLVParams: make(map[uint16]*TLVParam),
+		Header: NewHeader(),
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (d *DeliverSMResp) Marshal() ([]byte, error) {
-	// Calculate the total length
-	length := 16 // Header length
+// checkFields checks the mandatory fields against their specified sizes
+func (d *DeliverSMResp) checkFields() error {
+	return firstError(
+		checkCString("message_id", d.MessageID, MESSAGE_ID_MAX_LEN, ESME_RINVMSGID),
+	)
+}
+
+// EncodedLen returns the size of the encoded PDU in bytes
+func (d *DeliverSMResp) EncodedLen() int {
+	length := HeaderLength
 	length += len(d.MessageID) + 1
+	length += d.TLVParams.EncodedLen()
+	return length
+}
 
-	// Add TLV parameters length
-	for _, tlv := range d.TLVParams {
-		length += 4 + len(tlv.Value)
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (d *DeliverSMResp) AppendTo(dst []byte) ([]byte, error) {
+	if err := d.checkFields(); err != nil {
+		return dst, err
 	}
 
-	// Set command length in header
-	d.Header.CommandLength = uint32(length)
+	d.Header.CommandLength = uint32(d.EncodedLen())
 	d.Header.CommandID = DELIVER_SM_RESP
 
-	// Create the byte slice
-	buf := make([]byte, length)
-
-	// Marshal header
-	headerBytes, err := d.Header.Marshal()
-	if err != nil {
-		return nil, err
-	}
-	copy(buf[0:], headerBytes)
-
-	offset := 16
-
-	// Write message_id
-	copy(buf[offset:], d.MessageID)
-	offset += len(d.MessageID) + 1
-
-	// Write TLV parameters
-	for _, tlv := range d.TLVParams {
-		tlvBytes, err := tlv.Marshal()
-		if err != nil {
-			return nil, err
-		}
-		copy(buf[offset:], tlvBytes)
-		offset += 4 + len(tlv.Value)
-	}
+	dst = d.Header.AppendTo(dst)
+	dst = appendCString(dst, d.MessageID)
+	dst = d.TLVParams.AppendTo(dst)
+	return dst, nil
+}
 
-	return buf, nil
+// Marshal serializes the PDU into bytes
+func (d *DeliverSMResp) Marshal() ([]byte, error) {
+	return d.AppendTo(make([]byte, 0, d.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (d *DeliverSMResp) Unmarshal(data []byte) error {
-	var err error
+	return d.decode(newDecoder(data, false))
+}
 
-	// Unmarshal header
+// decode reads the PDU fields from dec
+func (d *DeliverSMResp) decode(dec *decoder) error {
 	d.Header = &Header{}
-	if err = d.Header.Unmarshal(data[:16]); err != nil {
-		return err
-	}
+	dec.header(d.Header)
 
-	offset := 16
+	d.MessageID = dec.cstring("message_id", MESSAGE_ID_MAX_LEN, ESME_RINVMSGID)
 
-	// Read message_id
-	if d.MessageID, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+	dec.tlvs(&d.TLVParams)
+	return dec.Err()
+}
+
+// CommandID returns the command_id of the PDU
+func (d *DeliverSMResp) CommandID() uint32 {
+	return DELIVER_SM_RESP
+}
+
+// GetHeader returns the PDU header
+func (d *DeliverSMResp) GetHeader() *Header {
+	return d.Header
+}
 
-	// Read TLV parameters if any remain
-	remaining := int(d.Header.CommandLength) - offset
-	currentOffset := offset
+// String returns a one-line description of the PDU for trace logs
+func (d *DeliverSMResp) String() string {
+	return FormatPDU(d)
+}
 
-	for remaining > 0 {
-		if remaining < 4 {
-			return errors.New("invalid TLV parameter: insufficient data")
-		}
+// MarshalJSON encodes the PDU as JSON
+func (d *DeliverSMResp) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(d)
+}
 
-		tlv := &TLVParam{}
-		if err := tlv.Unmarshal(data[currentOffset:]); err != nil {
-			return err
-		}
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (d *DeliverSMResp) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, d)
+}
 
-		d.TLVParams[tlv.Tag] = tlv
-		currentOffset += 4 + int(tlv.Length)
-		remaining -= 4 + int(tlv.Length)
-	}
+// GetTLVParams returns the optional parameters of the PDU
+func (d *DeliverSMResp) GetTLVParams() *TLVList {
+	return &d.TLVParams
+}
 
+// GetResponse returns nil as deliver_sm_resp has no response PDU
+func (d *DeliverSMResp) GetResponse() PDU {
 	return nil
 }
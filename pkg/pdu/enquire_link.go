@@ -2,7 +2,7 @@ package pdu
 
 // EnquireLink represents an SMPP enquire_link PDU
 type EnquireLink struct {
-	Header *Header
+	Header *Header `json:"header"`
 }
 
 // NewEnquireLink creates a new EnquireLink PDU
@@ -12,19 +12,66 @@ func NewEnquireLink() *EnquireLink {
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (e *EnquireLink) Marshal() ([]byte, error) {
-	// Set command length in header
-	e.Header.CommandLength = 16 // Only header, no body
+// EncodedLen returns the size of the encoded PDU in bytes
+func (e *EnquireLink) EncodedLen() int {
+	return HeaderLength
+}
+
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (e *EnquireLink) AppendTo(dst []byte) ([]byte, error) {
+	e.Header.CommandLength = uint32(e.EncodedLen())
 	e.Header.CommandID = ENQUIRE_LINK
 
-	// Marshal header
-	return e.Header.Marshal()
+	return e.Header.AppendTo(dst), nil
+}
+
+// Marshal serializes the PDU into bytes
+func (e *EnquireLink) Marshal() ([]byte, error) {
+	return e.AppendTo(make([]byte, 0, e.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (e *EnquireLink) Unmarshal(data []byte) error {
-	// Unmarshal header
+	return e.decode(newDecoder(data, false))
+}
+
+// decode reads the PDU fields from dec
+func (e *EnquireLink) decode(dec *decoder) error {
 	e.Header = &Header{}
-	return e.Header.Unmarshal(data[:16])
+	dec.header(e.Header)
+
+	dec.end()
+	return dec.Err()
+}
+
+// CommandID returns the command_id of the PDU
+func (e *EnquireLink) CommandID() uint32 {
+	return ENQUIRE_LINK
+}
+
+// GetHeader returns the PDU header
+func (e *EnquireLink) GetHeader() *Header {
+	return e.Header
+}
+
+// String returns a one-line description of the PDU for trace logs
+func (e *EnquireLink) String() string {
+	return FormatPDU(e)
+}
+
+// MarshalJSON encodes the PDU as JSON
+func (e *EnquireLink) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(e)
+}
+
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (e *EnquireLink) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, e)
+}
+
+// GetResponse creates the enquire_link_resp answering this PDU
+func (e *EnquireLink) GetResponse() PDU {
+	resp := NewEnquireLinkResp()
+	resp.Header = responseHeader(e.Header, ENQUIRE_LINK_RESP)
+	return resp
 }
@@ -2,7 +2,7 @@ package pdu
 
 // EnquireLinkResp represents an SMPP enquire_link_resp PDU
 type EnquireLinkResp struct {
-	Header *Header
+	Header *Header `json:"header"`
 }
 
 // NewEnquireLinkResp creates a new EnquireLinkResp PDU
@@ -12,19 +12,64 @@ func NewEnquireLinkResp() *EnquireLinkResp {
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (e *EnquireLinkResp) Marshal() ([]byte, error) {
-	// Set command length in header
-	e.Header.CommandLength = 16 // Only header, no body
+// EncodedLen returns the size of the encoded PDU in bytes
+func (e *EnquireLinkResp) EncodedLen() int {
+	return HeaderLength
+}
+
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (e *EnquireLinkResp) AppendTo(dst []byte) ([]byte, error) {
+	e.Header.CommandLength = uint32(e.EncodedLen())
 	e.Header.CommandID = ENQUIRE_LINK_RESP
 
-	// Marshal header
-	return e.Header.Marshal()
+	return e.Header.AppendTo(dst), nil
+}
+
+// Marshal serializes the PDU into bytes
+func (e *EnquireLinkResp) Marshal() ([]byte, error) {
+	return e.AppendTo(make([]byte, 0, e.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (e *EnquireLinkResp) Unmarshal(data []byte) error {
-	// Unmarshal header
+	return e.decode(newDecoder(data, false))
+}
+
+// decode reads the PDU fields from dec
+func (e *EnquireLinkResp) decode(dec *decoder) error {
 	e.Header = &Header{}
-	return e.Header.Unmarshal(data[:16])
+	dec.header(e.Header)
+
+	dec.end()
+	return dec.Err()
+}
+
+// CommandID returns the command_id of the PDU
+func (e *EnquireLinkResp) CommandID() uint32 {
+	return ENQUIRE_LINK_RESP
+}
+
+// GetHeader returns the PDU header
+func (e *EnquireLinkResp) GetHeader() *Header {
+	return e.Header
+}
+
+// String returns a one-line description of the PDU for trace logs
+func (e *EnquireLinkResp) String() string {
+	return FormatPDU(e)
+}
+
+// MarshalJSON encodes the PDU as JSON
+func (e *EnquireLinkResp) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(e)
+}
+
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (e *EnquireLinkResp) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, e)
+}
+
+// GetResponse returns nil as enquire_link_resp has no response PDU
+func (e *EnquireLinkResp) GetResponse() PDU {
+	return nil
 }
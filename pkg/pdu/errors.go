@@ -0,0 +1,51 @@
+package pdu
+
+import "errors"
+
+// StatusError is an error that maps to an SMPP command_status
+type StatusError struct {
+	Status  uint32
+	Message string
+}
+
+// NewStatusError creates an error carrying the given command_status
+func NewStatusError(status uint32, message string) *StatusError {
+	return &StatusError{Status: status, Message: message}
+}
+
+// ErrorFromStatus returns the error for the command_status of a response,
+// or nil if it reports success
+func ErrorFromStatus(status uint32) error {
+	if status == ESME_ROK {
+		return nil
+	}
+	return &StatusError{Status: status}
+}
+
+// Error returns the error message, or the description of the status if
+// there is none
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return StatusText(e.Status)
+	}
+	return e.Message
+}
+
+// CommandStatus returns the command_status to answer with
+func (e *StatusError) CommandStatus() uint32 {
+	return e.Status
+}
+
+// Temporary reports whether the command may succeed if retried later
+func (e *StatusError) Temporary() bool {
+	return IsTemporary(e.Status)
+}
+
+// ErrorStatus returns the command_status carried by err, if any
+func ErrorStatus(err error) (uint32, bool) {
+	var s interface{ CommandStatus() uint32 }
+	if errors.As(err, &s) {
+		return s.CommandStatus(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,71 @@
+package pdu
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Maximum C-Octet String field sizes, including the NULL terminator, as
+// given by the SMPP specification
+const (
+	SYSTEM_ID_MAX_LEN     int = 16
+	PASSWORD_MAX_LEN      int = 9
+	SYSTEM_TYPE_MAX_LEN   int = 13
+	ADDRESS_RANGE_MAX_LEN int = 41
+	SERVICE_TYPE_MAX_LEN  int = 6
+	ADDR_MAX_LEN          int = 21 // source_addr and destination_addr of most PDUs
+	LONG_ADDR_MAX_LEN     int = 65 // Addresses of data_sm and alert_notification
+	MESSAGE_ID_MAX_LEN    int = 65
+	TIME_MAX_LEN          int = 17 // schedule_delivery_time, validity_period and final_date
+	DL_NAME_MAX_LEN       int = 21
+)
+
+// FieldError reports a mandatory field that violates the specification.
+// Status is the command_status matching the field.
+type FieldError struct {
+	Field  string
+	Status uint32
+	Reason string
+}
+
+// Error returns the error message
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field %s: %s", e.Field, e.Reason)
+}
+
+// CommandStatus returns the command_status to answer with
+func (e *FieldError) CommandStatus() uint32 {
+	return e.Status
+}
+
+// checkCString checks that a C-Octet String fits into maxLen octets with its
+// NULL terminator and holds no NULL itself
+func checkCString(field string, value string, maxLen int, status uint32) error {
+	if len(value)+1 > maxLen {
+		return &FieldError{Field: field, Status: status,
+			Reason: fmt.Sprintf("length %d exceeds %d", len(value), maxLen-1)}
+	}
+	if strings.IndexByte(value, 0) != -1 {
+		return &FieldError{Field: field, Status: status, Reason: "contains a NULL octet"}
+	}
+	return nil
+}
+
+// checkOctets checks that an octet string is at most maxLen octets long
+func checkOctets(field string, value []byte, maxLen int, status uint32) error {
+	if len(value) > maxLen {
+		return &FieldError{Field: field, Status: status,
+			Reason: fmt.Sprintf("length %d exceeds %d", len(value), maxLen)}
+	}
+	return nil
+}
+
+// firstError returns the first non nil error
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package pdu
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// commandNames maps the command IDs to their specification names
+var commandNames = map[uint32]string{
+	GENERIC_NACK:             "generic_nack",
+	BIND_RECEIVER:            "bind_receiver",
+	BIND_RECEIVER_RESP:       "bind_receiver_resp",
+	BIND_TRANSMITTER:         "bind_transmitter",
+	BIND_TRANSMITTER_RESP:    "bind_transmitter_resp",
+	QUERY_SM:                 "query_sm",
+	QUERY_SM_RESP:            "query_sm_resp",
+	SUBMIT_SM:                "submit_sm",
+	SUBMIT_SM_RESP:           "submit_sm_resp",
+	DELIVER_SM:               "deliver_sm",
+	DELIVER_SM_RESP:          "deliver_sm_resp",
+	UNBIND:                   "unbind",
+	UNBIND_RESP:              "unbind_resp",
+	REPLACE_SM:               "replace_sm",
+	REPLACE_SM_RESP:          "replace_sm_resp",
+	CANCEL_SM:                "cancel_sm",
+	CANCEL_SM_RESP:           "cancel_sm_resp",
+	BIND_TRANSCEIVER:         "bind_transceiver",
+	BIND_TRANSCEIVER_RESP:    "bind_transceiver_resp",
+	OUTBIND:                  "outbind",
+	ENQUIRE_LINK:             "enquire_link",
+	ENQUIRE_LINK_RESP:        "enquire_link_resp",
+	SUBMIT_MULTI:             "submit_multi",
+	SUBMIT_MULTI_RESP:        "submit_multi_resp",
+	ALERT_NOTIFICATION:       "alert_notification",
+	DATA_SM:                  "data_sm",
+	DATA_SM_RESP:             "data_sm_resp",
+	BROADCAST_SM:             "broadcast_sm",
+	BROADCAST_SM_RESP:        "broadcast_sm_resp",
+	QUERY_BROADCAST_SM:       "query_broadcast_sm",
+	QUERY_BROADCAST_SM_RESP:  "query_broadcast_sm_resp",
+	CANCEL_BROADCAST_SM:      "cancel_broadcast_sm",
+	CANCEL_BROADCAST_SM_RESP: "cancel_broadcast_sm_resp",
+}
+
+// hexFields are the fields shown in hex by FormatPDU as they hold flags
+var hexFields = map[string]bool{
+	"esm_class":         true,
+	"data_coding":       true,
+	"interface_version": true,
+}
+
+// CommandName returns the specification name of a command, the name given
+// to a vendor command in DefaultRegistry, or its hex value if unknown. Use
+// the CommandName of a profile's registry for its vendor commands.
+func CommandName(commandID uint32) string {
+	return DefaultRegistry.CommandName(commandID)
+}
+
+// CommandIDByName returns the command ID of a specification name, a vendor
+// command name of DefaultRegistry or a 0x prefixed hex value as returned by
+// CommandName
+func CommandIDByName(name string) (uint32, bool) {
+	return DefaultRegistry.CommandIDByName(name)
+}
+
+// String returns the command, sequence number and status of the header
+func (h *Header) String() string {
+	return h.format(DefaultRegistry)
+}
+
+// pduHeader returns a copy of the header of a PDU with its command_id,
+// which is only set in the header once the PDU is marshalled
+func pduHeader(p PDU) *Header {
+	h := Header{}
+	if ph := p.GetHeader(); ph != nil {
+		h = *ph
+	}
+	h.CommandID = p.CommandID()
+	return &h
+}
+
+// format returns the String of the header with the command named after r
+func (h *Header) format(r *Registry) string {
+	return fmt.Sprintf("%s seq=%d status=%s", r.CommandName(h.CommandID), h.SequenceNumber, StatusName(h.CommandStatus))
+}
+
+// String returns the parameters as name=value pairs in order
+func (l TLVList) String() string {
+	parts := make([]string, 0, len(l))
+	for _, tlv := range l {
+		parts = append(parts, formatTLV(tlv))
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
+
+// FormatPDU returns a one-line description of a PDU for trace logs: the
+// header followed by the fields that are set, by specification name, and
+// the optional parameters. Text messages are shown decoded and binary
+// ones in hex. Vendor commands are named after DefaultRegistry.
+func FormatPDU(p PDU) string {
+	return DefaultRegistry.FormatPDU(p)
+}
+
+// FormatPDU returns the FormatPDU description of a PDU with vendor
+// commands named after the registry, e.g. that of a session's profile
+func (r *Registry) FormatPDU(p PDU) string {
+	parts := []string{pduHeader(p).format(r)}
+
+	v := reflect.ValueOf(p)
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Struct {
+		parts = append(parts, formatFields(v)...)
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatFields returns the name=value pairs of the fields of a PDU struct
+// that are set, named by their JSON tags
+func formatFields(v reflect.Value) []string {
+	var parts []string
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _ := jsonFieldName(t.Field(i))
+		if name == "" || name == "header" {
+			continue
+		}
+
+		f := v.Field(i)
+		if f.IsZero() || (f.Kind() == reflect.Slice && f.Len() == 0) {
+			continue
+		}
+
+		switch value := f.Interface().(type) {
+		case TLVList:
+			parts = append(parts, name+"="+value.String())
+		case []byte:
+			if name == "short_message" {
+				parts = append(parts, formatShortMessage(value, v)...)
+			} else {
+				parts = append(parts, name+"="+hex.EncodeToString(value))
+			}
+		case string:
+			parts = append(parts, fmt.Sprintf("%s=%q", name, value))
+		case uint8:
+			if hexFields[name] {
+				parts = append(parts, fmt.Sprintf("%s=0x%02X", name, value))
+			} else {
+				parts = append(parts, fmt.Sprintf("%s=%d", name, value))
+			}
+		default:
+			if f.Kind() == reflect.Slice && f.Type().Elem().Kind() == reflect.Struct {
+				items := make([]string, f.Len())
+				for j := range items {
+					items[j] = "{" + strings.Join(formatFields(f.Index(j)), " ") + "}"
+				}
+				parts = append(parts, name+"=["+strings.Join(items, " ")+"]")
+			} else {
+				parts = append(parts, fmt.Sprintf("%s=%v", name, value))
+			}
+		}
+	}
+	return parts
+}
+
+// formatShortMessage returns the short message of a PDU struct as text
+// decoded with its data_coding, after its UDH in hex, or in hex when it
+// is not text
+func formatShortMessage(sm []byte, v reflect.Value) []string {
+	esmClass, dataCoding := messageCoding(v)
+	text, udh, ok := shortMessageText(sm, esmClass, dataCoding)
+	if !ok {
+		return []string{"short_message=" + hex.EncodeToString(sm)}
+	}
+	parts := []string{fmt.Sprintf("short_message=%q", text)}
+	if len(udh) > 0 {
+		parts = append([]string{"udh=" + hex.EncodeToString(udh)}, parts...)
+	}
+	return parts
+}
+
+// formatTLV returns a parameter as name=value, with integer and c-string
+// values shown as such and anything else in hex
+func formatTLV(tlv *TLVParam) string {
+	name := tlv.Name()
+	value, ok := tlvValue(tlv)
+	if !ok {
+		return name + "=" + hex.EncodeToString(tlv.Value)
+	}
+	if s, ok := value.(string); ok {
+		return fmt.Sprintf("%s=%q", name, s)
+	}
+	return fmt.Sprintf("%s=%d", name, value)
+}
@@ -0,0 +1,113 @@
+package pdu
+
+import "testing"
+
+// testSubmitSM returns a submit_sm with a GSM text and optional parameters
+// of a known and an unknown tag
+func testSubmitSM() *SubmitSM {
+	sm := NewSubmitSM()
+	sm.Header.SequenceNumber = 3
+	sm.SourceAddrTON = TON_ALPHANUMERIC
+	sm.SourceAddr = "Shop"
+	sm.DestAddrTON = TON_INTERNATIONAL
+	sm.DestAddrNPI = NPI_ISDN
+	sm.DestinationAddr = "447700900123"
+	sm.RegisteredDelivery = 1
+	sm.SetMessageText("Hello {world}", DATA_CODING_DEFAULT)
+	sm.TLVParams.SetUserMessageReference(7)
+	sm.TLVParams.SetOctets(0x1500, []byte{0xCA, 0xFE})
+	return sm
+}
+
+// testDeliverSM returns a deliver_sm with a concatenation UDH before UCS2
+// text
+func testDeliverSM() *DeliverSM {
+	d := NewDeliverSM()
+	d.ESMClass = ESM_CLASS_UDHI
+	d.DataCoding = DATA_CODING_UCS2
+	udh := NewUDH()
+	udh.Add(&ConcatIE{Ref: 0x42, Total: 2, SeqNum: 1})
+	d.ShortMessage, _ = udh.Prepend([]byte{0x00, 0x48, 0x00, 0x69})
+	d.SMLength = uint8(len(d.ShortMessage))
+	d.TLVParams.SetReceiptedMessageID("abc")
+	return d
+}
+
+// testBinarySM returns a submit_sm with binary data and a source_addr that
+// is not valid UTF-8
+func testBinarySM() *SubmitSM {
+	sm := NewSubmitSM()
+	sm.DataCoding = DATA_CODING_OCTET
+	sm.SourceAddr = "\xff1"
+	sm.ShortMessage = []byte{0x01, 0x02}
+	sm.SMLength = 2
+	return sm
+}
+
+func TestFormatPDU(t *testing.T) {
+	multi := NewSubmitMulti()
+	multi.AddDestAddr("447700900123", TON_INTERNATIONAL, NPI_ISDN)
+	multi.AddDistributionList("staff")
+
+	nack := NewGenericNack()
+	nack.Header.CommandStatus = ESME_RINVCMDID
+	nack.Header.SequenceNumber = 9
+
+	raw := NewRawPDU(0x00010200)
+	raw.Body = Octets{0xAB}
+
+	tests := []struct {
+		name string
+		p    PDU
+		want string
+	}{
+		{"text and tlvs", testSubmitSM(),
+			`submit_sm seq=3 status=ESME_ROK source_addr_ton=5 source_addr="Shop" dest_addr_ton=1 dest_addr_npi=1 destination_addr="447700900123" registered_delivery=1 short_message="Hello {world}" tlvs=[user_message_reference=7 0x1500=cafe]`},
+		{"udh and ucs2", testDeliverSM(),
+			`deliver_sm seq=0 status=ESME_ROK esm_class=0x40 data_coding=0x08 udh=050003420201 short_message="Hi" tlvs=[receipted_message_id="abc"]`},
+		{"binary", testBinarySM(),
+			`submit_sm seq=0 status=ESME_ROK source_addr="\xff1" data_coding=0x04 short_message=0102`},
+		{"destination list", multi,
+			`submit_multi seq=0 status=ESME_ROK dest_addresses=[{dest_flag=1 dest_addr_ton=1 dest_addr_npi=1 destination_addr="447700900123"} {dest_flag=2 dl_name="staff"}]`},
+		{"header only", nack, `generic_nack seq=9 status=ESME_RINVCMDID`},
+		{"unknown command", raw, `0x00010200 seq=0 status=ESME_ROK body=ab`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatPDU(tt.p); got != tt.want {
+				t.Errorf("FormatPDU =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatPDUProfile(t *testing.T) {
+	profile := NewProfile("supplier")
+	if err := profile.RegisterTLV(&TLVDef{Tag: 0x1401, Name: "vendor_charge", Type: TLV_TYPE_UINT32, MinLen: 4, MaxLen: 4}); err != nil {
+		t.Fatal(err)
+	}
+	if err := profile.RegisterCommand(0x00010200, "vendor_ping", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	sm := NewSubmitSM()
+	sm.Header.SequenceNumber = 1
+	sm.TLVParams.SetOctets(0x1401, []byte{0, 0, 0, 150})
+	frame, err := sm.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := profile.Registry().Decode(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `submit_sm seq=1 status=ESME_ROK tlvs=[vendor_charge=150]`
+	if got := profile.Registry().FormatPDU(p); got != want {
+		t.Errorf("FormatPDU =\n%s\nwant\n%s", got, want)
+	}
+
+	raw := NewRawPDU(0x00010200)
+	if got, want := profile.Registry().FormatPDU(raw), `vendor_ping seq=0 status=ESME_ROK`; got != want {
+		t.Errorf("FormatPDU = %s, want %s", got, want)
+	}
+}
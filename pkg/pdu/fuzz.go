@@ -0,0 +1,234 @@
+//go:build gofuzz
+
+package pdu
+
+import (
+	"bytes"
+	"errors"
+)
+
+// Fuzz is the go-fuzz entry point decoding any frame through the default
+// registry; the per type functions below, selected with go-fuzz -func,
+// exercise a single decoder. Every decoder has to fail with a status error
+// rather than panic, and whatever decodes has to survive a round trip.
+func Fuzz(data []byte) int {
+	p, err := Decode(data)
+	if err != nil {
+		checkFuzzError(err)
+		return 0
+	}
+	return roundTrip(p, func() PDU {
+		q, _ := New(p.CommandID())
+		return q
+	})
+}
+
+// fuzzPDU decodes data with the decoder of one PDU type
+func fuzzPDU(data []byte, newPDU func() PDU) int {
+	p := newPDU()
+	if err := p.Unmarshal(data); err != nil {
+		checkFuzzError(err)
+		return 0
+	}
+	return roundTrip(p, newPDU)
+}
+
+// checkFuzzError panics on errors that carry no command_status
+func checkFuzzError(err error) {
+	var decodeErr *DecodeError
+	if errors.As(err, &decodeErr) {
+		return
+	}
+	if _, ok := ErrorStatus(err); !ok {
+		panic(err)
+	}
+}
+
+// roundTrip encodes a decoded PDU, decodes the result again and checks
+// that both encodings match
+func roundTrip(p PDU, newPDU func() PDU) int {
+	first, err := p.Marshal()
+	if err != nil {
+		// Decoded values the encoder refuses, such as an absent
+		// destination list, are not interesting
+		return 0
+	}
+
+	q := newPDU()
+	if err := q.Unmarshal(first); err != nil {
+		panic(err)
+	}
+	second, err := q.Marshal()
+	if err != nil {
+		panic(err)
+	}
+	if !bytes.Equal(first, second) {
+		panic("pdu encoding is not stable")
+	}
+	return 1
+}
+
+// FuzzAlertNotification decodes data as a AlertNotification
+func FuzzAlertNotification(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewAlertNotification() })
+}
+
+// FuzzBindReceiver decodes data as a BindReceiver
+func FuzzBindReceiver(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewBindReceiver() })
+}
+
+// FuzzBindReceiverResp decodes data as a BindReceiverResp
+func FuzzBindReceiverResp(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewBindReceiverResp() })
+}
+
+// FuzzBindTransceiver decodes data as a BindTransceiver
+func FuzzBindTransceiver(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewBindTransceiver() })
+}
+
+// FuzzBindTransceiverResp decodes data as a BindTransceiverResp
+func FuzzBindTransceiverResp(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewBindTransceiverResp() })
+}
+
+// FuzzBindTransmitter decodes data as a BindTransmitter
+func FuzzBindTransmitter(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewBindTransmitter() })
+}
+
+// FuzzBindTransmitterResp decodes data as a BindTransmitterResp
+func FuzzBindTransmitterResp(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewBindTransmitterResp() })
+}
+
+// FuzzBroadcastSM decodes data as a BroadcastSM
+func FuzzBroadcastSM(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewBroadcastSM() })
+}
+
+// FuzzBroadcastSMResp decodes data as a BroadcastSMResp
+func FuzzBroadcastSMResp(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewBroadcastSMResp() })
+}
+
+// FuzzCancelBroadcastSM decodes data as a CancelBroadcastSM
+func FuzzCancelBroadcastSM(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewCancelBroadcastSM() })
+}
+
+// FuzzCancelBroadcastSMResp decodes data as a CancelBroadcastSMResp
+func FuzzCancelBroadcastSMResp(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewCancelBroadcastSMResp() })
+}
+
+// FuzzCancelSM decodes data as a CancelSM
+func FuzzCancelSM(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewCancelSM() })
+}
+
+// FuzzCancelSMResp decodes data as a CancelSMResp
+func FuzzCancelSMResp(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewCancelSMResp() })
+}
+
+// FuzzDataSM decodes data as a DataSM
+func FuzzDataSM(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewDataSM() })
+}
+
+// FuzzDataSMResp decodes data as a DataSMResp
+func FuzzDataSMResp(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewDataSMResp() })
+}
+
+// FuzzDeliverSM decodes data as a DeliverSM
+func FuzzDeliverSM(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewDeliverSM() })
+}
+
+// FuzzDeliverSMResp decodes data as a DeliverSMResp
+func FuzzDeliverSMResp(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewDeliverSMResp() })
+}
+
+// FuzzEnquireLink decodes data as a EnquireLink
+func FuzzEnquireLink(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewEnquireLink() })
+}
+
+// FuzzEnquireLinkResp decodes data as a EnquireLinkResp
+func FuzzEnquireLinkResp(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewEnquireLinkResp() })
+}
+
+// FuzzGenericNack decodes data as a GenericNack
+func FuzzGenericNack(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewGenericNack() })
+}
+
+// FuzzOutbind decodes data as a Outbind
+func FuzzOutbind(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewOutbind() })
+}
+
+// FuzzQueryBroadcastSM decodes data as a QueryBroadcastSM
+func FuzzQueryBroadcastSM(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewQueryBroadcastSM() })
+}
+
+// FuzzQueryBroadcastSMResp decodes data as a QueryBroadcastSMResp
+func FuzzQueryBroadcastSMResp(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewQueryBroadcastSMResp() })
+}
+
+// FuzzQuerySM decodes data as a QuerySM
+func FuzzQuerySM(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewQuerySM() })
+}
+
+// FuzzQuerySMResp decodes data as a QuerySMResp
+func FuzzQuerySMResp(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewQuerySMResp() })
+}
+
+// FuzzReplaceSM decodes data as a ReplaceSM
+func FuzzReplaceSM(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewReplaceSM() })
+}
+
+// FuzzReplaceSMResp decodes data as a ReplaceSMResp
+func FuzzReplaceSMResp(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewReplaceSMResp() })
+}
+
+// FuzzSubmitMulti decodes data as a SubmitMulti
+func FuzzSubmitMulti(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewSubmitMulti() })
+}
+
+// FuzzSubmitMultiResp decodes data as a SubmitMultiResp
+func FuzzSubmitMultiResp(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewSubmitMultiResp() })
+}
+
+// FuzzSubmitSM decodes data as a SubmitSM
+func FuzzSubmitSM(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewSubmitSM() })
+}
+
+// FuzzSubmitSMResp decodes data as a SubmitSMResp
+func FuzzSubmitSMResp(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewSubmitSMResp() })
+}
+
+// FuzzUnbind decodes data as a Unbind
+func FuzzUnbind(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewUnbind() })
+}
+
+// FuzzUnbindResp decodes data as a UnbindResp
+func FuzzUnbindResp(data []byte) int {
+	return fuzzPDU(data, func() PDU { return NewUnbindResp() })
+}
@@ -2,7 +2,7 @@ package pdu
 
 // GenericNack represents an SMPP generic_nack PDU
 type GenericNack struct {
-	Header *Header
+	Header *Header `json:"header"`
 }
 
 // NewGenericNack creates a new GenericNack PDU
@@ -17,19 +17,64 @@ func (g *GenericNack) SetErrorCode(code uint32) {
 	g.Header.CommandStatus = code
 }
 
-// Marshal serializes the PDU into bytes
-func (g *GenericNack) Marshal() ([]byte, error) {
-	// Set command length in header
-	g.Header.CommandLength = 16 // Only header, no body
+// EncodedLen returns the size of the encoded PDU in bytes
+func (g *GenericNack) EncodedLen() int {
+	return HeaderLength
+}
+
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (g *GenericNack) AppendTo(dst []byte) ([]byte, error) {
+	g.Header.CommandLength = uint32(g.EncodedLen())
 	g.Header.CommandID = GENERIC_NACK
 
-	// Marshal header
-	return g.Header.Marshal()
+	return g.Header.AppendTo(dst), nil
+}
+
+// Marshal serializes the PDU into bytes
+func (g *GenericNack) Marshal() ([]byte, error) {
+	return g.AppendTo(make([]byte, 0, g.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (g *GenericNack) Unmarshal(data []byte) error {
-	// Unmarshal header
+	return g.decode(newDecoder(data, false))
+}
+
+// decode reads the PDU fields from dec
+func (g *GenericNack) decode(dec *decoder) error {
 	g.Header = &Header{}
-	return g.Header.Unmarshal(data[:16])
+	dec.header(g.Header)
+
+	dec.end()
+	return dec.Err()
+}
+
+// CommandID returns the command_id of the PDU
+func (g *GenericNack) CommandID() uint32 {
+	return GENERIC_NACK
+}
+
+// GetHeader returns the PDU header
+func (g *GenericNack) GetHeader() *Header {
+	return g.Header
+}
+
+// String returns a one-line description of the PDU for trace logs
+func (g *GenericNack) String() string {
+	return FormatPDU(g)
+}
+
+// MarshalJSON encodes the PDU as JSON
+func (g *GenericNack) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(g)
+}
+
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (g *GenericNack) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, g)
+}
+
+// GetResponse returns nil as generic_nack has no response PDU
+func (g *GenericNack) GetResponse() PDU {
+	return nil
 }
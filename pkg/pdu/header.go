@@ -19,19 +19,40 @@ func NewHeader() *Header {
 
 // Marshal serializes the header into bytes
 func (h *Header) Marshal() ([]byte, error) {
-	buf := make([]byte, 16)
-	binary.BigEndian.PutUint32(buf[0:4], h.CommandLength)
-	binary.BigEndian.PutUint32(buf[4:8], h.CommandID)
-	binary.BigEndian.PutUint32(buf[8:12], h.CommandStatus)
-	binary.BigEndian.PutUint32(buf[12:16], h.SequenceNumber)
-	return buf, nil
+	return h.AppendTo(make([]byte, 0, HeaderLength)), nil
+}
+
+// AppendTo appends the encoded header to dst
+func (h *Header) AppendTo(dst []byte) []byte {
+	dst = binary.BigEndian.AppendUint32(dst, h.CommandLength)
+	dst = binary.BigEndian.AppendUint32(dst, h.CommandID)
+	dst = binary.BigEndian.AppendUint32(dst, h.CommandStatus)
+	return binary.BigEndian.AppendUint32(dst, h.SequenceNumber)
 }
 
 // Unmarshal deserializes the header from bytes
 func (h *Header) Unmarshal(data []byte) error {
+	if len(data) < HeaderLength {
+		return ErrPDUTooShort
+	}
 	h.CommandLength = binary.BigEndian.Uint32(data[0:4])
 	h.CommandID = binary.BigEndian.Uint32(data[4:8])
 	h.CommandStatus = binary.BigEndian.Uint32(data[8:12])
 	h.SequenceNumber = binary.BigEndian.Uint32(data[12:16])
 	return nil
 }
+
+// responseHeader creates the header of a response to the PDU carrying h
+func responseHeader(h *Header, commandID uint32) *Header {
+	resp := &Header{CommandID: commandID}
+	if h != nil {
+		resp.SequenceNumber = h.SequenceNumber
+	}
+	return resp
+}
+
+// IsResponse reports whether a command ID is that of a response, which
+// has the bit of generic_nack set
+func IsResponse(commandID uint32) bool {
+	return commandID&GENERIC_NACK != 0
+}
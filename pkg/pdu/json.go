@@ -0,0 +1,590 @@
+package pdu
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrUnknownCommandName = errors.New("unknown command name")
+	ErrUnknownTLVName     = errors.New("unknown tlv name")
+	ErrInvalidTLVJSON     = errors.New("tlv needs exactly one of value and hex")
+)
+
+// The JSON encoding of a PDU holds its header, every mandatory field under
+// its specification name and the optional parameters in order. Lengths are
+// left out as they are computed when the PDU is marshalled, so a PDU decoded
+// from JSON marshals into the binary PDU it was encoded from.
+
+// headerJSON is the JSON form of a header
+type headerJSON struct {
+	Command        string `json:"command"`
+	CommandStatus  uint32 `json:"command_status"`
+	SequenceNumber uint32 `json:"sequence_number"`
+}
+
+// MarshalJSON encodes the header with the command by name, naming vendor
+// commands after DefaultRegistry
+func (h *Header) MarshalJSON() ([]byte, error) {
+	return h.marshalJSON(DefaultRegistry)
+}
+
+// marshalJSON encodes the header with vendor commands named after r
+func (h *Header) marshalJSON(r *Registry) ([]byte, error) {
+	return json.Marshal(headerJSON{
+		Command:        r.CommandName(h.CommandID),
+		CommandStatus:  h.CommandStatus,
+		SequenceNumber: h.SequenceNumber,
+	})
+}
+
+// UnmarshalJSON decodes a header encoded by MarshalJSON
+func (h *Header) UnmarshalJSON(data []byte) error {
+	return h.unmarshalJSON(data, DefaultRegistry)
+}
+
+// unmarshalJSON decodes a header with vendor commands named after r
+func (h *Header) unmarshalJSON(data []byte, r *Registry) error {
+	var v headerJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Command != "" {
+		id, ok := r.CommandIDByName(v.Command)
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownCommandName, v.Command)
+		}
+		h.CommandID = id
+	}
+	h.CommandStatus = v.CommandStatus
+	h.SequenceNumber = v.SequenceNumber
+	return nil
+}
+
+// tlvJSON is the JSON form of an optional parameter. Values of integer and
+// c-string parameters with a definition are given as numbers and strings,
+// anything else as hex. Length is only given when it does not
+// match the value.
+type tlvJSON struct {
+	Tag    string          `json:"tag"`
+	Value  json.RawMessage `json:"value,omitempty"`
+	Hex    *string         `json:"hex,omitempty"`
+	Length *uint16         `json:"length,omitempty"`
+}
+
+// MarshalJSON encodes the parameter with its tag by name
+func (tlv *TLVParam) MarshalJSON() ([]byte, error) {
+	v := tlvJSON{Tag: tlv.Name()}
+	if value, ok := tlvValue(tlv); ok {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		v.Value = raw
+	} else {
+		s := hex.EncodeToString(tlv.Value)
+		v.Hex = &s
+	}
+	if int(tlv.Length) != len(tlv.Value) {
+		v.Length = &tlv.Length
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes a parameter encoded by MarshalJSON, naming tags
+// after DefaultTLVSchema
+func (tlv *TLVParam) UnmarshalJSON(data []byte) error {
+	return tlv.unmarshalJSON(data, DefaultTLVSchema)
+}
+
+// unmarshalJSON decodes a parameter with tags named after a schema
+func (tlv *TLVParam) unmarshalJSON(data []byte, schema *TLVSchema) error {
+	var v tlvJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	tag, err := parseTLVTag(v.Tag, schema)
+	if err != nil {
+		return err
+	}
+	def, _ := schema.Lookup(tag)
+
+	var value []byte
+	switch {
+	case v.Hex != nil && v.Value == nil:
+		if value, err = hex.DecodeString(*v.Hex); err != nil {
+			return fmt.Errorf("tlv %s: %w", v.Tag, err)
+		}
+	case v.Hex == nil && v.Value != nil:
+		if value, err = parseTLVValue(tag, def, v.Value); err != nil {
+			return fmt.Errorf("tlv %s: %w", v.Tag, err)
+		}
+	default:
+		return fmt.Errorf("tlv %s: %w", v.Tag, ErrInvalidTLVJSON)
+	}
+
+	*tlv = *NewTLVParam(tag, value)
+	if schema != DefaultTLVSchema {
+		tlv.def = def
+	}
+	if v.Length != nil {
+		tlv.Length = *v.Length
+	}
+	return nil
+}
+
+// tlvValue returns the value of an integer or c-string parameter, or false
+// when it is shown in hex
+func tlvValue(tlv *TLVParam) (any, bool) {
+	def, ok := tlv.Def()
+	if !ok {
+		return nil, false
+	}
+	v := tlv.Value
+	switch def.Type {
+	case TLV_TYPE_UINT8:
+		if len(v) == 1 {
+			return v[0], true
+		}
+	case TLV_TYPE_UINT16:
+		if len(v) == 2 {
+			return binary.BigEndian.Uint16(v), true
+		}
+	case TLV_TYPE_UINT32:
+		if len(v) == 4 {
+			return binary.BigEndian.Uint32(v), true
+		}
+	case TLV_TYPE_CSTRING:
+		if len(v) > 0 && bytes.IndexByte(v, 0) == len(v)-1 && utf8.Valid(v) {
+			return string(v[:len(v)-1]), true
+		}
+	}
+	return nil, false
+}
+
+// parseTLVValue encodes a number or string value given for a tag with its
+// definition
+func parseTLVValue(tag uint16, def *TLVDef, raw json.RawMessage) ([]byte, error) {
+	if def == nil {
+		return nil, fmt.Errorf("unknown tag 0x%04X needs a hex value", tag)
+	}
+
+	switch def.Type {
+	case TLV_TYPE_UINT8, TLV_TYPE_UINT16, TLV_TYPE_UINT32:
+		size, _ := def.lengthBounds()
+		n, err := strconv.ParseUint(string(raw), 10, size*8)
+		if err != nil {
+			return nil, err
+		}
+		value := binary.BigEndian.AppendUint32(nil, uint32(n))
+		return value[4-size:], nil
+	case TLV_TYPE_CSTRING:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, err
+		}
+		return append([]byte(s), 0), nil
+	}
+	return nil, fmt.Errorf("%s value needs hex", def.Type)
+}
+
+// parseTLVTag returns the tag of a symbolic name in a schema or of a 0x
+// prefixed number
+func parseTLVTag(name string, schema *TLVSchema) (uint16, error) {
+	if def, ok := schema.LookupName(name); ok {
+		return def.Tag, nil
+	}
+	if s, ok := strings.CutPrefix(name, "0x"); ok {
+		if tag, err := strconv.ParseUint(s, 16, 16); err == nil {
+			return uint16(tag), nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrUnknownTLVName, name)
+}
+
+// shortMessageJSON is the JSON form of short_message. The text is given
+// when it encodes back into the same octets with the data coding, after
+// the UDH if there is one; anything else is given in hex.
+type shortMessageJSON struct {
+	UDH  string  `json:"udh,omitempty"`
+	Text *string `json:"text,omitempty"`
+	Hex  *string `json:"hex,omitempty"`
+}
+
+// newShortMessageJSON returns the JSON form of a short message
+func newShortMessageJSON(sm []byte, esmClass, dataCoding uint8) *shortMessageJSON {
+	if text, udh, ok := shortMessageText(sm, esmClass, dataCoding); ok {
+		return &shortMessageJSON{UDH: hex.EncodeToString(udh), Text: &text}
+	}
+	s := hex.EncodeToString(sm)
+	return &shortMessageJSON{Hex: &s}
+}
+
+// bytes returns the octets of the short message
+func (m *shortMessageJSON) bytes(dataCoding uint8) ([]byte, error) {
+	switch {
+	case m == nil:
+		return nil, nil
+	case m.Hex != nil:
+		return hex.DecodeString(*m.Hex)
+	case m.Text == nil:
+		return nil, nil
+	}
+
+	enc := TextEncoding{DataCoding: dataCoding}
+	udh, err := hex.DecodeString(m.UDH)
+	if err != nil {
+		return nil, err
+	}
+	if len(udh) > 0 {
+		u, _, err := ParseUDH(udh)
+		if err != nil {
+			return nil, err
+		}
+		enc.LockingShift, enc.SingleShift = u.NationalShifts()
+	}
+	text, err := enc.Encode(*m.Text)
+	if err != nil {
+		return nil, err
+	}
+	return append(udh, text...), nil
+}
+
+// shortMessageText returns the text of a short message and its UDH if the
+// text encodes back into the same octets. Binary data codings never give
+// text.
+func shortMessageText(sm []byte, esmClass, dataCoding uint8) (string, []byte, bool) {
+	switch Alphabet(dataCoding) {
+	case DATA_CODING_BINARY, DATA_CODING_OCTET:
+		return "", nil, false
+	}
+
+	udh, payload, err := SplitUserData(sm, esmClass)
+	if err != nil {
+		return "", nil, false
+	}
+	enc := TextEncoding{DataCoding: dataCoding}
+	if udh != nil {
+		enc.LockingShift, enc.SingleShift = udh.NationalShifts()
+	}
+	text, err := enc.Decode(payload)
+	if err != nil || !utf8.ValidString(text) {
+		return "", nil, false
+	}
+	if encoded, err := enc.Encode(text); err != nil || !bytes.Equal(encoded, payload) {
+		return "", nil, false
+	}
+	return text, sm[:len(sm)-len(payload)], true
+}
+
+// stringJSON is the JSON form of a c-string field. Strings that are not
+// valid UTF-8 are given as {"hex": "..."} as JSON strings cannot hold them.
+type stringJSON string
+
+// MarshalJSON encodes the string
+func (s stringJSON) MarshalJSON() ([]byte, error) {
+	if utf8.ValidString(string(s)) {
+		return json.Marshal(string(s))
+	}
+	return json.Marshal(struct {
+		Hex string `json:"hex"`
+	}{hex.EncodeToString([]byte(s))})
+}
+
+// UnmarshalJSON decodes a string encoded by MarshalJSON
+func (s *stringJSON) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Hex string `json:"hex"`
+	}
+	if err := json.Unmarshal(data, (*string)(s)); err == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	b, err := hex.DecodeString(v.Hex)
+	if err != nil {
+		return err
+	}
+	*s = stringJSON(b)
+	return nil
+}
+
+// jsonFieldName returns the JSON name of a struct field, or "" if it is
+// not encoded
+func jsonFieldName(f reflect.StructField) (string, bool) {
+	name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "-" || name == "" || !f.IsExported() {
+		return "", false
+	}
+	return name, opts == "omitempty"
+}
+
+// messageCoding returns the esm_class and data_coding fields of a PDU
+// struct, zero for those it lacks
+func messageCoding(v reflect.Value) (esmClass, dataCoding uint8) {
+	if f := v.FieldByName("ESMClass"); f.IsValid() && f.Kind() == reflect.Uint8 {
+		esmClass = uint8(f.Uint())
+	}
+	if f := v.FieldByName("DataCoding"); f.IsValid() && f.Kind() == reflect.Uint8 {
+		dataCoding = uint8(f.Uint())
+	}
+	return esmClass, dataCoding
+}
+
+// pduOf returns the PDU a struct value is the target of
+func pduOf(v reflect.Value) (PDU, bool) {
+	if !v.CanAddr() {
+		return nil, false
+	}
+	p, ok := v.Addr().Interface().(PDU)
+	return p, ok
+}
+
+// encodeJSON encodes the fields of a PDU struct in order under their JSON
+// names, with vendor commands named after r. Every PDU of this package
+// marshals through it.
+func encodeJSON(buf *bytes.Buffer, v reflect.Value, r *Registry) error {
+	t := v.Type()
+	buf.WriteByte('{')
+	first := true
+	for i := 0; i < t.NumField(); i++ {
+		name, omitEmpty := jsonFieldName(t.Field(i))
+		f := v.Field(i)
+		if name == "" || (omitEmpty && f.Kind() == reflect.Slice && f.Len() == 0) {
+			continue
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		buf.WriteString(strconv.Quote(name))
+		buf.WriteByte(':')
+
+		var value any
+		switch x := f.Interface().(type) {
+		case *Header:
+			h := x
+			if p, ok := pduOf(v); ok {
+				h = pduHeader(p)
+			} else if h == nil {
+				buf.WriteString("null")
+				continue
+			}
+			data, err := h.marshalJSON(r)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			buf.Write(data)
+			continue
+		case string:
+			value = stringJSON(x)
+		case []byte:
+			esmClass, dataCoding := messageCoding(v)
+			value = newShortMessageJSON(x, esmClass, dataCoding)
+		default:
+			if f.Kind() == reflect.Slice && f.Type().Elem().Kind() == reflect.Struct {
+				buf.WriteByte('[')
+				for j := 0; j < f.Len(); j++ {
+					if j > 0 {
+						buf.WriteByte(',')
+					}
+					if err := encodeJSON(buf, f.Index(j), r); err != nil {
+						return err
+					}
+				}
+				buf.WriteByte(']')
+				continue
+			}
+			value = x
+		}
+
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		buf.Write(data)
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// decodeJSON decodes a struct encoded by encodeJSON into v, naming vendor
+// commands and TLV tags after r. Fields missing from the object are left
+// unchanged.
+func decodeJSON(data []byte, v reflect.Value, r *Registry) error {
+	schema := r.TLVSchema()
+	if schema == nil {
+		schema = DefaultTLVSchema
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	t := v.Type()
+	var message *reflect.Value
+	var messageJSON *shortMessageJSON
+	for i := 0; i < t.NumField(); i++ {
+		name, _ := jsonFieldName(t.Field(i))
+		raw, ok := fields[name]
+		if name == "" || !ok {
+			continue
+		}
+
+		f := v.Field(i)
+		var err error
+		switch f.Interface().(type) {
+		case *Header:
+			h := &Header{}
+			if err = h.unmarshalJSON(raw, r); err == nil {
+				f.Set(reflect.ValueOf(h))
+			}
+		case string:
+			var s stringJSON
+			err = json.Unmarshal(raw, &s)
+			f.SetString(string(s))
+		case []byte:
+			// The data coding may follow short_message
+			message = &f
+			err = json.Unmarshal(raw, &messageJSON)
+		case TLVList:
+			err = decodeTLVsJSON(raw, f.Addr().Interface().(*TLVList), schema)
+		default:
+			if f.Kind() == reflect.Slice && f.Type().Elem().Kind() == reflect.Struct {
+				var items []json.RawMessage
+				if err = json.Unmarshal(raw, &items); err != nil {
+					break
+				}
+				f.Set(reflect.MakeSlice(f.Type(), len(items), len(items)))
+				for j, item := range items {
+					if err = decodeJSON(item, f.Index(j), r); err != nil {
+						break
+					}
+				}
+				break
+			}
+			err = json.Unmarshal(raw, f.Addr().Interface())
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	if message != nil {
+		_, dataCoding := messageCoding(v)
+		sm, err := messageJSON.bytes(dataCoding)
+		if err != nil {
+			return fmt.Errorf("short_message: %w", err)
+		}
+		message.SetBytes(sm)
+	}
+	return nil
+}
+
+// marshalPDUJSON returns the JSON encoding of a PDU struct
+func marshalPDUJSON(p PDU) ([]byte, error) {
+	return marshalPDUJSONWith(p, DefaultRegistry)
+}
+
+// marshalPDUJSONWith returns the JSON encoding of a PDU struct with vendor
+// commands named after r
+func marshalPDUJSONWith(p PDU, r *Registry) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeJSON(&buf, reflect.ValueOf(p).Elem(), r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalPDUJSON decodes the JSON encoding of a PDU struct into p
+func unmarshalPDUJSON(data []byte, p PDU) error {
+	return decodeJSON(data, reflect.ValueOf(p).Elem(), DefaultRegistry)
+}
+
+// decodeTLVsJSON decodes a list of parameters with tags named after schema
+func decodeTLVsJSON(data []byte, l *TLVList, schema *TLVSchema) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	*l = nil
+	for _, item := range items {
+		tlv := &TLVParam{}
+		if err := tlv.unmarshalJSON(item, schema); err != nil {
+			return err
+		}
+		*l = append(*l, tlv)
+	}
+	return nil
+}
+
+// pduPackage is the import path of the PDU types of this package
+var pduPackage = reflect.TypeOf(Header{}).PkgPath()
+
+// isPackagePDU reports whether p is a PDU type of this package, which
+// encodes through encodeJSON and decodeJSON. PDU types of other packages
+// marshal themselves.
+func isPackagePDU(p PDU) bool {
+	t := reflect.TypeOf(p)
+	return t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct && t.Elem().PkgPath() == pduPackage
+}
+
+// EncodeJSON returns the JSON encoding of a PDU with vendor commands named
+// after the registry, so that the commands of a profile keep their names
+func (r *Registry) EncodeJSON(p PDU) ([]byte, error) {
+	if !isPackagePDU(p) {
+		return json.Marshal(p)
+	}
+	return marshalPDUJSONWith(p, r)
+}
+
+// DecodeJSON turns the JSON encoding of a PDU into its typed PDU, using
+// the command named in its header. Commands and TLV tags are named after
+// the registry, so that vendor commands and parameters of a profile are
+// understood.
+func (r *Registry) DecodeJSON(data []byte) (PDU, error) {
+	var v struct {
+		Header headerJSON `json:"header"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	id, ok := r.CommandIDByName(v.Header.Command)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCommandName, v.Header.Command)
+	}
+
+	p, err := r.New(id)
+	if err != nil {
+		return nil, err
+	}
+	if isPackagePDU(p) {
+		err = decodeJSON(data, reflect.ValueOf(p).Elem(), r)
+	} else {
+		err = json.Unmarshal(data, p)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if h := p.GetHeader(); h != nil {
+		h.CommandID = id
+	}
+	return p, nil
+}
+
+// DecodeJSON turns the JSON encoding of a PDU into its typed PDU using the
+// default registry
+func DecodeJSON(data []byte) (PDU, error) {
+	return DefaultRegistry.DecodeJSON(data)
+}
@@ -0,0 +1,172 @@
+package pdu
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	gsm := NewSubmitSM()
+	gsm.SetMessageText("@£$ {€}", DATA_CODING_DEFAULT)
+
+	// 0x80 is no septet, so the message cannot be given as text
+	badSeptets := NewSubmitSM()
+	badSeptets.ShortMessage = []byte{0x41, 0x80}
+	badSeptets.SMLength = 2
+
+	latin1 := NewDeliverSM()
+	latin1.SetMessageText("Grüße", DATA_CODING_ISO8859_1)
+
+	payload := NewDataSM()
+	payload.TLVParams.SetMessagePayload([]byte("hello"))
+	payload.TLVParams.SetSourcePort(9200)
+
+	multi := NewSubmitMulti()
+	multi.AddDestAddr("447700900123", TON_INTERNATIONAL, NPI_ISDN)
+	multi.AddDistributionList("staff")
+	multi.SetMessageText("Hi all", DATA_CODING_DEFAULT)
+
+	tests := []struct {
+		name     string
+		p        PDU
+		contains []string
+	}{
+		{"gsm text and tlvs", testSubmitSM(), []string{
+			`"command":"submit_sm"`,
+			`"short_message":{"text":"Hello {world}"}`,
+			`{"tag":"user_message_reference","value":7}`,
+			`{"tag":"0x1500","hex":"cafe"}`,
+		}},
+		{"gsm escapes", gsm, []string{`"short_message":{"text":"@£$ {€}"}`}},
+		{"undecodable septets", badSeptets, []string{`"short_message":{"hex":"4180"}`}},
+		{"latin1", latin1, []string{`"data_coding":3`, `"short_message":{"text":"Grüße"}`}},
+		{"udh and ucs2", testDeliverSM(), []string{
+			`"short_message":{"udh":"050003420201","text":"Hi"}`,
+			`{"tag":"receipted_message_id","value":"abc"}`,
+		}},
+		{"binary and invalid utf-8", testBinarySM(), []string{
+			`"source_addr":{"hex":"ff31"}`,
+			`"short_message":{"hex":"0102"}`,
+		}},
+		{"octet tlv", payload, []string{
+			`{"tag":"message_payload","hex":"68656c6c6f"}`,
+			`{"tag":"source_port","value":9200}`,
+		}},
+		{"destination list", multi, []string{`{"dest_flag":2,"dest_addr_ton":0,"dest_addr_npi":0,"destination_addr":"","dl_name":"staff"}`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.p)
+			if err != nil {
+				t.Fatalf("Marshal JSON: %v", err)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(string(data), s) {
+					t.Errorf("JSON %s\nlacks %s", data, s)
+				}
+			}
+
+			decoded, err := DecodeJSON(data)
+			if err != nil {
+				t.Fatalf("DecodeJSON(%s): %v", data, err)
+			}
+			want, err := tt.p.Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := decoded.Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("round trip through %s\ngives % X\nwant  % X", data, got, want)
+			}
+		})
+	}
+}
+
+func TestJSONVendorTLVs(t *testing.T) {
+	profile := NewProfile("supplier")
+	if err := profile.RegisterTLV(&TLVDef{Tag: 0x1401, Name: "vendor_charge", Type: TLV_TYPE_UINT32, MinLen: 4, MaxLen: 4}); err != nil {
+		t.Fatal(err)
+	}
+	if err := profile.RegisterTLV(&TLVDef{Tag: 0x1402, Name: "vendor_campaign", Type: TLV_TYPE_CSTRING}); err != nil {
+		t.Fatal(err)
+	}
+	r := profile.Registry()
+
+	sm := testSubmitSM()
+	sm.TLVParams.SetOctets(0x1401, []byte{0, 0, 0x01, 0x2C})
+	sm.TLVParams.SetCString(0x1402, "spring")
+	frame, err := sm.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := r.Decode(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := r.EncodeJSON(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`{"tag":"vendor_charge","value":300}`,
+		`{"tag":"vendor_campaign","value":"spring"}`,
+		`{"tag":"0x1500","hex":"cafe"}`,
+	} {
+		if !strings.Contains(string(data), s) {
+			t.Errorf("JSON %s\nlacks %s", data, s)
+		}
+	}
+
+	decoded, err := r.DecodeJSON(data)
+	if err != nil {
+		t.Fatalf("DecodeJSON: %v", err)
+	}
+	got, err := decoded.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, frame) {
+		t.Errorf("round trip gives % X\nwant % X", got, frame)
+	}
+
+	// Without the profile the vendor names mean nothing
+	if _, err := DecodeJSON(data); !errors.Is(err, ErrUnknownTLVName) {
+		t.Errorf("DecodeJSON without the profile error = %v, want ErrUnknownTLVName", err)
+	}
+}
+
+func TestDecodeJSONInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  error
+	}{
+		{"unknown command", `{"header":{"command":"submit_everything"}}`, ErrUnknownCommandName},
+		{"unknown tlv", `{"header":{"command":"submit_sm"},"tlvs":[{"tag":"no_such_tlv","hex":"00"}]}`, ErrUnknownTLVName},
+		{"tlv with value and hex", `{"header":{"command":"submit_sm"},"tlvs":[{"tag":"user_message_reference","value":1,"hex":"0001"}]}`, ErrInvalidTLVJSON},
+		{"tlv without value", `{"header":{"command":"submit_sm"},"tlvs":[{"tag":"user_message_reference"}]}`, ErrInvalidTLVJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeJSON([]byte(tt.data)); !errors.Is(err, tt.err) {
+				t.Errorf("DecodeJSON error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+
+	// An unknown tag needs its value in hex
+	if _, err := DecodeJSON([]byte(`{"header":{"command":"submit_sm"},"tlvs":[{"tag":"0x1500","value":1}]}`)); err == nil {
+		t.Error("DecodeJSON accepted a value for an unknown tag")
+	}
+	// Text that the data coding cannot hold
+	if _, err := DecodeJSON([]byte(`{"header":{"command":"submit_sm"},"data_coding":0,"short_message":{"text":"中"}}`)); err == nil {
+		t.Error("DecodeJSON accepted text the data coding cannot encode")
+	}
+}
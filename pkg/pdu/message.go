@@ -0,0 +1,238 @@
+package pdu
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	ErrNoMessage          = errors.New("pdu does not carry a short message")
+	ErrMessageUnsupported = errors.New("message cannot be carried by the pdu")
+)
+
+// Message is the body of a short message independent of the PDU carrying
+// it: in short_message or the message_payload TLV, with or without a UDH,
+// and segmented through the UDH or the SAR TLVs
+type Message struct {
+	ESMClass   uint8      // esm_class of the carrying PDU, UDHI is set from UDH
+	DataCoding uint8      // data_coding of the carrying PDU
+	UDH        *UDH       // User Data Header, nil without one
+	Payload    []byte     // User data following the UDH
+	SAR        *SARParams // SAR TLVs of the carrying PDU, nil without them
+}
+
+// messageTLVs are the parameters a Message takes over from its PDU
+var messageTLVs = []uint16{
+	TLV_MESSAGE_PAYLOAD,
+	TLV_SAR_MSG_REF_NUM,
+	TLV_SAR_TOTAL_SEGMENTS,
+	TLV_SAR_SEGMENT_SEQNUM,
+}
+
+// MessageFrom returns the message carried by a submit_sm, submit_multi,
+// deliver_sm, data_sm or broadcast_sm. The message shares memory with the
+// PDU.
+func MessageFrom(p PDU) (*Message, error) {
+	switch v := p.(type) {
+	case *SubmitSM:
+		return newMessage(v.ShortMessage, v.ESMClass, v.DataCoding, v.TLVParams)
+	case *SubmitMulti:
+		return newMessage(v.ShortMessage, v.ESMClass, v.DataCoding, v.TLVParams)
+	case *DeliverSM:
+		return newMessage(v.ShortMessage, v.ESMClass, v.DataCoding, v.TLVParams)
+	case *DataSM:
+		return newMessage(nil, v.ESMClass, v.DataCoding, v.TLVParams)
+	case *BroadcastSM:
+		return newMessage(nil, 0, v.DataCoding, v.TLVParams)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNoMessage, CommandName(p.CommandID()))
+}
+
+// newMessage builds a message from short_message, falling back to the
+// message_payload TLV when short_message is empty
+func newMessage(sm []byte, esmClass, dataCoding uint8, params TLVList) (*Message, error) {
+	if len(sm) == 0 {
+		if payload, ok := params.MessagePayload(); ok {
+			sm = payload
+		}
+	}
+	udh, payload, err := SplitUserData(sm, esmClass)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Message{
+		ESMClass:   esmClass &^ ESM_CLASS_UDHI,
+		DataCoding: dataCoding,
+		UDH:        udh,
+		Payload:    payload,
+	}
+	if sar, ok := params.SAR(); ok {
+		m.SAR = &sar
+	}
+	return m, nil
+}
+
+// NewTextMessage creates a message holding text in the cheapest data
+// coding, with the national language shift UDH it needs
+func NewTextMessage(text string, opts *TextOptions) *Message {
+	encoded := SelectTextEncoding(text, opts)
+	m := &Message{DataCoding: encoded.DataCoding, Payload: encoded.Data}
+	if shifts := encoded.NationalShiftElements(); len(shifts) > 0 {
+		m.UDH = NewUDH(shifts...)
+	}
+	return m
+}
+
+// UserData returns the UDH followed by the payload, as carried in
+// short_message or message_payload
+func (m *Message) UserData() ([]byte, error) {
+	if m.UDH == nil {
+		return m.Payload, nil
+	}
+	return m.UDH.Prepend(m.Payload)
+}
+
+// Text decodes the payload, using the national language tables selected
+// by the UDH
+func (m *Message) Text() (string, error) {
+	enc := TextEncoding{DataCoding: m.DataCoding}
+	if m.UDH != nil {
+		enc.LockingShift, enc.SingleShift = m.UDH.NationalShifts()
+	}
+	return enc.Decode(m.Payload)
+}
+
+// Part returns the segmentation parameters of a message that is one part
+// of a longer one, from the concatenation UDH or else the SAR TLVs
+func (m *Message) Part() (SARParams, bool) {
+	if m.UDH != nil {
+		if c, ok := m.UDH.Concat(); ok {
+			return c.SAR(), true
+		}
+	}
+	if m.SAR != nil {
+		return *m.SAR, true
+	}
+	return SARParams{}, false
+}
+
+// ApplyTo stores the message in a submit_sm, submit_multi, deliver_sm,
+// data_sm or broadcast_sm, replacing the message it held. User data goes
+// into short_message when it fits and the PDU has one, into the
+// message_payload TLV otherwise.
+func (m *Message) ApplyTo(p PDU) error {
+	ud, err := m.UserData()
+	if err != nil {
+		return err
+	}
+	if len(ud) > MESSAGE_PAYLOAD_MAX_LENGTH {
+		return ErrMessageTooLong
+	}
+	esmClass := m.ESMClass &^ ESM_CLASS_UDHI
+	if m.UDH != nil {
+		esmClass |= ESM_CLASS_UDHI
+	}
+
+	switch v := p.(type) {
+	case *SubmitSM:
+		v.ESMClass, v.DataCoding = esmClass, m.DataCoding
+		v.ShortMessage, v.SMLength = m.setParams(&v.TLVParams, ud, true)
+	case *SubmitMulti:
+		v.ESMClass, v.DataCoding = esmClass, m.DataCoding
+		v.ShortMessage, v.SMLength = m.setParams(&v.TLVParams, ud, true)
+	case *DeliverSM:
+		v.ESMClass, v.DataCoding = esmClass, m.DataCoding
+		v.ShortMessage, v.SMLength = m.setParams(&v.TLVParams, ud, true)
+	case *DataSM:
+		v.ESMClass, v.DataCoding = esmClass, m.DataCoding
+		m.setParams(&v.TLVParams, ud, false)
+	case *BroadcastSM:
+		if m.UDH != nil {
+			return fmt.Errorf("%w: broadcast_sm has no esm_class to flag a UDH", ErrMessageUnsupported)
+		}
+		v.DataCoding = m.DataCoding
+		m.setParams(&v.TLVParams, ud, false)
+	default:
+		return fmt.Errorf("%w: %s", ErrNoMessage, CommandName(p.CommandID()))
+	}
+	return nil
+}
+
+// setParams replaces the message TLVs of a PDU and returns short_message
+// with its length. The user data is put into message_payload unless
+// inline is set and it fits into short_message.
+func (m *Message) setParams(params *TLVList, ud []byte, inline bool) ([]byte, uint8) {
+	for _, tag := range messageTLVs {
+		params.Delete(tag)
+	}
+	if m.SAR != nil {
+		params.SetSAR(*m.SAR)
+	}
+	if inline && len(ud) <= SM_MAX_LENGTH {
+		return ud, uint8(len(ud))
+	}
+	params.SetMessagePayload(ud)
+	return nil, 0
+}
+
+// ConvertMessage creates a PDU of another message carrying command, e.g. a
+// submit_sm for a supplier without data_sm support from a received data_sm.
+// Fields both PDUs have are copied, as are the TLVs the target may carry
+// in SMPP 5.0; the message is moved with ApplyTo. The result shares memory
+// with p.
+func ConvertMessage(p PDU, commandID uint32) (PDU, error) {
+	m, err := MessageFrom(p)
+	if err != nil {
+		return nil, err
+	}
+	out, err := New(commandID)
+	if err != nil {
+		return nil, err
+	}
+	copyCommonFields(out, p)
+
+	src, ok1 := p.(TLVCarrier)
+	dst, ok2 := out.(TLVCarrier)
+	if ok1 && ok2 {
+		params := dst.GetTLVParams()
+		for _, tlv := range *src.GetTLVParams() {
+			if def, ok := DefaultTLVSchema.Lookup(tlv.Tag); ok && !def.AllowedIn(commandID, SMPP_V50) {
+				continue
+			}
+			params.AddParam(tlv)
+		}
+	}
+
+	if err := m.ApplyTo(out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// messageFields are the fields a Message sets on the PDU it is applied to
+var messageFields = map[string]bool{
+	"Header":       true,
+	"TLVParams":    true,
+	"ShortMessage": true,
+	"SMLength":     true,
+	"ESMClass":     true,
+	"DataCoding":   true,
+}
+
+// copyCommonFields copies the fields of src that dst has with the same
+// name and type, such as service_type and the addresses
+func copyCommonFields(dst, src PDU) {
+	dv, sv := reflect.ValueOf(dst).Elem(), reflect.ValueOf(src).Elem()
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		f := st.Field(i)
+		if messageFields[f.Name] {
+			continue
+		}
+		if df := dv.FieldByName(f.Name); df.IsValid() && df.Type() == f.Type && df.CanSet() {
+			df.Set(sv.Field(i))
+		}
+	}
+}
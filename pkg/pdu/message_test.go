@@ -0,0 +1,154 @@
+package pdu
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// testDataSM returns a data_sm carrying payload in message_payload
+func testDataSM(payload []byte) *DataSM {
+	dm := NewDataSM()
+	dm.Header.SequenceNumber = 5
+	dm.ServiceType = "WAP"
+	dm.SetSource(InternationalAddress("447700900001"))
+	dm.SetDestination(InternationalAddress("447700900002"))
+	dm.RegisteredDelivery = 1
+	dm.DataCoding = DATA_CODING_BINARY
+	dm.TLVParams.SetMessagePayload(payload)
+	dm.TLVParams.SetUserMessageReference(7)
+	dm.TLVParams.SetUint32(TLV_QOS_TIME_TO_LIVE, 3600)
+	return dm
+}
+
+func TestConvertDataSM(t *testing.T) {
+	for _, size := range []int{0, 1, SM_MAX_LENGTH, SM_MAX_LENGTH + 1, 1000} {
+		payload := bytes.Repeat([]byte{0xA5}, size)
+		p, err := ConvertMessage(testDataSM(payload), SUBMIT_SM)
+		if err != nil {
+			t.Fatalf("%d octets: ConvertMessage: %v", size, err)
+		}
+		sm := p.(*SubmitSM)
+
+		// Up to 254 octets go into short_message, longer ones stay in
+		// message_payload
+		mp, hasPayload := sm.TLVParams.MessagePayload()
+		if size > SM_MAX_LENGTH {
+			if len(sm.ShortMessage) != 0 || !bytes.Equal(mp, payload) {
+				t.Errorf("%d octets: short_message %d octets, message_payload %d", size, len(sm.ShortMessage), len(mp))
+			}
+		} else if !bytes.Equal(sm.ShortMessage, payload) || int(sm.SMLength) != size || hasPayload {
+			t.Errorf("%d octets: short_message %d octets, sm_length %d, message_payload %v", size, len(sm.ShortMessage), sm.SMLength, hasPayload)
+		}
+
+		if sm.ServiceType != "WAP" || sm.Source() != InternationalAddress("447700900001") ||
+			sm.Destination() != InternationalAddress("447700900002") ||
+			sm.RegisteredDelivery != 1 || sm.DataCoding != DATA_CODING_BINARY {
+			t.Errorf("%d octets: fields not copied: %v", size, sm)
+		}
+		if ref, ok := sm.TLVParams.UserMessageReference(); !ok || ref != 7 {
+			t.Errorf("%d octets: user_message_reference %d, %v", size, ref, ok)
+		}
+		if sm.TLVParams.Has(TLV_QOS_TIME_TO_LIVE) {
+			t.Errorf("%d octets: qos_time_to_live is not allowed in submit_sm", size)
+		}
+		if _, err := sm.Marshal(); err != nil {
+			t.Errorf("%d octets: Marshal: %v", size, err)
+		}
+	}
+}
+
+func TestConvertSegment(t *testing.T) {
+	udh := NewUDH(&ConcatIE{Ref: 0x1234, Total: 3, SeqNum: 2, Ref16Bit: true})
+	ud, err := udh.Prepend([]byte("part two"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dm := testDataSM(ud)
+	dm.ESMClass = ESM_CLASS_UDHI
+	dm.TLVParams.SetSAR(SARParams{RefNum: 0x1234, Total: 3, SeqNum: 2})
+
+	for _, commandID := range []uint32{SUBMIT_SM, DELIVER_SM, SUBMIT_MULTI} {
+		name := CommandName(commandID)
+		p, err := ConvertMessage(dm, commandID)
+		if err != nil {
+			t.Fatalf("%s: ConvertMessage: %v", name, err)
+		}
+		if multi, ok := p.(*SubmitMulti); ok {
+			multi.AddDestAddress(dm.Destination())
+		}
+
+		// Through the wire and back
+		frame, err := p.Marshal()
+		if err != nil {
+			t.Fatalf("%s: Marshal: %v", name, err)
+		}
+		decoded, err := Decode(frame)
+		if err != nil {
+			t.Fatalf("%s: Decode: %v", name, err)
+		}
+		m, err := MessageFrom(decoded)
+		if err != nil {
+			t.Fatalf("%s: MessageFrom: %v", name, err)
+		}
+		if m.UDH == nil || string(m.Payload) != "part two" {
+			t.Errorf("%s: UDH %v, payload %q", name, m.UDH, m.Payload)
+			continue
+		}
+		if c, ok := m.UDH.Concat(); !ok || *c != *udh.Elements[0].(*ConcatIE) {
+			t.Errorf("%s: concatenation %+v", name, c)
+		}
+		if m.SAR == nil || *m.SAR != (SARParams{RefNum: 0x1234, Total: 3, SeqNum: 2}) {
+			t.Errorf("%s: SAR %+v", name, m.SAR)
+		}
+		if part, ok := m.Part(); !ok || part.SeqNum != 2 {
+			t.Errorf("%s: Part = %+v, %v", name, part, ok)
+		}
+	}
+
+	// And back into a data_sm, which only has message_payload
+	sm := NewSubmitSM()
+	if err := (&Message{UDH: udh, Payload: []byte("x")}).ApplyTo(sm); err != nil {
+		t.Fatal(err)
+	}
+	p, err := ConvertMessage(sm, DATA_SM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := p.(*DataSM)
+	if mp, _ := got.TLVParams.MessagePayload(); got.ESMClass&ESM_CLASS_UDHI == 0 || !bytes.Equal(mp, sm.ShortMessage) {
+		t.Errorf("data_sm esm_class 0x%02X, message_payload %X", got.ESMClass, mp)
+	}
+}
+
+func TestMessageApplyToErrors(t *testing.T) {
+	udh := NewUDH(&ConcatIE{Ref: 1, Total: 2, SeqNum: 1})
+	if err := (&Message{UDH: udh, Payload: []byte("hi")}).ApplyTo(NewBroadcastSM()); !errors.Is(err, ErrMessageUnsupported) {
+		t.Errorf("broadcast_sm with a UDH error = %v, want ErrMessageUnsupported", err)
+	}
+	sm := NewSubmitSM()
+	sm.ESMClass = ESM_CLASS_UDHI
+	sm.ShortMessage, _ = udh.Prepend([]byte("hi"))
+	if _, err := ConvertMessage(sm, BROADCAST_SM); !errors.Is(err, ErrMessageUnsupported) {
+		t.Errorf("ConvertMessage to broadcast_sm error = %v, want ErrMessageUnsupported", err)
+	}
+
+	// Without a UDH broadcast_sm takes the text in message_payload
+	b := NewBroadcastSM()
+	if err := (&Message{Payload: []byte("hi")}).ApplyTo(b); err != nil {
+		t.Errorf("broadcast_sm without a UDH: %v", err)
+	} else if mp, _ := b.TLVParams.MessagePayload(); string(mp) != "hi" {
+		t.Errorf("broadcast_sm message_payload %q", mp)
+	}
+
+	long := &Message{Payload: make([]byte, MESSAGE_PAYLOAD_MAX_LENGTH+1)}
+	if err := long.ApplyTo(NewDataSM()); !errors.Is(err, ErrMessageTooLong) {
+		t.Errorf("ApplyTo of %d octets error = %v", len(long.Payload), err)
+	}
+	if err := (&Message{}).ApplyTo(NewEnquireLink()); !errors.Is(err, ErrNoMessage) {
+		t.Errorf("ApplyTo enquire_link error = %v", err)
+	}
+	if _, err := ConvertMessage(NewEnquireLink(), SUBMIT_SM); !errors.Is(err, ErrNoMessage) {
+		t.Errorf("ConvertMessage from enquire_link error = %v", err)
+	}
+}
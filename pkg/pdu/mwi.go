@@ -0,0 +1,257 @@
+package pdu
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidMWI = NewStatusError(ESME_RINVOPTPARAMVAL, "invalid message waiting indication")
+)
+
+// MWIType is the kind of message an indicator on the handset is waiting for
+type MWIType uint8
+
+// Indication types, the same two bits in ms_msg_wait_facilities, the
+// special SMS UDH element and the data_coding groups
+const (
+	MWI_VOICEMAIL MWIType = 0x00
+	MWI_FAX       MWIType = 0x01
+	MWI_EMAIL     MWIType = 0x02
+	MWI_OTHER     MWIType = 0x03
+)
+
+// String returns the name of the indication type
+func (t MWIType) String() string {
+	switch t {
+	case MWI_VOICEMAIL:
+		return "voicemail"
+	case MWI_FAX:
+		return "fax"
+	case MWI_EMAIL:
+		return "email"
+	case MWI_OTHER:
+		return "other"
+	}
+	return fmt.Sprintf("0x%02X", uint8(t))
+}
+
+// MWIMethod is how a message carries its indication
+type MWIMethod uint8
+
+// Indication methods
+const (
+	MWI_METHOD_TLV         MWIMethod = iota // ms_msg_wait_facilities and number_of_messages, turned into an indication by the SMSC
+	MWI_METHOD_UDH                          // Special SMS message indication UDH element
+	MWI_METHOD_DATA_CODING                  // data_coding message waiting indication group
+)
+
+// String returns the name of the method
+func (m MWIMethod) String() string {
+	switch m {
+	case MWI_METHOD_TLV:
+		return "tlv"
+	case MWI_METHOD_UDH:
+		return "udh"
+	case MWI_METHOD_DATA_CODING:
+		return "data_coding"
+	}
+	return fmt.Sprintf("0x%02X", uint8(m))
+}
+
+// Message waiting indication values (GSM 03.38 and SMPP 3.4 5.3.2.13)
+const (
+	DATA_CODING_MWI_DISCARD    uint8 = 0xC0 // GSM 7-bit, message discarded after updating the indicator
+	DATA_CODING_MWI_STORE      uint8 = 0xD0 // GSM 7-bit, message stored
+	DATA_CODING_MWI_STORE_UCS2 uint8 = 0xE0 // UCS2, message stored
+	DATA_CODING_MWI_ACTIVE     uint8 = 0x08 // Indicator set, cleared otherwise
+	MS_MSG_WAIT_ACTIVE         uint8 = 0x80 // Indicator set in ms_msg_wait_facilities, cleared otherwise
+	NUMBER_OF_MESSAGES_MAX     uint8 = 99   // Largest number_of_messages
+)
+
+// MWI is a message waiting indication, setting or clearing an indicator
+// on the handset such as the voicemail icon
+type MWI struct {
+	Method MWIMethod
+	Type   MWIType
+	Active bool  // Set the indicator, clear it otherwise
+	Count  uint8 // Number of waiting messages, 0 if unknown; data_coding carries none
+	Store  bool  // Store the message after updating the indicator; the TLVs carry none
+}
+
+// Validate checks that the indication can be carried by its method
+func (w MWI) Validate() error {
+	if w.Type > MWI_OTHER {
+		return fmt.Errorf("%w: type %s", ErrInvalidMWI, w.Type)
+	}
+	switch w.Method {
+	case MWI_METHOD_TLV:
+		if w.Count > NUMBER_OF_MESSAGES_MAX {
+			return fmt.Errorf("%w: number_of_messages %d exceeds %d", ErrInvalidMWI, w.Count, NUMBER_OF_MESSAGES_MAX)
+		}
+	case MWI_METHOD_UDH, MWI_METHOD_DATA_CODING:
+	default:
+		return fmt.Errorf("%w: method %s", ErrInvalidMWI, w.Method)
+	}
+	return nil
+}
+
+// DataCoding returns the data_coding of the message waiting indication
+// group for text in alphabet, DATA_CODING_DEFAULT or DATA_CODING_UCS2.
+// UCS2 text can only be stored.
+func (w MWI) DataCoding(alphabet uint8) (uint8, error) {
+	var dataCoding uint8
+	switch {
+	case alphabet == DATA_CODING_DEFAULT && w.Store:
+		dataCoding = DATA_CODING_MWI_STORE
+	case alphabet == DATA_CODING_DEFAULT:
+		dataCoding = DATA_CODING_MWI_DISCARD
+	case alphabet == DATA_CODING_UCS2 && w.Store:
+		dataCoding = DATA_CODING_MWI_STORE_UCS2
+	case alphabet == DATA_CODING_UCS2:
+		return 0, fmt.Errorf("%w: ucs2 text must be stored", ErrInvalidMWI)
+	default:
+		return 0, fmt.Errorf("%w: data_coding 0x%02X has no message waiting group", ErrInvalidMWI, alphabet)
+	}
+	if w.Active {
+		dataCoding |= DATA_CODING_MWI_ACTIVE
+	}
+	return dataCoding | uint8(w.Type), nil
+}
+
+// SpecialSMSIE returns the UDH element of the indication. A count of zero
+// clears the indicator, so an active one counts at least one message.
+func (w MWI) SpecialSMSIE() *SpecialSMSIE {
+	e := &SpecialSMSIE{Store: w.Store, Type: uint8(w.Type)}
+	if w.Active {
+		e.Count = max(w.Count, 1)
+	}
+	return e
+}
+
+// ApplyTo adds the indication to a submit_sm, submit_multi, deliver_sm or
+// data_sm already holding the message text. A special SMS element of the
+// same type is replaced, those of other types are kept.
+func (w MWI) ApplyTo(p PDU) error {
+	if err := w.Validate(); err != nil {
+		return err
+	}
+
+	if w.Method == MWI_METHOD_TLV {
+		c, ok := p.(TLVCarrier)
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrNoMessage, CommandName(p.CommandID()))
+		}
+		params := c.GetTLVParams()
+		facilities := uint8(w.Type)
+		if w.Active {
+			facilities |= MS_MSG_WAIT_ACTIVE
+		}
+		params.SetMSMsgWaitFacilities(facilities)
+		if w.Count > 0 {
+			params.SetNumberOfMessages(w.Count)
+		} else {
+			params.Delete(TLV_NUMBER_OF_MESSAGES)
+		}
+		return nil
+	}
+
+	msg, err := MessageFrom(p)
+	if err != nil {
+		return err
+	}
+	switch w.Method {
+	case MWI_METHOD_UDH:
+		udh := NewUDH()
+		if msg.UDH != nil {
+			for _, e := range msg.UDH.Elements {
+				if s, ok := e.(*SpecialSMSIE); !ok || MWIType(s.Type&0x03) != w.Type {
+					udh.Add(e)
+				}
+			}
+		}
+		udh.Add(w.SpecialSMSIE())
+		msg.UDH = udh
+	case MWI_METHOD_DATA_CODING:
+		if msg.DataCoding, err = w.DataCoding(Alphabet(msg.DataCoding)); err != nil {
+			return err
+		}
+	}
+	return msg.ApplyTo(p)
+}
+
+// NewMWISubmitSM creates a submit_sm setting or clearing an indicator,
+// with text for the user that fits into one short message. The text may
+// be empty, as is usual for clearing.
+func NewMWISubmitSM(w MWI, text string) (*SubmitSM, error) {
+	encoded := SelectTextEncoding(text, nil)
+	// Room is kept for the special SMS element next to any national
+	// language shift elements
+	mwiLen := 0
+	if w.Method == MWI_METHOD_UDH {
+		mwiLen = 2 + len(w.SpecialSMSIE().Value())
+	}
+	if encoded.Length > encoded.Capacity(encoded.udhLength(mwiLen)) {
+		return nil, ErrMessageTooLong
+	}
+	msg := &Message{DataCoding: encoded.DataCoding, Payload: encoded.Data}
+	if shifts := encoded.NationalShiftElements(); len(shifts) > 0 {
+		msg.UDH = NewUDH(shifts...)
+	}
+
+	sm := NewSubmitSM()
+	if err := msg.ApplyTo(sm); err != nil {
+		return nil, err
+	}
+	if err := w.ApplyTo(sm); err != nil {
+		return nil, err
+	}
+	return sm, nil
+}
+
+// MWIFrom returns the message waiting indications a PDU carries in its
+// TLVs, data_coding and UDH, none for an ordinary message. A message may
+// carry several, e.g. for voicemail and fax.
+func MWIFrom(p PDU) ([]MWI, error) {
+	var mwis []MWI
+	if c, ok := p.(TLVCarrier); ok {
+		params := c.GetTLVParams()
+		if facilities, ok := params.MSMsgWaitFacilities(); ok {
+			w := MWI{
+				Method: MWI_METHOD_TLV,
+				Type:   MWIType(facilities & 0x03),
+				Active: facilities&MS_MSG_WAIT_ACTIVE != 0,
+			}
+			w.Count, _ = params.NumberOfMessages()
+			mwis = append(mwis, w)
+		}
+	}
+
+	msg, err := MessageFrom(p)
+	if errors.Is(err, ErrNoMessage) {
+		return mwis, nil
+	}
+	if err != nil {
+		return mwis, err
+	}
+	if dc := msg.DataCoding; dc >= DATA_CODING_MWI_DISCARD && dc <= 0xEF {
+		mwis = append(mwis, MWI{
+			Method: MWI_METHOD_DATA_CODING,
+			Type:   MWIType(dc & 0x03),
+			Active: dc&DATA_CODING_MWI_ACTIVE != 0,
+			Store:  dc >= DATA_CODING_MWI_STORE,
+		})
+	}
+	if msg.UDH != nil {
+		for _, e := range msg.UDH.SpecialSMS() {
+			mwis = append(mwis, MWI{
+				Method: MWI_METHOD_UDH,
+				Type:   MWIType(e.Type & 0x03),
+				Active: e.Count > 0,
+				Count:  e.Count,
+				Store:  e.Store,
+			})
+		}
+	}
+	return mwis, nil
+}
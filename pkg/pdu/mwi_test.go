@@ -0,0 +1,176 @@
+package pdu
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMWIDataCoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		w        MWI
+		alphabet uint8
+		want     uint8
+		err      error
+	}{
+		{"discard inactive", MWI{Type: MWI_VOICEMAIL}, DATA_CODING_DEFAULT, 0xC0, nil},
+		{"discard active", MWI{Type: MWI_VOICEMAIL, Active: true}, DATA_CODING_DEFAULT, 0xC8, nil},
+		{"store inactive fax", MWI{Type: MWI_FAX, Store: true}, DATA_CODING_DEFAULT, 0xD1, nil},
+		{"store active email", MWI{Type: MWI_EMAIL, Active: true, Store: true}, DATA_CODING_DEFAULT, 0xDA, nil},
+		{"ucs2 store inactive", MWI{Type: MWI_OTHER, Store: true}, DATA_CODING_UCS2, 0xE3, nil},
+		{"ucs2 store active", MWI{Type: MWI_VOICEMAIL, Active: true, Store: true}, DATA_CODING_UCS2, 0xE8, nil},
+		{"ucs2 discard", MWI{Type: MWI_VOICEMAIL, Active: true}, DATA_CODING_UCS2, 0, ErrInvalidMWI},
+		{"binary", MWI{Type: MWI_VOICEMAIL, Store: true}, DATA_CODING_BINARY, 0, ErrInvalidMWI},
+	}
+	for _, tt := range tests {
+		got, err := tt.w.DataCoding(tt.alphabet)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("%s: DataCoding = 0x%02X, %v, want 0x%02X, %v", tt.name, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+// mwiCarriers returns a submit_sm and a deliver_sm holding text
+func mwiCarriers(t *testing.T, text string) []PDU {
+	t.Helper()
+	sm := NewSubmitSM()
+	if err := sm.SetMessageText(text, DATA_CODING_DEFAULT); err != nil {
+		t.Fatal(err)
+	}
+	dm := NewDeliverSM()
+	if err := dm.SetMessageText(text, DATA_CODING_DEFAULT); err != nil {
+		t.Fatal(err)
+	}
+	return []PDU{sm, dm}
+}
+
+func TestMWIRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		w    MWI
+		want MWI // As read back
+	}{
+		{"tlv active", MWI{Method: MWI_METHOD_TLV, Type: MWI_VOICEMAIL, Active: true, Count: 3}, MWI{Method: MWI_METHOD_TLV, Type: MWI_VOICEMAIL, Active: true, Count: 3}},
+		{"tlv inactive", MWI{Method: MWI_METHOD_TLV, Type: MWI_FAX}, MWI{Method: MWI_METHOD_TLV, Type: MWI_FAX}},
+		{"udh active", MWI{Method: MWI_METHOD_UDH, Type: MWI_EMAIL, Active: true, Count: 5, Store: true}, MWI{Method: MWI_METHOD_UDH, Type: MWI_EMAIL, Active: true, Count: 5, Store: true}},
+		{"udh active without count", MWI{Method: MWI_METHOD_UDH, Type: MWI_VOICEMAIL, Active: true}, MWI{Method: MWI_METHOD_UDH, Type: MWI_VOICEMAIL, Active: true, Count: 1}},
+		{"udh inactive", MWI{Method: MWI_METHOD_UDH, Type: MWI_VOICEMAIL, Count: 4}, MWI{Method: MWI_METHOD_UDH, Type: MWI_VOICEMAIL}},
+		{"data_coding store", MWI{Method: MWI_METHOD_DATA_CODING, Type: MWI_OTHER, Active: true, Store: true}, MWI{Method: MWI_METHOD_DATA_CODING, Type: MWI_OTHER, Active: true, Store: true}},
+		{"data_coding discard", MWI{Method: MWI_METHOD_DATA_CODING, Type: MWI_VOICEMAIL}, MWI{Method: MWI_METHOD_DATA_CODING, Type: MWI_VOICEMAIL}},
+	}
+	for _, tt := range tests {
+		for _, p := range mwiCarriers(t, "You have new messages") {
+			name := tt.name + " " + CommandName(p.CommandID())
+			if err := tt.w.ApplyTo(p); err != nil {
+				t.Errorf("%s: ApplyTo: %v", name, err)
+				continue
+			}
+			frame, err := p.Marshal()
+			if err != nil {
+				t.Fatalf("%s: Marshal: %v", name, err)
+			}
+			decoded, err := Decode(frame)
+			if err != nil {
+				t.Fatalf("%s: Decode: %v", name, err)
+			}
+			got, err := MWIFrom(decoded)
+			if err != nil {
+				t.Fatalf("%s: MWIFrom: %v", name, err)
+			}
+			if !slices.Equal(got, []MWI{tt.want}) {
+				t.Errorf("%s: MWIFrom = %+v, want %+v", name, got, tt.want)
+			}
+
+			// The text survives the indication
+			msg, err := MessageFrom(decoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if text, err := msg.Text(); err != nil || text != "You have new messages" {
+				t.Errorf("%s: text %q, %v", name, text, err)
+			}
+		}
+	}
+}
+
+func TestMWIApplyToReplaces(t *testing.T) {
+	sm := NewSubmitSM()
+	for _, w := range []MWI{
+		{Method: MWI_METHOD_UDH, Type: MWI_VOICEMAIL, Active: true, Count: 1},
+		{Method: MWI_METHOD_UDH, Type: MWI_FAX, Active: true, Count: 2},
+		{Method: MWI_METHOD_UDH, Type: MWI_VOICEMAIL, Active: true, Count: 7},
+		{Method: MWI_METHOD_TLV, Type: MWI_EMAIL, Active: true},
+	} {
+		if err := w.ApplyTo(sm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := MWIFrom(sm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []MWI{
+		{Method: MWI_METHOD_TLV, Type: MWI_EMAIL, Active: true},
+		{Method: MWI_METHOD_UDH, Type: MWI_FAX, Active: true, Count: 2},
+		{Method: MWI_METHOD_UDH, Type: MWI_VOICEMAIL, Active: true, Count: 7},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("MWIFrom = %+v, want %+v", got, want)
+	}
+
+	if got, err := MWIFrom(NewEnquireLink()); err != nil || len(got) != 0 {
+		t.Errorf("MWIFrom(enquire_link) = %v, %v", got, err)
+	}
+	if err := (MWI{Method: MWI_METHOD_TLV, Count: 100}).ApplyTo(sm); !errors.Is(err, ErrInvalidMWI) {
+		t.Errorf("ApplyTo with 100 messages error = %v", err)
+	}
+}
+
+func TestNewMWISubmitSM(t *testing.T) {
+	udh := MWI{Method: MWI_METHOD_UDH, Type: MWI_VOICEMAIL, Active: true}
+	tests := []struct {
+		name string
+		w    MWI
+		text string
+		err  error
+	}{
+		{"tlv 160 characters", MWI{Method: MWI_METHOD_TLV, Active: true}, strings.Repeat("a", 160), nil},
+		{"udh 154 characters", udh, strings.Repeat("a", 154), nil},
+		{"udh 155 characters", udh, strings.Repeat("a", 155), ErrMessageTooLong},
+		{"udh 160 characters", udh, strings.Repeat("a", 160), ErrMessageTooLong},
+		{"udh 67 ucs2 characters", udh, strings.Repeat("я", 67), nil},
+		{"udh 68 ucs2 characters", udh, strings.Repeat("я", 68), ErrMessageTooLong},
+		{"data_coding empty", MWI{Method: MWI_METHOD_DATA_CODING}, "", nil},
+		{"tlv 161 characters", MWI{Method: MWI_METHOD_TLV}, strings.Repeat("a", 161), ErrMessageTooLong},
+	}
+	for _, tt := range tests {
+		sm, err := NewMWISubmitSM(tt.w, tt.text)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if _, err := sm.Marshal(); err != nil {
+			t.Errorf("%s: Marshal: %v", tt.name, err)
+		}
+		// GSM 7-bit text starts on the septet boundary after the UDH
+		msg, err := MessageFrom(sm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bits := len(msg.Payload) * 8
+		if Alphabet(msg.DataCoding) == DATA_CODING_DEFAULT {
+			bits = len(msg.Payload) * 7
+		}
+		if msg.UDH != nil {
+			bits += (msg.UDH.Len()*8 + 6) / 7 * 7
+		}
+		if bits > SM_MAX_USER_DATA*8 {
+			t.Errorf("%s: %d bits of user data", tt.name, bits)
+		}
+	}
+}
@@ -1,12 +1,10 @@
 package pdu
 
-import "errors"
-
 // Outbind represents an SMPP outbind PDU
 type Outbind struct {
-	Header   *Header
-	SystemID string
-	Password string
+	Header   *Header `json:"header"`
+	SystemID string  `json:"system_id"`
+	Password string  `json:"password"`
 }
 
 // NewOutbind creates a new Outbind PDU
@@ -16,66 +14,85 @@ func NewOutbind() *Outbind {
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (o *Outbind) Marshal() ([]byte, error) {
-	// Calculate the total length
-	length := 16 // Header length
+// checkFields checks the mandatory fields against their specified sizes
+func (o *Outbind) checkFields() error {
+	return firstError(
+		checkCString("system_id", o.SystemID, SYSTEM_ID_MAX_LEN, ESME_RINVSYSID),
+		checkCString("password", o.Password, PASSWORD_MAX_LEN, ESME_RINVPASWD),
+	)
+}
+
+// EncodedLen returns the size of the encoded PDU in bytes
+func (o *Outbind) EncodedLen() int {
+	length := HeaderLength
 	length += len(o.SystemID) + 1
 	length += len(o.Password) + 1
+	return length
+}
 
-	// Set command length in header
-	o.Header.CommandLength = uint32(length)
-	o.Header.CommandID = OUTBIND
-
-	// Create the byte slice
-	buf := make([]byte, length)
-
-	// Marshal header
-	headerBytes, err := o.Header.Marshal()
-	if err != nil {
-		return nil, err
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (o *Outbind) AppendTo(dst []byte) ([]byte, error) {
+	if err := o.checkFields(); err != nil {
+		return dst, err
 	}
-	copy(buf[0:], headerBytes)
 
-	offset := 16
-
-	// Write system_id
-	copy(buf[offset:], o.SystemID)
-	offset += len(o.SystemID) + 1
+	o.Header.CommandLength = uint32(o.EncodedLen())
+	o.Header.CommandID = OUTBIND
 
-	// Write password
-	copy(buf[offset:], o.Password)
-	offset += len(o.Password) + 1
+	dst = o.Header.AppendTo(dst)
+	dst = appendCString(dst, o.SystemID)
+	dst = appendCString(dst, o.Password)
+	return dst, nil
+}
 
-	return buf, nil
+// Marshal serializes the PDU into bytes
+func (o *Outbind) Marshal() ([]byte, error) {
+	return o.AppendTo(make([]byte, 0, o.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (o *Outbind) Unmarshal(data []byte) error {
-	var err error
+	return o.decode(newDecoder(data, false))
+}
 
-	// Unmarshal header
+// decode reads the PDU fields from dec
+func (o *Outbind) decode(dec *decoder) error {
 	o.Header = &Header{}
-	if err = o.Header.Unmarshal(data[:16]); err != nil {
-		return err
-	}
+	dec.header(o.Header)
 
-	offset := 16
+	o.SystemID = dec.cstring("system_id", SYSTEM_ID_MAX_LEN, ESME_RINVSYSID)
+	o.Password = dec.cstring("password", PASSWORD_MAX_LEN, ESME_RINVPASWD)
 
-	// Read system_id
-	if o.SystemID, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+	dec.end()
+	return dec.Err()
+}
 
-	// Read password
-	if o.Password, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+// CommandID returns the command_id of the PDU
+func (o *Outbind) CommandID() uint32 {
+	return OUTBIND
+}
 
-	// Verify we've read all the data
-	if offset != int(o.Header.CommandLength) {
-		return errors.New("invalid PDU length")
-	}
+// GetHeader returns the PDU header
+func (o *Outbind) GetHeader() *Header {
+	return o.Header
+}
+
+// String returns a one-line description of the PDU for trace logs
+func (o *Outbind) String() string {
+	return FormatPDU(o)
+}
+
+// MarshalJSON encodes the PDU as JSON
+func (o *Outbind) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(o)
+}
+
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (o *Outbind) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, o)
+}
 
+// GetResponse returns nil as outbind has no response PDU
+func (o *Outbind) GetResponse() PDU {
 	return nil
 }
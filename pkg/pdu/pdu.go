@@ -6,6 +6,42 @@ import (
 	"io"
 )
 
+// PDU is implemented by every SMPP PDU type
+type PDU interface {
+	// CommandID returns the command_id of the PDU
+	CommandID() uint32
+	// GetHeader returns the PDU header
+	GetHeader() *Header
+	// Marshal serializes the PDU into bytes
+	Marshal() ([]byte, error)
+	// Unmarshal deserializes the PDU from bytes
+	Unmarshal(data []byte) error
+	// GetResponse creates the response PDU for a request, or nil if the PDU has none
+	GetResponse() PDU
+}
+
+// Appender is implemented by PDUs that can encode into a caller supplied
+// buffer. Every PDU of this package implements it.
+type Appender interface {
+	// EncodedLen returns the size of the encoded PDU in bytes
+	EncodedLen() int
+	// AppendTo appends the encoded PDU to dst and returns the extended slice
+	AppendTo(dst []byte) ([]byte, error)
+}
+
+// AppendPDU appends the encoding of p to dst, through AppendTo when p
+// implements Appender and Marshal otherwise
+func AppendPDU(dst []byte, p PDU) ([]byte, error) {
+	if a, ok := p.(Appender); ok {
+		return a.AppendTo(dst)
+	}
+	data, err := p.Marshal()
+	if err != nil {
+		return dst, err
+	}
+	return append(dst, data...), nil
+}
+
 var (
 	ErrCStringTooLong   = errors.New("c-string is too long")
 	ErrInvalidCString   = errors.New("invalid c-string: contains null byte before end")
@@ -99,6 +135,12 @@ func ReadCString(data []byte) (string, int, error) {
 	return string(data[:nullIndex]), nullIndex + 1, nil
 }
 
+// appendCString appends s and its NULL terminator to dst
+func appendCString(dst []byte, s string) []byte {
+	dst = append(dst, s...)
+	return append(dst, 0)
+}
+
 // WriteCString writes a C-string to a writer
 func WriteCString(w io.Writer, s string) error {
 	b := make([]byte, len(s)+1)
@@ -0,0 +1,153 @@
+package pdu
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidVendorTLV     = errors.New("invalid vendor tlv definition")
+	ErrInvalidVendorCommand = errors.New("invalid vendor command")
+)
+
+// Tag range reserved for vendor specific optional parameters
+const (
+	TLV_VENDOR_SPECIFIC_MIN uint16 = 0x1400
+	TLV_VENDOR_SPECIFIC_MAX uint16 = 0x3FFF
+)
+
+// Profile holds the extensions a supplier speaks on top of the
+// specification: optional parameters in the vendor specific range and
+// proprietary commands. PDUs read with the profile's registry decode
+// vendor parameters into named, typed values that keep their names in
+// trace logs and JSON, and are passed on unchanged.
+type Profile struct {
+	Name string
+
+	registry *Registry
+	schema   *TLVSchema
+}
+
+// NewProfile creates a profile with every standard PDU type and TLV
+func NewProfile(name string) *Profile {
+	schema := DefaultTLVSchema.Clone()
+	registry := DefaultRegistry.Clone()
+	registry.SetTLVSchema(schema)
+	return &Profile{
+		Name:     name,
+		registry: registry,
+		schema:   schema,
+	}
+}
+
+// Registry returns the registry to read and write the connections of the
+// profile with
+func (p *Profile) Registry() *Registry {
+	return p.registry
+}
+
+// TLVSchema returns the standard and vendor TLV definitions of the profile
+func (p *Profile) TLVSchema() *TLVSchema {
+	return p.schema
+}
+
+// RegisterTLV adds a vendor TLV definition. The tag must lie in the vendor
+// specific range and the name must not be taken by another tag. Without
+// Allowed entries the parameter is permitted in every PDU.
+func (p *Profile) RegisterTLV(def *TLVDef) error {
+	if def.Tag < TLV_VENDOR_SPECIFIC_MIN || def.Tag > TLV_VENDOR_SPECIFIC_MAX {
+		return fmt.Errorf("%w: tag 0x%04X outside 0x%04X..0x%04X", ErrInvalidVendorTLV,
+			def.Tag, TLV_VENDOR_SPECIFIC_MIN, TLV_VENDOR_SPECIFIC_MAX)
+	}
+	if def.Name == "" {
+		return fmt.Errorf("%w: tag 0x%04X has no name", ErrInvalidVendorTLV, def.Tag)
+	}
+	if other, ok := p.schema.LookupName(def.Name); ok && other.Tag != def.Tag {
+		return fmt.Errorf("%w: name %q is taken by tag 0x%04X", ErrInvalidVendorTLV, def.Name, other.Tag)
+	}
+	p.schema.Register(def)
+	return nil
+}
+
+// RegisterCommand adds a vendor command and names it in the profile's
+// registry. PDUs of a command without a factory decode into a RawPDU.
+func (p *Profile) RegisterCommand(commandID uint32, name string, f Factory) error {
+	if err := p.registry.RegisterCommandName(commandID, name); err != nil {
+		return err
+	}
+	if f == nil {
+		f = func() PDU { return NewRawPDU(commandID) }
+	}
+	p.registry.Register(commandID, f)
+	return nil
+}
+
+// RegisterCommandName names a vendor command for the CommandName,
+// FormatPDU and JSON encoding of the registry. Standard commands cannot be
+// renamed and a name can only be given to one command. Names are kept per
+// registry, so suppliers reusing a command ID under different names do not
+// affect each other.
+func (r *Registry) RegisterCommandName(commandID uint32, name string) error {
+	if _, ok := commandNames[commandID]; ok {
+		return fmt.Errorf("%w: 0x%08X is a standard command", ErrInvalidVendorCommand, commandID)
+	}
+	if name == "" {
+		return fmt.Errorf("%w: 0x%08X has no name", ErrInvalidVendorCommand, commandID)
+	}
+	for _, n := range commandNames {
+		if n == name {
+			return fmt.Errorf("%w: %q is a standard command", ErrInvalidVendorCommand, name)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, n := range r.names {
+		if n == name && id != commandID {
+			return fmt.Errorf("%w: %q is taken by 0x%08X", ErrInvalidVendorCommand, name, id)
+		}
+	}
+	r.names[commandID] = name
+	return nil
+}
+
+// CommandName returns the specification name of a command, the name given
+// to a vendor command in the registry, or its hex value if unknown
+func (r *Registry) CommandName(commandID uint32) string {
+	if name, ok := commandNames[commandID]; ok {
+		return name
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if name, ok := r.names[commandID]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%08X", commandID)
+}
+
+// CommandIDByName returns the command ID of a specification name, a vendor
+// command name of the registry or a 0x prefixed hex value as returned by
+// CommandName
+func (r *Registry) CommandIDByName(name string) (uint32, bool) {
+	for id, n := range commandNames {
+		if n == name {
+			return id, true
+		}
+	}
+	r.mu.RLock()
+	for id, n := range r.names {
+		if n == name {
+			r.mu.RUnlock()
+			return id, true
+		}
+	}
+	r.mu.RUnlock()
+	if s, ok := strings.CutPrefix(name, "0x"); ok {
+		if id, err := strconv.ParseUint(s, 16, 32); err == nil {
+			return uint32(id), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,86 @@
+package pdu
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestProfileCommandNames(t *testing.T) {
+	const commandID uint32 = 0x00010201
+	a := NewProfile("supplier-a")
+	b := NewProfile("supplier-b")
+	if err := a.RegisterCommand(commandID, "a_ping", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.RegisterCommand(commandID, "b_status", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		r    *Registry
+		name string
+	}{
+		{a.Registry(), "a_ping"},
+		{b.Registry(), "b_status"},
+		{DefaultRegistry, "0x00010201"},
+	} {
+		if got := tt.r.CommandName(commandID); got != tt.name {
+			t.Errorf("CommandName = %q, want %q", got, tt.name)
+		}
+		if id, ok := tt.r.CommandIDByName(tt.name); !ok || id != commandID {
+			t.Errorf("CommandIDByName(%q) = 0x%08X, %v", tt.name, id, ok)
+		}
+
+		p := NewRawPDU(commandID)
+		p.Header.SequenceNumber = 7
+		p.Body = Octets{0x01, 0x02}
+		if got := tt.r.FormatPDU(p); !strings.HasPrefix(got, tt.name+" seq=7 ") {
+			t.Errorf("FormatPDU = %q, want the command named %q", got, tt.name)
+		}
+
+		data, err := tt.r.EncodeJSON(p)
+		if err != nil {
+			t.Fatalf("EncodeJSON: %v", err)
+		}
+		if !strings.Contains(string(data), `"command":"`+tt.name+`"`) {
+			t.Errorf("EncodeJSON = %s, want the command named %q", data, tt.name)
+		}
+		if tt.r == DefaultRegistry {
+			// Without a factory the command cannot be decoded
+			continue
+		}
+		decoded, err := tt.r.DecodeJSON(data)
+		if err != nil {
+			t.Fatalf("DecodeJSON(%s): %v", data, err)
+		}
+		if decoded.CommandID() != commandID || decoded.GetHeader().SequenceNumber != 7 {
+			t.Errorf("DecodeJSON = %s", tt.r.FormatPDU(decoded))
+		}
+	}
+
+	// A name of one profile means nothing to another
+	if _, err := b.Registry().DecodeJSON([]byte(`{"header":{"command":"a_ping"}}`)); !errors.Is(err, ErrUnknownCommandName) {
+		t.Errorf("DecodeJSON error = %v, want ErrUnknownCommandName", err)
+	}
+}
+
+func TestProfileRegisterCommandInvalid(t *testing.T) {
+	p := NewProfile("supplier")
+	if err := p.RegisterCommand(0x00010201, "ping", nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		commandID uint32
+		name      string
+	}{
+		{SUBMIT_SM, "vendor_submit"},
+		{0x00010202, "submit_sm"},
+		{0x00010202, ""},
+		{0x00010202, "ping"},
+	} {
+		if err := p.RegisterCommand(tt.commandID, tt.name, nil); !errors.Is(err, ErrInvalidVendorCommand) {
+			t.Errorf("RegisterCommand(0x%08X, %q) = %v, want ErrInvalidVendorCommand", tt.commandID, tt.name, err)
+		}
+	}
+}
@@ -1,143 +1,124 @@
 package pdu
 
-import (
-	"encoding/binary"
-	"errors"
-)
-
 // QueryBroadcastSM represents an SMPP query_broadcast_sm PDU (SMPP v5.0)
 type QueryBroadcastSM struct {
-	Header        *Header
-	MessageID     string
-	SourceAddrTON uint8
-	SourceAddrNPI uint8
-	SourceAddr    string
-	TLVParams     map[uint16]*TLVParam
+	Header        *Header `json:"header"`
+	MessageID     string  `json:"message_id"`
+	SourceAddrTON uint8   `json:"source_addr_ton"`
+	SourceAddrNPI uint8   `json:"source_addr_npi"`
+	SourceAddr    string  `json:"source_addr"`
+	TLVParams     TLVList `json:"tlvs,omitempty"`
 }
 
 // NewQueryBroadcastSM creates a new QueryBroadcastSM PDU
 func NewQueryBroadcastSM() *QueryBroadcastSM {
 	return &QueryBroadcastSM{
-		Header:    NewHeader(),
-		TLVParams: make(map[uint16]*TLVParam),
+		Header: NewHeader(),
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (q *QueryBroadcastSM) Marshal() ([]byte, error) {
-	// Calculate the total length
-	length := 16 // Header length
+// Source returns source_addr with its TON and NPI
+func (q *QueryBroadcastSM) Source() Address {
+	return Address{TON: q.SourceAddrTON, NPI: q.SourceAddrNPI, Addr: q.SourceAddr}
+}
+
+// SetSource sets source_addr with its TON and NPI
+func (q *QueryBroadcastSM) SetSource(a Address) {
+	q.SourceAddrTON, q.SourceAddrNPI, q.SourceAddr = a.TON, a.NPI, a.Addr
+}
+
+// checkFields checks the mandatory fields against their specified sizes
+func (q *QueryBroadcastSM) checkFields() error {
+	return firstError(
+		checkCString("message_id", q.MessageID, MESSAGE_ID_MAX_LEN, ESME_RINVMSGID),
+		checkCString("source_addr", q.SourceAddr, ADDR_MAX_LEN, ESME_RINVSRCADR),
+	)
+}
+
+// EncodedLen returns the size of the encoded PDU in bytes
+func (q *QueryBroadcastSM) EncodedLen() int {
+	length := HeaderLength
 	length += len(q.MessageID) + 1
-	length += 2 // Source addr ton and npi
+	length += 2 // source_addr_ton, source_addr_npi
 	length += len(q.SourceAddr) + 1
+	length += q.TLVParams.EncodedLen()
+	return length
+}
 
-	// Add TLV parameters length
-	for _, tlv := range q.TLVParams {
-		length += 4 + len(tlv.Value) // Tag(2) + Length(2) + Value(n)
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (q *QueryBroadcastSM) AppendTo(dst []byte) ([]byte, error) {
+	if err := q.checkFields(); err != nil {
+		return dst, err
 	}
 
-	// Set command length in header
-	q.Header.CommandLength = uint32(length)
+	q.Header.CommandLength = uint32(q.EncodedLen())
 	q.Header.CommandID = QUERY_BROADCAST_SM
 
-	// Create the byte slice
-	buf := make([]byte, length)
-
-	// Marshal header
-	headerBytes, err := q.Header.Marshal()
-	if err != nil {
-		return nil, err
-	}
-	copy(buf[0:], headerBytes)
-
-	offset := 16
-
-	// Write message_id
-	copy(buf[offset:], q.MessageID)
-	offset += len(q.MessageID) + 1
-
-	// Write source_addr_ton
-	buf[offset] = q.SourceAddrTON
-	offset++
-
-	// Write source_addr_npi
-	buf[offset] = q.SourceAddrNPI
-	offset++
-
-	// Write source_addr
-	copy(buf[offset:], q.SourceAddr)
-	offset += len(q.SourceAddr) + 1
-
-	// Write TLV parameters
-	for _, tlv := range q.TLVParams {
-		tlvBytes, err := tlv.Marshal()
-		if err != nil {
-			return nil, err
-		}
-		copy(buf[offset:], tlvBytes)
-		offset += 4 + len(tlv.Value)
-	}
+	dst = q.Header.AppendTo(dst)
+	dst = appendCString(dst, q.MessageID)
+	dst = append(dst, q.SourceAddrTON, q.SourceAddrNPI)
+	dst = appendCString(dst, q.SourceAddr)
+	dst = q.TLVParams.AppendTo(dst)
+	return dst, nil
+}
 
-	return buf, nil
+// Marshal serializes the PDU into bytes
+func (q *QueryBroadcastSM) Marshal() ([]byte, error) {
+	return q.AppendTo(make([]byte, 0, q.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (q *QueryBroadcastSM) Unmarshal(data []byte) error {
-	var err error
+	return q.decode(newDecoder(data, false))
+}
 
-	// Unmarshal header
+// decode reads the PDU fields from dec
+func (q *QueryBroadcastSM) decode(dec *decoder) error {
 	q.Header = &Header{}
-	if err = q.Header.Unmarshal(data[:16]); err != nil {
-		return err
-	}
-
-	offset := 16
-
-	// Read message_id
-	if q.MessageID, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
-
-	// Read source_addr_ton
-	q.SourceAddrTON = data[offset]
-	offset++
+	dec.header(q.Header)
 
-	// Read source_addr_npi
-	q.SourceAddrNPI = data[offset]
-	offset++
+	q.MessageID = dec.cstring("message_id", MESSAGE_ID_MAX_LEN, ESME_RINVMSGID)
+	q.SourceAddrTON = dec.uint8("source_addr_ton")
+	q.SourceAddrNPI = dec.uint8("source_addr_npi")
+	q.SourceAddr = dec.cstring("source_addr", ADDR_MAX_LEN, ESME_RINVSRCADR)
 
-	// Read source_addr
-	if q.SourceAddr, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+	dec.tlvs(&q.TLVParams)
+	return dec.Err()
+}
 
-	// Read TLV parameters if any remain
-	remaining := int(q.Header.CommandLength) - offset
-	currentOffset := offset
+// CommandID returns the command_id of the PDU
+func (q *QueryBroadcastSM) CommandID() uint32 {
+	return QUERY_BROADCAST_SM
+}
 
-	for remaining > 0 {
-		if remaining < 4 {
-			return errors.New("invalid TLV parameter: insufficient data")
-		}
+// GetHeader returns the PDU header
+func (q *QueryBroadcastSM) GetHeader() *Header {
+	return q.Header
+}
 
-		tag := binary.BigEndian.Uint16(data[currentOffset:])
-		length := binary.BigEndian.Uint16(data[currentOffset+2:])
+// String returns a one-line description of the PDU for trace logs
+func (q *QueryBroadcastSM) String() string {
+	return FormatPDU(q)
+}
 
-		if remaining < int(4+length) {
-			return errors.New("invalid TLV parameter: insufficient data for value")
-		}
+// MarshalJSON encodes the PDU as JSON
+func (q *QueryBroadcastSM) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(q)
+}
 
-		tlv := &TLVParam{
-			Tag:    tag,
-			Length: length,
-			Value:  make([]byte, length),
-		}
-		copy(tlv.Value, data[currentOffset+4:currentOffset+4+int(length)])
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (q *QueryBroadcastSM) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, q)
+}
 
-		q.TLVParams[tag] = tlv
-		currentOffset += 4 + int(length)
-		remaining -= 4 + int(length)
-	}
+// GetTLVParams returns the optional parameters of the PDU
+func (q *QueryBroadcastSM) GetTLVParams() *TLVList {
+	return &q.TLVParams
+}
 
-	return nil
+// GetResponse creates the query_broadcast_sm_resp answering this PDU
+func (q *QueryBroadcastSM) GetResponse() PDU {
+	resp := NewQueryBroadcastSMResp()
+	resp.Header = responseHeader(q.Header, QUERY_BROADCAST_SM_RESP)
+	return resp
 }
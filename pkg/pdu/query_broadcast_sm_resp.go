@@ -1,113 +1,101 @@
 package pdu
 
-import (
-	"encoding/binary"
-	"errors"
-)
-
 // QueryBroadcastSMResp represents an SMPP query_broadcast_sm_resp PDU (SMPP v5.0)
 type QueryBroadcastSMResp struct {
-	Header    *Header
-	MessageID string
-	TLVParams map[uint16]*TLVParam
+	Header    *Header `json:"header"`
+	MessageID string  `json:"message_id"`
+	TLVParams TLVList `json:"tlvs,omitempty"`
 }
 
 // NewQueryBroadcastSMResp creates a new QueryBroadcastSMResp PDU
 func NewQueryBroadcastSMResp() *QueryBroadcastSMResp {
 	return &QueryBroadcastSMResp{
-		Header:    NewHeader(),
-		TLVParams: make(map[uint16]*TLVParam),
+		Header: NewHeader(),
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (q *QueryBroadcastSMResp) Marshal() ([]byte, error) {
-	// Calculate the total length
-	length := 16 // Header length
+// checkFields checks the mandatory fields against their specified sizes
+func (q *QueryBroadcastSMResp) checkFields() error {
+	return firstError(
+		checkCString("message_id", q.MessageID, MESSAGE_ID_MAX_LEN, ESME_RINVMSGID),
+	)
+}
+
+// EncodedLen returns the size of the encoded PDU in bytes
+func (q *QueryBroadcastSMResp) EncodedLen() int {
+	length := HeaderLength
 	length += len(q.MessageID) + 1
+	length += q.TLVParams.EncodedLen()
+	return length
+}
 
-	// Add TLV parameters length
-	for _, tlv := range q.TLVParams {
-		length += 4 + len(tlv.Value) // Tag(2) + Length(2) + Value(n)
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (q *QueryBroadcastSMResp) AppendTo(dst []byte) ([]byte, error) {
+	if err := q.checkFields(); err != nil {
+		return dst, err
 	}
 
-	// Set command length in header
-	q.Header.CommandLength = uint32(length)
+	q.Header.CommandLength = uint32(q.EncodedLen())
 	q.Header.CommandID = QUERY_BROADCAST_SM_RESP
 
-	// Create the byte slice
-	buf := make([]byte, length)
-
-	// Marshal header
-	headerBytes, err := q.Header.Marshal()
-	if err != nil {
-		return nil, err
-	}
-	copy(buf[0:], headerBytes)
-
-	offset := 16
-
-	// Write message_id
-	copy(buf[offset:], q.MessageID)
-	offset += len(q.MessageID) + 1
-
-	// Write TLV parameters
-	for _, tlv := range q.TLVParams {
-		tlvBytes, err := tlv.Marshal()
-		if err != nil {
-			return nil, err
-		}
-		copy(buf[offset:], tlvBytes)
-		offset += 4 + len(tlv.Value)
-	}
+	dst = q.Header.AppendTo(dst)
+	dst = appendCString(dst, q.MessageID)
+	dst = q.TLVParams.AppendTo(dst)
+	return dst, nil
+}
 
-	return buf, nil
+// Marshal serializes the PDU into bytes
+func (q *QueryBroadcastSMResp) Marshal() ([]byte, error) {
+	return q.AppendTo(make([]byte, 0, q.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (q *QueryBroadcastSMResp) Unmarshal(data []byte) error {
-	var err error
+	return q.decode(newDecoder(data, false))
+}
 
-	// Unmarshal header
+// decode reads the PDU fields from dec
+func (q *QueryBroadcastSMResp) decode(dec *decoder) error {
 	q.Header = &Header{}
-	if err = q.Header.Unmarshal(data[:16]); err != nil {
-		return err
-	}
+	dec.header(q.Header)
 
-	offset := 16
+	q.MessageID = dec.cstring("message_id", MESSAGE_ID_MAX_LEN, ESME_RINVMSGID)
 
-	// Read message_id
-	if q.MessageID, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+	dec.tlvs(&q.TLVParams)
+	return dec.Err()
+}
 
-	// Read TLV parameters if any remain
-	remaining := int(q.Header.CommandLength) - offset
-	currentOffset := offset
+// CommandID returns the command_id of the PDU
+func (q *QueryBroadcastSMResp) CommandID() uint32 {
+	return QUERY_BROADCAST_SM_RESP
+}
 
-	for remaining > 0 {
-		if remaining < 4 {
-			return errors.New("invalid TLV parameter: insufficient data")
-		}
+// GetHeader returns the PDU header
+func (q *QueryBroadcastSMResp) GetHeader() *Header {
+	return q.Header
+}
 
-		tag := binary.BigEndian.Uint16(data[currentOffset:])
-		length := binary.BigEndian.Uint16(data[currentOffset+2:])
+// String returns a one-line description of the PDU for trace logs
+func (q *QueryBroadcastSMResp) String() string {
+	return FormatPDU(q)
+}
 
-		if remaining < int(4+length) {
-			return errors.New("invalid TLV parameter: insufficient data for value")
-		}
+// MarshalJSON encodes the PDU as JSON
+func (q *QueryBroadcastSMResp) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(q)
+}
 
-		tlv := &TLVParam{
-			Tag:    tag,
-			Length: length,
-			Value:  make([]byte, length),
-		}
-		copy(tlv.Value, data[currentOffset+4:currentOffset+4+int(length)])
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (q *QueryBroadcastSMResp) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, q)
+}
 
-		q.TLVParams[tag] = tlv
-		currentOffset += 4 + int(length)
-		remaining -= 4 + int(length)
-	}
+// GetTLVParams returns the optional parameters of the PDU
+func (q *QueryBroadcastSMResp) GetTLVParams() *TLVList {
+	return &q.TLVParams
+}
 
+// GetResponse returns nil as query_broadcast_sm_resp has no response PDU
+func (q *QueryBroadcastSMResp) GetResponse() PDU {
 	return nil
 }
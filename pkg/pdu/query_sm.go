@@ -1,14 +1,12 @@
 package pdu
 
-import "errors"
-
 // QuerySM represents an SMPP query_sm PDU
 type QuerySM struct {
-	Header        *Header
-	MessageID     string
-	SourceAddrTON uint8
-	SourceAddrNPI uint8
-	SourceAddr    string
+	Header        *Header `json:"header"`
+	MessageID     string  `json:"message_id"`
+	SourceAddrTON uint8   `json:"source_addr_ton"`
+	SourceAddrNPI uint8   `json:"source_addr_npi"`
+	SourceAddr    string  `json:"source_addr"`
 }
 
 // NewQuerySM creates a new QuerySM PDU
@@ -18,83 +16,101 @@ func NewQuerySM() *QuerySM {
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (q *QuerySM) Marshal() ([]byte, error) {
-	// Calculate the total length
-	length := 16 // Header length
-	length += len(q.MessageID) + 1
-	length += 2 // Source addr ton and npi
-	length += len(q.SourceAddr) + 1
-
-	// Set command length in header
-	q.Header.CommandLength = uint32(length)
-	q.Header.CommandID = QUERY_SM
-
-	// Create the byte slice
-	buf := make([]byte, length)
+// Source returns source_addr with its TON and NPI
+func (q *QuerySM) Source() Address {
+	return Address{TON: q.SourceAddrTON, NPI: q.SourceAddrNPI, Addr: q.SourceAddr}
+}
 
-	// Marshal header
-	headerBytes, err := q.Header.Marshal()
-	if err != nil {
-		return nil, err
-	}
-	copy(buf[0:], headerBytes)
+// SetSource sets source_addr with its TON and NPI
+func (q *QuerySM) SetSource(a Address) {
+	q.SourceAddrTON, q.SourceAddrNPI, q.SourceAddr = a.TON, a.NPI, a.Addr
+}
 
-	offset := 16
+// checkFields checks the mandatory fields against their specified sizes
+func (q *QuerySM) checkFields() error {
+	return firstError(
+		checkCString("message_id", q.MessageID, MESSAGE_ID_MAX_LEN, ESME_RINVMSGID),
+		checkCString("source_addr", q.SourceAddr, ADDR_MAX_LEN, ESME_RINVSRCADR),
+	)
+}
 
-	// Write message_id
-	copy(buf[offset:], q.MessageID)
-	offset += len(q.MessageID) + 1
+// EncodedLen returns the size of the encoded PDU in bytes
+func (q *QuerySM) EncodedLen() int {
+	length := HeaderLength
+	length += len(q.MessageID) + 1
+	length += 2 // source_addr_ton, source_addr_npi
+	length += len(q.SourceAddr) + 1
+	return length
+}
 
-	// Write source_addr_ton
-	buf[offset] = q.SourceAddrTON
-	offset++
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (q *QuerySM) AppendTo(dst []byte) ([]byte, error) {
+	if err := q.checkFields(); err != nil {
+		return dst, err
+	}
 
-	// Write source_addr_npi
-	buf[offset] = q.SourceAddrNPI
-	offset++
+	q.Header.CommandLength = uint32(q.EncodedLen())
+	q.Header.CommandID = QUERY_SM
 
-	// Write source_addr
-	copy(buf[offset:], q.SourceAddr)
-	offset += len(q.SourceAddr) + 1
+	dst = q.Header.AppendTo(dst)
+	dst = appendCString(dst, q.MessageID)
+	dst = append(dst, q.SourceAddrTON, q.SourceAddrNPI)
+	dst = appendCString(dst, q.SourceAddr)
+	return dst, nil
+}
 
-	return buf, nil
+// Marshal serializes the PDU into bytes
+func (q *QuerySM) Marshal() ([]byte, error) {
+	return q.AppendTo(make([]byte, 0, q.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (q *QuerySM) Unmarshal(data []byte) error {
-	var err error
+	return q.decode(newDecoder(data, false))
+}
 
-	// Unmarshal header
+// decode reads the PDU fields from dec
+func (q *QuerySM) decode(dec *decoder) error {
 	q.Header = &Header{}
-	if err = q.Header.Unmarshal(data[:16]); err != nil {
-		return err
-	}
+	dec.header(q.Header)
 
-	offset := 16
+	q.MessageID = dec.cstring("message_id", MESSAGE_ID_MAX_LEN, ESME_RINVMSGID)
+	q.SourceAddrTON = dec.uint8("source_addr_ton")
+	q.SourceAddrNPI = dec.uint8("source_addr_npi")
+	q.SourceAddr = dec.cstring("source_addr", ADDR_MAX_LEN, ESME_RINVSRCADR)
 
-	// Read message_id
-	if q.MessageID, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+	dec.end()
+	return dec.Err()
+}
 
-	// Read source_addr_ton
-	q.SourceAddrTON = data[offset]
-	offset++
+// CommandID returns the command_id of the PDU
+func (q *QuerySM) CommandID() uint32 {
+	return QUERY_SM
+}
 
-	// Read source_addr_npi
-	q.SourceAddrNPI = data[offset]
-	offset++
+// GetHeader returns the PDU header
+func (q *QuerySM) GetHeader() *Header {
+	return q.Header
+}
 
-	// Read source_addr
-	if q.SourceAddr, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+// String returns a one-line description of the PDU for trace logs
+func (q *QuerySM) String() string {
+	return FormatPDU(q)
+}
 
-	// Verify we've read all the data
-	if offset != int(q.Header.CommandLength) {
-		return errors.New("invalid PDU length")
-	}
+// MarshalJSON encodes the PDU as JSON
+func (q *QuerySM) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(q)
+}
+
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (q *QuerySM) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, q)
+}
 
-	return nil
+// GetResponse creates the query_sm_resp answering this PDU
+func (q *QuerySM) GetResponse() PDU {
+	resp := NewQuerySMResp()
+	resp.Header = responseHeader(q.Header, QUERY_SM_RESP)
+	return resp
 }
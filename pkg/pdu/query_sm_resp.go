@@ -1,131 +1,128 @@
 package pdu
 
-import "errors"
+import "time"
 
 // QuerySMResp represents an SMPP query_sm_resp PDU
 type QuerySMResp struct {
-	Header       *Header
-	MessageID    string
-	FinalDate    string
-	MessageState uint8
-	ErrorCode    uint8
-	TLVParams    map[uint16]*TLVParam
+	Header       *Header `json:"header"`
+	MessageID    string  `json:"message_id"`
+	FinalDate    string  `json:"final_date"`
+	MessageState uint8   `json:"message_state"`
+	ErrorCode    uint8   `json:"error_code"`
+	TLVParams    TLVList `json:"tlvs,omitempty"`
 }
 
 // NewQuerySMResp creates a new QuerySMResp PDU
 func NewQuerySMResp() *QuerySMResp {
 	return &QuerySMResp{
-		Header:    NewHeader(),
-		TLVParams: make(map[uint16]*TLVParam),
+		Header: NewHeader(),
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (q *QuerySMResp) Marshal() ([]byte, error) {
-	// Calculate the total length
-	length := 16 // Header length
+// SetFinalDate sets the final_date, the zero time clears it
+func (q *QuerySMResp) SetFinalDate(t time.Time) {
+	q.FinalDate = FormatAbsoluteTime(t)
+}
+
+// FinalTime returns the final_date, or the zero time when the message has
+// not reached a final state
+func (q *QuerySMResp) FinalTime() (time.Time, error) {
+	if q.FinalDate == "" {
+		return time.Time{}, nil
+	}
+	return ParseAbsoluteTime(q.FinalDate)
+}
+
+// checkFields checks the mandatory fields against their specified sizes
+func (q *QuerySMResp) checkFields() error {
+	return firstError(
+		checkCString("message_id", q.MessageID, MESSAGE_ID_MAX_LEN, ESME_RINVMSGID),
+		checkCString("final_date", q.FinalDate, TIME_MAX_LEN, ESME_RQUERYFAIL),
+	)
+}
+
+// EncodedLen returns the size of the encoded PDU in bytes
+func (q *QuerySMResp) EncodedLen() int {
+	length := HeaderLength
 	length += len(q.MessageID) + 1
 	length += len(q.FinalDate) + 1
-	length += 2 // Message state and error code
+	length += 2 // message_state, error_code
+	length += q.TLVParams.EncodedLen()
+	return length
+}
 
-	// Add TLV parameters length
-	for _, tlv := range q.TLVParams {
-		length += 4 + len(tlv.Value) // Tag(2) + Length(2) + Value(n)
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (q *QuerySMResp) AppendTo(dst []byte) ([]byte, error) {
+	if err := q.checkFields(); err != nil {
+		return dst, err
 	}
 
-	// Set command length in header
-	q.Header.CommandLength = uint32(length)
+	q.Header.CommandLength = uint32(q.EncodedLen())
 	q.Header.CommandID = QUERY_SM_RESP
 
-	// Create the byte slice
-	buf := make([]byte, length)
-
-	// Marshal header
-	headerBytes, err := q.Header.Marshal()
-	if err != nil {
-		return nil, err
-	}
-	copy(buf[0:], headerBytes)
-
-	offset := 16
-
-	// Write message_id
-	copy(buf[offset:], q.MessageID)
-	offset += len(q.MessageID) + 1
-
-	// Write final_date
-	copy(buf[offset:], q.FinalDate)
-	offset += len(q.FinalDate) + 1
-
-	// Write message_state
-	buf[offset] = q.MessageState
-	offset++
-
-	// Write error_code
-	buf[offset] = q.ErrorCode
-	offset++
-
-	// Write TLV parameters
-	for _, tlv := range q.TLVParams {
-		tlvBytes, err := tlv.Marshal()
-		if err != nil {
-			return nil, err
-		}
-		copy(buf[offset:], tlvBytes)
-		offset += 4 + len(tlv.Value)
-	}
+	dst = q.Header.AppendTo(dst)
+	dst = appendCString(dst, q.MessageID)
+	dst = appendCString(dst, q.FinalDate)
+	dst = append(dst, q.MessageState, q.ErrorCode)
+	dst = q.TLVParams.AppendTo(dst)
+	return dst, nil
+}
 
-	return buf, nil
+// Marshal serializes the PDU into bytes
+func (q *QuerySMResp) Marshal() ([]byte, error) {
+	return q.AppendTo(make([]byte, 0, q.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (q *QuerySMResp) Unmarshal(data []byte) error {
-	var err error
+	return q.decode(newDecoder(data, false))
+}
 
-	// Unmarshal header
+// decode reads the PDU fields from dec
+func (q *QuerySMResp) decode(dec *decoder) error {
 	q.Header = &Header{}
-	if err = q.Header.Unmarshal(data[:16]); err != nil {
-		return err
-	}
+	dec.header(q.Header)
 
-	offset := 16
+	q.MessageID = dec.cstring("message_id", MESSAGE_ID_MAX_LEN, ESME_RINVMSGID)
+	q.FinalDate = dec.cstring("final_date", TIME_MAX_LEN, ESME_RQUERYFAIL)
+	q.MessageState = dec.uint8("message_state")
+	q.ErrorCode = dec.uint8("error_code")
 
-	// Read message_id
-	if q.MessageID, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
-
-	// Read final_date
-	if q.FinalDate, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+	dec.tlvs(&q.TLVParams)
+	return dec.Err()
+}
 
-	// Read message_state
-	q.MessageState = data[offset]
-	offset++
+// CommandID returns the command_id of the PDU
+func (q *QuerySMResp) CommandID() uint32 {
+	return QUERY_SM_RESP
+}
 
-	// Read error_code
-	q.ErrorCode = data[offset]
-	offset++
+// GetHeader returns the PDU header
+func (q *QuerySMResp) GetHeader() *Header {
+	return q.Header
+}
 
-	// Read TLV parameters if any remain
-	remaining := int(q.Header.CommandLength) - offset
-	currentOffset := offset
+// String returns a one-line description of the PDU for trace logs
+func (q *QuerySMResp) String() string {
+	return FormatPDU(q)
+}
 
-	for remaining > 0 {
-		if remaining < 4 {
-			return errors.New("invalid TLV parameter: insufficient data")
-		}
+// MarshalJSON encodes the PDU as JSON
+func (q *QuerySMResp) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(q)
+}
 
-		tlv := &TLVParam{}
-		if err := tlv.Unmarshal(data[currentOffset:]); err != nil {
-			return err
-		}
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (q *QuerySMResp) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, q)
+}
 
-		q.TLVParams[tlv.Tag] = tlv
-		currentOffset += 4 + int(tlv.Length)
-		remaining -= 4 + int(tlv.Length)
-	}
+// GetTLVParams returns the optional parameters of the PDU
+func (q *QuerySMResp) GetTLVParams() *TLVList {
+	return &q.TLVParams
+}
 
+// GetResponse returns nil as query_sm_resp has no response PDU
+func (q *QuerySMResp) GetResponse() PDU {
 	return nil
 }
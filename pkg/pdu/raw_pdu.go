@@ -0,0 +1,114 @@
+package pdu
+
+import (
+	"encoding/hex"
+	"encoding/json"
+)
+
+// Octets is an octet string shown in hex in trace logs and JSON
+type Octets []byte
+
+// String returns the octets in hex
+func (o Octets) String() string {
+	return hex.EncodeToString(o)
+}
+
+// MarshalJSON encodes the octets as a hex string
+func (o Octets) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(o))
+}
+
+// UnmarshalJSON decodes a hex string
+func (o *Octets) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	*o = b
+	return nil
+}
+
+// RawPDU is a PDU whose body is kept as octets. It stands in for vendor
+// commands without a type of their own, so that they can be logged and
+// passed through unchanged.
+type RawPDU struct {
+	Header *Header `json:"header"`
+	Body   Octets  `json:"body"` // Everything following the header
+}
+
+// NewRawPDU creates a new RawPDU for a command ID
+func NewRawPDU(commandID uint32) *RawPDU {
+	h := NewHeader()
+	h.CommandID = commandID
+	return &RawPDU{
+		Header: h,
+	}
+}
+
+// EncodedLen returns the size of the encoded PDU in bytes
+func (r *RawPDU) EncodedLen() int {
+	return HeaderLength + len(r.Body)
+}
+
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (r *RawPDU) AppendTo(dst []byte) ([]byte, error) {
+	r.Header.CommandLength = uint32(r.EncodedLen())
+
+	dst = r.Header.AppendTo(dst)
+	return append(dst, r.Body...), nil
+}
+
+// Marshal serializes the PDU into bytes
+func (r *RawPDU) Marshal() ([]byte, error) {
+	return r.AppendTo(make([]byte, 0, r.EncodedLen()))
+}
+
+// Unmarshal deserializes the PDU from bytes
+func (r *RawPDU) Unmarshal(data []byte) error {
+	return r.decode(newDecoder(data, false))
+}
+
+// decode reads the PDU fields from dec
+func (r *RawPDU) decode(dec *decoder) error {
+	r.Header = &Header{}
+	dec.header(r.Header)
+
+	n := dec.remaining()
+	r.Body = dec.octets("body", n, n, ESME_RINVCMDLEN)
+	return dec.Err()
+}
+
+// CommandID returns the command_id of the PDU
+func (r *RawPDU) CommandID() uint32 {
+	return r.Header.CommandID
+}
+
+// GetHeader returns the PDU header
+func (r *RawPDU) GetHeader() *Header {
+	return r.Header
+}
+
+// String returns a one-line description of the PDU for trace logs
+func (r *RawPDU) String() string {
+	return FormatPDU(r)
+}
+
+// MarshalJSON encodes the PDU as JSON
+func (r *RawPDU) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(r)
+}
+
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (r *RawPDU) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, r)
+}
+
+// GetResponse returns nil as the body of a vendor response is unknown;
+// the session answers with generic_nack unless a handler responds
+func (r *RawPDU) GetResponse() PDU {
+	return nil
+}
@@ -0,0 +1,408 @@
+package pdu
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotDeliveryReceipt = errors.New("pdu is not a delivery receipt")
+)
+
+// Receipt stat values of the SMPP v3.4 Appendix B text format
+const (
+	RECEIPT_STAT_ENROUTE       = "ENROUTE"
+	RECEIPT_STAT_DELIVERED     = "DELIVRD"
+	RECEIPT_STAT_EXPIRED       = "EXPIRED"
+	RECEIPT_STAT_DELETED       = "DELETED"
+	RECEIPT_STAT_UNDELIVERABLE = "UNDELIV"
+	RECEIPT_STAT_ACCEPTED      = "ACCEPTD"
+	RECEIPT_STAT_UNKNOWN       = "UNKNOWN"
+	RECEIPT_STAT_REJECTED      = "REJECTD"
+	RECEIPT_STAT_SKIPPED       = "SKIPPED"
+)
+
+// RECEIPT_DATE_FORMAT is the YYMMDDhhmm date layout of the receipt text
+const RECEIPT_DATE_FORMAT = "0601021504"
+
+// RECEIPT_TEXT_LENGTH is the number of message characters quoted in the
+// text field of a receipt
+const RECEIPT_TEXT_LENGTH = 20
+
+var receiptStats = map[uint32]string{
+	SMPP_34_MESSAGE_STATE_ENROUTE:       RECEIPT_STAT_ENROUTE,
+	SMPP_34_MESSAGE_STATE_DELIVERED:     RECEIPT_STAT_DELIVERED,
+	SMPP_34_MESSAGE_STATE_EXPIRED:       RECEIPT_STAT_EXPIRED,
+	SMPP_34_MESSAGE_STATE_DELETED:       RECEIPT_STAT_DELETED,
+	SMPP_34_MESSAGE_STATE_UNDELIVERABLE: RECEIPT_STAT_UNDELIVERABLE,
+	SMPP_34_MESSAGE_STATE_ACCEPTED:      RECEIPT_STAT_ACCEPTED,
+	SMPP_34_MESSAGE_STATE_UNKNOWN:       RECEIPT_STAT_UNKNOWN,
+	SMPP_34_MESSAGE_STATE_REJECTED:      RECEIPT_STAT_REJECTED,
+	SMPP_50_MESSAGE_STATE_SKIPPED:       RECEIPT_STAT_SKIPPED,
+}
+
+// ReceiptStat returns the receipt stat value of a message state
+func ReceiptStat(state uint32) string {
+	if stat, ok := receiptStats[state]; ok {
+		return stat
+	}
+	return RECEIPT_STAT_UNKNOWN
+}
+
+// ParseReceiptStat returns the message state of a receipt stat value. It
+// accepts the abbreviated Appendix B values as well as spelled out and
+// lowercase vendor variants such as "delivered" or "FAILED".
+func ParseReceiptStat(stat string) (uint32, bool) {
+	stat = strings.ToUpper(strings.TrimSpace(stat))
+	switch {
+	case strings.HasPrefix(stat, "UNDELIV"), strings.HasPrefix(stat, "FAIL"):
+		return SMPP_34_MESSAGE_STATE_UNDELIVERABLE, true
+	case strings.HasPrefix(stat, "DELIV"):
+		return SMPP_34_MESSAGE_STATE_DELIVERED, true
+	case strings.HasPrefix(stat, "ENROUTE"), strings.HasPrefix(stat, "BUFFERED"):
+		return SMPP_34_MESSAGE_STATE_ENROUTE, true
+	case strings.HasPrefix(stat, "EXPIRE"):
+		return SMPP_34_MESSAGE_STATE_EXPIRED, true
+	case strings.HasPrefix(stat, "DELETE"):
+		return SMPP_34_MESSAGE_STATE_DELETED, true
+	case strings.HasPrefix(stat, "ACCEPT"):
+		return SMPP_34_MESSAGE_STATE_ACCEPTED, true
+	case strings.HasPrefix(stat, "REJECT"):
+		return SMPP_34_MESSAGE_STATE_REJECTED, true
+	case strings.HasPrefix(stat, "SKIP"):
+		return SMPP_50_MESSAGE_STATE_SKIPPED, true
+	case strings.HasPrefix(stat, "UNKNOWN"):
+		return SMPP_34_MESSAGE_STATE_UNKNOWN, true
+	}
+	return SMPP_34_MESSAGE_STATE_UNKNOWN, false
+}
+
+// IsFinalState checks if a message state is final, i.e. no further
+// receipt will follow it
+func IsFinalState(state uint32) bool {
+	switch state {
+	case SMPP_34_MESSAGE_STATE_DELIVERED,
+		SMPP_34_MESSAGE_STATE_EXPIRED,
+		SMPP_34_MESSAGE_STATE_DELETED,
+		SMPP_34_MESSAGE_STATE_UNDELIVERABLE,
+		SMPP_34_MESSAGE_STATE_REJECTED,
+		SMPP_50_MESSAGE_STATE_SKIPPED:
+		return true
+	}
+	return false
+}
+
+// DeliveryReceipt is an SMSC delivery receipt, combining the Appendix B
+// short message text with the receipt TLVs
+type DeliveryReceipt struct {
+	MessageID  string
+	Submitted  int // Number of messages originally submitted (sub)
+	Delivered  int // Number of messages delivered (dlvrd)
+	SubmitDate time.Time
+	DoneDate   time.Time
+	Stat       string // Stat value as found in or written to the text
+	State      uint32 // SMPP_34_MESSAGE_STATE_* value
+	Err        string // Network or SMSC specific error code
+	Text       string // First characters of the original message
+
+	// network_error_code TLV
+	NetworkType      uint8
+	NetworkErrorCode uint16
+	HasNetworkError  bool
+}
+
+// NewDeliveryReceipt creates a receipt for a message in the given state
+// completed now
+func NewDeliveryReceipt(messageID string, state uint32) *DeliveryReceipt {
+	now := time.Now().UTC()
+	r := &DeliveryReceipt{
+		MessageID:  messageID,
+		Submitted:  1,
+		SubmitDate: now,
+		DoneDate:   now,
+		Stat:       ReceiptStat(state),
+		State:      state,
+		Err:        "000",
+	}
+	if state == SMPP_34_MESSAGE_STATE_DELIVERED {
+		r.Delivered = 1
+	}
+	return r
+}
+
+// SetNetworkError sets the network_error_code and the err field from it
+func (r *DeliveryReceipt) SetNetworkError(networkType uint8, code uint16) {
+	r.NetworkType = networkType
+	r.NetworkErrorCode = code
+	r.HasNetworkError = true
+	r.Err = fmt.Sprintf("%03d", code)
+}
+
+// IsFinal checks if the receipt reports a final message state
+func (r *DeliveryReceipt) IsFinal() bool {
+	return IsFinalState(r.State)
+}
+
+// String formats the receipt text as described in SMPP v3.4 Appendix B
+func (r *DeliveryReceipt) String() string {
+	stat := r.Stat
+	if stat == "" {
+		stat = ReceiptStat(r.State)
+	}
+	errCode := r.Err
+	if errCode == "" {
+		errCode = "000"
+	}
+	text := []rune(r.Text)
+	if len(text) > RECEIPT_TEXT_LENGTH {
+		text = text[:RECEIPT_TEXT_LENGTH]
+	}
+	return fmt.Sprintf("id:%s sub:%03d dlvrd:%03d submit date:%s done date:%s stat:%s err:%s text:%s",
+		r.MessageID, r.Submitted, r.Delivered,
+		formatReceiptDate(r.SubmitDate), formatReceiptDate(r.DoneDate),
+		stat, errCode, string(text))
+}
+
+// formatReceiptDate formats a receipt date, leaving it empty when unset
+func formatReceiptDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(RECEIPT_DATE_FORMAT)
+}
+
+// receiptField matches the keys of the receipt text, allowing the
+// underscore and run-together spellings used by some vendors. Other keys,
+// such as an imsi added by some SMSCs, end the value before them.
+var receiptField = regexp.MustCompile(`(?i)(?:^|\s)(id|sub|dlvrd|submit[ _]?date|done[ _]?date|stat|err|text|[a-z][a-z0-9_]*)\s*:`)
+
+// ParseDeliveryReceipt parses the Appendix B receipt text. Keys are matched
+// case insensitively and unknown ones are skipped, dates may have 10
+// (YYMMDDhhmm), 12 (YYMMDDhhmmss) or 14 (YYYYMMDDhhmmss) digits and are
+// read as UTC, and the text field, when present, takes the rest of the
+// message.
+func ParseDeliveryReceipt(text string) (*DeliveryReceipt, error) {
+	matches := receiptField.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: no receipt fields in %q", ErrNotDeliveryReceipt, text)
+	}
+
+	r := &DeliveryReceipt{}
+	for i, m := range matches {
+		key := strings.ToLower(strings.NewReplacer(" ", "", "_", "").Replace(text[m[2]:m[3]]))
+		end := len(text)
+		if i+1 < len(matches) && key != "text" {
+			end = matches[i+1][0]
+		}
+		value := strings.TrimSpace(text[m[1]:end])
+
+		var err error
+		switch key {
+		case "id":
+			r.MessageID = value
+		case "sub":
+			r.Submitted, err = parseReceiptCount(value)
+		case "dlvrd":
+			r.Delivered, err = parseReceiptCount(value)
+		case "submitdate":
+			r.SubmitDate, err = parseReceiptDate(value)
+		case "donedate":
+			r.DoneDate, err = parseReceiptDate(value)
+		case "stat":
+			r.Stat = value
+			r.State, _ = ParseReceiptStat(value)
+		case "err":
+			r.Err = value
+		case "text":
+			r.Text = strings.TrimLeft(text[m[1]:], " ")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid receipt field %s: %w", key, err)
+		}
+		if key == "text" {
+			break
+		}
+	}
+
+	if r.MessageID == "" {
+		return nil, fmt.Errorf("%w: missing id in %q", ErrNotDeliveryReceipt, text)
+	}
+	return r, nil
+}
+
+// parseReceiptCount parses the sub and dlvrd counters, which may be empty
+func parseReceiptCount(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// parseReceiptDate parses a receipt date of 10, 12 or 14 digits
+func parseReceiptDate(value string) (time.Time, error) {
+	switch len(value) {
+	case 0:
+		return time.Time{}, nil
+	case 10:
+		return time.ParseInLocation(RECEIPT_DATE_FORMAT, value, time.UTC)
+	case 12:
+		return time.ParseInLocation("060102150405", value, time.UTC)
+	case 14:
+		return time.ParseInLocation("20060102150405", value, time.UTC)
+	}
+	return time.Time{}, fmt.Errorf("unexpected date %q", value)
+}
+
+// applyTLVs completes the receipt with the receipt TLVs, which take
+// precedence over the text
+func (r *DeliveryReceipt) applyTLVs(params TLVList) {
+	if id, ok := params.ReceiptedMessageID(); ok && id != "" {
+		r.MessageID = id
+	}
+	if state, ok := params.MessageState(); ok {
+		r.State = uint32(state)
+		if r.Stat == "" {
+			r.Stat = ReceiptStat(r.State)
+		}
+	}
+	if netType, code, ok := params.NetworkErrorCode(); ok {
+		r.NetworkType = netType
+		r.NetworkErrorCode = code
+		r.HasNetworkError = true
+	}
+}
+
+// setTLVs adds the receipt TLVs to params
+func (r *DeliveryReceipt) setTLVs(params *TLVList) {
+	params.SetReceiptedMessageID(r.MessageID)
+	params.SetMessageState(uint8(r.State))
+	if r.HasNetworkError {
+		params.SetNetworkErrorCode(r.NetworkType, r.NetworkErrorCode)
+	}
+}
+
+// receiptFromPDU builds a receipt out of the text and TLVs of a deliver_sm
+// or data_sm. The text is optional when the TLVs identify the message.
+func receiptFromPDU(text string, textErr error, params TLVList) (*DeliveryReceipt, error) {
+	r, err := ParseDeliveryReceipt(text)
+	if textErr != nil || err != nil {
+		if !params.Has(TLV_RECEIPTED_MESSAGE_ID) {
+			if textErr != nil {
+				return nil, textErr
+			}
+			return nil, err
+		}
+		r = &DeliveryReceipt{}
+	}
+	r.applyTLVs(params)
+	return r, nil
+}
+
+// isReceiptESMClass checks if esm_class marks a delivery receipt or an
+// intermediate delivery notification
+func isReceiptESMClass(esmClass uint8) bool {
+	switch esmClass & ESM_CLASS_TYPE_MASK {
+	case ESM_CLASS_TYPE_DELIVERY_RECEIPT, ESM_CLASS_TYPE_INTERMEDIATE_NOTIFICATION:
+		return true
+	}
+	return false
+}
+
+// DeliveryReceipt parses the delivery receipt carried by the PDU
+func (d *DeliverSM) DeliveryReceipt() (*DeliveryReceipt, error) {
+	if !isReceiptESMClass(d.ESMClass) {
+		return nil, ErrNotDeliveryReceipt
+	}
+	text, err := d.MessageText()
+	return receiptFromPDU(text, err, d.TLVParams)
+}
+
+// IsDeliveryReceipt checks if this PDU is a delivery receipt or an
+// intermediate delivery notification
+func (d *DataSM) IsDeliveryReceipt() bool {
+	return isReceiptESMClass(d.ESMClass)
+}
+
+// DeliveryReceipt parses the delivery receipt carried by the PDU
+func (d *DataSM) DeliveryReceipt() (*DeliveryReceipt, error) {
+	if !isReceiptESMClass(d.ESMClass) {
+		return nil, ErrNotDeliveryReceipt
+	}
+	text, err := d.MessageText()
+	return receiptFromPDU(text, err, d.TLVParams)
+}
+
+// Receipts are written in the GSM default alphabet, or UCS2 when the quoted
+// text needs it, without national language shift tables
+var receiptTextOptions = &TextOptions{Languages: []uint8{}}
+
+// DeliverSM builds the deliver_sm carrying this receipt back to the sender
+// of orig. The receipt goes from the original destination to the original
+// source.
+func (r *DeliveryReceipt) DeliverSM(orig *SubmitSM) (*DeliverSM, error) {
+	d := NewDeliverSM()
+	d.ServiceType = orig.ServiceType
+	d.SourceAddrTON = orig.DestAddrTON
+	d.SourceAddrNPI = orig.DestAddrNPI
+	d.SourceAddr = orig.DestinationAddr
+	d.DestAddrTON = orig.SourceAddrTON
+	d.DestAddrNPI = orig.SourceAddrNPI
+	d.DestinationAddr = orig.SourceAddr
+	d.ESMClass = ESM_CLASS_TYPE_DELIVERY_RECEIPT
+	if !r.IsFinal() {
+		d.ESMClass = ESM_CLASS_TYPE_INTERMEDIATE_NOTIFICATION
+	}
+
+	if _, err := d.SetText(r.String(), receiptTextOptions); err != nil {
+		return nil, err
+	}
+	r.setTLVs(&d.TLVParams)
+	return d, nil
+}
+
+// DataSM builds the data_sm carrying this receipt back to the sender of
+// orig, with the receipt text in message_payload
+func (r *DeliveryReceipt) DataSM(orig *SubmitSM) (*DataSM, error) {
+	d := NewDataSM()
+	d.ServiceType = orig.ServiceType
+	d.SourceAddrTON = orig.DestAddrTON
+	d.SourceAddrNPI = orig.DestAddrNPI
+	d.SourceAddr = orig.DestinationAddr
+	d.DestAddrTON = orig.SourceAddrTON
+	d.DestAddrNPI = orig.SourceAddrNPI
+	d.DestinationAddr = orig.SourceAddr
+	d.ESMClass = ESM_CLASS_TYPE_DELIVERY_RECEIPT
+	if !r.IsFinal() {
+		d.ESMClass = ESM_CLASS_TYPE_INTERMEDIATE_NOTIFICATION
+	}
+
+	encoded := SelectTextEncoding(r.String(), receiptTextOptions)
+	d.DataCoding = encoded.DataCoding
+	d.TLVParams.SetMessagePayload(encoded.Data)
+	r.setTLVs(&d.TLVParams)
+	return d, nil
+}
+
+// SameMessageID compares message IDs that may be written in decimal by one
+// side and hexadecimal by the other, as commonly seen between the
+// submit_sm_resp and the receipt of the same message
+func SameMessageID(a, b string) bool {
+	if strings.EqualFold(a, b) {
+		return true
+	}
+	if da, err := strconv.ParseUint(a, 10, 64); err == nil {
+		if hb, err := strconv.ParseUint(b, 16, 64); err == nil && da == hb {
+			return true
+		}
+	}
+	if db, err := strconv.ParseUint(b, 10, 64); err == nil {
+		if ha, err := strconv.ParseUint(a, 16, 64); err == nil && db == ha {
+			return true
+		}
+	}
+	return false
+}
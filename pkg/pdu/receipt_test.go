@@ -0,0 +1,303 @@
+package pdu
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseDeliveryReceipt(t *testing.T) {
+	submitted := time.Date(2024, 1, 2, 15, 4, 0, 0, time.UTC)
+	done := time.Date(2024, 1, 2, 15, 5, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		text string
+		want DeliveryReceipt
+	}{
+		{
+			"appendix b",
+			"id:1234567890 sub:001 dlvrd:001 submit date:2401021504 done date:2401021505 stat:DELIVRD err:000 text:Hello world",
+			DeliveryReceipt{MessageID: "1234567890", Submitted: 1, Delivered: 1, SubmitDate: submitted, DoneDate: done,
+				Stat: "DELIVRD", State: SMPP_34_MESSAGE_STATE_DELIVERED, Err: "000", Text: "Hello world"},
+		},
+		{
+			"12 digit dates",
+			"id:1 sub:001 dlvrd:000 submit date:240102150407 done date:240102150512 stat:UNDELIV err:001 text:",
+			DeliveryReceipt{MessageID: "1", Submitted: 1, SubmitDate: submitted.Add(7 * time.Second), DoneDate: done.Add(12 * time.Second),
+				Stat: "UNDELIV", State: SMPP_34_MESSAGE_STATE_UNDELIVERABLE, Err: "001"},
+		},
+		{
+			"14 digit dates",
+			"id:1 sub:001 dlvrd:001 submit date:20240102150407 done date:20240102150512 stat:DELIVRD err:000",
+			DeliveryReceipt{MessageID: "1", Submitted: 1, Delivered: 1, SubmitDate: submitted.Add(7 * time.Second), DoneDate: done.Add(12 * time.Second),
+				Stat: "DELIVRD", State: SMPP_34_MESSAGE_STATE_DELIVERED, Err: "000"},
+		},
+		{
+			"uppercase keys and vendor stat",
+			"ID:abc SUB:1 DLVRD:1 SUBMIT DATE:2401021504 DONE DATE:2401021505 STAT:delivered ERR:0 TEXT:x",
+			DeliveryReceipt{MessageID: "abc", Submitted: 1, Delivered: 1, SubmitDate: submitted, DoneDate: done,
+				Stat: "delivered", State: SMPP_34_MESSAGE_STATE_DELIVERED, Err: "0", Text: "x"},
+		},
+		{
+			"underscore and run-together keys",
+			"id:abc submit_date:2401021504 donedate:2401021505 stat:EXPIRED",
+			DeliveryReceipt{MessageID: "abc", SubmitDate: submitted, DoneDate: done,
+				Stat: "EXPIRED", State: SMPP_34_MESSAGE_STATE_EXPIRED},
+		},
+		{
+			"missing fields",
+			"id:abc stat:REJECTD",
+			DeliveryReceipt{MessageID: "abc", Stat: "REJECTD", State: SMPP_34_MESSAGE_STATE_REJECTED},
+		},
+		{
+			"empty fields",
+			"id:abc sub: dlvrd: submit date: done date: stat:ENROUTE err:",
+			DeliveryReceipt{MessageID: "abc", Stat: "ENROUTE", State: SMPP_34_MESSAGE_STATE_ENROUTE},
+		},
+		{
+			"extra fields",
+			"id:abc sub:001 dlvrd:001 submit date:2401021504 done date:2401021505 stat:DELIVRD err:000 imsi:234150000000001 mccmnc:23415 text:Hi",
+			DeliveryReceipt{MessageID: "abc", Submitted: 1, Delivered: 1, SubmitDate: submitted, DoneDate: done,
+				Stat: "DELIVRD", State: SMPP_34_MESSAGE_STATE_DELIVERED, Err: "000", Text: "Hi"},
+		},
+		{
+			"text takes the rest",
+			"id:abc stat:DELIVRD text:code: 1234 stat:x",
+			DeliveryReceipt{MessageID: "abc", Stat: "DELIVRD", State: SMPP_34_MESSAGE_STATE_DELIVERED, Text: "code: 1234 stat:x"},
+		},
+		{
+			"unknown stat",
+			"id:abc stat:WHATEVER",
+			DeliveryReceipt{MessageID: "abc", Stat: "WHATEVER", State: SMPP_34_MESSAGE_STATE_UNKNOWN},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseDeliveryReceipt(tt.text)
+			if err != nil {
+				t.Fatalf("ParseDeliveryReceipt: %v", err)
+			}
+			if *r != tt.want {
+				t.Errorf("ParseDeliveryReceipt =\n%+v\nwant\n%+v", *r, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDeliveryReceiptInvalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		notAReceipt bool
+	}{
+		{"plain text", "Hello world", true},
+		{"missing id", "sub:001 dlvrd:001 stat:DELIVRD", true},
+		{"empty id", "id: sub:001 stat:DELIVRD", true},
+		{"bad date", "id:1 submit date:24010215 stat:DELIVRD", false},
+		{"bad count", "id:1 sub:one stat:DELIVRD", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDeliveryReceipt(tt.text)
+			if err == nil {
+				t.Fatal("ParseDeliveryReceipt succeeded")
+			}
+			if errors.Is(err, ErrNotDeliveryReceipt) != tt.notAReceipt {
+				t.Errorf("ParseDeliveryReceipt error = %v, ErrNotDeliveryReceipt %v", err, tt.notAReceipt)
+			}
+		})
+	}
+}
+
+func TestParseReceiptStat(t *testing.T) {
+	tests := []struct {
+		stat  string
+		state uint32
+		ok    bool
+	}{
+		{"DELIVRD", SMPP_34_MESSAGE_STATE_DELIVERED, true},
+		{"delivered", SMPP_34_MESSAGE_STATE_DELIVERED, true},
+		{" Deliv ", SMPP_34_MESSAGE_STATE_DELIVERED, true},
+		{"UNDELIV", SMPP_34_MESSAGE_STATE_UNDELIVERABLE, true},
+		{"undeliverable", SMPP_34_MESSAGE_STATE_UNDELIVERABLE, true},
+		{"FAILED", SMPP_34_MESSAGE_STATE_UNDELIVERABLE, true},
+		{"ENROUTE", SMPP_34_MESSAGE_STATE_ENROUTE, true},
+		{"buffered", SMPP_34_MESSAGE_STATE_ENROUTE, true},
+		{"EXPIRED", SMPP_34_MESSAGE_STATE_EXPIRED, true},
+		{"DELETED", SMPP_34_MESSAGE_STATE_DELETED, true},
+		{"ACCEPTD", SMPP_34_MESSAGE_STATE_ACCEPTED, true},
+		{"REJECTD", SMPP_34_MESSAGE_STATE_REJECTED, true},
+		{"SKIPPED", SMPP_50_MESSAGE_STATE_SKIPPED, true},
+		{"UNKNOWN", SMPP_34_MESSAGE_STATE_UNKNOWN, true},
+		{"", SMPP_34_MESSAGE_STATE_UNKNOWN, false},
+		{"PENDING", SMPP_34_MESSAGE_STATE_UNKNOWN, false},
+	}
+	for _, tt := range tests {
+		if state, ok := ParseReceiptStat(tt.stat); state != tt.state || ok != tt.ok {
+			t.Errorf("ParseReceiptStat(%q) = %d, %v, want %d, %v", tt.stat, state, ok, tt.state, tt.ok)
+		}
+	}
+}
+
+func TestDeliveryReceiptString(t *testing.T) {
+	r := &DeliveryReceipt{
+		MessageID:  "abc",
+		Submitted:  1,
+		Delivered:  1,
+		SubmitDate: time.Date(2024, 1, 2, 15, 4, 0, 0, time.UTC),
+		DoneDate:   time.Date(2024, 1, 2, 15, 5, 0, 0, time.UTC),
+		State:      SMPP_34_MESSAGE_STATE_DELIVERED,
+		Text:       "The quick brown fox jumps over the lazy dog",
+	}
+	want := "id:abc sub:001 dlvrd:001 submit date:2401021504 done date:2401021505 stat:DELIVRD err:000 text:The quick brown fox "
+	if got := r.String(); got != want {
+		t.Errorf("String =\n%q\nwant\n%q", got, want)
+	}
+
+	parsed, err := ParseDeliveryReceipt(r.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.MessageID != r.MessageID || parsed.State != r.State || !parsed.DoneDate.Equal(r.DoneDate) {
+		t.Errorf("ParseDeliveryReceipt(String) = %+v", parsed)
+	}
+}
+
+func TestSameMessageID(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"123", "123", true},
+		{"abc", "ABC", true},
+		{"123", "7B", true},
+		{"7b", "123", true},
+		{"4294967295", "ffffffff", true},
+		{"0A", "10", true},
+		{"123", "124", false},
+		{"123", "7C", false},
+		{"abc", "abd", false},
+		{"", "0", false},
+		{"xyz", "123", false},
+	}
+	for _, tt := range tests {
+		if got := SameMessageID(tt.a, tt.b); got != tt.same {
+			t.Errorf("SameMessageID(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.same)
+		}
+	}
+}
+
+func TestDeliverSMReceipt(t *testing.T) {
+	receipt := func(text string) *DeliverSM {
+		d := NewDeliverSM()
+		d.ESMClass = ESM_CLASS_TYPE_DELIVERY_RECEIPT
+		d.ShortMessage = []byte(text)
+		d.SMLength = uint8(len(text))
+		return d
+	}
+
+	const gsm = 3 // network_error_code network type
+
+	// TLVs take precedence over the text, the stat of the text is kept
+	d := receipt("id:123 sub:001 dlvrd:000 submit date:2401021504 done date:2401021505 stat:UNDELIV err:000 text:")
+	d.TLVParams.SetReceiptedMessageID("7B")
+	d.TLVParams.SetMessageState(uint8(SMPP_34_MESSAGE_STATE_DELIVERED))
+	d.TLVParams.SetNetworkErrorCode(gsm, 0x22)
+	r, err := d.DeliveryReceipt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.MessageID != "7B" || r.State != SMPP_34_MESSAGE_STATE_DELIVERED || r.Stat != "UNDELIV" {
+		t.Errorf("receipt id %q state %d stat %q, want the TLV id and state", r.MessageID, r.State, r.Stat)
+	}
+	if !r.HasNetworkError || r.NetworkType != gsm || r.NetworkErrorCode != 0x22 {
+		t.Errorf("network error %v %d 0x%X", r.HasNetworkError, r.NetworkType, r.NetworkErrorCode)
+	}
+
+	// TLVs alone identify the receipt
+	d = receipt("")
+	d.TLVParams.SetReceiptedMessageID("abc")
+	d.TLVParams.SetMessageState(uint8(SMPP_34_MESSAGE_STATE_EXPIRED))
+	if r, err = d.DeliveryReceipt(); err != nil {
+		t.Fatal(err)
+	}
+	if r.MessageID != "abc" || r.State != SMPP_34_MESSAGE_STATE_EXPIRED || r.Stat != RECEIPT_STAT_EXPIRED {
+		t.Errorf("receipt %+v, want abc EXPIRED", r)
+	}
+
+	// Without TLVs the text must be a receipt
+	if _, err = receipt("Hello").DeliveryReceipt(); !errors.Is(err, ErrNotDeliveryReceipt) {
+		t.Errorf("DeliveryReceipt error = %v, want ErrNotDeliveryReceipt", err)
+	}
+
+	// Mobile originated messages are no receipts
+	d = receipt("id:1 stat:DELIVRD")
+	d.ESMClass = 0
+	if _, err = d.DeliveryReceipt(); !errors.Is(err, ErrNotDeliveryReceipt) {
+		t.Errorf("DeliveryReceipt error = %v, want ErrNotDeliveryReceipt", err)
+	}
+}
+
+func TestDeliveryReceiptDeliverSM(t *testing.T) {
+	orig := NewSubmitSM()
+	orig.SourceAddr = "Shop"
+	orig.DestinationAddr = "447700900123"
+
+	for _, state := range []uint32{SMPP_34_MESSAGE_STATE_DELIVERED, SMPP_34_MESSAGE_STATE_ENROUTE} {
+		r := NewDeliveryReceipt("abc", state)
+		r.Text = "Your order"
+		d, err := r.DeliverSM(orig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.SourceAddr != orig.DestinationAddr || d.DestinationAddr != orig.SourceAddr {
+			t.Errorf("receipt from %q to %q", d.SourceAddr, d.DestinationAddr)
+		}
+		wantClass := ESM_CLASS_TYPE_DELIVERY_RECEIPT
+		if state == SMPP_34_MESSAGE_STATE_ENROUTE {
+			wantClass = ESM_CLASS_TYPE_INTERMEDIATE_NOTIFICATION
+		}
+		if d.ESMClass != wantClass {
+			t.Errorf("esm_class 0x%02X, want 0x%02X", d.ESMClass, wantClass)
+		}
+
+		parsed, err := d.DeliveryReceipt()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.MessageID != "abc" || parsed.State != state || parsed.Text != "Your order" {
+			t.Errorf("DeliveryReceipt = %+v", parsed)
+		}
+	}
+}
+
+func TestDataSMReceipt(t *testing.T) {
+	tests := []struct {
+		name     string
+		esmClass uint8
+		receipt  bool
+	}{
+		{"default", ESM_CLASS_TYPE_DEFAULT, false},
+		{"delivery receipt", ESM_CLASS_TYPE_DELIVERY_RECEIPT, true},
+		{"intermediate notification", ESM_CLASS_TYPE_INTERMEDIATE_NOTIFICATION, true},
+		{"receipt with udhi", ESM_CLASS_TYPE_DELIVERY_RECEIPT | ESM_CLASS_UDHI, true},
+		{"user ack", ESM_CLASS_TYPE_USER_ACK, false},
+	}
+	for _, tt := range tests {
+		d := NewDataSM()
+		d.ESMClass = tt.esmClass
+		d.TLVParams.SetReceiptedMessageID("abc")
+		d.TLVParams.SetMessageState(uint8(SMPP_34_MESSAGE_STATE_ENROUTE))
+		if got := d.IsDeliveryReceipt(); got != tt.receipt {
+			t.Errorf("%s: IsDeliveryReceipt = %v, want %v", tt.name, got, tt.receipt)
+		}
+		// IsDeliveryReceipt and DeliveryReceipt agree
+		_, err := d.DeliveryReceipt()
+		if tt.receipt && err != nil {
+			t.Errorf("%s: DeliveryReceipt: %v", tt.name, err)
+		}
+		if !tt.receipt && !errors.Is(err, ErrNotDeliveryReceipt) {
+			t.Errorf("%s: DeliveryReceipt error = %v, want ErrNotDeliveryReceipt", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package pdu
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	ErrUnknownCommandID     = NewStatusError(ESME_RINVCMDID, "unknown command id")
+	ErrPDUTooShort          = NewStatusError(ESME_RINVCMDLEN, "pdu is shorter than the header")
+	ErrInvalidCommandLength = NewStatusError(ESME_RINVCMDLEN, "command length does not match pdu size")
+)
+
+// Validator is implemented by PDUs that check their field values after
+// decoding
+type Validator interface {
+	Validate() error
+}
+
+// Factory creates an empty PDU ready to be unmarshaled
+type Factory func() PDU
+
+// Registry maps command IDs to the factories of their PDU types
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[uint32]Factory
+	names     map[uint32]string // Names of vendor commands
+	schema    *TLVSchema
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		factories: make(map[uint32]Factory),
+		names:     make(map[uint32]string),
+		schema:    DefaultTLVSchema,
+	}
+}
+
+// DefaultRegistry holds every PDU type defined in this package
+var DefaultRegistry = newDefaultRegistry()
+
+func newDefaultRegistry() *Registry {
+	r := NewRegistry()
+
+	// Bind operations
+	r.Register(BIND_RECEIVER, func() PDU { return NewBindReceiver() })
+	r.Register(BIND_RECEIVER_RESP, func() PDU { return NewBindReceiverResp() })
+	r.Register(BIND_TRANSMITTER, func() PDU { return NewBindTransmitter() })
+	r.Register(BIND_TRANSMITTER_RESP, func() PDU { return NewBindTransmitterResp() })
+	r.Register(BIND_TRANSCEIVER, func() PDU { return NewBindTransceiver() })
+	r.Register(BIND_TRANSCEIVER_RESP, func() PDU { return NewBindTransceiverResp() })
+	r.Register(OUTBIND, func() PDU { return NewOutbind() })
+	r.Register(UNBIND, func() PDU { return NewUnbind() })
+	r.Register(UNBIND_RESP, func() PDU { return NewUnbindResp() })
+
+	// Messaging operations
+	r.Register(SUBMIT_SM, func() PDU { return NewSubmitSM() })
+	r.Register(SUBMIT_SM_RESP, func() PDU { return NewSubmitSMResp() })
+	r.Register(SUBMIT_MULTI, func() PDU { return NewSubmitMulti() })
+	r.Register(SUBMIT_MULTI_RESP, func() PDU { return NewSubmitMultiResp() })
+	r.Register(DELIVER_SM, func() PDU { return NewDeliverSM() })
+	r.Register(DELIVER_SM_RESP, func() PDU { return NewDeliverSMResp() })
+	r.Register(DATA_SM, func() PDU { return NewDataSM() })
+	r.Register(DATA_SM_RESP, func() PDU { return NewDataSMResp() })
+	r.Register(ALERT_NOTIFICATION, func() PDU { return NewAlertNotification() })
+
+	// Query, cancel and replace operations
+	r.Register(QUERY_SM, func() PDU { return NewQuerySM() })
+	r.Register(QUERY_SM_RESP, func() PDU { return NewQuerySMResp() })
+	r.Register(CANCEL_SM, func() PDU { return NewCancelSM() })
+	r.Register(CANCEL_SM_RESP, func() PDU { return NewCancelSMResp() })
+	r.Register(REPLACE_SM, func() PDU { return NewReplaceSM() })
+	r.Register(REPLACE_SM_RESP, func() PDU { return NewReplaceSMResp() })
+
+	// Broadcast operations (SMPP v5.0)
+	r.Register(BROADCAST_SM, func() PDU { return NewBroadcastSM() })
+	r.Register(BROADCAST_SM_RESP, func() PDU { return NewBroadcastSMResp() })
+	r.Register(QUERY_BROADCAST_SM, func() PDU { return NewQueryBroadcastSM() })
+	r.Register(QUERY_BROADCAST_SM_RESP, func() PDU { return NewQueryBroadcastSMResp() })
+	r.Register(CANCEL_BROADCAST_SM, func() PDU { return NewCancelBroadcastSM() })
+	r.Register(CANCEL_BROADCAST_SM_RESP, func() PDU { return NewCancelBroadcastSMResp() })
+
+	// Connection management
+	r.Register(ENQUIRE_LINK, func() PDU { return NewEnquireLink() })
+	r.Register(ENQUIRE_LINK_RESP, func() PDU { return NewEnquireLinkResp() })
+	r.Register(GENERIC_NACK, func() PDU { return NewGenericNack() })
+
+	return r
+}
+
+// Register adds or replaces the factory for a command ID
+func (r *Registry) Register(commandID uint32, f Factory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories[commandID] = f
+}
+
+// Unregister removes the factory for a command ID
+func (r *Registry) Unregister(commandID uint32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.factories, commandID)
+}
+
+// SetTLVSchema sets the schema decoded optional parameters are validated
+// against. A nil schema disables validation.
+func (r *Registry) SetTLVSchema(schema *TLVSchema) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schema = schema
+}
+
+// TLVSchema returns the schema used to validate optional parameters
+func (r *Registry) TLVSchema() *TLVSchema {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.schema
+}
+
+// Lookup returns the factory registered for a command ID
+func (r *Registry) Lookup(commandID uint32) (Factory, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	f, ok := r.factories[commandID]
+	return f, ok
+}
+
+// Clone returns a copy of the registry that can be extended independently
+func (r *Registry) Clone() *Registry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c := NewRegistry()
+	for id, f := range r.factories {
+		c.factories[id] = f
+	}
+	for id, name := range r.names {
+		c.names[id] = name
+	}
+	c.schema = r.schema
+	return c
+}
+
+// New creates an empty PDU for a command ID
+func (r *Registry) New(commandID uint32) (PDU, error) {
+	f, ok := r.Lookup(commandID)
+	if !ok {
+		return nil, fmt.Errorf("%w: 0x%08X", ErrUnknownCommandID, commandID)
+	}
+	return f(), nil
+}
+
+// Decode turns a complete PDU frame into its typed PDU. When the header is
+// valid but the body is not, the PDU is returned together with the error so
+// that the caller can still answer with the matching response.
+func (r *Registry) Decode(data []byte) (PDU, error) {
+	return r.DecodeVersion(data, SMPP_V50)
+}
+
+// DecodeVersion decodes a PDU frame received over a session using the given
+// interface version. Commands the version does not define are rejected
+// with ESME_RINVCMDID, optional parameters it does not allow with
+// ESME_ROPTPARNOTALLWD.
+func (r *Registry) DecodeVersion(data []byte, version uint32) (PDU, error) {
+	return r.decode(data, version, false)
+}
+
+// DecodeAliased decodes a PDU frame like Decode without copying octet
+// strings: short_message and TLV values share memory with data, which must
+// not be modified or reused while the PDU is in use
+func (r *Registry) DecodeAliased(data []byte) (PDU, error) {
+	return r.decode(data, SMPP_V50, true)
+}
+
+// decode decodes a PDU frame for an interface version
+func (r *Registry) decode(data []byte, version uint32, alias bool) (PDU, error) {
+	if len(data) < 16 {
+		return nil, ErrPDUTooShort
+	}
+
+	header := &Header{}
+	if err := header.Unmarshal(data[:16]); err != nil {
+		return nil, err
+	}
+
+	if int(header.CommandLength) != len(data) {
+		return nil, fmt.Errorf("%w: header says %d, got %d", ErrInvalidCommandLength, header.CommandLength, len(data))
+	}
+
+	if err := checkCommandVersion(header.CommandID, version); err != nil {
+		return nil, err
+	}
+
+	p, err := r.New(header.CommandID)
+	if err != nil {
+		return nil, err
+	}
+
+	if d, ok := p.(decodable); ok {
+		err = d.decode(newDecoder(data, alias))
+	} else {
+		err = p.Unmarshal(data)
+	}
+	if err != nil {
+		return p, err
+	}
+
+	// Validate the optional parameters against the TLV schema. SMPP 3.3
+	// has none at all.
+	if c, ok := p.(TLVCarrier); ok {
+		params := *c.GetTLVParams()
+		if version < SMPP_V34 && len(params) > 0 {
+			return p, ErrTLVNotSupported
+		}
+		if schema := r.TLVSchema(); schema != nil {
+			if err := schema.Validate(p.CommandID(), version, params); err != nil {
+				return p, err
+			}
+			// Vendor parameters of a profile keep their names in logs
+			// and JSON
+			if schema != DefaultTLVSchema {
+				schema.bind(params)
+			}
+		}
+	}
+
+	// Validate the mandatory fields of PDUs that know how to
+	if v, ok := p.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return p, err
+		}
+	}
+
+	return p, nil
+}
+
+// Register adds or replaces a factory in the default registry
+func Register(commandID uint32, f Factory) {
+	DefaultRegistry.Register(commandID, f)
+}
+
+// New creates an empty PDU for a command ID using the default registry
+func New(commandID uint32) (PDU, error) {
+	return DefaultRegistry.New(commandID)
+}
+
+// DecodeAliased decodes a PDU frame without copying octet strings using the
+// default registry
+func DecodeAliased(data []byte) (PDU, error) {
+	return DefaultRegistry.DecodeAliased(data)
+}
+
+// Decode turns a complete PDU frame into its typed PDU using the default registry
+func Decode(data []byte) (PDU, error) {
+	return DefaultRegistry.Decode(data)
+}
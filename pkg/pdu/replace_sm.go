@@ -1,31 +1,44 @@
 package pdu
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 // ReplaceSM represents an SMPP replace_sm PDU
 type ReplaceSM struct {
-	Header               *Header
-	MessageID            string
-	SourceAddrTON        uint8
-	SourceAddrNPI        uint8
-	SourceAddr           string
-	ScheduleDeliveryTime string
-	ValidityPeriod       string
-	RegisteredDelivery   uint8
-	SMDefaultMsgID       uint8
-	SMLength             uint8
-	ShortMessage         []byte
-	TLVParams            map[uint16]*TLVParam
+	Header               *Header `json:"header"`
+	MessageID            string  `json:"message_id"`
+	SourceAddrTON        uint8   `json:"source_addr_ton"`
+	SourceAddrNPI        uint8   `json:"source_addr_npi"`
+	SourceAddr           string  `json:"source_addr"`
+	ScheduleDeliveryTime string  `json:"schedule_delivery_time"`
+	ValidityPeriod       string  `json:"validity_period"`
+	RegisteredDelivery   uint8   `json:"registered_delivery"`
+	SMDefaultMsgID       uint8   `json:"sm_default_msg_id"`
+	SMLength             uint8   `json:"-"`
+	ShortMessage         []byte  `json:"short_message"`
+	TLVParams            TLVList `json:"tlvs,omitempty"`
 }
 
 // NewReplaceSM creates a new ReplaceSM PDU
 func NewReplaceSM() *ReplaceSM {
 	return &ReplaceSM{
-		Header:    NewHeader(),
-		TLVParams: make(map[uint16]*TLVParam),
+		Header: NewHeader(),
 	}
 }
 
+// Source returns source_addr with its TON and NPI
+func (r *ReplaceSM) Source() Address {
+	return Address{TON: r.SourceAddrTON, NPI: r.SourceAddrNPI, Addr: r.SourceAddr}
+}
+
+// SetSource sets source_addr with its TON and NPI
+func (r *ReplaceSM) SetSource(a Address) {
+	r.SourceAddrTON, r.SourceAddrNPI, r.SourceAddr = a.TON, a.NPI, a.Addr
+}
+
 // SetMessageText sets the message text
 func (r *ReplaceSM) SetMessageText(text string) error {
 	if len(text) > 254 {
@@ -36,170 +49,162 @@ func (r *ReplaceSM) SetMessageText(text string) error {
 	return nil
 }
 
-// Marshal serializes the PDU into bytes
-func (r *ReplaceSM) Marshal() ([]byte, error) {
-	// Calculate the total length
-	length := 16 // Header length
+// SetScheduleDeliveryTime sets an absolute schedule_delivery_time, the zero
+// time clears it
+func (r *ReplaceSM) SetScheduleDeliveryTime(t time.Time) {
+	r.ScheduleDeliveryTime = FormatAbsoluteTime(t)
+}
+
+// SetScheduleDeliveryDelay sets a relative schedule_delivery_time
+func (r *ReplaceSM) SetScheduleDeliveryDelay(delay time.Duration) {
+	r.ScheduleDeliveryTime = FormatRelativeTime(delay)
+}
+
+// ScheduledAt returns the schedule_delivery_time, resolving a relative time
+// against now. The zero time means immediate delivery.
+func (r *ReplaceSM) ScheduledAt(now time.Time) (time.Time, error) {
+	t, err := ParseSMPPTime(r.ScheduleDeliveryTime, now)
+	if err != nil {
+		return t, fmt.Errorf("%w: %v", ErrInvalidScheduleTime, err)
+	}
+	return t, nil
+}
+
+// SetValidityPeriod sets an absolute validity_period, the zero time clears it
+func (r *ReplaceSM) SetValidityPeriod(t time.Time) {
+	r.ValidityPeriod = FormatAbsoluteTime(t)
+}
+
+// SetValidityDuration sets a relative validity_period
+func (r *ReplaceSM) SetValidityDuration(validity time.Duration) {
+	r.ValidityPeriod = FormatRelativeTime(validity)
+}
+
+// ExpiresAt returns the validity_period, resolving a relative time against
+// now. The zero time means the SMSC default validity.
+func (r *ReplaceSM) ExpiresAt(now time.Time) (time.Time, error) {
+	t, err := ParseSMPPTime(r.ValidityPeriod, now)
+	if err != nil {
+		return t, fmt.Errorf("%w: %v", ErrInvalidValidityPeriod, err)
+	}
+	return t, nil
+}
+
+// Validate checks the schedule_delivery_time and validity_period
+func (r *ReplaceSM) Validate() error {
+	return validateMessageTimes(r.ScheduleDeliveryTime, r.ValidityPeriod)
+}
+
+// checkFields checks the mandatory fields against their specified sizes
+func (r *ReplaceSM) checkFields() error {
+	return firstError(
+		checkCString("message_id", r.MessageID, MESSAGE_ID_MAX_LEN, ESME_RINVMSGID),
+		checkCString("source_addr", r.SourceAddr, ADDR_MAX_LEN, ESME_RINVSRCADR),
+		checkCString("schedule_delivery_time", r.ScheduleDeliveryTime, TIME_MAX_LEN, ESME_RINVSCHED),
+		checkCString("validity_period", r.ValidityPeriod, TIME_MAX_LEN, ESME_RINVEXPIRY),
+		checkOctets("short_message", r.ShortMessage, SM_MAX_LENGTH, ESME_RINVMSGLEN),
+	)
+}
+
+// EncodedLen returns the size of the encoded PDU in bytes
+func (r *ReplaceSM) EncodedLen() int {
+	length := HeaderLength
 	length += len(r.MessageID) + 1
-	length += 2 // Source addr ton and npi
+	length += 2 // source_addr_ton, source_addr_npi
 	length += len(r.SourceAddr) + 1
 	length += len(r.ScheduleDeliveryTime) + 1
 	length += len(r.ValidityPeriod) + 1
 	length += 3 // registered_delivery, sm_default_msg_id, sm_length
 	length += len(r.ShortMessage)
+	length += r.TLVParams.EncodedLen()
+	return length
+}
 
-	// Add TLV parameters length
-	for _, tlv := range r.TLVParams {
-		length += 4 + len(tlv.Value) // Tag(2) + Length(2) + Value(n)
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (r *ReplaceSM) AppendTo(dst []byte) ([]byte, error) {
+	if err := r.checkFields(); err != nil {
+		return dst, err
 	}
 
-	// Set command length in header
-	r.Header.CommandLength = uint32(length)
+	r.SMLength = uint8(len(r.ShortMessage))
+	r.Header.CommandLength = uint32(r.EncodedLen())
 	r.Header.CommandID = REPLACE_SM
 
-	// Create the byte slice
-	buf := make([]byte, length)
-
-	// Marshal header
-	headerBytes, err := r.Header.Marshal()
-	if err != nil {
-		return nil, err
-	}
-	copy(buf[0:], headerBytes)
-
-	offset := 16
-
-	// Write message_id
-	copy(buf[offset:], r.MessageID)
-	offset += len(r.MessageID) + 1
-
-	// Write source_addr_ton
-	buf[offset] = r.SourceAddrTON
-	offset++
-
-	// Write source_addr_npi
-	buf[offset] = r.SourceAddrNPI
-	offset++
-
-	// Write source_addr
-	copy(buf[offset:], r.SourceAddr)
-	offset += len(r.SourceAddr) + 1
-
-	// Write schedule_delivery_time
-	copy(buf[offset:], r.ScheduleDeliveryTime)
-	offset += len(r.ScheduleDeliveryTime) + 1
-
-	// Write validity_period
-	copy(buf[offset:], r.ValidityPeriod)
-	offset += len(r.ValidityPeriod) + 1
-
-	// Write registered_delivery
-	buf[offset] = r.RegisteredDelivery
-	offset++
-
-	// Write sm_default_msg_id
-	buf[offset] = r.SMDefaultMsgID
-	offset++
-
-	// Write sm_length
-	buf[offset] = r.SMLength
-	offset++
-
-	// Write short_message
-	copy(buf[offset:], r.ShortMessage)
-	offset += len(r.ShortMessage)
-
-	// Write TLV parameters
-	for _, tlv := range r.TLVParams {
-		tlvBytes, err := tlv.Marshal()
-		if err != nil {
-			return nil, err
-		}
-		copy(buf[offset:], tlvBytes)
-		offset += 4 + len(tlv.Value)
-	}
+	dst = r.Header.AppendTo(dst)
+	dst = appendCString(dst, r.MessageID)
+	dst = append(dst, r.SourceAddrTON, r.SourceAddrNPI)
+	dst = appendCString(dst, r.SourceAddr)
+	dst = appendCString(dst, r.ScheduleDeliveryTime)
+	dst = appendCString(dst, r.ValidityPeriod)
+	dst = append(dst, r.RegisteredDelivery, r.SMDefaultMsgID, r.SMLength)
+	dst = append(dst, r.ShortMessage...)
+	dst = r.TLVParams.AppendTo(dst)
+	return dst, nil
+}
 
-	return buf, nil
+// Marshal serializes the PDU into bytes
+func (r *ReplaceSM) Marshal() ([]byte, error) {
+	return r.AppendTo(make([]byte, 0, r.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (r *ReplaceSM) Unmarshal(data []byte) error {
-	var err error
+	return r.decode(newDecoder(data, false))
+}
 
-	// Unmarshal header
+// decode reads the PDU fields from dec
+func (r *ReplaceSM) decode(dec *decoder) error {
 	r.Header = &Header{}
-	if err = r.Header.Unmarshal(data[:16]); err != nil {
-		return err
-	}
-
-	offset := 16
-
-	// Read message_id
-	if r.MessageID, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
-
-	// Read source_addr_ton
-	r.SourceAddrTON = data[offset]
-	offset++
-
-	// Read source_addr_npi
-	r.SourceAddrNPI = data[offset]
-	offset++
-
-	// Read source_addr
-	if r.SourceAddr, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+	dec.header(r.Header)
+
+	r.MessageID = dec.cstring("message_id", MESSAGE_ID_MAX_LEN, ESME_RINVMSGID)
+	r.SourceAddrTON = dec.uint8("source_addr_ton")
+	r.SourceAddrNPI = dec.uint8("source_addr_npi")
+	r.SourceAddr = dec.cstring("source_addr", ADDR_MAX_LEN, ESME_RINVSRCADR)
+	r.ScheduleDeliveryTime = dec.cstring("schedule_delivery_time", TIME_MAX_LEN, ESME_RINVSCHED)
+	r.ValidityPeriod = dec.cstring("validity_period", TIME_MAX_LEN, ESME_RINVEXPIRY)
+	r.RegisteredDelivery = dec.uint8("registered_delivery")
+	r.SMDefaultMsgID = dec.uint8("sm_default_msg_id")
+	r.SMLength = dec.uint8("sm_length")
+	r.ShortMessage = dec.octets("short_message", int(r.SMLength), SM_MAX_LENGTH, ESME_RINVMSGLEN)
+
+	dec.tlvs(&r.TLVParams)
+	return dec.Err()
+}
 
-	// Read schedule_delivery_time
-	if r.ScheduleDeliveryTime, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+// CommandID returns the command_id of the PDU
+func (r *ReplaceSM) CommandID() uint32 {
+	return REPLACE_SM
+}
 
-	// Read validity_period
-	if r.ValidityPeriod, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+// GetHeader returns the PDU header
+func (r *ReplaceSM) GetHeader() *Header {
+	return r.Header
+}
 
-	// Read registered_delivery
-	r.RegisteredDelivery = data[offset]
-	offset++
+// String returns a one-line description of the PDU for trace logs
+func (r *ReplaceSM) String() string {
+	return FormatPDU(r)
+}
 
-	// Read sm_default_msg_id
-	r.SMDefaultMsgID = data[offset]
-	offset++
+// MarshalJSON encodes the PDU as JSON
+func (r *ReplaceSM) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(r)
+}
 
-	// Read sm_length
-	r.SMLength = data[offset]
-	offset++
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (r *ReplaceSM) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, r)
+}
 
-	// Read short_message
-	if int(r.SMLength) > len(data[offset:]) {
-		return errors.New("invalid short message length")
-	}
-	r.ShortMessage = make([]byte, r.SMLength)
-	copy(r.ShortMessage, data[offset:offset+int(r.SMLength)])
-	offset += int(r.SMLength)
-
-	// Read TLV parameters if any remain
-	remaining := int(r.Header.CommandLength) - offset
-	currentOffset := offset
-
-	for remaining > 0 {
-		if remaining < 4 {
-			return errors.New("invalid TLV parameter: insufficient data")
-		}
-
-		tlv := &TLVParam{}
-		if err := tlv.Unmarshal(data[currentOffset:]); err != nil {
-			return err
-		}
-
-		r.TLVParams[tlv.Tag] = tlv
-		currentOffset += 4 + int(tlv.Length)
-		remaining -= 4 + int(tlv.Length)
-	}
+// GetTLVParams returns the optional parameters of the PDU
+func (r *ReplaceSM) GetTLVParams() *TLVList {
+	return &r.TLVParams
+}
 
-	return nil
+// GetResponse creates the replace_sm_resp answering this PDU
+func (r *ReplaceSM) GetResponse() PDU {
+	resp := NewReplaceSMResp()
+	resp.Header = responseHeader(r.Header, REPLACE_SM_RESP)
+	return resp
 }
@@ -2,7 +2,7 @@ package pdu
 
 // ReplaceSMResp represents an SMPP replace_sm_resp PDU
 type ReplaceSMResp struct {
-	Header *Header
+	Header *Header `json:"header"`
 }
 
 // NewReplaceSMResp creates a new ReplaceSMResp PDU
@@ -12,19 +12,64 @@ func NewReplaceSMResp() *ReplaceSMResp {
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (r *ReplaceSMResp) Marshal() ([]byte, error) {
-	// Set command length in header
-	r.Header.CommandLength = 16 // Only header, no body
+// EncodedLen returns the size of the encoded PDU in bytes
+func (r *ReplaceSMResp) EncodedLen() int {
+	return HeaderLength
+}
+
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (r *ReplaceSMResp) AppendTo(dst []byte) ([]byte, error) {
+	r.Header.CommandLength = uint32(r.EncodedLen())
 	r.Header.CommandID = REPLACE_SM_RESP
 
-	// Marshal header
-	return r.Header.Marshal()
+	return r.Header.AppendTo(dst), nil
+}
+
+// Marshal serializes the PDU into bytes
+func (r *ReplaceSMResp) Marshal() ([]byte, error) {
+	return r.AppendTo(make([]byte, 0, r.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (r *ReplaceSMResp) Unmarshal(data []byte) error {
-	// Unmarshal header
+	return r.decode(newDecoder(data, false))
+}
+
+// decode reads the PDU fields from dec
+func (r *ReplaceSMResp) decode(dec *decoder) error {
 	r.Header = &Header{}
-	return r.Header.Unmarshal(data[:16])
+	dec.header(r.Header)
+
+	dec.end()
+	return dec.Err()
+}
+
+// CommandID returns the command_id of the PDU
+func (r *ReplaceSMResp) CommandID() uint32 {
+	return REPLACE_SM_RESP
+}
+
+// GetHeader returns the PDU header
+func (r *ReplaceSMResp) GetHeader() *Header {
+	return r.Header
+}
+
+// String returns a one-line description of the PDU for trace logs
+func (r *ReplaceSMResp) String() string {
+	return FormatPDU(r)
+}
+
+// MarshalJSON encodes the PDU as JSON
+func (r *ReplaceSMResp) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(r)
+}
+
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (r *ReplaceSMResp) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, r)
+}
+
+// GetResponse returns nil as replace_sm_resp has no response PDU
+func (r *ReplaceSMResp) GetResponse() PDU {
+	return nil
 }
@@ -0,0 +1,228 @@
+package pdu
+
+import (
+	"errors"
+	"hash/fnv"
+	"math/rand/v2"
+	"sync"
+)
+
+// SegmentStrategy selects how the parts of a long message are linked
+type SegmentStrategy int
+
+const (
+	SEGMENT_UDH_8BIT  SegmentStrategy = iota // Concatenation UDH with an 8-bit reference
+	SEGMENT_UDH_16BIT                        // Concatenation UDH with a 16-bit reference
+	SEGMENT_SAR                              // sar_msg_ref_num, sar_total_segments and sar_segment_seqnum TLVs
+	SEGMENT_PAYLOAD                          // Whole message in a single message_payload TLV
+)
+
+const (
+	// SEGMENT_MAX_PARTS is the largest number of parts a message can be
+	// split into, limited by the one octet total and sequence fields
+	SEGMENT_MAX_PARTS int = 255
+
+	// MESSAGE_PAYLOAD_MAX_LENGTH is the largest message_payload value
+	MESSAGE_PAYLOAD_MAX_LENGTH int = 0xFFFF
+
+	// referenceBuckets is the number of reference counters destinations
+	// are spread over
+	referenceBuckets = 4096
+)
+
+var (
+	ErrTooManySegments = errors.New("message needs too many segments")
+)
+
+// ReferenceAllocator hands out concatenation reference numbers per
+// destination, so that long messages sent concurrently to the same handset
+// get different references. Destinations are hashed onto a fixed number of
+// counters, each starting at a random value, which keeps memory bounded.
+type ReferenceAllocator struct {
+	mu       sync.Mutex
+	counters []uint16
+}
+
+// NewReferenceAllocator creates a reference allocator
+func NewReferenceAllocator() *ReferenceAllocator {
+	counters := make([]uint16, referenceBuckets)
+	for i := range counters {
+		counters[i] = uint16(rand.N(0x10000))
+	}
+	return &ReferenceAllocator{counters: counters}
+}
+
+// Next returns the next reference number for a destination
+func (a *ReferenceAllocator) Next(dest string) uint16 {
+	h := fnv.New32a()
+	h.Write([]byte(dest))
+	bucket := h.Sum32() % referenceBuckets
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.counters[bucket]++
+	return a.counters[bucket]
+}
+
+// DefaultReferenceAllocator is used by segmenters without their own allocator
+var DefaultReferenceAllocator = NewReferenceAllocator()
+
+// Segment is one part of a message ready to be put into a PDU
+type Segment struct {
+	DataCoding   uint8
+	ESMClass     uint8 // ESM_CLASS_UDHI when ShortMessage starts with a UDH
+	ShortMessage []byte
+	TLVParams    TLVList // SAR or message_payload parameters
+}
+
+// Segmenter splits text into the parts needed to send it
+type Segmenter struct {
+	Strategy SegmentStrategy
+	Options  *TextOptions        // Data coding selection, nil for the defaults
+	Refs     *ReferenceAllocator // Reference numbers, nil for DefaultReferenceAllocator
+}
+
+// NewSegmenter creates a segmenter using the given strategy
+func NewSegmenter(strategy SegmentStrategy) *Segmenter {
+	return &Segmenter{
+		Strategy: strategy,
+	}
+}
+
+// concatLength returns the length of the concatenation IE each part makes
+// room for. SAR parts carry none, but the SMSC turns the SAR TLVs into an
+// 8-bit concatenation IE over the air, so they are sized as if they did.
+func (s *Segmenter) concatLength() int {
+	switch s.Strategy {
+	case SEGMENT_UDH_8BIT, SEGMENT_SAR:
+		return UDH_CONCAT_8BIT_IE_LEN
+	case SEGMENT_UDH_16BIT:
+		return UDH_CONCAT_16BIT_IE_LEN
+	}
+	return 0
+}
+
+// Split encodes text and splits it into segments for dest. A message that
+// fits into one short message is returned as a single plain segment
+// whatever the strategy.
+func (s *Segmenter) Split(dest string, text string) (*EncodedText, []Segment, error) {
+	encoded := SelectTextEncoding(text, s.Options)
+	shifts := encoded.NationalShiftElements()
+
+	single := encoded.Capacity(encoded.udhLength(0))
+	if encoded.Length <= single {
+		seg := Segment{DataCoding: encoded.DataCoding, ShortMessage: encoded.ShortMessage()}
+		if encoded.HasUDH() {
+			seg.ESMClass = ESM_CLASS_UDHI
+		}
+		return encoded, []Segment{seg}, nil
+	}
+
+	if s.Strategy == SEGMENT_PAYLOAD {
+		payload := encoded.ShortMessage()
+		if len(payload) > MESSAGE_PAYLOAD_MAX_LENGTH {
+			return encoded, nil, ErrMessageTooLong
+		}
+		seg := Segment{DataCoding: encoded.DataCoding}
+		if encoded.HasUDH() {
+			seg.ESMClass = ESM_CLASS_UDHI
+		}
+		seg.TLVParams.SetMessagePayload(payload)
+		return encoded, []Segment{seg}, nil
+	}
+
+	parts := encoded.chunks(single, encoded.Capacity(encoded.udhLength(s.concatLength())))
+	if len(parts) > SEGMENT_MAX_PARTS {
+		return encoded, nil, ErrTooManySegments
+	}
+
+	refs := s.Refs
+	if refs == nil {
+		refs = DefaultReferenceAllocator
+	}
+	ref := refs.Next(dest)
+	total := uint8(len(parts))
+
+	segments := make([]Segment, len(parts))
+	for i, part := range parts {
+		seq := uint8(i + 1)
+		seg := Segment{DataCoding: encoded.DataCoding}
+
+		udh := NewUDH()
+		switch s.Strategy {
+		case SEGMENT_UDH_8BIT:
+			udh.Add(&ConcatIE{Ref: ref & 0xFF, Total: total, SeqNum: seq})
+		case SEGMENT_UDH_16BIT:
+			udh.Add(&ConcatIE{Ref: ref, Total: total, SeqNum: seq, Ref16Bit: true})
+		case SEGMENT_SAR:
+			seg.TLVParams.SetSAR(SARParams{RefNum: ref, Total: total, SeqNum: seq})
+		}
+		for _, shift := range shifts {
+			udh.Add(shift)
+		}
+
+		if len(udh.Elements) > 0 {
+			seg.ESMClass = ESM_CLASS_UDHI
+			seg.ShortMessage, _ = udh.Prepend(part)
+		} else {
+			seg.ShortMessage = part
+		}
+		segments[i] = seg
+	}
+
+	return encoded, segments, nil
+}
+
+// SubmitSM splits text into submit_sm PDUs copied from tmpl, which holds
+// the addresses and other fields common to every part
+func (s *Segmenter) SubmitSM(tmpl *SubmitSM, text string) ([]*SubmitSM, error) {
+	_, segments, err := s.Split(tmpl.DestinationAddr, text)
+	if err != nil {
+		return nil, err
+	}
+
+	pdus := make([]*SubmitSM, len(segments))
+	for i, seg := range segments {
+		sm := *tmpl
+		sm.Header = NewHeader()
+		sm.DataCoding = seg.DataCoding
+		sm.ESMClass = tmpl.ESMClass&^ESM_CLASS_UDHI | seg.ESMClass
+		sm.ShortMessage = seg.ShortMessage
+		sm.SMLength = uint8(len(seg.ShortMessage))
+		sm.TLVParams = mergeSegmentParams(tmpl.TLVParams, seg.TLVParams)
+		pdus[i] = &sm
+	}
+	return pdus, nil
+}
+
+// DeliverSM splits text into deliver_sm PDUs copied from tmpl, which holds
+// the addresses and other fields common to every part
+func (s *Segmenter) DeliverSM(tmpl *DeliverSM, text string) ([]*DeliverSM, error) {
+	_, segments, err := s.Split(tmpl.DestinationAddr, text)
+	if err != nil {
+		return nil, err
+	}
+
+	pdus := make([]*DeliverSM, len(segments))
+	for i, seg := range segments {
+		sm := *tmpl
+		sm.Header = NewHeader()
+		sm.DataCoding = seg.DataCoding
+		sm.ESMClass = tmpl.ESMClass&^ESM_CLASS_UDHI | seg.ESMClass
+		sm.ShortMessage = seg.ShortMessage
+		sm.SMLength = uint8(len(seg.ShortMessage))
+		sm.TLVParams = mergeSegmentParams(tmpl.TLVParams, seg.TLVParams)
+		pdus[i] = &sm
+	}
+	return pdus, nil
+}
+
+// mergeSegmentParams copies the template parameters and sets those of the
+// segment over them
+func mergeSegmentParams(tmpl, seg TLVList) TLVList {
+	params := tmpl.Clone()
+	for _, tlv := range seg {
+		params.Set(tlv.Tag, tlv.Value)
+	}
+	return params
+}
@@ -0,0 +1,81 @@
+package pdu
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// segmentSizes returns the size of the text in every segment: septets for
+// the GSM 7-bit alphabet, characters for UCS2 and octets otherwise
+func segmentSizes(t *testing.T, segments []Segment) []int {
+	t.Helper()
+	var sizes []int
+	for _, seg := range segments {
+		ud := seg.ShortMessage
+		if payload, ok := seg.TLVParams.MessagePayload(); ok {
+			ud = payload
+		}
+		_, data, err := SplitUserData(ud, seg.ESMClass)
+		if err != nil {
+			t.Fatalf("SplitUserData: %v", err)
+		}
+		n := len(data)
+		if Alphabet(seg.DataCoding) == DATA_CODING_UCS2 {
+			n /= 2
+		}
+		sizes = append(sizes, n)
+	}
+	return sizes
+}
+
+func TestSegmenterPartSizes(t *testing.T) {
+	noShifts := &TextOptions{Languages: []uint8{}, Latin1: true}
+	texts := []struct {
+		name       string
+		text       string
+		dataCoding uint8
+	}{
+		{"gsm7", strings.Repeat("a", 400), DATA_CODING_DEFAULT},
+		{"8-bit", strings.Repeat("â", 300), DATA_CODING_ISO8859_1},
+		{"ucs2", strings.Repeat("中", 150), DATA_CODING_UCS2},
+	}
+	tests := []struct {
+		strategy SegmentStrategy
+		sizes    map[string][]int
+	}{
+		{SEGMENT_UDH_8BIT, map[string][]int{"gsm7": {153, 153, 94}, "8-bit": {134, 134, 32}, "ucs2": {67, 67, 16}}},
+		{SEGMENT_UDH_16BIT, map[string][]int{"gsm7": {152, 152, 96}, "8-bit": {133, 133, 34}, "ucs2": {66, 66, 18}}},
+		// The SMSC adds an 8-bit concatenation IE to SAR parts over the air
+		{SEGMENT_SAR, map[string][]int{"gsm7": {153, 153, 94}, "8-bit": {134, 134, 32}, "ucs2": {67, 67, 16}}},
+		{SEGMENT_PAYLOAD, map[string][]int{"gsm7": {400}, "8-bit": {300}, "ucs2": {150}}},
+	}
+	for _, tt := range tests {
+		for _, text := range texts {
+			s := &Segmenter{Strategy: tt.strategy, Options: noShifts}
+			encoded, segments, err := s.Split("447700900123", text.text)
+			if err != nil {
+				t.Fatalf("strategy %d, %s: Split: %v", tt.strategy, text.name, err)
+			}
+			if encoded.DataCoding != text.dataCoding {
+				t.Fatalf("strategy %d, %s: data_coding 0x%02X, want 0x%02X", tt.strategy, text.name, encoded.DataCoding, text.dataCoding)
+			}
+			if got := segmentSizes(t, segments); !slices.Equal(got, tt.sizes[text.name]) {
+				t.Errorf("strategy %d, %s: part sizes %v, want %v", tt.strategy, text.name, got, tt.sizes[text.name])
+			}
+		}
+	}
+}
+
+func TestSegmenterSingle(t *testing.T) {
+	for _, strategy := range []SegmentStrategy{SEGMENT_UDH_8BIT, SEGMENT_UDH_16BIT, SEGMENT_SAR, SEGMENT_PAYLOAD} {
+		s := &Segmenter{Strategy: strategy}
+		_, segments, err := s.Split("447700900123", strings.Repeat("a", 160))
+		if err != nil {
+			t.Fatalf("strategy %d: Split: %v", strategy, err)
+		}
+		if len(segments) != 1 || len(segments[0].ShortMessage) != 160 || len(segments[0].TLVParams) != 0 {
+			t.Errorf("strategy %d: want one plain segment of 160 septets, got %+v", strategy, segments)
+		}
+	}
+}
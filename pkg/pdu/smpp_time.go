@@ -0,0 +1,275 @@
+package pdu
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// SMPP_TIME_LENGTH is the length of an absolute or relative time string
+	SMPP_TIME_LENGTH = 16
+
+	// smppTimeLayout is the YYMMDDhhmmss part shared by both formats
+	smppTimeLayout = "060102150405"
+
+	// Approximations used to turn relative years and months into durations
+	relativeYear  = 365 * 24 * time.Hour
+	relativeMonth = 30 * 24 * time.Hour
+	relativeDay   = 24 * time.Hour
+)
+
+var (
+	ErrInvalidSMPPTime       = errors.New("invalid SMPP time")
+	ErrInvalidScheduleTime   = NewStatusError(ESME_RINVSCHED, "invalid schedule_delivery_time")
+	ErrInvalidValidityPeriod = NewStatusError(ESME_RINVEXPIRY, "invalid validity_period")
+)
+
+// RelativeTime is an SMPP relative time, an offset from the time the SMSC
+// receives the message
+type RelativeTime struct {
+	Years   int
+	Months  int
+	Days    int
+	Hours   int
+	Minutes int
+	Seconds int
+}
+
+// NewRelativeTime splits a duration into a relative time. Days beyond a
+// month or year are expressed as 30 day months and 365 day years, as the
+// two digit fields cannot hold more than 99 of each.
+func NewRelativeTime(d time.Duration) RelativeTime {
+	if d < 0 {
+		d = 0
+	}
+	r := RelativeTime{}
+	r.Years, d = int(d/relativeYear), d%relativeYear
+	r.Months, d = int(d/relativeMonth), d%relativeMonth
+	r.Days, d = int(d/relativeDay), d%relativeDay
+	r.Hours, d = int(d/time.Hour), d%time.Hour
+	r.Minutes, d = int(d/time.Minute), d%time.Minute
+	r.Seconds = int(d / time.Second)
+	if r.Years > 99 {
+		r.Years = 99
+	}
+	return r
+}
+
+// Duration returns the relative time as a duration, counting 365 days per
+// year and 30 days per month
+func (r RelativeTime) Duration() time.Duration {
+	return time.Duration(r.Years)*relativeYear +
+		time.Duration(r.Months)*relativeMonth +
+		time.Duration(r.Days)*relativeDay +
+		time.Duration(r.Hours)*time.Hour +
+		time.Duration(r.Minutes)*time.Minute +
+		time.Duration(r.Seconds)*time.Second
+}
+
+// AddTo returns ref moved forward by the relative time using calendar
+// arithmetic for years, months and days
+func (r RelativeTime) AddTo(ref time.Time) time.Time {
+	return ref.AddDate(r.Years, r.Months, r.Days).Add(
+		time.Duration(r.Hours)*time.Hour +
+			time.Duration(r.Minutes)*time.Minute +
+			time.Duration(r.Seconds)*time.Second)
+}
+
+// String formats the relative time as YYMMDDhhmmss000R
+func (r RelativeTime) String() string {
+	return fmt.Sprintf("%02d%02d%02d%02d%02d%02d000R", r.Years, r.Months, r.Days, r.Hours, r.Minutes, r.Seconds)
+}
+
+// IsRelativeTime checks if s is in the relative time format
+func IsRelativeTime(s string) bool {
+	return len(s) == SMPP_TIME_LENGTH && s[15] == 'R'
+}
+
+// parseTimeDigits reads the 15 digits preceding the format indicator
+func parseTimeDigits(s string) ([6]int, int, int, error) {
+	var fields [6]int
+	if len(s) != SMPP_TIME_LENGTH {
+		return fields, 0, 0, fmt.Errorf("%w: %q is not %d characters long", ErrInvalidSMPPTime, s, SMPP_TIME_LENGTH)
+	}
+	for i := 0; i < 15; i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return fields, 0, 0, fmt.Errorf("%w: %q has a non digit at %d", ErrInvalidSMPPTime, s, i)
+		}
+	}
+	for i := range fields {
+		fields[i], _ = strconv.Atoi(s[2*i : 2*i+2])
+	}
+	tenths := int(s[12] - '0')
+	quarters, _ := strconv.Atoi(s[13:15])
+	return fields, tenths, quarters, nil
+}
+
+// ParseRelativeTime parses the YYMMDDhhmmss000R relative format
+func ParseRelativeTime(s string) (RelativeTime, error) {
+	fields, _, _, err := parseTimeDigits(s)
+	if err != nil {
+		return RelativeTime{}, err
+	}
+	if s[15] != 'R' {
+		return RelativeTime{}, fmt.Errorf("%w: %q is not a relative time", ErrInvalidSMPPTime, s)
+	}
+	if s[12:15] != "000" {
+		return RelativeTime{}, fmt.Errorf("%w: %q has tenths or an offset in a relative time", ErrInvalidSMPPTime, s)
+	}
+	return RelativeTime{
+		Years:   fields[0],
+		Months:  fields[1],
+		Days:    fields[2],
+		Hours:   fields[3],
+		Minutes: fields[4],
+		Seconds: fields[5],
+	}, nil
+}
+
+// ParseAbsoluteTime parses the YYMMDDhhmmsstnnp absolute format, where t is
+// tenths of a second, nn the UTC offset in quarter hours and p '+' or '-'.
+// The result is in a fixed zone with that offset.
+func ParseAbsoluteTime(s string) (time.Time, error) {
+	fields, tenths, quarters, err := parseTimeDigits(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var sign int
+	switch s[15] {
+	case '+':
+		sign = 1
+	case '-':
+		sign = -1
+	default:
+		return time.Time{}, fmt.Errorf("%w: %q has no UTC offset direction", ErrInvalidSMPPTime, s)
+	}
+	if quarters > 48 {
+		return time.Time{}, fmt.Errorf("%w: %q has a UTC offset above 12 hours", ErrInvalidSMPPTime, s)
+	}
+
+	loc := time.FixedZone("", sign*quarters*15*60)
+	t, err := time.ParseInLocation(smppTimeLayout, s[:12], loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %q: %v", ErrInvalidSMPPTime, s, err)
+	}
+	if t.Month() != time.Month(fields[1]) || t.Day() != fields[2] {
+		return time.Time{}, fmt.Errorf("%w: %q is not a valid date", ErrInvalidSMPPTime, s)
+	}
+	return t.Add(time.Duration(tenths) * 100 * time.Millisecond), nil
+}
+
+// ParseSMPPTime parses an absolute or relative time, resolving a relative
+// time against ref. An empty string is the NULL time and returns the zero
+// time.
+func ParseSMPPTime(s string, ref time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if IsRelativeTime(s) {
+		r, err := ParseRelativeTime(s)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return r.AddTo(ref), nil
+	}
+	return ParseAbsoluteTime(s)
+}
+
+// FormatAbsoluteTime formats t in the absolute format using its own UTC
+// offset, or UTC when the offset is not a whole number of quarter hours. A
+// zero time is formatted as the NULL (empty) time.
+func FormatAbsoluteTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	_, offset := t.Zone()
+	if offset%(15*60) != 0 || offset > 12*3600 || offset < -12*3600 {
+		t = t.UTC()
+		offset = 0
+	}
+
+	sign := byte('+')
+	if offset < 0 {
+		sign = '-'
+		offset = -offset
+	}
+	tenths := t.Nanosecond() / int(100*time.Millisecond)
+	return fmt.Sprintf("%s%d%02d%c", t.Format(smppTimeLayout), tenths, offset/(15*60), sign)
+}
+
+// FormatRelativeTime formats a duration in the relative format
+func FormatRelativeTime(d time.Duration) string {
+	return NewRelativeTime(d).String()
+}
+
+// ValidateSMPPTime checks that s is empty or a valid absolute or relative time
+func ValidateSMPPTime(s string) error {
+	if s == "" {
+		return nil
+	}
+	if IsRelativeTime(s) {
+		_, err := ParseRelativeTime(s)
+		return err
+	}
+	_, err := ParseAbsoluteTime(s)
+	return err
+}
+
+// ValidateScheduleDeliveryTime checks a schedule_delivery_time value and
+// reports ESME_RINVSCHED when it is malformed
+func ValidateScheduleDeliveryTime(s string) error {
+	if err := ValidateSMPPTime(s); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidScheduleTime, err)
+	}
+	return nil
+}
+
+// ValidateValidityPeriod checks a validity_period value and reports
+// ESME_RINVEXPIRY when it is malformed
+func ValidateValidityPeriod(s string) error {
+	if err := ValidateSMPPTime(s); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidValidityPeriod, err)
+	}
+	return nil
+}
+
+// validateMessageTimes checks the schedule_delivery_time and validity_period
+// of a message, including that an absolute expiry does not precede an
+// absolute schedule
+func validateMessageTimes(schedule, validity string) error {
+	if err := ValidateScheduleDeliveryTime(schedule); err != nil {
+		return err
+	}
+	if err := ValidateValidityPeriod(validity); err != nil {
+		return err
+	}
+	if schedule == "" || validity == "" || IsRelativeTime(schedule) || IsRelativeTime(validity) {
+		return nil
+	}
+
+	scheduled, _ := ParseAbsoluteTime(schedule)
+	expiry, _ := ParseAbsoluteTime(validity)
+	if expiry.Before(scheduled) {
+		return fmt.Errorf("%w: expires before the scheduled delivery time", ErrInvalidValidityPeriod)
+	}
+	return nil
+}
+
+// BroadcastEndTime returns the broadcast_end_time TLV
+func (l TLVList) BroadcastEndTime() (time.Time, bool, error) {
+	s, ok := l.GetCString(TLV_BROADCAST_END_TIME)
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	t, err := ParseAbsoluteTime(s)
+	return t, true, err
+}
+
+// SetBroadcastEndTime sets the broadcast_end_time TLV
+func (l *TLVList) SetBroadcastEndTime(t time.Time) {
+	l.SetCString(TLV_BROADCAST_END_TIME, FormatAbsoluteTime(t))
+}
@@ -0,0 +1,185 @@
+package pdu
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseAbsoluteTime(t *testing.T) {
+	tests := []struct {
+		s      string
+		want   time.Time
+		offset int // Seconds east of UTC
+	}{
+		{"240102150405000+", time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), 0},
+		{"240102150405300+", time.Date(2024, 1, 2, 15, 4, 5, 300_000_000, time.UTC), 0},
+		{"240102150405004+", time.Date(2024, 1, 2, 14, 4, 5, 0, time.UTC), 3600},
+		{"240102150405022+", time.Date(2024, 1, 2, 9, 34, 5, 0, time.UTC), 5*3600 + 30*60},
+		{"240102150405023+", time.Date(2024, 1, 2, 9, 19, 5, 0, time.UTC), 5*3600 + 45*60},
+		{"240102150405014-", time.Date(2024, 1, 2, 18, 34, 5, 0, time.UTC), -(3*3600 + 30*60)},
+		{"240102150405048+", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), 12 * 3600},
+		{"240102150405048-", time.Date(2024, 1, 3, 3, 4, 5, 0, time.UTC), -12 * 3600},
+		{"240229235959900+", time.Date(2024, 2, 29, 23, 59, 59, 900_000_000, time.UTC), 0},
+	}
+	for _, tt := range tests {
+		got, err := ParseAbsoluteTime(tt.s)
+		if err != nil {
+			t.Errorf("ParseAbsoluteTime(%q): %v", tt.s, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseAbsoluteTime(%q) = %v, want %v", tt.s, got.UTC(), tt.want)
+		}
+		if _, offset := got.Zone(); offset != tt.offset {
+			t.Errorf("ParseAbsoluteTime(%q) offset %d, want %d", tt.s, offset, tt.offset)
+		}
+		if s := FormatAbsoluteTime(got); s != tt.s {
+			t.Errorf("FormatAbsoluteTime(ParseAbsoluteTime(%q)) = %q", tt.s, s)
+		}
+	}
+}
+
+func TestParseSMPPTimeInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+	}{
+		{"short", "24010215040500+"},
+		{"long", "2401021504050000+"},
+		{"relative without R", "000001000000000"},
+		{"non digit", "2401021504x5000+"},
+		{"no offset direction", "2401021504050000"},
+		{"offset above 48", "240102150405049+"},
+		{"month 13", "241302150405000+"},
+		{"february 30", "240230150405000+"},
+		{"hour 24", "240102240405000+"},
+		{"lowercase r", "000001000000000r"},
+		{"relative non digit", "0000010000000x0R"},
+		{"relative tenths", "000001000000100R"},
+		{"relative offset", "000001000000004R"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseSMPPTime(tt.s, time.Now()); !errors.Is(err, ErrInvalidSMPPTime) {
+				t.Errorf("ParseSMPPTime(%q) error = %v, want ErrInvalidSMPPTime", tt.s, err)
+			}
+			if err := ValidateSMPPTime(tt.s); !errors.Is(err, ErrInvalidSMPPTime) {
+				t.Errorf("ValidateSMPPTime(%q) error = %v, want ErrInvalidSMPPTime", tt.s, err)
+			}
+		})
+	}
+
+	if _, err := ParseRelativeTime("240102150405000+"); !errors.Is(err, ErrInvalidSMPPTime) {
+		t.Errorf("ParseRelativeTime of an absolute time error = %v", err)
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	tests := []struct {
+		s   string
+		rel RelativeTime
+		dur time.Duration
+	}{
+		{"000000000000000R", RelativeTime{}, 0},
+		{"000000000030000R", RelativeTime{Seconds: 30}, 30 * time.Second},
+		{"000001020304000R", RelativeTime{Days: 1, Hours: 2, Minutes: 3, Seconds: 4}, 26*time.Hour + 3*time.Minute + 4*time.Second},
+		{"000100000000000R", RelativeTime{Months: 1}, 30 * 24 * time.Hour},
+		{"010000000000000R", RelativeTime{Years: 1}, 365 * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		rel, err := ParseRelativeTime(tt.s)
+		if err != nil {
+			t.Errorf("ParseRelativeTime(%q): %v", tt.s, err)
+			continue
+		}
+		if rel != tt.rel {
+			t.Errorf("ParseRelativeTime(%q) = %+v, want %+v", tt.s, rel, tt.rel)
+		}
+		if rel.Duration() != tt.dur {
+			t.Errorf("%q: Duration = %v, want %v", tt.s, rel.Duration(), tt.dur)
+		}
+		if s := FormatRelativeTime(tt.dur); s != tt.s {
+			t.Errorf("FormatRelativeTime(%v) = %q, want %q", tt.dur, s, tt.s)
+		}
+		if !IsRelativeTime(tt.s) {
+			t.Errorf("IsRelativeTime(%q) = false", tt.s)
+		}
+	}
+
+	// Calendar arithmetic against the reference time
+	ref := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	got, err := ParseSMPPTime("000100000130000R", ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 3, 2, 12, 1, 30, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("ParseSMPPTime relative = %v, want %v", got, want)
+	}
+
+	if got := FormatRelativeTime(-time.Hour); got != "000000000000000R" {
+		t.Errorf("FormatRelativeTime of a negative duration = %q", got)
+	}
+	if got := NewRelativeTime(200 * relativeYear); got.Years != 99 {
+		t.Errorf("NewRelativeTime years = %d, want 99", got.Years)
+	}
+}
+
+func TestFormatAbsoluteTime(t *testing.T) {
+	tests := []struct {
+		name string
+		t    time.Time
+		want string
+	}{
+		{"zero is NULL", time.Time{}, ""},
+		{"utc", time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), "240102150405000+"},
+		{"tenths truncated", time.Date(2024, 1, 2, 15, 4, 5, 999_000_000, time.UTC), "240102150405900+"},
+		{"quarter hour offset", time.Date(2024, 1, 2, 15, 4, 5, 0, time.FixedZone("", 5*3600+45*60)), "240102150405023+"},
+		{"negative offset", time.Date(2024, 1, 2, 15, 4, 5, 0, time.FixedZone("", -9*3600-30*60)), "240102150405038-"},
+		{"odd offset in utc", time.Date(2024, 1, 2, 15, 4, 5, 0, time.FixedZone("", 10*60)), "240102145405000+"},
+		{"offset above 12 hours in utc", time.Date(2024, 1, 2, 15, 4, 5, 0, time.FixedZone("", 13*3600)), "240102020405000+"},
+	}
+	for _, tt := range tests {
+		if got := FormatAbsoluteTime(tt.t); got != tt.want {
+			t.Errorf("%s: FormatAbsoluteTime = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateMessageTimes(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		validity string
+		status   uint32
+	}{
+		{"both NULL", "", "", ESME_ROK},
+		{"absolute", "240102150405000+", "240103150405000+", ESME_ROK},
+		{"relative", "000000010000000R", "000001000000000R", ESME_ROK},
+		{"mixed", "000000010000000R", "240102150405000+", ESME_ROK},
+		{"same instant in other zones", "240102150405004+", "240102140405000+", ESME_ROK},
+		{"bad schedule", "2401021504", "", ESME_RINVSCHED},
+		{"bad schedule offset", "240102150405049+", "", ESME_RINVSCHED},
+		{"bad validity", "", "000001000000000X", ESME_RINVEXPIRY},
+		{"bad validity length", "", "00000100000000R", ESME_RINVEXPIRY},
+		{"expires before schedule", "240103150405000+", "240102150405000+", ESME_RINVEXPIRY},
+		{"both bad", "x", "y", ESME_RINVSCHED},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := NewSubmitSM()
+			sm.ScheduleDeliveryTime = tt.schedule
+			sm.ValidityPeriod = tt.validity
+			err := sm.Validate()
+			if tt.status == ESME_ROK {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+			if status, ok := ErrorStatus(err); !ok || status != tt.status {
+				t.Errorf("Validate error = %v, status 0x%08X, want 0x%08X", err, status, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package pdu
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StatusClass tells whether a failed command may succeed when retried
+type StatusClass uint8
+
+const (
+	STATUS_OK        StatusClass = iota // The command succeeded
+	STATUS_TEMPORARY                    // The command may succeed if retried later
+	STATUS_PERMANENT                    // The command will fail again if retried
+)
+
+// String returns the name of the class
+func (c StatusClass) String() string {
+	switch c {
+	case STATUS_OK:
+		return "ok"
+	case STATUS_TEMPORARY:
+		return "temporary"
+	case STATUS_PERMANENT:
+		return "permanent"
+	}
+	return fmt.Sprintf("StatusClass(%d)", uint8(c))
+}
+
+// StatusInfo describes a command_status
+type StatusInfo struct {
+	Name  string      // Constant name, e.g. ESME_RTHROTTLED
+	Text  string      // Human readable description
+	Class StatusClass // Whether a retry may succeed
+}
+
+// statusCatalog describes every command_status defined in this package.
+// Besides the queue, throttling and system errors of the specification,
+// errors that depend on the state of the session or of a downstream
+// system are temporary; everything else is a property of the request and
+// permanent.
+var statusCatalog = map[uint32]StatusInfo{
+	ESME_ROK:                        {"ESME_ROK", "No Error", STATUS_OK},
+	ESME_RINVMSGLEN:                 {"ESME_RINVMSGLEN", "Message Length is invalid", STATUS_PERMANENT},
+	ESME_RINVCMDLEN:                 {"ESME_RINVCMDLEN", "Command Length is invalid", STATUS_PERMANENT},
+	ESME_RINVCMDID:                  {"ESME_RINVCMDID", "Invalid Command ID", STATUS_PERMANENT},
+	ESME_RINVBNDSTS:                 {"ESME_RINVBNDSTS", "Incorrect BIND Status for given command", STATUS_TEMPORARY},
+	ESME_RALYBND:                    {"ESME_RALYBND", "ESME Already in Bound State", STATUS_PERMANENT},
+	ESME_RINVPRTFLG:                 {"ESME_RINVPRTFLG", "Invalid Priority Flag", STATUS_PERMANENT},
+	ESME_RINVREGDLVFLG:              {"ESME_RINVREGDLVFLG", "Invalid Registered Delivery Flag", STATUS_PERMANENT},
+	ESME_RSYSERR:                    {"ESME_RSYSERR", "System Error", STATUS_TEMPORARY},
+	ESME_RINVSRCADR:                 {"ESME_RINVSRCADR", "Invalid Source Address", STATUS_PERMANENT},
+	ESME_RINVDSTADR:                 {"ESME_RINVDSTADR", "Invalid Dest Addr", STATUS_PERMANENT},
+	ESME_RINVMSGID:                  {"ESME_RINVMSGID", "Message ID is invalid", STATUS_PERMANENT},
+	ESME_RBINDFAIL:                  {"ESME_RBINDFAIL", "Bind Failed", STATUS_PERMANENT},
+	ESME_RINVPASWD:                  {"ESME_RINVPASWD", "Invalid Password", STATUS_PERMANENT},
+	ESME_RINVSYSID:                  {"ESME_RINVSYSID", "Invalid System ID", STATUS_PERMANENT},
+	ESME_RCANCELFAIL:                {"ESME_RCANCELFAIL", "Cancel SM Failed", STATUS_PERMANENT},
+	ESME_RREPLACEFAIL:               {"ESME_RREPLACEFAIL", "Replace SM Failed", STATUS_PERMANENT},
+	ESME_RMSGQFUL:                   {"ESME_RMSGQFUL", "Message Queue Full", STATUS_TEMPORARY},
+	ESME_RINVSERTYP:                 {"ESME_RINVSERTYP", "Invalid Service Type", STATUS_PERMANENT},
+	ESME_RINVNUMDESTS:               {"ESME_RINVNUMDESTS", "Invalid number of destinations", STATUS_PERMANENT},
+	ESME_RINVDLNAME:                 {"ESME_RINVDLNAME", "Invalid Distribution List name", STATUS_PERMANENT},
+	ESME_RINVDESTFLAG:               {"ESME_RINVDESTFLAG", "Destination flag is invalid", STATUS_PERMANENT},
+	ESME_RINVSUBREP:                 {"ESME_RINVSUBREP", "Invalid 'submit with replace' request", STATUS_PERMANENT},
+	ESME_RINVESMCLASS:               {"ESME_RINVESMCLASS", "Invalid esm_class field data", STATUS_PERMANENT},
+	ESME_RCNTSUBDL:                  {"ESME_RCNTSUBDL", "Cannot Submit to Distribution List", STATUS_PERMANENT},
+	ESME_RSUBMITFAIL:                {"ESME_RSUBMITFAIL", "submit_sm or submit_multi failed", STATUS_PERMANENT},
+	ESME_RINVSRCTON:                 {"ESME_RINVSRCTON", "Invalid Source address TON", STATUS_PERMANENT},
+	ESME_RINVSRCNPI:                 {"ESME_RINVSRCNPI", "Invalid Source address NPI", STATUS_PERMANENT},
+	ESME_RINVDSTTON:                 {"ESME_RINVDSTTON", "Invalid Destination address TON", STATUS_PERMANENT},
+	ESME_RINVDSTNPI:                 {"ESME_RINVDSTNPI", "Invalid Destination address NPI", STATUS_PERMANENT},
+	ESME_RINVSYSTYP:                 {"ESME_RINVSYSTYP", "Invalid system_type field", STATUS_PERMANENT},
+	ESME_RINVREPFLAG:                {"ESME_RINVREPFLAG", "Invalid replace_if_present flag", STATUS_PERMANENT},
+	ESME_RINVNUMMSGS:                {"ESME_RINVNUMMSGS", "Invalid number of messages", STATUS_PERMANENT},
+	ESME_RTHROTTLED:                 {"ESME_RTHROTTLED", "Throttling error", STATUS_TEMPORARY},
+	ESME_RINVSCHED:                  {"ESME_RINVSCHED", "Invalid Scheduled Delivery Time", STATUS_PERMANENT},
+	ESME_RINVEXPIRY:                 {"ESME_RINVEXPIRY", "Invalid message validity period (Expiry time)", STATUS_PERMANENT},
+	ESME_RINVDFTMSGID:               {"ESME_RINVDFTMSGID", "Predefined Message Invalid or Not Found", STATUS_PERMANENT},
+	ESME_RX_T_APPN:                  {"ESME_RX_T_APPN", "ESME Receiver Temporary App Error Code", STATUS_TEMPORARY},
+	ESME_RX_P_APPN:                  {"ESME_RX_P_APPN", "ESME Receiver Permanent App Error Code", STATUS_PERMANENT},
+	ESME_RX_R_APPN:                  {"ESME_RX_R_APPN", "ESME Receiver Reject Message Error Code", STATUS_PERMANENT},
+	ESME_RQUERYFAIL:                 {"ESME_RQUERYFAIL", "query_sm request failed", STATUS_PERMANENT},
+	ESME_RINVOPTPARSTREAM:           {"ESME_RINVOPTPARSTREAM", "Error in the optional part of the PDU Body", STATUS_PERMANENT},
+	ESME_ROPTPARNOTALLWD:            {"ESME_ROPTPARNOTALLWD", "Optional Parameter not allowed", STATUS_PERMANENT},
+	ESME_RINVPARLEN:                 {"ESME_RINVPARLEN", "Invalid Parameter Length", STATUS_PERMANENT},
+	ESME_RMISSINGOPTPARAM:           {"ESME_RMISSINGOPTPARAM", "Expected Optional Parameter missing", STATUS_PERMANENT},
+	ESME_RINVOPTPARAMVAL:            {"ESME_RINVOPTPARAMVAL", "Invalid Optional Parameter Value", STATUS_PERMANENT},
+	ESME_RDELIVERYFAILURE:           {"ESME_RDELIVERYFAILURE", "Delivery Failure (used for data_sm_resp)", STATUS_TEMPORARY},
+	ESME_RUNKNOWNERR:                {"ESME_RUNKNOWNERR", "Unknown Error", STATUS_PERMANENT},
+	ESME_RMESSAGE_TOO_LONG:          {"ESME_RMESSAGE_TOO_LONG", "Message too long", STATUS_PERMANENT},
+	ESME_RSERVICE_TYPE_NOT_FOUND:    {"ESME_RSERVICE_TYPE_NOT_FOUND", "Service type not found", STATUS_PERMANENT},
+	ESME_ROPER_NOT_ALLOWED:          {"ESME_ROPER_NOT_ALLOWED", "Operation not allowed", STATUS_PERMANENT},
+	ESME_RSERVICE_UNAVAILABLE:       {"ESME_RSERVICE_UNAVAILABLE", "Service unavailable", STATUS_TEMPORARY},
+	ESME_RSERVICE_DENIED:            {"ESME_RSERVICE_DENIED", "Service denied", STATUS_PERMANENT},
+	ESME_RINVALID_REFERENCE:         {"ESME_RINVALID_REFERENCE", "Invalid reference number", STATUS_PERMANENT},
+	ESME_RINVALID_DELIVERY_TIME:     {"ESME_RINVALID_DELIVERY_TIME", "Invalid delivery time", STATUS_PERMANENT},
+	ESME_RINVALID_DESTS:             {"ESME_RINVALID_DESTS", "Invalid destinations", STATUS_PERMANENT},
+	ESME_RUNKNOWN_DEST:              {"ESME_RUNKNOWN_DEST", "Unknown destination", STATUS_PERMANENT},
+	ESME_RDEST_UNAVAILABLE:          {"ESME_RDEST_UNAVAILABLE", "Destination unavailable", STATUS_TEMPORARY},
+	ESME_RDEST_FLAGGED:              {"ESME_RDEST_FLAGGED", "Destination flagged as invalid", STATUS_PERMANENT},
+	ESME_RDUPLICATE_MSGID:           {"ESME_RDUPLICATE_MSGID", "Duplicate message ID", STATUS_PERMANENT},
+	ESME_RPAYLOAD_NOT_SUPPORTED:     {"ESME_RPAYLOAD_NOT_SUPPORTED", "Payload not supported", STATUS_PERMANENT},
+	ESME_RAPP_BUSY:                  {"ESME_RAPP_BUSY", "Application busy", STATUS_TEMPORARY},
+	ESME_RAPP_QUEUE_FULL:            {"ESME_RAPP_QUEUE_FULL", "Application queue full", STATUS_TEMPORARY},
+	ESME_RAPP_NOT_AVAILABLE:         {"ESME_RAPP_NOT_AVAILABLE", "Application not available", STATUS_TEMPORARY},
+	ESME_RAPP_INVALID_REQUEST:       {"ESME_RAPP_INVALID_REQUEST", "Invalid application request", STATUS_PERMANENT},
+	ESME_RAUTHENTICATION_FAILURE:    {"ESME_RAUTHENTICATION_FAILURE", "Authentication failure", STATUS_PERMANENT},
+	ESME_RSECURITY_VIOLATION:        {"ESME_RSECURITY_VIOLATION", "Security violation", STATUS_PERMANENT},
+	ESME_RPROHIBITED_BY_SECURITY:    {"ESME_RPROHIBITED_BY_SECURITY", "Prohibited by security settings", STATUS_PERMANENT},
+	ESME_RINSUFFICIENT_CREDITS:      {"ESME_RINSUFFICIENT_CREDITS", "Insufficient credits", STATUS_PERMANENT},
+	ESME_RBILLING_FAILED:            {"ESME_RBILLING_FAILED", "Billing operation failed", STATUS_TEMPORARY},
+	ESME_RBILLING_NOT_SUPPORTED:     {"ESME_RBILLING_NOT_SUPPORTED", "Billing not supported", STATUS_PERMANENT},
+	ESME_RBATCH_SUBMISSION_FAILED:   {"ESME_RBATCH_SUBMISSION_FAILED", "Batch submission failed", STATUS_TEMPORARY},
+	ESME_RBATCH_SIZE_EXCEEDED:       {"ESME_RBATCH_SIZE_EXCEEDED", "Batch size exceeded", STATUS_PERMANENT},
+	ESME_RINVALID_MSG_FORMAT:        {"ESME_RINVALID_MSG_FORMAT", "Invalid message format", STATUS_PERMANENT},
+	ESME_RUNSUPPORTED_CHARSET:       {"ESME_RUNSUPPORTED_CHARSET", "Unsupported character set", STATUS_PERMANENT},
+	ESME_RINVALID_ENCODING:          {"ESME_RINVALID_ENCODING", "Invalid encoding", STATUS_PERMANENT},
+	ESME_RPROTOCOL_VERSION:          {"ESME_RPROTOCOL_VERSION", "Protocol version mismatch", STATUS_PERMANENT},
+	ESME_RSEQUENCE_ERROR:            {"ESME_RSEQUENCE_ERROR", "Sequence number error", STATUS_PERMANENT},
+	ESME_RINVALID_TLV:               {"ESME_RINVALID_TLV", "Invalid TLV parameter", STATUS_PERMANENT},
+	ESME_RTEMP_NETWORK_ERROR:        {"ESME_RTEMP_NETWORK_ERROR", "Temporary network error", STATUS_TEMPORARY},
+	ESME_RTEMP_SYSTEM_ERROR:         {"ESME_RTEMP_SYSTEM_ERROR", "Temporary system error", STATUS_TEMPORARY},
+	ESME_RTEMP_APP_ERROR:            {"ESME_RTEMP_APP_ERROR", "Temporary application error", STATUS_TEMPORARY},
+	ESME_RPERM_NETWORK_ERROR:        {"ESME_RPERM_NETWORK_ERROR", "Permanent network error", STATUS_PERMANENT},
+	ESME_RPERM_SYSTEM_ERROR:         {"ESME_RPERM_SYSTEM_ERROR", "Permanent system error", STATUS_PERMANENT},
+	ESME_RPERM_APP_ERROR:            {"ESME_RPERM_APP_ERROR", "Permanent application error", STATUS_PERMANENT},
+	ESME_RBCAST_QUERY_FAIL:          {"ESME_RBCAST_QUERY_FAIL", "Broadcast query operation failed", STATUS_PERMANENT},
+	ESME_RBCAST_CANCEL_FAIL:         {"ESME_RBCAST_CANCEL_FAIL", "Broadcast cancel operation failed", STATUS_PERMANENT},
+	ESME_RBCAST_REPLACE_FAIL:        {"ESME_RBCAST_REPLACE_FAIL", "Broadcast replace operation failed", STATUS_PERMANENT},
+	ESME_RBCAST_MSG_NOT_FOUND:       {"ESME_RBCAST_MSG_NOT_FOUND", "Broadcast message not found", STATUS_PERMANENT},
+	ESME_RBCAST_AREA_FORMAT_INVALID: {"ESME_RBCAST_AREA_FORMAT_INVALID", "Broadcast area format invalid", STATUS_PERMANENT},
+	ESME_RBCAST_AREA_NOT_SUPPORTED:  {"ESME_RBCAST_AREA_NOT_SUPPORTED", "Broadcast area not supported", STATUS_PERMANENT},
+	ESME_RBCAST_PRIORITY_INVALID:    {"ESME_RBCAST_PRIORITY_INVALID", "Broadcast priority invalid", STATUS_PERMANENT},
+	ESME_RBCAST_CHANNEL_INVALID:     {"ESME_RBCAST_CHANNEL_INVALID", "Broadcast channel invalid", STATUS_PERMANENT},
+	ESME_RBCAST_CHANNEL_NOT_AVAIL:   {"ESME_RBCAST_CHANNEL_NOT_AVAIL", "Broadcast channel not available", STATUS_TEMPORARY},
+	ESME_RBCAST_EMERGENCY_NOT_SUPP:  {"ESME_RBCAST_EMERGENCY_NOT_SUPP", "Emergency broadcast not supported", STATUS_PERMANENT},
+	ESME_RBCAST_RESPONSE_TIMEOUT:    {"ESME_RBCAST_RESPONSE_TIMEOUT", "Broadcast response timeout", STATUS_TEMPORARY},
+}
+
+// statusRange is a block of codes set aside for one kind of error
+type statusRange struct {
+	start, end uint32
+	text       string
+	class      StatusClass
+}
+
+// statusRanges describe the codes that are not in the catalog by the block
+// they fall in
+var statusRanges = []statusRange{
+	{ESME_NETWORK_ERROR_START, ESME_APP_ERROR_START - 1, "Network error", STATUS_PERMANENT},
+	{ESME_APP_ERROR_START, ESME_SECURITY_ERROR_START - 1, "Application error", STATUS_PERMANENT},
+	{ESME_SECURITY_ERROR_START, ESME_BILLING_ERROR_START - 1, "Security error", STATUS_PERMANENT},
+	{ESME_BILLING_ERROR_START, ESME_BATCH_ERROR_START - 1, "Billing error", STATUS_PERMANENT},
+	{ESME_BATCH_ERROR_START, ESME_FORMAT_ERROR_START - 1, "Batch error", STATUS_PERMANENT},
+	{ESME_FORMAT_ERROR_START, ESME_PROTOCOL_ERROR_START - 1, "Format error", STATUS_PERMANENT},
+	{ESME_PROTOCOL_ERROR_START, ESME_TEMP_ERROR_START - 1, "Protocol error", STATUS_PERMANENT},
+	{ESME_TEMP_ERROR_START, ESME_PERM_ERROR_START - 1, "Temporary error", STATUS_TEMPORARY},
+	{ESME_PERM_ERROR_START, ESME_RESERVED_START - 1, "Permanent error", STATUS_PERMANENT},
+	{ESME_RESERVED_START, ESME_RESERVED_END, "Reserved error", STATUS_PERMANENT},
+}
+
+// LookupStatus returns the description of a command_status in the catalog
+func LookupStatus(status uint32) (StatusInfo, bool) {
+	info, ok := statusCatalog[status]
+	return info, ok
+}
+
+// statusRangeOf returns the block a code outside the catalog falls in
+func statusRangeOf(status uint32) (statusRange, bool) {
+	for _, r := range statusRanges {
+		if status >= r.start && status <= r.end {
+			return r, true
+		}
+	}
+	return statusRange{}, false
+}
+
+// StatusName returns the constant name of a command_status, or its hex
+// value if unknown
+func StatusName(status uint32) string {
+	if info, ok := statusCatalog[status]; ok {
+		return info.Name
+	}
+	return fmt.Sprintf("0x%08X", status)
+}
+
+// StatusByName returns the command_status of a constant name or of a 0x
+// prefixed hex value as returned by StatusName
+func StatusByName(name string) (uint32, bool) {
+	for status, info := range statusCatalog {
+		if info.Name == name {
+			return status, true
+		}
+	}
+	if s, ok := strings.CutPrefix(name, "0x"); ok {
+		if status, err := strconv.ParseUint(s, 16, 32); err == nil {
+			return uint32(status), true
+		}
+	}
+	return 0, false
+}
+
+// StatusText returns a description of a command_status. Unknown codes are
+// described by the block they fall in.
+func StatusText(status uint32) string {
+	if info, ok := statusCatalog[status]; ok {
+		return info.Text
+	}
+	if r, ok := statusRangeOf(status); ok {
+		return fmt.Sprintf("%s 0x%08X", r.text, status)
+	}
+	return fmt.Sprintf("Unknown error 0x%08X", status)
+}
+
+// ClassifyStatus returns whether a command_status is a success, a temporary
+// or a permanent error. Unknown codes are permanent unless they fall in the
+// temporary error block.
+func ClassifyStatus(status uint32) StatusClass {
+	if info, ok := statusCatalog[status]; ok {
+		return info.Class
+	}
+	if r, ok := statusRangeOf(status); ok {
+		return r.class
+	}
+	return STATUS_PERMANENT
+}
+
+// IsTemporary reports whether a command failed with an error that may go
+// away if the command is retried later
+func IsTemporary(status uint32) bool {
+	return ClassifyStatus(status) == STATUS_TEMPORARY
+}
+
+// IsPermanent reports whether a command failed with an error that will not
+// go away by retrying it
+func IsPermanent(status uint32) bool {
+	return ClassifyStatus(status) == STATUS_PERMANENT
+}
@@ -0,0 +1,129 @@
+package pdu
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidStatusMap = errors.New("invalid status map")
+)
+
+// StatusMap translates between the command_status codes used internally and
+// the vendor specific codes of one supplier or customer. Codes without a
+// mapping pass through unchanged. A vendor code should be translated with
+// FromVendor before it is classified.
+type StatusMap struct {
+	toVendor   map[uint32]uint32
+	fromVendor map[uint32]uint32
+}
+
+// NewStatusMap creates an empty StatusMap
+func NewStatusMap() *StatusMap {
+	return &StatusMap{
+		toVendor:   make(map[uint32]uint32),
+		fromVendor: make(map[uint32]uint32),
+	}
+}
+
+// Add maps an internal and a vendor code onto each other. When several
+// codes map onto the same code, the first mapping added wins in the
+// reverse direction.
+func (m *StatusMap) Add(internal, vendor uint32) *StatusMap {
+	return m.AddToVendor(internal, vendor).AddFromVendor(vendor, internal)
+}
+
+// AddToVendor maps an internal code to a vendor code, but not back
+func (m *StatusMap) AddToVendor(internal, vendor uint32) *StatusMap {
+	if _, ok := m.toVendor[internal]; !ok {
+		m.toVendor[internal] = vendor
+	}
+	return m
+}
+
+// AddFromVendor maps a vendor code to an internal code, but not back
+func (m *StatusMap) AddFromVendor(vendor, internal uint32) *StatusMap {
+	if _, ok := m.fromVendor[vendor]; !ok {
+		m.fromVendor[vendor] = internal
+	}
+	return m
+}
+
+// ToVendor returns the vendor code for an internal code
+func (m *StatusMap) ToVendor(internal uint32) uint32 {
+	if vendor, ok := m.toVendor[internal]; ok {
+		return vendor
+	}
+	return internal
+}
+
+// FromVendor returns the internal code for a vendor code
+func (m *StatusMap) FromVendor(vendor uint32) uint32 {
+	if internal, ok := m.fromVendor[vendor]; ok {
+		return internal
+	}
+	return vendor
+}
+
+// LoadStatusMap reads a StatusMap from a text table with one mapping per
+// line: an internal code, a direction and a vendor code. Codes are constant
+// names or numbers, directions are "=" for both ways, ">" for internal to
+// vendor only and "<" for vendor to internal only. Text after a "#" is a
+// comment.
+//
+//	ESME_RTHROTTLED  =  0x00000401
+//	ESME_RSYSERR     <  0x00000402  # vendor database error
+//	ESME_RX_T_APPN   >  0x00000058
+func LoadStatusMap(r io.Reader) (*StatusMap, error) {
+	m := NewStatusMap()
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%w: line %d: expected internal code, direction and vendor code", ErrInvalidStatusMap, line)
+		}
+
+		internal, err := parseStatus(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidStatusMap, line, err)
+		}
+		vendor, err := parseStatus(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidStatusMap, line, err)
+		}
+		switch fields[1] {
+		case "=":
+			m.Add(internal, vendor)
+		case ">":
+			m.AddToVendor(internal, vendor)
+		case "<":
+			m.AddFromVendor(vendor, internal)
+		default:
+			return nil, fmt.Errorf("%w: line %d: unknown direction %q", ErrInvalidStatusMap, line, fields[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// parseStatus parses a constant name or a decimal, 0x hex or 0 octal number
+func parseStatus(s string) (uint32, error) {
+	if status, ok := StatusByName(s); ok {
+		return status, nil
+	}
+	status, err := strconv.ParseUint(s, 0, 32)
+	if err != nil {
+		return 0, fmt.Errorf("unknown status %q", s)
+	}
+	return uint32(status), nil
+}
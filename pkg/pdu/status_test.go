@@ -0,0 +1,189 @@
+package pdu
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLoadStatusMap(t *testing.T) {
+	table := `
+# Supplier X
+ESME_RTHROTTLED   =  0x00000401
+ESME_RSYSERR      <  0x00000402   # vendor database error
+ESME_RSYSERR      <  0x00000403
+ESME_RX_T_APPN    >  0x00000058
+ESME_RMSGQFUL     =  0x00000401   # ignored back, 0x401 is taken
+ESME_RMSGQFUL     =  1090
+0x00000045        =  ESME_RX_P_APPN
+`
+	m, err := LoadStatusMap(strings.NewReader(table))
+	if err != nil {
+		t.Fatalf("LoadStatusMap: %v", err)
+	}
+
+	toVendor := []struct{ internal, vendor uint32 }{
+		{ESME_RTHROTTLED, 0x401},
+		{ESME_RSYSERR, ESME_RSYSERR}, // "<" only maps back
+		{ESME_RX_T_APPN, 0x58},
+		{ESME_RMSGQFUL, 0x401}, // First mapping wins
+		{ESME_RSUBMITFAIL, ESME_RX_P_APPN},
+		{ESME_RINVDSTADR, ESME_RINVDSTADR}, // Unmapped
+	}
+	for _, tt := range toVendor {
+		if got := m.ToVendor(tt.internal); got != tt.vendor {
+			t.Errorf("ToVendor(%s) = 0x%08X, want 0x%08X", StatusName(tt.internal), got, tt.vendor)
+		}
+	}
+
+	fromVendor := []struct{ vendor, internal uint32 }{
+		{0x401, ESME_RTHROTTLED}, // First mapping wins
+		{0x402, ESME_RSYSERR},
+		{0x403, ESME_RSYSERR},
+		{0x58, 0x58}, // ">" only maps forward
+		{1090, ESME_RMSGQFUL},
+		{ESME_RX_P_APPN, ESME_RSUBMITFAIL},
+		{0x499, 0x499}, // Unmapped
+	}
+	for _, tt := range fromVendor {
+		if got := m.FromVendor(tt.vendor); got != tt.internal {
+			t.Errorf("FromVendor(0x%08X) = %s, want %s", tt.vendor, StatusName(got), StatusName(tt.internal))
+		}
+	}
+}
+
+func TestLoadStatusMapInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		table string
+		line  int
+	}{
+		{"two fields", "ESME_RSYSERR = 0x401\nESME_RTHROTTLED 0x402", 2},
+		{"four fields", "# header\n\nESME_RSYSERR = 0x401 0x402", 3},
+		{"unknown name", "ESME_RNOSUCH = 0x401", 1},
+		{"bad number", "ESME_RSYSERR = 0x40G", 1},
+		{"too large", "ESME_RSYSERR = 0x100000000", 1},
+		{"bad direction", "ESME_RSYSERR\t=>\t0x401", 1},
+	}
+	for _, tt := range tests {
+		_, err := LoadStatusMap(strings.NewReader(tt.table))
+		if !errors.Is(err, ErrInvalidStatusMap) {
+			t.Errorf("%s: error = %v, want ErrInvalidStatusMap", tt.name, err)
+			continue
+		}
+		if want := fmt.Sprintf("line %d:", tt.line); !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: error %q does not name %s", tt.name, err, want)
+		}
+	}
+
+	if m, err := LoadStatusMap(strings.NewReader("# nothing but comments\n\n")); err != nil || m.ToVendor(ESME_RSYSERR) != ESME_RSYSERR {
+		t.Errorf("empty table = %v, %v", m, err)
+	}
+}
+
+func TestStatusByName(t *testing.T) {
+	tests := []struct {
+		name   string
+		status uint32
+		ok     bool
+	}{
+		{"ESME_ROK", ESME_ROK, true},
+		{"ESME_RTHROTTLED", ESME_RTHROTTLED, true},
+		{"ESME_RBCAST_RESPONSE_TIMEOUT", ESME_RBCAST_RESPONSE_TIMEOUT, true},
+		{"0x00000058", 0x58, true},
+		{"0x0ABC", 0xABC, true},
+		{StatusName(0x0A12), 0x0A12, true},
+		{"esme_rthrottled", 0, false},
+		{"88", 0, false},
+		{"0x", 0, false},
+		{"0x100000000", 0, false},
+	}
+	for _, tt := range tests {
+		status, ok := StatusByName(tt.name)
+		if status != tt.status || ok != tt.ok {
+			t.Errorf("StatusByName(%q) = 0x%08X, %v, want 0x%08X, %v", tt.name, status, ok, tt.status, tt.ok)
+		}
+	}
+
+	// Every catalogued name resolves to its code
+	for status, info := range statusCatalog {
+		if got, ok := StatusByName(info.Name); !ok || got != status {
+			t.Errorf("StatusByName(%q) = 0x%08X, %v", info.Name, got, ok)
+		}
+	}
+}
+
+func TestClassifyStatus(t *testing.T) {
+	tests := []struct {
+		status uint32
+		class  StatusClass
+	}{
+		{ESME_ROK, STATUS_OK},
+		{ESME_RTHROTTLED, STATUS_TEMPORARY},
+		{ESME_RMSGQFUL, STATUS_TEMPORARY},
+		{ESME_RINVDSTADR, STATUS_PERMANENT},
+		{ESME_RTEMP_NETWORK_ERROR, STATUS_TEMPORARY},
+		{ESME_RPERM_NETWORK_ERROR, STATUS_PERMANENT},
+		{0x0A00, STATUS_TEMPORARY},
+		{0x0A7F, STATUS_TEMPORARY},
+		{0x0AFF, STATUS_TEMPORARY},
+		{0x0B00, STATUS_PERMANENT},
+		{0x0B42, STATUS_PERMANENT},
+		{0x0BFF, STATUS_PERMANENT},
+		{0x0999, STATUS_PERMANENT},
+		{0x0C00, STATUS_PERMANENT},
+		{0x10000, STATUS_PERMANENT},
+		{0x7F, STATUS_PERMANENT},
+	}
+	for _, tt := range tests {
+		if got := ClassifyStatus(tt.status); got != tt.class {
+			t.Errorf("ClassifyStatus(0x%08X) = %s, want %s", tt.status, got, tt.class)
+		}
+		if got := IsTemporary(tt.status); got != (tt.class == STATUS_TEMPORARY) {
+			t.Errorf("IsTemporary(0x%08X) = %v", tt.status, got)
+		}
+		if got := IsPermanent(tt.status); got != (tt.class == STATUS_PERMANENT) {
+			t.Errorf("IsPermanent(0x%08X) = %v", tt.status, got)
+		}
+	}
+
+	if got := StatusText(0x0A42); got != "Temporary error 0x00000A42" {
+		t.Errorf("StatusText(0x0A42) = %q", got)
+	}
+	if got := StatusText(0x12345); got != "Unknown error 0x00012345" {
+		t.Errorf("StatusText(0x12345) = %q", got)
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status uint32
+		ok     bool
+	}{
+		{"status error", NewStatusError(ESME_RTHROTTLED, "slow down"), ESME_RTHROTTLED, true},
+		{"wrapped", fmt.Errorf("submit: %w", ErrInvalidNumDests), ESME_RINVNUMDESTS, true},
+		{"wrapped twice", fmt.Errorf("route: %w", fmt.Errorf("submit: %w", ErrorFromStatus(0x0A81))), 0x0A81, true},
+		{"joined", errors.Join(errors.New("first"), ErrInvalidDestFlag), ESME_RINVDESTFLAG, true},
+		{"decode error", &DecodeError{Field: "dl_name", Status: ESME_RINVDLNAME, Err: ErrInvalidDLName}, ESME_RINVDLNAME, true},
+		{"tlv error", fmt.Errorf("encode: %w", &TLVError{Tag: TLV_BROADCAST_REP_NUM, Status: ESME_RMISSINGOPTPARAM}), ESME_RMISSINGOPTPARAM, true},
+		{"plain", errors.New("plain"), 0, false},
+		{"nil", nil, 0, false},
+	}
+	for _, tt := range tests {
+		status, ok := ErrorStatus(tt.err)
+		if status != tt.status || ok != tt.ok {
+			t.Errorf("%s: ErrorStatus = 0x%08X, %v, want 0x%08X, %v", tt.name, status, ok, tt.status, tt.ok)
+		}
+	}
+
+	if ErrorFromStatus(ESME_ROK) != nil {
+		t.Error("ErrorFromStatus(ESME_ROK) is not nil")
+	}
+	var serr *StatusError
+	if err := ErrorFromStatus(0x0A81); !errors.As(err, &serr) || !serr.Temporary() || err.Error() != "Temporary error 0x00000A81" {
+		t.Errorf("ErrorFromStatus(0x0A81) = %v", err)
+	}
+}
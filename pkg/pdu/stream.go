@@ -0,0 +1,244 @@
+package pdu
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+const (
+	// HeaderLength is the size of the PDU header in bytes
+	HeaderLength = 16
+
+	// DefaultMaxCommandLength is the largest command_length accepted by a
+	// Reader unless configured otherwise. It leaves room for a full 64K
+	// message_payload plus the mandatory fields.
+	DefaultMaxCommandLength uint32 = 128 * 1024
+
+	// maxPooledFrame is the capacity above which frame buffers are left to
+	// the garbage collector instead of being pooled
+	maxPooledFrame = 64 * 1024
+)
+
+// framePool recycles the buffers frames are read into and encoded in
+var framePool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 1024)
+		return &b
+	},
+}
+
+// getFrameBuffer returns an empty buffer from the pool
+func getFrameBuffer() *[]byte {
+	return framePool.Get().(*[]byte)
+}
+
+// putFrameBuffer returns a buffer to the pool
+func putFrameBuffer(b *[]byte) {
+	if cap(*b) > maxPooledFrame {
+		return
+	}
+	*b = (*b)[:0]
+	framePool.Put(b)
+}
+
+var (
+	ErrCommandLengthTooLarge = NewStatusError(ESME_RINVCMDLEN, "command length exceeds maximum")
+)
+
+// FrameError is returned by Reader when a frame cannot be turned into a PDU.
+// Status is the command_status to send back, either in a generic_nack or in
+// the response of the PDU, and Fatal reports that the stream is no longer
+// in sync and the connection has to be closed.
+type FrameError struct {
+	Header *Header
+	Status uint32
+	Fatal  bool
+	Err    error
+}
+
+// Error returns the error message
+func (e *FrameError) Error() string {
+	if e.Header != nil {
+		return fmt.Sprintf("pdu frame (command_id 0x%08X, sequence %d): %v", e.Header.CommandID, e.Header.SequenceNumber, e.Err)
+	}
+	return fmt.Sprintf("pdu frame: %v", e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *FrameError) Unwrap() error {
+	return e.Err
+}
+
+// CommandStatus returns the command_status to answer with
+func (e *FrameError) CommandStatus() uint32 {
+	return e.Status
+}
+
+func newFrameError(header *Header, fatal bool, err error) *FrameError {
+	status, ok := ErrorStatus(err)
+	if !ok {
+		status = ESME_RSYSERR
+	}
+	return &FrameError{Header: header, Status: status, Fatal: fatal, Err: err}
+}
+
+// Reader reads length-prefixed PDU frames from an io.Reader
+type Reader struct {
+	r        io.Reader
+	registry *Registry
+	version  uint32
+	maxLen   uint32
+	header   [HeaderLength]byte
+}
+
+// NewReader creates a Reader decoding with the default registry and
+// limited to DefaultMaxCommandLength
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		r:        r,
+		registry: DefaultRegistry,
+		version:  SMPP_V50,
+		maxLen:   DefaultMaxCommandLength,
+	}
+}
+
+// SetMaxCommandLength sets the largest command_length the reader accepts
+func (r *Reader) SetMaxCommandLength(n uint32) {
+	if n < HeaderLength {
+		n = HeaderLength
+	}
+	r.maxLen = n
+}
+
+// SetRegistry sets the registry used by ReadPDU
+func (r *Reader) SetRegistry(registry *Registry) {
+	r.registry = registry
+}
+
+// SetVersion sets the negotiated interface version PDUs are decoded with
+func (r *Reader) SetVersion(version uint32) {
+	r.version = NegotiateVersion(version)
+}
+
+// ReadFrame reads one complete PDU frame including its header. I/O errors
+// are returned as they are; a command_length outside [16, max] is reported
+// as a fatal *FrameError because the next frame boundary is unknown.
+func (r *Reader) ReadFrame() ([]byte, error) {
+	return r.readFrame(nil)
+}
+
+// readFrame reads one frame like ReadFrame, into buf when it is large enough
+func (r *Reader) readFrame(buf []byte) ([]byte, error) {
+	if _, err := io.ReadFull(r.r, r.header[:]); err != nil {
+		return nil, err
+	}
+
+	header := &Header{}
+	if err := header.Unmarshal(r.header[:]); err != nil {
+		return nil, newFrameError(nil, true, err)
+	}
+
+	if header.CommandLength < HeaderLength {
+		return nil, newFrameError(header, true, ErrPDUTooShort)
+	}
+	if header.CommandLength > r.maxLen {
+		return nil, newFrameError(header, true, fmt.Errorf("%w: %d > %d", ErrCommandLengthTooLarge, header.CommandLength, r.maxLen))
+	}
+
+	n := int(header.CommandLength)
+	if cap(buf) < n {
+		buf = make([]byte, n)
+	}
+	frame := buf[:n]
+	copy(frame, r.header[:])
+	if _, err := io.ReadFull(r.r, frame[HeaderLength:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	return frame, nil
+}
+
+// ReadPDU reads and decodes the next PDU. Errors confined to a single frame,
+// such as an unknown command_id or a malformed body, are returned as a
+// non-fatal *FrameError and leave the stream in sync; the PDU is returned
+// alongside when its type is known so the caller can answer it. The frame
+// is read into a pooled buffer; the PDU holds copies of its fields.
+func (r *Reader) ReadPDU() (PDU, error) {
+	buf := getFrameBuffer()
+	defer putFrameBuffer(buf)
+
+	frame, err := r.readFrame(*buf)
+	if err != nil {
+		return nil, err
+	}
+	*buf = frame
+
+	header := &Header{}
+	if err := header.Unmarshal(frame[:HeaderLength]); err != nil {
+		return nil, newFrameError(nil, false, err)
+	}
+
+	p, err := r.registry.DecodeVersion(frame, r.version)
+	if err != nil {
+		return p, newFrameError(header, false, err)
+	}
+
+	return p, nil
+}
+
+// Writer writes PDU frames to an io.Writer. It is safe for concurrent use.
+type Writer struct {
+	mu  sync.Mutex
+	w   io.Writer
+	ctx *Context
+}
+
+// NewWriter creates a Writer encoding for SMPP 5.0 with the default registry
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w, ctx: NewContext(SMPP_V50)}
+}
+
+// SetVersion sets the negotiated interface version PDUs are encoded for
+func (w *Writer) SetVersion(version uint32) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.ctx = &Context{Version: NegotiateVersion(version), Registry: w.ctx.Registry}
+}
+
+// SetRegistry sets the registry whose TLV schema decides which optional
+// parameters the peer's version allows
+func (w *Writer) SetRegistry(registry *Registry) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.ctx = &Context{Version: w.ctx.Version, Registry: registry}
+}
+
+// WritePDU marshals a PDU for the negotiated version and writes it as a
+// single frame
+func (w *Writer) WritePDU(p PDU) error {
+	w.mu.Lock()
+	ctx := w.ctx
+	w.mu.Unlock()
+
+	buf := getFrameBuffer()
+	defer putFrameBuffer(buf)
+
+	data, err := ctx.AppendTo(*buf, p)
+	if err != nil {
+		return err
+	}
+	*buf = data
+	return w.WriteFrame(data)
+}
+
+// WriteFrame writes an already encoded PDU frame
+func (w *Writer) WriteFrame(frame []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := w.w.Write(frame)
+	return err
+}
@@ -0,0 +1,202 @@
+package pdu
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// enquireLinkFrame returns an encoded enquire_link with a sequence number
+func enquireLinkFrame(t *testing.T, seq uint32) []byte {
+	t.Helper()
+	p := NewEnquireLink()
+	p.Header.SequenceNumber = seq
+	frame, err := p.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return frame
+}
+
+func TestReaderCommandLength(t *testing.T) {
+	tests := []struct {
+		name   string
+		length uint32
+		max    uint32
+	}{
+		{"zero", 0, 0},
+		{"below header", HeaderLength - 1, 0},
+		{"above default", DefaultMaxCommandLength + 1, 0},
+		{"above configured", 101, 100},
+		{"huge", 0xFFFFFFFF, 0},
+	}
+	for _, tt := range tests {
+		frame := enquireLinkFrame(t, 1)
+		binary.BigEndian.PutUint32(frame, tt.length)
+		r := NewReader(bytes.NewReader(frame))
+		if tt.max != 0 {
+			r.SetMaxCommandLength(tt.max)
+		}
+		_, err := r.ReadFrame()
+		var frameErr *FrameError
+		if !errors.As(err, &frameErr) {
+			t.Errorf("%s: ReadFrame error = %v, want a FrameError", tt.name, err)
+			continue
+		}
+		if !frameErr.Fatal || frameErr.Status != ESME_RINVCMDLEN {
+			t.Errorf("%s: fatal %v, status 0x%08X", tt.name, frameErr.Fatal, frameErr.Status)
+		}
+		if frameErr.Header == nil || frameErr.Header.SequenceNumber != 1 {
+			t.Errorf("%s: header %v", tt.name, frameErr.Header)
+		}
+	}
+
+	// A frame of exactly the configured maximum is read
+	frame := enquireLinkFrame(t, 1)
+	r := NewReader(bytes.NewReader(frame))
+	r.SetMaxCommandLength(uint32(len(frame)))
+	if got, err := r.ReadFrame(); err != nil || !bytes.Equal(got, frame) {
+		t.Errorf("ReadFrame at the maximum = %X, %v", got, err)
+	}
+}
+
+func TestReaderUnknownCommand(t *testing.T) {
+	var stream []byte
+	stream = append(stream, enquireLinkFrame(t, 1)...)
+	unknown := testFrame(0x00000099, []byte{1, 2, 3})
+	binary.BigEndian.PutUint32(unknown[12:], 2)
+	stream = append(stream, unknown...)
+	stream = append(stream, testFrame(SUBMIT_SM, cstr("LONGTYP"))...)
+	stream = append(stream, enquireLinkFrame(t, 4)...)
+
+	r := NewReader(bytes.NewReader(stream))
+	if p, err := r.ReadPDU(); err != nil || p.GetHeader().SequenceNumber != 1 {
+		t.Fatalf("first ReadPDU = %v, %v", p, err)
+	}
+
+	// An unknown command_id skips its frame
+	p, err := r.ReadPDU()
+	var frameErr *FrameError
+	if !errors.As(err, &frameErr) {
+		t.Fatalf("ReadPDU error = %v, want a FrameError", err)
+	}
+	if frameErr.Fatal || frameErr.Status != ESME_RINVCMDID || p != nil {
+		t.Errorf("unknown command: fatal %v, status 0x%08X, pdu %v", frameErr.Fatal, frameErr.Status, p)
+	}
+	if frameErr.Header == nil || frameErr.Header.SequenceNumber != 2 {
+		t.Errorf("unknown command: header %v", frameErr.Header)
+	}
+
+	// A malformed body of a known command comes with the PDU to answer
+	p, err = r.ReadPDU()
+	if !errors.As(err, &frameErr) || frameErr.Fatal || frameErr.Status != ESME_RINVSERTYP {
+		t.Errorf("malformed submit_sm error = %v", err)
+	}
+	if _, ok := p.(*SubmitSM); !ok {
+		t.Errorf("malformed submit_sm returned %v", p)
+	}
+
+	if p, err := r.ReadPDU(); err != nil || p.GetHeader().SequenceNumber != 4 {
+		t.Errorf("ReadPDU after the errors = %v, %v", p, err)
+	}
+	if _, err := r.ReadPDU(); err != io.EOF {
+		t.Errorf("ReadPDU at the end error = %v, want io.EOF", err)
+	}
+}
+
+func TestReaderTruncated(t *testing.T) {
+	frame := testFrame(SUBMIT_SM_RESP, cstr("message-1"))
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"empty", nil, io.EOF},
+		{"partial header", frame[:10], io.ErrUnexpectedEOF},
+		{"header only", frame[:HeaderLength], io.ErrUnexpectedEOF},
+		{"partial body", frame[:len(frame)-1], io.ErrUnexpectedEOF},
+	}
+	for _, tt := range tests {
+		_, err := NewReader(bytes.NewReader(tt.data)).ReadFrame()
+		if err != tt.err {
+			t.Errorf("%s: ReadFrame error = %v, want %v", tt.name, err, tt.err)
+		}
+		_, err = NewReader(bytes.NewReader(tt.data)).ReadPDU()
+		if err != tt.err {
+			t.Errorf("%s: ReadPDU error = %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
+
+// lockedBuffer is a bytes.Buffer recording the size of every write
+type lockedBuffer struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	writes []int
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.writes = append(b.writes, len(p))
+	return b.buf.Write(p)
+}
+
+func TestWriterConcurrent(t *testing.T) {
+	const writers, perWriter = 8, 50
+	var out lockedBuffer
+	w := NewWriter(&out)
+
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range perWriter {
+				sm := NewSubmitSM()
+				sm.Header.SequenceNumber = uint32(i*perWriter + j + 1)
+				sm.DestinationAddr = "447700900123"
+				sm.ShortMessage = bytes.Repeat([]byte{byte('a' + i)}, j)
+				if err := w.WritePDU(sm); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(out.writes) != writers*perWriter {
+		t.Errorf("%d writes, want one per PDU", len(out.writes))
+	}
+	r := NewReader(&out.buf)
+	var seqs []uint32
+	for {
+		p, err := r.ReadPDU()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("ReadPDU after %d PDUs: %v", len(seqs), err)
+		}
+		sm := p.(*SubmitSM)
+		seq := sm.Header.SequenceNumber - 1
+		if want := bytes.Repeat([]byte{byte('a' + seq/perWriter)}, int(seq%perWriter)); !bytes.Equal(sm.ShortMessage, want) {
+			t.Errorf("sequence %d: short_message %q", seq+1, sm.ShortMessage)
+		}
+		seqs = append(seqs, seq+1)
+	}
+	slices.Sort(seqs)
+	for i, seq := range seqs {
+		if seq != uint32(i+1) {
+			t.Fatalf("sequence numbers %v", seqs)
+		}
+	}
+	if len(seqs) != writers*perWriter {
+		t.Errorf("read %d PDUs, want %d", len(seqs), writers*perWriter)
+	}
+}
@@ -0,0 +1,421 @@
+package pdu
+
+import (
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidNumDests = NewStatusError(ESME_RINVNUMDESTS, "invalid number of destinations")
+	ErrInvalidDestFlag = NewStatusError(ESME_RINVDESTFLAG, "invalid destination flag")
+	ErrInvalidDLName   = NewStatusError(ESME_RINVDLNAME, "invalid distribution list name")
+)
+
+// DestAddress represents one entry of the submit_multi dest_address list.
+// Depending on DestFlag it holds either an SME address or a distribution list name.
+type DestAddress struct {
+	DestFlag        uint8  `json:"dest_flag"`
+	DestAddrTON     uint8  `json:"dest_addr_ton"`
+	DestAddrNPI     uint8  `json:"dest_addr_npi"`
+	DestinationAddr string `json:"destination_addr"`
+	DLName          string `json:"dl_name"`
+}
+
+// IsDistributionList checks if the destination refers to a distribution list
+func (da *DestAddress) IsDistributionList() bool {
+	return da.DestFlag == DEST_FLAG_DISTRIBUTION_LIST
+}
+
+// Address returns the SME address with its TON and NPI
+func (da *DestAddress) Address() Address {
+	return Address{TON: da.DestAddrTON, NPI: da.DestAddrNPI, Addr: da.DestinationAddr}
+}
+
+// SetAddress sets the SME address with its TON and NPI
+func (da *DestAddress) SetAddress(a Address) {
+	da.DestAddrTON, da.DestAddrNPI, da.DestinationAddr = a.TON, a.NPI, a.Addr
+}
+
+// SubmitMulti represents an SMPP submit_multi PDU
+type SubmitMulti struct {
+	Header               *Header       `json:"header"`
+	ServiceType          string        `json:"service_type"`
+	SourceAddrTON        uint8         `json:"source_addr_ton"`
+	SourceAddrNPI        uint8         `json:"source_addr_npi"`
+	SourceAddr           string        `json:"source_addr"`
+	DestAddresses        []DestAddress `json:"dest_addresses"`
+	ESMClass             uint8         `json:"esm_class"`
+	ProtocolID           uint8         `json:"protocol_id"`
+	PriorityFlag         uint8         `json:"priority_flag"`
+	ScheduleDeliveryTime string        `json:"schedule_delivery_time"`
+	ValidityPeriod       string        `json:"validity_period"`
+	RegisteredDelivery   uint8         `json:"registered_delivery"`
+	ReplaceIfPresent     uint8         `json:"replace_if_present_flag"`
+	DataCoding           uint8         `json:"data_coding"`
+	SMDefaultMsgID       uint8         `json:"sm_default_msg_id"`
+	SMLength             uint8         `json:"-"`
+	ShortMessage         []byte        `json:"short_message"`
+	TLVParams            TLVList       `json:"tlvs,omitempty"`
+}
+
+// NewSubmitMulti creates a new SubmitMulti PDU
+func NewSubmitMulti() *SubmitMulti {
+	return &SubmitMulti{
+		Header: NewHeader(),
+	}
+}
+
+// Source returns source_addr with its TON and NPI
+func (s *SubmitMulti) Source() Address {
+	return Address{TON: s.SourceAddrTON, NPI: s.SourceAddrNPI, Addr: s.SourceAddr}
+}
+
+// SetSource sets source_addr with its TON and NPI
+func (s *SubmitMulti) SetSource(a Address) {
+	s.SourceAddrTON, s.SourceAddrNPI, s.SourceAddr = a.TON, a.NPI, a.Addr
+}
+
+// AddDestAddr adds an SME address to the destination list
+func (s *SubmitMulti) AddDestAddr(addr string, ton, npi uint8) {
+	s.DestAddresses = append(s.DestAddresses, DestAddress{
+		DestFlag:        DEST_FLAG_SME_ADDRESS,
+		DestAddrTON:     ton,
+		DestAddrNPI:     npi,
+		DestinationAddr: addr,
+	})
+}
+
+// AddDestAddress adds an SME address to the destination list
+func (s *SubmitMulti) AddDestAddress(a Address) {
+	s.AddDestAddr(a.Addr, a.TON, a.NPI)
+}
+
+// AddDistributionList adds a distribution list name to the destination list
+func (s *SubmitMulti) AddDistributionList(name string) {
+	s.DestAddresses = append(s.DestAddresses, DestAddress{
+		DestFlag: DEST_FLAG_DISTRIBUTION_LIST,
+		DLName:   name,
+	})
+}
+
+// SetMessageText sets the message text with the specified data coding
+func (s *SubmitMulti) SetMessageText(text string, coding uint8) error {
+	data, err := TextEncoding{DataCoding: coding}.Encode(text)
+	if err != nil {
+		return err
+	}
+	if len(data) > SM_MAX_LENGTH {
+		return ErrMessageTooLong
+	}
+	s.DataCoding = coding
+	s.ShortMessage = data
+	s.SMLength = uint8(len(data))
+	return nil
+}
+
+// SetText sets the message text using the cheapest data coding, adding a
+// UDH when national language shift tables are needed
+func (s *SubmitMulti) SetText(text string, opts *TextOptions) (*EncodedText, error) {
+	encoded := SelectTextEncoding(text, opts)
+	sm := encoded.ShortMessage()
+	if len(sm) > SM_MAX_LENGTH {
+		return encoded, ErrMessageTooLong
+	}
+	s.DataCoding = encoded.DataCoding
+	s.ShortMessage = sm
+	s.SMLength = uint8(len(sm))
+	if encoded.HasUDH() {
+		s.ESMClass |= ESM_CLASS_UDHI
+	}
+	return encoded, nil
+}
+
+// MessageText decodes the message text from short_message, or from the
+// message_payload TLV when short_message is empty
+func (s *SubmitMulti) MessageText() (string, error) {
+	return messageText(s.ShortMessage, s.ESMClass, s.DataCoding, s.TLVParams)
+}
+
+// UserData splits short_message into its UDH, nil when the UDHI flag is not
+// set in esm_class, and the user payload following it
+func (s *SubmitMulti) UserData() (*UDH, []byte, error) {
+	return SplitUserData(s.ShortMessage, s.ESMClass)
+}
+
+// SubmitSM creates the submit_sm carrying this message to a single SME address.
+// It is used to fan a submit_multi out into individual messages.
+func (s *SubmitMulti) SubmitSM(dest DestAddress) *SubmitSM {
+	sm := NewSubmitSM()
+	sm.Header.SequenceNumber = s.Header.SequenceNumber
+	sm.ServiceType = s.ServiceType
+	sm.SourceAddrTON = s.SourceAddrTON
+	sm.SourceAddrNPI = s.SourceAddrNPI
+	sm.SourceAddr = s.SourceAddr
+	sm.DestAddrTON = dest.DestAddrTON
+	sm.DestAddrNPI = dest.DestAddrNPI
+	sm.DestinationAddr = dest.DestinationAddr
+	sm.ESMClass = s.ESMClass
+	sm.ProtocolID = s.ProtocolID
+	sm.PriorityFlag = s.PriorityFlag
+	sm.ScheduleDeliveryTime = s.ScheduleDeliveryTime
+	sm.ValidityPeriod = s.ValidityPeriod
+	sm.RegisteredDelivery = s.RegisteredDelivery
+	sm.ReplaceIfPresent = s.ReplaceIfPresent
+	sm.DataCoding = s.DataCoding
+	sm.SMDefaultMsgID = s.SMDefaultMsgID
+	sm.SMLength = s.SMLength
+	sm.ShortMessage = append([]byte(nil), s.ShortMessage...)
+	sm.TLVParams = s.TLVParams.Clone()
+	return sm
+}
+
+// destAddressesLength returns the encoded size of the dest_address list
+func (s *SubmitMulti) destAddressesLength() int {
+	length := 0
+	for _, da := range s.DestAddresses {
+		length++ // dest_flag
+		if da.IsDistributionList() {
+			length += len(da.DLName) + 1
+		} else {
+			length += 2 + len(da.DestinationAddr) + 1
+		}
+	}
+	return length
+}
+
+// SetScheduleDeliveryTime sets an absolute schedule_delivery_time, the zero
+// time clears it
+func (s *SubmitMulti) SetScheduleDeliveryTime(t time.Time) {
+	s.ScheduleDeliveryTime = FormatAbsoluteTime(t)
+}
+
+// SetScheduleDeliveryDelay sets a relative schedule_delivery_time
+func (s *SubmitMulti) SetScheduleDeliveryDelay(delay time.Duration) {
+	s.ScheduleDeliveryTime = FormatRelativeTime(delay)
+}
+
+// ScheduledAt returns the schedule_delivery_time, resolving a relative time
+// against now. The zero time means immediate delivery.
+func (s *SubmitMulti) ScheduledAt(now time.Time) (time.Time, error) {
+	t, err := ParseSMPPTime(s.ScheduleDeliveryTime, now)
+	if err != nil {
+		return t, fmt.Errorf("%w: %v", ErrInvalidScheduleTime, err)
+	}
+	return t, nil
+}
+
+// SetValidityPeriod sets an absolute validity_period, the zero time clears it
+func (s *SubmitMulti) SetValidityPeriod(t time.Time) {
+	s.ValidityPeriod = FormatAbsoluteTime(t)
+}
+
+// SetValidityDuration sets a relative validity_period
+func (s *SubmitMulti) SetValidityDuration(validity time.Duration) {
+	s.ValidityPeriod = FormatRelativeTime(validity)
+}
+
+// ExpiresAt returns the validity_period, resolving a relative time against
+// now. The zero time means the SMSC default validity.
+func (s *SubmitMulti) ExpiresAt(now time.Time) (time.Time, error) {
+	t, err := ParseSMPPTime(s.ValidityPeriod, now)
+	if err != nil {
+		return t, fmt.Errorf("%w: %v", ErrInvalidValidityPeriod, err)
+	}
+	return t, nil
+}
+
+// Validate checks the schedule_delivery_time and validity_period
+func (s *SubmitMulti) Validate() error {
+	return validateMessageTimes(s.ScheduleDeliveryTime, s.ValidityPeriod)
+}
+
+// checkFields checks the mandatory fields against their specified sizes
+func (s *SubmitMulti) checkFields() error {
+	if err := firstError(
+		checkCString("service_type", s.ServiceType, SERVICE_TYPE_MAX_LEN, ESME_RINVSERTYP),
+		checkCString("source_addr", s.SourceAddr, ADDR_MAX_LEN, ESME_RINVSRCADR),
+		checkCString("schedule_delivery_time", s.ScheduleDeliveryTime, TIME_MAX_LEN, ESME_RINVSCHED),
+		checkCString("validity_period", s.ValidityPeriod, TIME_MAX_LEN, ESME_RINVEXPIRY),
+		checkOctets("short_message", s.ShortMessage, SM_MAX_LENGTH, ESME_RINVMSGLEN),
+	); err != nil {
+		return err
+	}
+	for _, da := range s.DestAddresses {
+		var err error
+		if da.IsDistributionList() {
+			err = checkCString("dl_name", da.DLName, DL_NAME_MAX_LEN, ESME_RINVDLNAME)
+		} else {
+			err = checkCString("destination_addr", da.DestinationAddr, ADDR_MAX_LEN, ESME_RINVDSTADR)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EncodedLen returns the size of the encoded PDU in bytes
+func (s *SubmitMulti) EncodedLen() int {
+	length := HeaderLength
+	length += len(s.ServiceType) + 1
+	length += 2 // source_addr_ton, source_addr_npi
+	length += len(s.SourceAddr) + 1
+	length++ // number_of_dests
+	length += s.destAddressesLength()
+	length += 3 // esm_class, protocol_id, priority_flag
+	length += len(s.ScheduleDeliveryTime) + 1
+	length += len(s.ValidityPeriod) + 1
+	length += 5 // registered_delivery, replace_if_present, data_coding, sm_default_msg_id, sm_length
+	length += len(s.ShortMessage)
+	length += s.TLVParams.EncodedLen()
+	return length
+}
+
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (s *SubmitMulti) AppendTo(dst []byte) ([]byte, error) {
+	if err := s.checkFields(); err != nil {
+		return dst, err
+	}
+
+	if len(s.DestAddresses) == 0 || len(s.DestAddresses) > SUBMIT_MULTI_MAX_DESTS {
+		return dst, ErrInvalidNumDests
+	}
+
+	s.SMLength = uint8(len(s.ShortMessage))
+	s.Header.CommandLength = uint32(s.EncodedLen())
+	s.Header.CommandID = SUBMIT_MULTI
+
+	start := len(dst)
+	dst = s.Header.AppendTo(dst)
+	dst = appendCString(dst, s.ServiceType)
+	dst = append(dst, s.SourceAddrTON, s.SourceAddrNPI)
+	dst = appendCString(dst, s.SourceAddr)
+
+	// Write dest_address list
+	dst = append(dst, uint8(len(s.DestAddresses)))
+	for _, da := range s.DestAddresses {
+		dst = append(dst, da.DestFlag)
+
+		switch da.DestFlag {
+		case DEST_FLAG_SME_ADDRESS:
+			dst = append(dst, da.DestAddrTON, da.DestAddrNPI)
+			dst = appendCString(dst, da.DestinationAddr)
+		case DEST_FLAG_DISTRIBUTION_LIST:
+			if da.DLName == "" {
+				return dst[:start], ErrInvalidDLName
+			}
+			dst = appendCString(dst, da.DLName)
+		default:
+			return dst[:start], ErrInvalidDestFlag
+		}
+	}
+
+	dst = append(dst, s.ESMClass, s.ProtocolID, s.PriorityFlag)
+	dst = appendCString(dst, s.ScheduleDeliveryTime)
+	dst = appendCString(dst, s.ValidityPeriod)
+	dst = append(dst, s.RegisteredDelivery, s.ReplaceIfPresent, s.DataCoding, s.SMDefaultMsgID, s.SMLength)
+	dst = append(dst, s.ShortMessage...)
+	dst = s.TLVParams.AppendTo(dst)
+	return dst, nil
+}
+
+// Marshal serializes the PDU into bytes
+func (s *SubmitMulti) Marshal() ([]byte, error) {
+	return s.AppendTo(make([]byte, 0, s.EncodedLen()))
+}
+
+// Unmarshal deserializes the PDU from bytes
+func (s *SubmitMulti) Unmarshal(data []byte) error {
+	return s.decode(newDecoder(data, false))
+}
+
+// decode reads the PDU fields from dec
+func (s *SubmitMulti) decode(dec *decoder) error {
+	s.Header = &Header{}
+	dec.header(s.Header)
+
+	s.ServiceType = dec.cstring("service_type", SERVICE_TYPE_MAX_LEN, ESME_RINVSERTYP)
+	s.SourceAddrTON = dec.uint8("source_addr_ton")
+	s.SourceAddrNPI = dec.uint8("source_addr_npi")
+	s.SourceAddr = dec.cstring("source_addr", ADDR_MAX_LEN, ESME_RINVSRCADR)
+
+	// Read dest_address list
+	at := dec.offset
+	numDests := int(dec.uint8("number_of_dests"))
+	if numDests == 0 || numDests > SUBMIT_MULTI_MAX_DESTS {
+		dec.reject("number_of_dests", at, ErrInvalidNumDests)
+	}
+	s.DestAddresses = make([]DestAddress, 0, numDests)
+	for i := 0; i < numDests && dec.Err() == nil; i++ {
+		at = dec.offset
+		da := DestAddress{DestFlag: dec.uint8("dest_flag")}
+
+		switch da.DestFlag {
+		case DEST_FLAG_SME_ADDRESS:
+			da.DestAddrTON = dec.uint8("dest_addr_ton")
+			da.DestAddrNPI = dec.uint8("dest_addr_npi")
+			da.DestinationAddr = dec.cstring("destination_addr", ADDR_MAX_LEN, ESME_RINVDSTADR)
+		case DEST_FLAG_DISTRIBUTION_LIST:
+			at = dec.offset
+			if da.DLName = dec.cstring("dl_name", DL_NAME_MAX_LEN, ESME_RINVDLNAME); da.DLName == "" {
+				dec.reject("dl_name", at, ErrInvalidDLName)
+			}
+		default:
+			dec.reject("dest_flag", at, ErrInvalidDestFlag)
+		}
+
+		s.DestAddresses = append(s.DestAddresses, da)
+	}
+
+	s.ESMClass = dec.uint8("esm_class")
+	s.ProtocolID = dec.uint8("protocol_id")
+	s.PriorityFlag = dec.uint8("priority_flag")
+	s.ScheduleDeliveryTime = dec.cstring("schedule_delivery_time", TIME_MAX_LEN, ESME_RINVSCHED)
+	s.ValidityPeriod = dec.cstring("validity_period", TIME_MAX_LEN, ESME_RINVEXPIRY)
+	s.RegisteredDelivery = dec.uint8("registered_delivery")
+	s.ReplaceIfPresent = dec.uint8("replace_if_present")
+	s.DataCoding = dec.uint8("data_coding")
+	s.SMDefaultMsgID = dec.uint8("sm_default_msg_id")
+	s.SMLength = dec.uint8("sm_length")
+	s.ShortMessage = dec.octets("short_message", int(s.SMLength), SM_MAX_LENGTH, ESME_RINVMSGLEN)
+
+	dec.tlvs(&s.TLVParams)
+	return dec.Err()
+}
+
+// CommandID returns the command_id of the PDU
+func (s *SubmitMulti) CommandID() uint32 {
+	return SUBMIT_MULTI
+}
+
+// GetHeader returns the PDU header
+func (s *SubmitMulti) GetHeader() *Header {
+	return s.Header
+}
+
+// String returns a one-line description of the PDU for trace logs
+func (s *SubmitMulti) String() string {
+	return FormatPDU(s)
+}
+
+// MarshalJSON encodes the PDU as JSON
+func (s *SubmitMulti) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(s)
+}
+
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (s *SubmitMulti) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, s)
+}
+
+// GetTLVParams returns the optional parameters of the PDU
+func (s *SubmitMulti) GetTLVParams() *TLVList {
+	return &s.TLVParams
+}
+
+// GetResponse creates the submit_multi_resp answering this PDU
+func (s *SubmitMulti) GetResponse() PDU {
+	resp := NewSubmitMultiResp()
+	resp.Header = responseHeader(s.Header, SUBMIT_MULTI_RESP)
+	return resp
+}
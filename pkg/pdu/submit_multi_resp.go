@@ -0,0 +1,168 @@
+package pdu
+
+import (
+	"encoding/binary"
+)
+
+// UnsuccessSME represents a destination that could not be delivered to,
+// as reported in the submit_multi_resp unsuccess_sme list
+type UnsuccessSME struct {
+	DestAddrTON     uint8  `json:"dest_addr_ton"`
+	DestAddrNPI     uint8  `json:"dest_addr_npi"`
+	DestinationAddr string `json:"destination_addr"`
+	ErrorStatusCode uint32 `json:"error_status_code"`
+}
+
+// Address returns the SME address with its TON and NPI
+func (u *UnsuccessSME) Address() Address {
+	return Address{TON: u.DestAddrTON, NPI: u.DestAddrNPI, Addr: u.DestinationAddr}
+}
+
+// SubmitMultiResp represents an SMPP submit_multi_resp PDU
+type SubmitMultiResp struct {
+	Header       *Header        `json:"header"`
+	MessageID    string         `json:"message_id"`
+	UnsuccessSME []UnsuccessSME `json:"unsuccess_sme"`
+	TLVParams    TLVList        `json:"tlvs,omitempty"`
+}
+
+// NewSubmitMultiResp creates a new SubmitMultiResp PDU
+func NewSubmitMultiResp() *SubmitMultiResp {
+	return &SubmitMultiResp{
+		Header: NewHeader(),
+	}
+}
+
+// AddUnsuccessSME records a destination that failed with the given status
+func (s *SubmitMultiResp) AddUnsuccessSME(addr string, ton, npi uint8, status uint32) {
+	s.UnsuccessSME = append(s.UnsuccessSME, UnsuccessSME{
+		DestAddrTON:     ton,
+		DestAddrNPI:     npi,
+		DestinationAddr: addr,
+		ErrorStatusCode: status,
+	})
+}
+
+// checkFields checks the mandatory fields against their specified sizes
+func (s *SubmitMultiResp) checkFields() error {
+	if err := firstError(
+		checkCString("message_id", s.MessageID, MESSAGE_ID_MAX_LEN, ESME_RINVMSGID),
+	); err != nil {
+		return err
+	}
+	for _, sme := range s.UnsuccessSME {
+		if err := checkCString("destination_addr", sme.DestinationAddr, ADDR_MAX_LEN, ESME_RINVDSTADR); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EncodedLen returns the size of the encoded PDU in bytes
+func (s *SubmitMultiResp) EncodedLen() int {
+	length := HeaderLength
+	length += len(s.MessageID) + 1
+	length++ // no_unsuccess
+	for _, sme := range s.UnsuccessSME {
+		length += 2 + len(sme.DestinationAddr) + 1 + 4 // TON, NPI, address, error_status_code
+	}
+	length += s.TLVParams.EncodedLen()
+	return length
+}
+
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (s *SubmitMultiResp) AppendTo(dst []byte) ([]byte, error) {
+	if err := s.checkFields(); err != nil {
+		return dst, err
+	}
+
+	if len(s.UnsuccessSME) > SUBMIT_MULTI_MAX_DESTS {
+		return dst, ErrInvalidNumDests
+	}
+
+	s.Header.CommandLength = uint32(s.EncodedLen())
+	s.Header.CommandID = SUBMIT_MULTI_RESP
+
+	dst = s.Header.AppendTo(dst)
+	dst = appendCString(dst, s.MessageID)
+
+	// Write unsuccess_sme list
+	dst = append(dst, uint8(len(s.UnsuccessSME)))
+	for _, sme := range s.UnsuccessSME {
+		dst = append(dst, sme.DestAddrTON, sme.DestAddrNPI)
+		dst = appendCString(dst, sme.DestinationAddr)
+		dst = binary.BigEndian.AppendUint32(dst, sme.ErrorStatusCode)
+	}
+
+	dst = s.TLVParams.AppendTo(dst)
+	return dst, nil
+}
+
+// Marshal serializes the PDU into bytes
+func (s *SubmitMultiResp) Marshal() ([]byte, error) {
+	return s.AppendTo(make([]byte, 0, s.EncodedLen()))
+}
+
+// Unmarshal deserializes the PDU from bytes
+func (s *SubmitMultiResp) Unmarshal(data []byte) error {
+	return s.decode(newDecoder(data, false))
+}
+
+// decode reads the PDU fields from dec
+func (s *SubmitMultiResp) decode(dec *decoder) error {
+	s.Header = &Header{}
+	dec.header(s.Header)
+
+	s.MessageID = dec.cstring("message_id", MESSAGE_ID_MAX_LEN, ESME_RINVMSGID)
+
+	// Read unsuccess_sme list
+	noUnsuccess := int(dec.uint8("no_unsuccess"))
+	s.UnsuccessSME = make([]UnsuccessSME, 0, noUnsuccess)
+	for i := 0; i < noUnsuccess && dec.Err() == nil; i++ {
+		sme := UnsuccessSME{
+			DestAddrTON: dec.uint8("dest_addr_ton"),
+			DestAddrNPI: dec.uint8("dest_addr_npi"),
+		}
+		sme.DestinationAddr = dec.cstring("destination_addr", ADDR_MAX_LEN, ESME_RINVDSTADR)
+		sme.ErrorStatusCode = dec.uint32("error_status_code")
+		s.UnsuccessSME = append(s.UnsuccessSME, sme)
+	}
+
+	dec.tlvs(&s.TLVParams)
+	return dec.Err()
+}
+
+// CommandID returns the command_id of the PDU
+func (s *SubmitMultiResp) CommandID() uint32 {
+	return SUBMIT_MULTI_RESP
+}
+
+// GetHeader returns the PDU header
+func (s *SubmitMultiResp) GetHeader() *Header {
+	return s.Header
+}
+
+// String returns a one-line description of the PDU for trace logs
+func (s *SubmitMultiResp) String() string {
+	return FormatPDU(s)
+}
+
+// MarshalJSON encodes the PDU as JSON
+func (s *SubmitMultiResp) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(s)
+}
+
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (s *SubmitMultiResp) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, s)
+}
+
+// GetTLVParams returns the optional parameters of the PDU
+func (s *SubmitMultiResp) GetTLVParams() *TLVList {
+	return &s.TLVParams
+}
+
+// GetResponse returns nil as submit_multi_resp has no response PDU
+func (s *SubmitMultiResp) GetResponse() PDU {
+	return nil
+}
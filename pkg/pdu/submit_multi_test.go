@@ -0,0 +1,162 @@
+package pdu
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestSubmitMultiRoundTrip(t *testing.T) {
+	multi := NewSubmitMulti()
+	multi.Header.SequenceNumber = 7
+	multi.SetSource(Address{TON: TON_ALPHANUMERIC, NPI: NPI_UNKNOWN, Addr: "Shop"})
+	multi.AddDestAddr("447700900123", TON_INTERNATIONAL, NPI_ISDN)
+	multi.AddDistributionList("customers")
+	multi.AddDestAddress(Address{TON: TON_NATIONAL, NPI: NPI_ISDN, Addr: "07700900456"})
+	if err := multi.SetMessageText("Sale starts today", DATA_CODING_DEFAULT); err != nil {
+		t.Fatal(err)
+	}
+	multi.TLVParams.SetUserMessageReference(42)
+
+	frame, err := multi.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if len(frame) != multi.EncodedLen() {
+		t.Errorf("Marshal wrote %d octets, EncodedLen = %d", len(frame), multi.EncodedLen())
+	}
+	p, err := Decode(frame)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	got := p.(*SubmitMulti)
+	if !slices.Equal(got.DestAddresses, multi.DestAddresses) {
+		t.Errorf("dest_addresses %+v, want %+v", got.DestAddresses, multi.DestAddresses)
+	}
+	if got.Source() != multi.Source() || !bytes.Equal(got.ShortMessage, multi.ShortMessage) {
+		t.Errorf("decoded %v, want %v", got, multi)
+	}
+	if ref, ok := got.TLVParams.UserMessageReference(); !ok || ref != 42 {
+		t.Errorf("user_message_reference %d, %v", ref, ok)
+	}
+
+	// Marshalling the decoded PDU gives the same frame
+	again, err := got.Marshal()
+	if err != nil || !bytes.Equal(again, frame) {
+		t.Errorf("Marshal after Decode = %X, %v, want %X", again, err, frame)
+	}
+}
+
+func TestSubmitMultiNumDests(t *testing.T) {
+	for _, n := range []int{0, 1, SUBMIT_MULTI_MAX_DESTS, SUBMIT_MULTI_MAX_DESTS + 1} {
+		multi := NewSubmitMulti()
+		for i := range n {
+			multi.AddDestAddr(fmt.Sprintf("4477009%05d", i), TON_INTERNATIONAL, NPI_ISDN)
+		}
+		_, err := multi.Marshal()
+		if n == 0 || n > SUBMIT_MULTI_MAX_DESTS {
+			if !errors.Is(err, ErrInvalidNumDests) {
+				t.Errorf("Marshal with %d destinations error = %v, want ErrInvalidNumDests", n, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Marshal with %d destinations: %v", n, err)
+		}
+	}
+
+	// number_of_dests is checked when decoding too
+	body := [][]byte{cstr(""), {0, 0}, cstr("")}
+	tail := [][]byte{{0, 0, 0}, cstr(""), cstr(""), {0, 0, 0, 0, 0}}
+	for _, n := range []uint8{0, 255} {
+		frame := testFrame(SUBMIT_MULTI, slices.Concat(slices.Concat(body...), []byte{n}, slices.Concat(tail...)))
+		derr := decodeError(t, frame)
+		if derr.Field != "number_of_dests" || derr.Status != ESME_RINVNUMDESTS {
+			t.Errorf("%d destinations: %v, status 0x%08X", n, derr, derr.Status)
+		}
+	}
+}
+
+func TestSubmitMultiInvalidDest(t *testing.T) {
+	head := slices.Concat(cstr(""), []byte{0, 0}, cstr(""), []byte{1})
+	tail := slices.Concat([]byte{0, 0, 0}, cstr(""), cstr(""), []byte{0, 0, 0, 0, 0})
+	tests := []struct {
+		name   string
+		dest   []byte
+		field  string
+		status uint32
+	}{
+		{"dest_flag 0", []byte{0, 1, 1, '1', 0}, "dest_flag", ESME_RINVDESTFLAG},
+		{"dest_flag 3", []byte{3, 1, 1, '1', 0}, "dest_flag", ESME_RINVDESTFLAG},
+		{"empty dl_name", slices.Concat([]byte{DEST_FLAG_DISTRIBUTION_LIST}, cstr("")), "dl_name", ESME_RINVDLNAME},
+		{"long dl_name", slices.Concat([]byte{DEST_FLAG_DISTRIBUTION_LIST}, cstr("twenty-one-characters")), "dl_name", ESME_RINVDLNAME},
+	}
+	for _, tt := range tests {
+		derr := decodeError(t, testFrame(SUBMIT_MULTI, head, tt.dest, tail))
+		if derr.Field != tt.field || derr.Status != tt.status {
+			t.Errorf("%s: %v, status 0x%08X, want %s with 0x%08X", tt.name, derr, derr.Status, tt.field, tt.status)
+		}
+	}
+
+	// The same destinations are refused when marshalling
+	for _, tt := range []struct {
+		dest DestAddress
+		err  error
+	}{
+		{DestAddress{DestFlag: 0, DestinationAddr: "1"}, ErrInvalidDestFlag},
+		{DestAddress{DestFlag: DEST_FLAG_DISTRIBUTION_LIST}, ErrInvalidDLName},
+	} {
+		multi := NewSubmitMulti()
+		multi.DestAddresses = []DestAddress{tt.dest}
+		if frame, err := multi.Marshal(); !errors.Is(err, tt.err) || len(frame) != 0 {
+			t.Errorf("Marshal %+v = %X, %v, want %v", tt.dest, frame, err, tt.err)
+		}
+	}
+}
+
+func TestSubmitMultiRespEncoding(t *testing.T) {
+	resp := NewSubmitMultiResp()
+	resp.Header.SequenceNumber = 1
+	resp.MessageID = "m1"
+	resp.AddUnsuccessSME("447700900123", TON_INTERNATIONAL, NPI_ISDN, ESME_RINVDSTADR)
+	resp.AddUnsuccessSME("staff", 0, 0, ESME_RINVDLNAME)
+
+	frame, err := resp.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	want := testFrame(SUBMIT_MULTI_RESP,
+		cstr("m1"),
+		[]byte{2},
+		[]byte{TON_INTERNATIONAL, NPI_ISDN}, cstr("447700900123"), []byte{0, 0, 0, 0x0B},
+		[]byte{0, 0}, cstr("staff"), []byte{0, 0, 0, 0x34},
+	)
+	if !bytes.Equal(frame, want) {
+		t.Errorf("Marshal = %X, want %X", frame, want)
+	}
+
+	p, err := Decode(frame)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	got := p.(*SubmitMultiResp)
+	if got.MessageID != "m1" || !slices.Equal(got.UnsuccessSME, resp.UnsuccessSME) {
+		t.Errorf("decoded %+v, want %+v", got, resp)
+	}
+
+	// An unsuccess_sme entry cut short
+	derr := decodeError(t, resize(frame, len(frame)-2))
+	if derr.Field != "error_status_code" {
+		t.Errorf("truncated frame: %v", derr)
+	}
+
+	// The list is limited like the destinations
+	for range SUBMIT_MULTI_MAX_DESTS {
+		resp.AddUnsuccessSME("1", 0, 0, ESME_RSUBMITFAIL)
+	}
+	if _, err := resp.Marshal(); !errors.Is(err, ErrInvalidNumDests) {
+		t.Errorf("Marshal with %d entries error = %v", len(resp.UnsuccessSME), err)
+	}
+}
@@ -1,66 +1,119 @@
 package pdu
 
 import (
-	"errors"
+	"fmt"
+	"time"
 )
 
 // SubmitSM represents an SMPP submit_sm PDU
 type SubmitSM struct {
-	Header               *Header
-	ServiceType          string
-	SourceAddrTON        uint8
-	SourceAddrNPI        uint8
-	SourceAddr           string
-	DestAddrTON          uint8
-	DestAddrNPI          uint8
-	DestinationAddr      string
-	ESMClass             uint8
-	ProtocolID           uint8
-	PriorityFlag         uint8
-	ScheduleDeliveryTime string
-	ValidityPeriod       string
-	RegisteredDelivery   uint8
-	ReplaceIfPresent     uint8
-	DataCoding           uint8
-	SMDefaultMsgID       uint8
-	SMLength             uint8
-	ShortMessage         []byte
-	TLVParams            map[uint16]*TLVParam
+	Header               *Header `json:"header"`
+	ServiceType          string  `json:"service_type"`
+	SourceAddrTON        uint8   `json:"source_addr_ton"`
+	SourceAddrNPI        uint8   `json:"source_addr_npi"`
+	SourceAddr           string  `json:"source_addr"`
+	DestAddrTON          uint8   `json:"dest_addr_ton"`
+	DestAddrNPI          uint8   `json:"dest_addr_npi"`
+	DestinationAddr      string  `json:"destination_addr"`
+	ESMClass             uint8   `json:"esm_class"`
+	ProtocolID           uint8   `json:"protocol_id"`
+	PriorityFlag         uint8   `json:"priority_flag"`
+	ScheduleDeliveryTime string  `json:"schedule_delivery_time"`
+	ValidityPeriod       string  `json:"validity_period"`
+	RegisteredDelivery   uint8   `json:"registered_delivery"`
+	ReplaceIfPresent     uint8   `json:"replace_if_present_flag"`
+	DataCoding           uint8   `json:"data_coding"`
+	SMDefaultMsgID       uint8   `json:"sm_default_msg_id"`
+	SMLength             uint8   `json:"-"`
+	ShortMessage         []byte  `json:"short_message"`
+	TLVParams            TLVList `json:"tlvs,omitempty"`
 }
 
 // NewSubmitSM creates a new SubmitSM PDU
 func NewSubmitSM() *SubmitSM {
 	return &SubmitSM{
-		Header:    NewHeader(),
-		TLVParams: make(map[uint16]*TLVParam),
+		Header: NewHeader(),
 	}
 }
 
+// Source returns source_addr with its TON and NPI
+func (s *SubmitSM) Source() Address {
+	return Address{TON: s.SourceAddrTON, NPI: s.SourceAddrNPI, Addr: s.SourceAddr}
+}
+
+// SetSource sets source_addr with its TON and NPI
+func (s *SubmitSM) SetSource(a Address) {
+	s.SourceAddrTON, s.SourceAddrNPI, s.SourceAddr = a.TON, a.NPI, a.Addr
+}
+
+// Destination returns destination_addr with its TON and NPI
+func (s *SubmitSM) Destination() Address {
+	return Address{TON: s.DestAddrTON, NPI: s.DestAddrNPI, Addr: s.DestinationAddr}
+}
+
+// SetDestination sets destination_addr with its TON and NPI
+func (s *SubmitSM) SetDestination(a Address) {
+	s.DestAddrTON, s.DestAddrNPI, s.DestinationAddr = a.TON, a.NPI, a.Addr
+}
+
 // SetMessageText sets the message text with the specified data coding
 func (s *SubmitSM) SetMessageText(text string, coding uint8) error {
-	s.DataCoding = coding
-	s.ShortMessage = []byte(text)
-	if len(s.ShortMessage) > 255 {
-		return errors.New("message text too long")
+	data, err := TextEncoding{DataCoding: coding}.Encode(text)
+	if err != nil {
+		return err
 	}
-	s.SMLength = uint8(len(s.ShortMessage))
+	if len(data) > SM_MAX_LENGTH {
+		return ErrMessageTooLong
+	}
+	s.DataCoding = coding
+	s.ShortMessage = data
+	s.SMLength = uint8(len(data))
 	return nil
 }
 
+// SetText sets the message text using the cheapest data coding, adding a
+// UDH when national language shift tables are needed
+func (s *SubmitSM) SetText(text string, opts *TextOptions) (*EncodedText, error) {
+	encoded := SelectTextEncoding(text, opts)
+	sm := encoded.ShortMessage()
+	if len(sm) > SM_MAX_LENGTH {
+		return encoded, ErrMessageTooLong
+	}
+	s.DataCoding = encoded.DataCoding
+	s.ShortMessage = sm
+	s.SMLength = uint8(len(sm))
+	if encoded.HasUDH() {
+		s.ESMClass |= ESM_CLASS_UDHI
+	}
+	return encoded, nil
+}
+
+// MessageText decodes the message text from short_message, or from the
+// message_payload TLV when short_message is empty
+func (s *SubmitSM) MessageText() (string, error) {
+	return messageText(s.ShortMessage, s.ESMClass, s.DataCoding, s.TLVParams)
+}
+
+// UserData splits short_message into its UDH, nil when the UDHI flag is not
+// set in esm_class, and the user payload following it
+func (s *SubmitSM) UserData() (*UDH, []byte, error) {
+	return SplitUserData(s.ShortMessage, s.ESMClass)
+}
+
 // SetUDH sets User Data Header for concatenated messages
 func (s *SubmitSM) SetUDH(refNum uint16, total, seqNum uint8) {
 	// Set ESM class to indicate UDH presence
-	s.ESMClass |= 0x40
+	s.ESMClass |= ESM_CLASS_UDHI
 
 	// Create UDH for 16-bit reference
-	udh := make([]byte, 6)
-	udh[0] = 5                 // UDH Length
-	udh[1] = 0x08              // IE Identifier (16-bit reference)
-	udh[2] = 0x04              // IE Length
-	udh[3] = byte(refNum >> 8) // Reference high byte
-	udh[4] = byte(refNum)      // Reference low byte
-	udh[5] = total             // Total segments
-	udh[6] = seqNum            // Sequence number
+	udh := make([]byte, 7)
+	udh[0] = 6                   // UDH Length
+	udh[1] = UDH_IE_CONCAT_16BIT // IE Identifier (16-bit reference)
+	udh[2] = 0x04                // IE Length
+	udh[3] = byte(refNum >> 8)   // Reference high byte
+	udh[4] = byte(refNum)        // Reference low byte
+	udh[5] = total               // Total segments
+	udh[6] = seqNum              // Sequence number
 
 	// Prepend UDH to message
 	newMsg := make([]byte, len(udh)+len(s.ShortMessage))
@@ -70,238 +123,177 @@ func (s *SubmitSM) SetUDH(refNum uint16, total, seqNum uint8) {
 	s.SMLength = uint8(len(s.ShortMessage))
 }
 
-// Marshal serializes the PDU into bytes
-func (s *SubmitSM) Marshal() ([]byte, error) {
-	// Calculate the total length
-	length := 16 // Header length
-	length += len(s.ServiceType) + 1
-	length += 2 // Source addr ton and npi
-	length += len(s.SourceAddr) + 1
-	length += 2 // Dest addr ton and npi
-	length += len(s.DestinationAddr) + 1
-	length += 5 // ESM class, protocol id, priority flag, registered delivery, replace if present
-	length += len(s.ScheduleDeliveryTime) + 1
-	length += len(s.ValidityPeriod) + 1
-	length += 3 // Data coding, sm default msg id, sm length
-	length += len(s.ShortMessage)
-
-	// Add TLV parameters length
-	for _, tlv := range s.TLVParams {
-		length += 4 + len(tlv.Value)
-	}
-
-	// Set command length in header
-	s.Header.CommandLength = uint32(length)
-	s.Header.CommandID = SUBMIT_SM
+// SetScheduleDeliveryTime sets an absolute schedule_delivery_time, the zero
+// time clears it
+func (s *SubmitSM) SetScheduleDeliveryTime(t time.Time) {
+	s.ScheduleDeliveryTime = FormatAbsoluteTime(t)
+}
 
-	// Create the byte slice
-	buf := make([]byte, length)
+// SetScheduleDeliveryDelay sets a relative schedule_delivery_time
+func (s *SubmitSM) SetScheduleDeliveryDelay(delay time.Duration) {
+	s.ScheduleDeliveryTime = FormatRelativeTime(delay)
+}
 
-	// Marshal header
-	headerBytes, err := s.Header.Marshal()
+// ScheduledAt returns the schedule_delivery_time, resolving a relative time
+// against now. The zero time means immediate delivery.
+func (s *SubmitSM) ScheduledAt(now time.Time) (time.Time, error) {
+	t, err := ParseSMPPTime(s.ScheduleDeliveryTime, now)
 	if err != nil {
-		return nil, err
+		return t, fmt.Errorf("%w: %v", ErrInvalidScheduleTime, err)
 	}
-	copy(buf[0:], headerBytes)
-
-	offset := 16
-
-	// Write service_type
-	copy(buf[offset:], s.ServiceType)
-	offset += len(s.ServiceType) + 1
-
-	// Write source_addr_ton
-	buf[offset] = s.SourceAddrTON
-	offset++
-
-	// Write source_addr_npi
-	buf[offset] = s.SourceAddrNPI
-	offset++
-
-	// Write source_addr
-	copy(buf[offset:], s.SourceAddr)
-	offset += len(s.SourceAddr) + 1
-
-	// Write dest_addr_ton
-	buf[offset] = s.DestAddrTON
-	offset++
-
-	// Write dest_addr_npi
-	buf[offset] = s.DestAddrNPI
-	offset++
-
-	// Write destination_addr
-	copy(buf[offset:], s.DestinationAddr)
-	offset += len(s.DestinationAddr) + 1
-
-	// Write esm_class
-	buf[offset] = s.ESMClass
-	offset++
-
-	// Write protocol_id
-	buf[offset] = s.ProtocolID
-	offset++
-
-	// Write priority_flag
-	buf[offset] = s.PriorityFlag
-	offset++
-
-	// Write schedule_delivery_time
-	copy(buf[offset:], s.ScheduleDeliveryTime)
-	offset += len(s.ScheduleDeliveryTime) + 1
-
-	// Write validity_period
-	copy(buf[offset:], s.ValidityPeriod)
-	offset += len(s.ValidityPeriod) + 1
-
-	// Write registered_delivery
-	buf[offset] = s.RegisteredDelivery
-	offset++
-
-	// Write replace_if_present
-	buf[offset] = s.ReplaceIfPresent
-	offset++
-
-	// Write data_coding
-	buf[offset] = s.DataCoding
-	offset++
-
-	// Write sm_default_msg_id
-	buf[offset] = s.SMDefaultMsgID
-	offset++
-
-	// Write sm_length
-	buf[offset] = s.SMLength
-	offset++
-
-	// Write short_message
-	copy(buf[offset:], s.ShortMessage)
-	offset += len(s.ShortMessage)
-
-	// Write TLV parameters
-	for _, tlv := range s.TLVParams {
-		tlvBytes, err := tlv.Marshal()
-		if err != nil {
-			return nil, err
-		}
-		copy(buf[offset:], tlvBytes)
-		offset += 4 + len(tlv.Value)
-	}
-
-	return buf, nil
+	return t, nil
 }
 
-// Unmarshal deserializes the PDU from bytes
-func (s *SubmitSM) Unmarshal(data []byte) error {
-	var err error
-
-	// Unmarshal header
-	s.Header = &Header{}
-	if err = s.Header.Unmarshal(data[:16]); err != nil {
-		return err
-	}
+// SetValidityPeriod sets an absolute validity_period, the zero time clears it
+func (s *SubmitSM) SetValidityPeriod(t time.Time) {
+	s.ValidityPeriod = FormatAbsoluteTime(t)
+}
 
-	offset := 16
+// SetValidityDuration sets a relative validity_period
+func (s *SubmitSM) SetValidityDuration(validity time.Duration) {
+	s.ValidityPeriod = FormatRelativeTime(validity)
+}
 
-	// Read service_type
-	if s.ServiceType, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
+// ExpiresAt returns the validity_period, resolving a relative time against
+// now. The zero time means the SMSC default validity.
+func (s *SubmitSM) ExpiresAt(now time.Time) (time.Time, error) {
+	t, err := ParseSMPPTime(s.ValidityPeriod, now)
+	if err != nil {
+		return t, fmt.Errorf("%w: %v", ErrInvalidValidityPeriod, err)
 	}
+	return t, nil
+}
 
-	// Read source_addr_ton
-	s.SourceAddrTON = data[offset]
-	offset++
-
-	// Read source_addr_npi
-	s.SourceAddrNPI = data[offset]
-	offset++
-
-	// Read source_addr
-	if s.SourceAddr, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+// Validate checks the schedule_delivery_time and validity_period
+func (s *SubmitSM) Validate() error {
+	return validateMessageTimes(s.ScheduleDeliveryTime, s.ValidityPeriod)
+}
 
-	// Read dest_addr_ton
-	s.DestAddrTON = data[offset]
-	offset++
+// checkFields checks the mandatory fields against their specified sizes
+func (s *SubmitSM) checkFields() error {
+	return firstError(
+		checkCString("service_type", s.ServiceType, SERVICE_TYPE_MAX_LEN, ESME_RINVSERTYP),
+		checkCString("source_addr", s.SourceAddr, ADDR_MAX_LEN, ESME_RINVSRCADR),
+		checkCString("destination_addr", s.DestinationAddr, ADDR_MAX_LEN, ESME_RINVDSTADR),
+		checkCString("schedule_delivery_time", s.ScheduleDeliveryTime, TIME_MAX_LEN, ESME_RINVSCHED),
+		checkCString("validity_period", s.ValidityPeriod, TIME_MAX_LEN, ESME_RINVEXPIRY),
+		checkOctets("short_message", s.ShortMessage, SM_MAX_LENGTH, ESME_RINVMSGLEN),
+	)
+}
 
-	// Read dest_addr_npi
-	s.DestAddrNPI = data[offset]
-	offset++
+// EncodedLen returns the size of the encoded PDU in bytes
+func (s *SubmitSM) EncodedLen() int {
+	length := HeaderLength
+	length += len(s.ServiceType) + 1
+	length += 2 // source_addr_ton, source_addr_npi
+	length += len(s.SourceAddr) + 1
+	length += 2 // dest_addr_ton, dest_addr_npi
+	length += len(s.DestinationAddr) + 1
+	length += 3 // esm_class, protocol_id, priority_flag
+	length += len(s.ScheduleDeliveryTime) + 1
+	length += len(s.ValidityPeriod) + 1
+	length += 5 // registered_delivery, replace_if_present, data_coding, sm_default_msg_id, sm_length
+	length += len(s.ShortMessage)
+	length += s.TLVParams.EncodedLen()
+	return length
+}
 
-	// Read destination_addr
-	if s.DestinationAddr, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (s *SubmitSM) AppendTo(dst []byte) ([]byte, error) {
+	if err := s.checkFields(); err != nil {
+		return dst, err
 	}
 
-	// Read esm_class
-	s.ESMClass = data[offset]
-	offset++
+	s.SMLength = uint8(len(s.ShortMessage))
+	s.Header.CommandLength = uint32(s.EncodedLen())
+	s.Header.CommandID = SUBMIT_SM
 
-	// Read protocol_id
-	s.ProtocolID = data[offset]
-	offset++
+	dst = s.Header.AppendTo(dst)
+	dst = appendCString(dst, s.ServiceType)
+	dst = append(dst, s.SourceAddrTON, s.SourceAddrNPI)
+	dst = appendCString(dst, s.SourceAddr)
+	dst = append(dst, s.DestAddrTON, s.DestAddrNPI)
+	dst = appendCString(dst, s.DestinationAddr)
+	dst = append(dst, s.ESMClass, s.ProtocolID, s.PriorityFlag)
+	dst = appendCString(dst, s.ScheduleDeliveryTime)
+	dst = appendCString(dst, s.ValidityPeriod)
+	dst = append(dst, s.RegisteredDelivery, s.ReplaceIfPresent, s.DataCoding, s.SMDefaultMsgID, s.SMLength)
+	dst = append(dst, s.ShortMessage...)
+	dst = s.TLVParams.AppendTo(dst)
+	return dst, nil
+}
 
-	// Read priority_flag
-	s.PriorityFlag = data[offset]
-	offset++
+// Marshal serializes the PDU into bytes
+func (s *SubmitSM) Marshal() ([]byte, error) {
+	return s.AppendTo(make([]byte, 0, s.EncodedLen()))
+}
 
-	// Read schedule_delivery_time
-	if s.ScheduleDeliveryTime, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+// Unmarshal deserializes the PDU from bytes
+func (s *SubmitSM) Unmarshal(data []byte) error {
+	return s.decode(newDecoder(data, false))
+}
 
-	// Read validity_period
-	if s.ValidityPeriod, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+// decode reads the PDU fields from dec
+func (s *SubmitSM) decode(dec *decoder) error {
+	s.Header = &Header{}
+	dec.header(s.Header)
+
+	s.ServiceType = dec.cstring("service_type", SERVICE_TYPE_MAX_LEN, ESME_RINVSERTYP)
+	s.SourceAddrTON = dec.uint8("source_addr_ton")
+	s.SourceAddrNPI = dec.uint8("source_addr_npi")
+	s.SourceAddr = dec.cstring("source_addr", ADDR_MAX_LEN, ESME_RINVSRCADR)
+	s.DestAddrTON = dec.uint8("dest_addr_ton")
+	s.DestAddrNPI = dec.uint8("dest_addr_npi")
+	s.DestinationAddr = dec.cstring("destination_addr", ADDR_MAX_LEN, ESME_RINVDSTADR)
+	s.ESMClass = dec.uint8("esm_class")
+	s.ProtocolID = dec.uint8("protocol_id")
+	s.PriorityFlag = dec.uint8("priority_flag")
+	s.ScheduleDeliveryTime = dec.cstring("schedule_delivery_time", TIME_MAX_LEN, ESME_RINVSCHED)
+	s.ValidityPeriod = dec.cstring("validity_period", TIME_MAX_LEN, ESME_RINVEXPIRY)
+	s.RegisteredDelivery = dec.uint8("registered_delivery")
+	s.ReplaceIfPresent = dec.uint8("replace_if_present")
+	s.DataCoding = dec.uint8("data_coding")
+	s.SMDefaultMsgID = dec.uint8("sm_default_msg_id")
+	s.SMLength = dec.uint8("sm_length")
+	s.ShortMessage = dec.octets("short_message", int(s.SMLength), SM_MAX_LENGTH, ESME_RINVMSGLEN)
+
+	dec.tlvs(&s.TLVParams)
+	return dec.Err()
+}
 
-	// Read registered_delivery
-	s.RegisteredDelivery = data[offset]
-	offset++
+// CommandID returns the command_id of the PDU
+func (s *SubmitSM) CommandID() uint32 {
+	return SUBMIT_SM
+}
 
-	// Read replace_if_present
-	s.ReplaceIfPresent = data[offset]
-	offset++
+// GetHeader returns the PDU header
+func (s *SubmitSM) GetHeader() *Header {
+	return s.Header
+}
 
-	// Read data_coding
-	s.DataCoding = data[offset]
-	offset++
+// String returns a one-line description of the PDU for trace logs
+func (s *SubmitSM) String() string {
+	return FormatPDU(s)
+}
 
-	// Read sm_default_msg_id
-	s.SMDefaultMsgID = data[offset]
-	offset++
+// MarshalJSON encodes the PDU as JSON
+func (s *SubmitSM) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(s)
+}
 
-	// Read sm_length
-	s.SMLength = data[offset]
-	offset++
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (s *SubmitSM) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, s)
+}
 
-	// Read short_message
-	if int(s.SMLength) > len(data[offset:]) {
-		return errors.New("invalid short message length")
-	}
-	s.ShortMessage = make([]byte, s.SMLength)
-	copy(s.ShortMessage, data[offset:offset+int(s.SMLength)])
-	offset += int(s.SMLength)
-
-	// Read TLV parameters if any remain
-	remaining := int(s.Header.CommandLength) - offset
-	currentOffset := offset
-
-	for remaining > 0 {
-		if remaining < 4 {
-			return errors.New("invalid TLV parameter: insufficient data")
-		}
-
-		tlv := &TLVParam{}
-		if err := tlv.Unmarshal(data[currentOffset:]); err != nil {
-			return err
-		}
-
-		s.TLVParams[tlv.Tag] = tlv
-		currentOffset += 4 + int(tlv.Length)
-		remaining -= 4 + int(tlv.Length)
-	}
+// GetTLVParams returns the optional parameters of the PDU
+func (s *SubmitSM) GetTLVParams() *TLVList {
+	return &s.TLVParams
+}
 
-	return nil
+// GetResponse creates the submit_sm_resp answering this PDU
+func (s *SubmitSM) GetResponse() PDU {
+	resp := NewSubmitSMResp()
+	resp.Header = responseHeader(s.Header, SUBMIT_SM_RESP)
+	return resp
 }
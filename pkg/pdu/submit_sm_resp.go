@@ -1,101 +1,101 @@
 package pdu
 
-import "errors"
-
 // SubmitSMResp represents an SMPP submit_sm_resp PDU
 type SubmitSMResp struct {
-	Header    *Header
-	MessageID string
-	TLVParams map[uint16]*TLVParam
+	Header    *Header `json:"header"`
+	MessageID string  `json:"message_id"`
+	TLVParams TLVList `json:"tlvs,omitempty"`
 }
 
 // NewSubmitSMResp creates a new SubmitSMResp PDU
 func NewSubmitSMResp() *SubmitSMResp {
 	return &SubmitSMResp{
-		Header:    NewHeader(),
-		TLVParams: make(map[uint16]*TLVParam),
+		Header: NewHeader(),
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (s *SubmitSMResp) Marshal() ([]byte, error) {
-	// Calculate the total length
-	length := 16 // Header length
+// checkFields checks the mandatory fields against their specified sizes
+func (s *SubmitSMResp) checkFields() error {
+	return firstError(
+		checkCString("message_id", s.MessageID, MESSAGE_ID_MAX_LEN, ESME_RINVMSGID),
+	)
+}
+
+// EncodedLen returns the size of the encoded PDU in bytes
+func (s *SubmitSMResp) EncodedLen() int {
+	length := HeaderLength
 	length += len(s.MessageID) + 1
+	length += s.TLVParams.EncodedLen()
+	return length
+}
 
-	// Add TLV parameters length
-	for _, tlv := range s.TLVParams {
-		length += 4 + len(tlv.Value)
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (s *SubmitSMResp) AppendTo(dst []byte) ([]byte, error) {
+	if err := s.checkFields(); err != nil {
+		return dst, err
 	}
 
-	// Set command length in header
-	s.Header.CommandLength = uint32(length)
+	s.Header.CommandLength = uint32(s.EncodedLen())
 	s.Header.CommandID = SUBMIT_SM_RESP
 
-	// Create the byte slice
-	buf := make([]byte, length)
-
-	// Marshal header
-	headerBytes, err := s.Header.Marshal()
-	if err != nil {
-		return nil, err
-	}
-	copy(buf[0:], headerBytes)
-
-	offset := 16
-
-	// Write message_id
-	copy(buf[offset:], s.MessageID)
-	offset += len(s.MessageID) + 1
-
-	// Write TLV parameters
-	for _, tlv := range s.TLVParams {
-		tlvBytes, err := tlv.Marshal()
-		if err != nil {
-			return nil, err
-		}
-		copy(buf[offset:], tlvBytes)
-		offset += 4 + len(tlv.Value)
-	}
+	dst = s.Header.AppendTo(dst)
+	dst = appendCString(dst, s.MessageID)
+	dst = s.TLVParams.AppendTo(dst)
+	return dst, nil
+}
 
-	return buf, nil
+// Marshal serializes the PDU into bytes
+func (s *SubmitSMResp) Marshal() ([]byte, error) {
+	return s.AppendTo(make([]byte, 0, s.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (s *SubmitSMResp) Unmarshal(data []byte) error {
-	var err error
+	return s.decode(newDecoder(data, false))
+}
 
-	// Unmarshal header
+// decode reads the PDU fields from dec
+func (s *SubmitSMResp) decode(dec *decoder) error {
 	s.Header = &Header{}
-	if err = s.Header.Unmarshal(data[:16]); err != nil {
-		return err
-	}
+	dec.header(s.Header)
 
-	offset := 16
+	s.MessageID = dec.cstring("message_id", MESSAGE_ID_MAX_LEN, ESME_RINVMSGID)
 
-	// Read message_id
-	if s.MessageID, offset, err = ReadCString(data[offset:]); err != nil {
-		return err
-	}
+	dec.tlvs(&s.TLVParams)
+	return dec.Err()
+}
+
+// CommandID returns the command_id of the PDU
+func (s *SubmitSMResp) CommandID() uint32 {
+	return SUBMIT_SM_RESP
+}
+
+// GetHeader returns the PDU header
+func (s *SubmitSMResp) GetHeader() *Header {
+	return s.Header
+}
 
-	// Read TLV parameters if any remain
-	remaining := int(s.Header.CommandLength) - offset
-	currentOffset := offset
+// String returns a one-line description of the PDU for trace logs
+func (s *SubmitSMResp) String() string {
+	return FormatPDU(s)
+}
 
-	for remaining > 0 {
-		if remaining < 4 {
-			return errors.New("invalid TLV parameter: insufficient data")
-		}
+// MarshalJSON encodes the PDU as JSON
+func (s *SubmitSMResp) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(s)
+}
 
-		tlv := &TLVParam{}
-		if err := tlv.Unmarshal(data[currentOffset:]); err != nil {
-			return err
-		}
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (s *SubmitSMResp) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, s)
+}
 
-		s.TLVParams[tlv.Tag] = tlv
-		currentOffset += 4 + int(tlv.Length)
-		remaining -= 4 + int(tlv.Length)
-	}
+// GetTLVParams returns the optional parameters of the PDU
+func (s *SubmitSMResp) GetTLVParams() *TLVList {
+	return &s.TLVParams
+}
 
+// GetResponse returns nil as submit_sm_resp has no response PDU
+func (s *SubmitSMResp) GetResponse() PDU {
 	return nil
 }
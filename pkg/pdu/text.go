@@ -0,0 +1,490 @@
+package pdu
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"nessmpp/pkg/gsm7"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+)
+
+const (
+	// SM_MAX_USER_DATA is the number of user data octets in one short message
+	SM_MAX_USER_DATA int = 140
+
+	// UDH_CONCAT_8BIT_IE_LEN is the length of an 8-bit concatenation IE
+	UDH_CONCAT_8BIT_IE_LEN int = 5
+
+	// UDH_CONCAT_16BIT_IE_LEN is the length of a 16-bit concatenation IE
+	UDH_CONCAT_16BIT_IE_LEN int = 6
+
+	// SM_MAX_LENGTH is the largest short_message the specification allows
+	SM_MAX_LENGTH int = 254
+)
+
+var (
+	ErrUnsupportedDataCoding = errors.New("unsupported data coding")
+	ErrUnencodableText       = errors.New("text cannot be represented in data coding")
+	ErrInvalidUCS2           = errors.New("invalid UCS2 data: odd length")
+	ErrInvalidJIS            = errors.New("invalid JIS data: odd length")
+	ErrInvalidUDH            = errors.New("invalid user data header")
+	ErrMessageTooLong        = errors.New("message text too long")
+)
+
+// Character sets of the SMPP specific data_coding values handled by
+// golang.org/x/text
+var textCharsets = map[uint8]encoding.Encoding{
+	DATA_CODING_ISO8859_1:  charmap.ISO8859_1,
+	DATA_CODING_CYRILLIC:   charmap.ISO8859_5,
+	DATA_CODING_HEBREW:     charmap.ISO8859_8,
+	DATA_CODING_ISO2022_JP: japanese.ISO2022JP,
+	DATA_CODING_KSC5601:    korean.EUCKR,
+}
+
+// Alphabet returns the character set selected by a data_coding value. The
+// SMPP specific values 0x00-0x0F are returned as they are, while the GSM
+// 03.38 coding groups are reduced to DATA_CODING_DEFAULT,
+// DATA_CODING_BINARY or DATA_CODING_UCS2.
+func Alphabet(dataCoding uint8) uint8 {
+	switch {
+	case dataCoding <= 0x0F:
+		return dataCoding
+	case dataCoding <= 0x7F:
+		// General data coding and automatic deletion groups
+		if dataCoding&0x20 != 0 {
+			// Compressed text cannot be decoded
+			return DATA_CODING_BINARY
+		}
+		switch (dataCoding >> 2) & 0x03 {
+		case 0x01:
+			return DATA_CODING_BINARY
+		case 0x02:
+			return DATA_CODING_UCS2
+		}
+		return DATA_CODING_DEFAULT
+	case dataCoding <= 0xDF:
+		// Reserved groups and message waiting indication groups
+		return DATA_CODING_DEFAULT
+	case dataCoding <= 0xEF:
+		// Message waiting indication group, store message, UCS2
+		return DATA_CODING_UCS2
+	default:
+		// Data coding/message class group
+		if dataCoding&0x04 != 0 {
+			return DATA_CODING_BINARY
+		}
+		return DATA_CODING_DEFAULT
+	}
+}
+
+// TextEncoding describes how text is represented in short_message. The
+// national language shift tables only apply to the GSM 7-bit alphabet and
+// are identified by NLI_* constants.
+type TextEncoding struct {
+	DataCoding   uint8
+	LockingShift uint8
+	SingleShift  uint8
+}
+
+// IsGSM7 checks if the encoding uses the GSM 7-bit alphabet
+func (e TextEncoding) IsGSM7() bool {
+	return Alphabet(e.DataCoding) == DATA_CODING_DEFAULT
+}
+
+// NationalShiftElements returns the UDH elements announcing the national
+// language shift tables, or nil when the default tables are used
+func (e TextEncoding) NationalShiftElements() []UDHElement {
+	if !e.IsGSM7() {
+		return nil
+	}
+	var elements []UDHElement
+	if e.LockingShift != NLI_DEFAULT {
+		elements = append(elements, &NationalShiftIE{Language: e.LockingShift, Locking: true})
+	}
+	if e.SingleShift != NLI_DEFAULT {
+		elements = append(elements, &NationalShiftIE{Language: e.SingleShift})
+	}
+	return elements
+}
+
+// udhLength returns the length of the UDH, including the UDHL octet, that
+// a message in this encoding needs next to a concatenation IE of concatLen
+// octets
+func (e TextEncoding) udhLength(concatLen int) int {
+	n := 0
+	for _, ie := range e.NationalShiftElements() {
+		n += 2 + len(ie.Value())
+	}
+	n += concatLen
+	if n > 0 {
+		n++ // UDHL
+	}
+	return n
+}
+
+// Capacity returns how many septets (GSM 7-bit) or octets of text fit in
+// one short message next to a UDH of udhLen octets including the UDHL
+func (e TextEncoding) Capacity(udhLen int) int {
+	if e.IsGSM7() {
+		// The text starts on the septet boundary following the UDH
+		return SM_MAX_USER_DATA*8/7 - (udhLen*8+6)/7
+	}
+	n := SM_MAX_USER_DATA - udhLen
+	if Alphabet(e.DataCoding) == DATA_CODING_UCS2 {
+		n &^= 1
+	}
+	return n
+}
+
+// Encode converts text into short_message octets. GSM 7-bit text is
+// returned unpacked, one septet per octet.
+func (e TextEncoding) Encode(text string) ([]byte, error) {
+	alphabet := Alphabet(e.DataCoding)
+	switch alphabet {
+	case DATA_CODING_DEFAULT:
+		return gsm7.Encoding{LockingShift: e.LockingShift, SingleShift: e.SingleShift}.Encode(text)
+	case DATA_CODING_IA5:
+		for i := 0; i < len(text); i++ {
+			if text[i] >= utf8.RuneSelf {
+				r, _ := utf8.DecodeRuneInString(text[i:])
+				return nil, fmt.Errorf("%w 0x%02X: %q", ErrUnencodableText, e.DataCoding, r)
+			}
+		}
+		return []byte(text), nil
+	case DATA_CODING_BINARY, DATA_CODING_OCTET:
+		return []byte(text), nil
+	case DATA_CODING_UCS2:
+		return encodeUCS2(text), nil
+	case DATA_CODING_JIS, DATA_CODING_KANJI:
+		return encodeJIS(text, alphabet == DATA_CODING_KANJI)
+	}
+
+	charset, ok := textCharsets[alphabet]
+	if !ok {
+		return nil, fmt.Errorf("%w: 0x%02X", ErrUnsupportedDataCoding, e.DataCoding)
+	}
+	data, err := charset.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		return nil, fmt.Errorf("%w 0x%02X: %v", ErrUnencodableText, e.DataCoding, err)
+	}
+	return data, nil
+}
+
+// Decode converts short_message octets, without UDH, into text
+func (e TextEncoding) Decode(data []byte) (string, error) {
+	alphabet := Alphabet(e.DataCoding)
+	switch alphabet {
+	case DATA_CODING_DEFAULT:
+		return gsm7.Encoding{LockingShift: e.LockingShift, SingleShift: e.SingleShift}.Decode(data)
+	case DATA_CODING_IA5:
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+			if b >= utf8.RuneSelf {
+				runes[i] = utf8.RuneError
+			}
+		}
+		return string(runes), nil
+	case DATA_CODING_BINARY, DATA_CODING_OCTET:
+		return string(data), nil
+	case DATA_CODING_UCS2:
+		return decodeUCS2(data)
+	case DATA_CODING_JIS, DATA_CODING_KANJI:
+		return decodeJIS(data, alphabet == DATA_CODING_KANJI)
+	}
+
+	charset, ok := textCharsets[alphabet]
+	if !ok {
+		return "", fmt.Errorf("%w: 0x%02X", ErrUnsupportedDataCoding, e.DataCoding)
+	}
+	text, err := charset.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", err
+	}
+	return string(text), nil
+}
+
+// widths returns the encoded length of every character of text in
+// septets or octets, so that a message is never split inside a character.
+// It returns nil for stateful encodings that cannot be split that way.
+func (e TextEncoding) widths(text string, data []byte) []int {
+	widths := make([]int, 0, utf8.RuneCountInString(text))
+	switch Alphabet(e.DataCoding) {
+	case DATA_CODING_DEFAULT:
+		for i := 0; i < len(data); i++ {
+			if data[i] == gsm7.ESCAPE && i+1 < len(data) {
+				widths = append(widths, 2)
+				i++
+				continue
+			}
+			widths = append(widths, 1)
+		}
+	case DATA_CODING_UCS2:
+		for _, r := range text {
+			widths = append(widths, 2*utf16.RuneLen(r))
+		}
+	case DATA_CODING_BINARY, DATA_CODING_OCTET, DATA_CODING_IA5:
+		for range data {
+			widths = append(widths, 1)
+		}
+	case DATA_CODING_ISO2022_JP:
+		return nil
+	default:
+		for _, r := range text {
+			encoded, err := e.Encode(string(r))
+			if err != nil {
+				return nil
+			}
+			widths = append(widths, len(encoded))
+		}
+	}
+	return widths
+}
+
+// EncodedText is text encoded for a short message together with its size
+type EncodedText struct {
+	TextEncoding
+	Data     []byte // Encoded text without UDH; GSM 7-bit text is unpacked
+	Length   int    // Length in septets for the GSM 7-bit alphabet, octets otherwise
+	Segments int    // Number of short messages needed with 8-bit concatenation
+	widths   []int  // Encoded length of every character
+}
+
+// NewEncodedText encodes text and measures how many short messages it needs
+func NewEncodedText(text string, enc TextEncoding) (*EncodedText, error) {
+	data, err := enc.Encode(text)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &EncodedText{
+		TextEncoding: enc,
+		Data:         data,
+		Length:       len(data),
+	}
+	t.widths = enc.widths(text, data)
+	t.Segments = len(t.chunks(enc.Capacity(enc.udhLength(0)), enc.Capacity(enc.udhLength(UDH_CONCAT_8BIT_IE_LEN))))
+	return t, nil
+}
+
+// chunks splits the encoded text into parts of at most limit septets or
+// octets without breaking a character. single is the limit that applies
+// when the text fits in one message.
+func (t *EncodedText) chunks(single, limit int) [][]byte {
+	if len(t.Data) <= single || limit <= 0 {
+		return [][]byte{t.Data}
+	}
+
+	var parts [][]byte
+	if t.widths == nil {
+		for start := 0; start < len(t.Data); start += limit {
+			end := start + limit
+			if end > len(t.Data) {
+				end = len(t.Data)
+			}
+			parts = append(parts, t.Data[start:end])
+		}
+		return parts
+	}
+
+	start, end := 0, 0
+	for _, w := range t.widths {
+		if end+w-start > limit {
+			parts = append(parts, t.Data[start:end])
+			start = end
+		}
+		end += w
+	}
+	return append(parts, t.Data[start:end])
+}
+
+// ShortMessage returns the short_message content, prefixed with a UDH when
+// national language shift tables are used
+func (t *EncodedText) ShortMessage() []byte {
+	elements := t.NationalShiftElements()
+	if len(elements) == 0 {
+		return t.Data
+	}
+	sm, _ := NewUDH(elements...).Prepend(t.Data)
+	return sm
+}
+
+// HasUDH checks if ShortMessage starts with a UDH
+func (t *EncodedText) HasUDH() bool {
+	return len(t.NationalShiftElements()) > 0
+}
+
+// octets returns the size of the text on the air interface
+func (t *EncodedText) octets() int {
+	if t.IsGSM7() {
+		return (t.Length*7+7)/8 + t.udhLength(0)
+	}
+	return t.Length
+}
+
+// TextOptions controls the automatic data_coding selection
+type TextOptions struct {
+	// Languages lists the national languages whose shift tables may be
+	// used. A nil slice allows every registered language, an empty one none.
+	Languages []uint8
+
+	// Latin1 allows DATA_CODING_ISO8859_1, which not every SMSC supports
+	Latin1 bool
+}
+
+// SelectTextEncoding encodes text in the representation needing the fewest
+// short messages and octets: the GSM 7-bit default alphabet, GSM 7-bit with
+// national language shift tables, Latin-1 if allowed, and finally UCS2
+// which can represent any text, using UTF-16 surrogate pairs outside the
+// basic multilingual plane.
+func SelectTextEncoding(text string, opts *TextOptions) *EncodedText {
+	if opts == nil {
+		opts = &TextOptions{}
+	}
+
+	candidates := []TextEncoding{{DataCoding: DATA_CODING_DEFAULT}}
+
+	languages := opts.Languages
+	if languages == nil {
+		languages = gsm7.Languages()
+	}
+	for _, nli := range languages {
+		if nli == NLI_DEFAULT {
+			continue
+		}
+		_, locking := gsm7.LockingShift(nli)
+		_, single := gsm7.SingleShift(nli)
+		if locking {
+			candidates = append(candidates, TextEncoding{DataCoding: DATA_CODING_DEFAULT, LockingShift: nli})
+		}
+		if single {
+			candidates = append(candidates, TextEncoding{DataCoding: DATA_CODING_DEFAULT, SingleShift: nli})
+		}
+		if locking && single {
+			candidates = append(candidates, TextEncoding{DataCoding: DATA_CODING_DEFAULT, LockingShift: nli, SingleShift: nli})
+		}
+	}
+
+	if opts.Latin1 {
+		candidates = append(candidates, TextEncoding{DataCoding: DATA_CODING_ISO8859_1})
+	}
+
+	var best *EncodedText
+	for _, enc := range candidates {
+		t, err := NewEncodedText(text, enc)
+		if err != nil {
+			continue
+		}
+		if best == nil || t.Segments < best.Segments ||
+			(t.Segments == best.Segments && t.octets() < best.octets()) {
+			best = t
+		}
+	}
+	if best != nil {
+		return best
+	}
+
+	// UCS2 represents any text
+	t, _ := NewEncodedText(text, TextEncoding{DataCoding: DATA_CODING_UCS2})
+	return t
+}
+
+// DecodeShortMessage decodes the text of a message. The UDH is skipped when
+// esmClass has the UDHI flag set, and its national language shift IEs
+// select the GSM 7-bit tables.
+func DecodeShortMessage(data []byte, esmClass, dataCoding uint8) (string, error) {
+	udh, payload, err := SplitUserData(data, esmClass)
+	if err != nil {
+		return "", err
+	}
+	enc := TextEncoding{DataCoding: dataCoding}
+	if udh != nil {
+		enc.LockingShift, enc.SingleShift = udh.NationalShifts()
+	}
+	return enc.Decode(payload)
+}
+
+// messageText decodes short_message, falling back to the message_payload TLV
+func messageText(sm []byte, esmClass, dataCoding uint8, params TLVList) (string, error) {
+	if len(sm) == 0 {
+		if payload, ok := params.MessagePayload(); ok {
+			sm = payload
+		}
+	}
+	return DecodeShortMessage(sm, esmClass, dataCoding)
+}
+
+// encodeUCS2 converts text into big endian UTF-16
+func encodeUCS2(text string) []byte {
+	units := utf16.Encode([]rune(text))
+	data := make([]byte, 2*len(units))
+	for i, u := range units {
+		binary.BigEndian.PutUint16(data[2*i:], u)
+	}
+	return data
+}
+
+// decodeUCS2 converts big endian UTF-16 into text
+func decodeUCS2(data []byte) (string, error) {
+	if len(data)%2 != 0 {
+		return "", ErrInvalidUCS2
+	}
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = binary.BigEndian.Uint16(data[2*i:])
+	}
+	return string(utf16.Decode(units)), nil
+}
+
+// encodeJIS converts text into two octet JIS X 0208 codes, or JIS X 0212
+// codes when extended is set, by way of EUC-JP
+func encodeJIS(text string, extended bool) ([]byte, error) {
+	euc, err := japanese.EUCJP.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnencodableText, err)
+	}
+
+	data := make([]byte, 0, len(euc))
+	for i := 0; i < len(euc); {
+		switch {
+		case extended && euc[i] == 0x8F && i+2 < len(euc):
+			data = append(data, euc[i+1]&0x7F, euc[i+2]&0x7F)
+			i += 3
+		case !extended && euc[i] >= 0xA1 && i+1 < len(euc):
+			data = append(data, euc[i]&0x7F, euc[i+1]&0x7F)
+			i += 2
+		default:
+			return nil, fmt.Errorf("%w: character outside the JIS character set", ErrUnencodableText)
+		}
+	}
+	return data, nil
+}
+
+// decodeJIS converts two octet JIS X 0208 codes, or JIS X 0212 codes when
+// extended is set, into text by way of EUC-JP
+func decodeJIS(data []byte, extended bool) (string, error) {
+	if len(data)%2 != 0 {
+		return "", ErrInvalidJIS
+	}
+
+	euc := make([]byte, 0, len(data)*3/2)
+	for i := 0; i < len(data); i += 2 {
+		if extended {
+			euc = append(euc, 0x8F)
+		}
+		euc = append(euc, data[i]|0x80, data[i+1]|0x80)
+	}
+
+	text, err := japanese.EUCJP.NewDecoder().Bytes(euc)
+	if err != nil {
+		return "", err
+	}
+	return string(text), nil
+}
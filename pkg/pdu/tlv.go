@@ -0,0 +1,158 @@
+package pdu
+
+var (
+	ErrTLVTooShort       = NewStatusError(ESME_RINVPARLEN, "invalid TLV data: too short")
+	ErrTLVLengthMismatch = NewStatusError(ESME_RINVPARLEN, "invalid TLV data: value length mismatch")
+)
+
+// TLVList is an ordered list of optional parameters. Parameters are encoded
+// in the order they were added or decoded, and a tag may appear more than
+// once where the specification allows it (e.g. broadcast_area_identifier).
+type TLVList []*TLVParam
+
+// TLVCarrier is implemented by PDUs that carry optional parameters
+type TLVCarrier interface {
+	PDU
+	GetTLVParams() *TLVList
+}
+
+// Get returns the first parameter with the given tag, or nil
+func (l TLVList) Get(tag uint16) *TLVParam {
+	for _, tlv := range l {
+		if tlv.Tag == tag {
+			return tlv
+		}
+	}
+	return nil
+}
+
+// GetAll returns every parameter with the given tag in order
+func (l TLVList) GetAll(tag uint16) []*TLVParam {
+	var params []*TLVParam
+	for _, tlv := range l {
+		if tlv.Tag == tag {
+			params = append(params, tlv)
+		}
+	}
+	return params
+}
+
+// Has checks if a parameter with the given tag is present
+func (l TLVList) Has(tag uint16) bool {
+	return l.Get(tag) != nil
+}
+
+// Set stores value as the only parameter with the given tag. An existing
+// parameter keeps its position; further duplicates are removed.
+func (l *TLVList) Set(tag uint16, value []byte) {
+	found := false
+	params := (*l)[:0]
+	for _, tlv := range *l {
+		if tlv.Tag != tag {
+			params = append(params, tlv)
+			continue
+		}
+		if !found {
+			params = append(params, NewTLVParam(tag, value))
+			found = true
+		}
+	}
+	for i := len(params); i < len(*l); i++ {
+		(*l)[i] = nil
+	}
+	*l = params
+
+	if !found {
+		*l = append(*l, NewTLVParam(tag, value))
+	}
+}
+
+// Add appends a parameter, keeping any existing ones with the same tag
+func (l *TLVList) Add(tag uint16, value []byte) {
+	*l = append(*l, NewTLVParam(tag, value))
+}
+
+// AddParam appends an existing parameter
+func (l *TLVList) AddParam(tlv *TLVParam) {
+	*l = append(*l, tlv)
+}
+
+// Delete removes every parameter with the given tag
+func (l *TLVList) Delete(tag uint16) {
+	params := (*l)[:0]
+	for _, tlv := range *l {
+		if tlv.Tag != tag {
+			params = append(params, tlv)
+		}
+	}
+	for i := len(params); i < len(*l); i++ {
+		(*l)[i] = nil
+	}
+	*l = params
+}
+
+// Len returns the number of parameters
+func (l TLVList) Len() int {
+	return len(l)
+}
+
+// EncodedLen returns the number of bytes the parameters occupy on the wire
+func (l TLVList) EncodedLen() int {
+	length := 0
+	for _, tlv := range l {
+		length += 4 + len(tlv.Value) // Tag(2) + Length(2) + Value(n)
+	}
+	return length
+}
+
+// Clone returns a deep copy of the list
+func (l TLVList) Clone() TLVList {
+	if l == nil {
+		return nil
+	}
+	params := make(TLVList, 0, len(l))
+	for _, tlv := range l {
+		c := NewTLVParam(tlv.Tag, append([]byte(nil), tlv.Value...))
+		c.def = tlv.def
+		params = append(params, c)
+	}
+	return params
+}
+
+// Marshal serializes the parameters in order
+func (l TLVList) Marshal() ([]byte, error) {
+	return l.AppendTo(make([]byte, 0, l.EncodedLen())), nil
+}
+
+// AppendTo appends the encoded parameters to dst in order
+func (l TLVList) AppendTo(dst []byte) []byte {
+	for _, tlv := range l {
+		dst = tlv.AppendTo(dst)
+	}
+	return dst
+}
+
+// Unmarshal replaces the list with the parameters encoded in data
+func (l *TLVList) Unmarshal(data []byte) error {
+	*l = (*l)[:0]
+
+	offset := 0
+	for offset < len(data) {
+		if len(data)-offset < 4 {
+			return ErrTLVTooShort
+		}
+
+		tlv := &TLVParam{}
+		if err := tlv.Unmarshal(data[offset:]); err != nil {
+			return err
+		}
+
+		*l = append(*l, tlv)
+		offset += 4 + int(tlv.Length)
+	}
+
+	if len(*l) == 0 {
+		*l = nil
+	}
+	return nil
+}
@@ -0,0 +1,491 @@
+package pdu
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sync"
+)
+
+// TLVType describes how the value of a TLV is encoded
+type TLVType uint8
+
+// TLV value types
+const (
+	TLV_TYPE_OCTETS  TLVType = iota // Octet string
+	TLV_TYPE_UINT8                  // 1 octet integer
+	TLV_TYPE_UINT16                 // 2 octet integer
+	TLV_TYPE_UINT32                 // 4 octet integer
+	TLV_TYPE_CSTRING                // Null terminated string
+)
+
+// String returns the name of the type
+func (t TLVType) String() string {
+	switch t {
+	case TLV_TYPE_UINT8:
+		return "uint8"
+	case TLV_TYPE_UINT16:
+		return "uint16"
+	case TLV_TYPE_UINT32:
+		return "uint32"
+	case TLV_TYPE_CSTRING:
+		return "c-string"
+	default:
+		return "octets"
+	}
+}
+
+// TLVError reports a TLV that violates its schema
+type TLVError struct {
+	Tag    uint16
+	Name   string
+	Status uint32
+	Reason string
+}
+
+// Error returns the error message
+func (e *TLVError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("tlv %s (0x%04X): %s", e.Name, e.Tag, e.Reason)
+	}
+	return fmt.Sprintf("tlv 0x%04X: %s", e.Tag, e.Reason)
+}
+
+// CommandStatus returns the command_status to answer with
+func (e *TLVError) CommandStatus() uint32 {
+	return e.Status
+}
+
+// TLVDef describes a TLV tag: its name, value type, permitted value length
+// and the PDUs it may appear in for each interface version
+type TLVDef struct {
+	Tag    uint16
+	Name   string
+	Type   TLVType
+	MinLen int
+	MaxLen int
+
+	// Allowed maps a command_id to the first interface version in which
+	// the tag is permitted in that PDU
+	Allowed map[uint32]uint32
+
+	// Validate optionally checks the value beyond its length
+	Validate func(value []byte) error
+}
+
+// Allow permits the tag in the given PDUs from the given interface version on
+func (d *TLVDef) Allow(version uint32, commandIDs ...uint32) *TLVDef {
+	if d.Allowed == nil {
+		d.Allowed = make(map[uint32]uint32)
+	}
+	for _, id := range commandIDs {
+		if v, ok := d.Allowed[id]; !ok || version < v {
+			d.Allowed[id] = version
+		}
+	}
+	return d
+}
+
+// AllowedIn checks if the tag may appear in a PDU for an interface version.
+// A definition without any Allowed entries, as most vendor TLVs have, is
+// permitted in every PDU.
+func (d *TLVDef) AllowedIn(commandID uint32, version uint32) bool {
+	if d.Allowed == nil {
+		return version >= SMPP_V34
+	}
+	v, ok := d.Allowed[commandID]
+	return ok && version >= v
+}
+
+// lengthBounds returns the permitted value length range
+func (d *TLVDef) lengthBounds() (int, int) {
+	switch d.Type {
+	case TLV_TYPE_UINT8:
+		return 1, 1
+	case TLV_TYPE_UINT16:
+		return 2, 2
+	case TLV_TYPE_UINT32:
+		return 4, 4
+	}
+	if d.MaxLen == 0 {
+		return d.MinLen, 0xFFFF
+	}
+	return d.MinLen, d.MaxLen
+}
+
+// CheckValue validates a value against the definition's type and length
+func (d *TLVDef) CheckValue(value []byte) error {
+	min, max := d.lengthBounds()
+	if len(value) < min || len(value) > max {
+		return &TLVError{Tag: d.Tag, Name: d.Name, Status: ESME_RINVPARLEN,
+			Reason: fmt.Sprintf("length %d outside %d..%d", len(value), min, max)}
+	}
+
+	if d.Type == TLV_TYPE_CSTRING && len(value) > 0 {
+		if i := bytes.IndexByte(value, 0); i != len(value)-1 {
+			return &TLVError{Tag: d.Tag, Name: d.Name, Status: ESME_RINVOPTPARAMVAL,
+				Reason: "c-string is not null terminated"}
+		}
+	}
+
+	if d.Validate != nil {
+		if err := d.Validate(value); err != nil {
+			// Validators may report a more specific status
+			status, ok := ErrorStatus(err)
+			if !ok {
+				status = ESME_RINVOPTPARAMVAL
+			}
+			return &TLVError{Tag: d.Tag, Name: d.Name, Status: status, Reason: err.Error()}
+		}
+	}
+
+	return nil
+}
+
+// TLVSchema is a registry of TLV definitions keyed by tag
+type TLVSchema struct {
+	mu   sync.RWMutex
+	defs map[uint16]*TLVDef
+}
+
+// NewTLVSchema creates an empty schema
+func NewTLVSchema() *TLVSchema {
+	return &TLVSchema{
+		defs: make(map[uint16]*TLVDef),
+	}
+}
+
+// Register adds or replaces a definition and returns it for chaining
+func (s *TLVSchema) Register(def *TLVDef) *TLVDef {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.defs[def.Tag] = def
+	return def
+}
+
+// Lookup returns the definition of a tag
+func (s *TLVSchema) Lookup(tag uint16) (*TLVDef, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	def, ok := s.defs[tag]
+	return def, ok
+}
+
+// Name returns the symbolic name of a tag, or its hex value if unknown
+func (s *TLVSchema) Name(tag uint16) string {
+	if def, ok := s.Lookup(tag); ok {
+		return def.Name
+	}
+	return fmt.Sprintf("0x%04X", tag)
+}
+
+// LookupName returns the definition with the given symbolic name
+func (s *TLVSchema) LookupName(name string) (*TLVDef, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, def := range s.defs {
+		if def.Name == name {
+			return def, true
+		}
+	}
+	return nil, false
+}
+
+// Clone returns a copy of the schema that can be extended independently
+func (s *TLVSchema) Clone() *TLVSchema {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c := NewTLVSchema()
+	for tag, def := range s.defs {
+		c.defs[tag] = def
+	}
+	return c
+}
+
+// Validate checks every known TLV in the list against the schema for the
+// given PDU and interface version. Unknown tags are ignored as required by
+// the specification.
+func (s *TLVSchema) Validate(commandID uint32, version uint32, params TLVList) error {
+	for _, tlv := range params {
+		def, ok := s.Lookup(tlv.Tag)
+		if !ok {
+			continue
+		}
+
+		if !def.AllowedIn(commandID, version) {
+			return &TLVError{Tag: def.Tag, Name: def.Name, Status: ESME_ROPTPARNOTALLWD,
+				Reason: fmt.Sprintf("not allowed in command 0x%08X for version 0x%02X", commandID, version)}
+		}
+
+		if int(tlv.Length) != len(tlv.Value) {
+			return &TLVError{Tag: def.Tag, Name: def.Name, Status: ESME_RINVPARLEN,
+				Reason: fmt.Sprintf("length field %d does not match value length %d", tlv.Length, len(tlv.Value))}
+		}
+
+		if err := def.CheckValue(tlv.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bind attaches the definitions of known tags to the parameters, so that
+// they keep their names and types outside of the schema
+func (s *TLVSchema) bind(params TLVList) {
+	for _, tlv := range params {
+		if def, ok := s.Lookup(tlv.Tag); ok {
+			tlv.def = def
+		}
+	}
+}
+
+// Def returns the definition of the parameter: the one it was decoded
+// with, or else the one in DefaultTLVSchema
+func (tlv *TLVParam) Def() (*TLVDef, bool) {
+	if tlv.def != nil {
+		return tlv.def, true
+	}
+	return DefaultTLVSchema.Lookup(tlv.Tag)
+}
+
+// Name returns the symbolic name of the parameter, or its tag in hex
+func (tlv *TLVParam) Name() string {
+	if def, ok := tlv.Def(); ok {
+		return def.Name
+	}
+	return fmt.Sprintf("0x%04X", tlv.Tag)
+}
+
+// TypedValue returns the value of an integer parameter as uint8, uint16
+// or uint32 and that of a c-string parameter as string. It returns false
+// for octet strings, unknown tags and values that do not match their type.
+func (tlv *TLVParam) TypedValue() (any, bool) {
+	return tlvValue(tlv)
+}
+
+// NewParam creates a parameter of the definition from a typed value: an
+// integer for integer types, a string for c-strings and octet strings, or
+// raw octets for any type. The value is checked against the definition.
+func (d *TLVDef) NewParam(v any) (*TLVParam, error) {
+	var value []byte
+	switch x := v.(type) {
+	case []byte:
+		value = x
+	case string:
+		value = []byte(x)
+		if d.Type == TLV_TYPE_CSTRING {
+			value = append(value, 0)
+		}
+	default:
+		n, ok := toUint64(v)
+		size, _ := d.lengthBounds()
+		switch d.Type {
+		case TLV_TYPE_UINT8, TLV_TYPE_UINT16, TLV_TYPE_UINT32:
+		default:
+			ok = false
+		}
+		if !ok || n>>(size*8) != 0 {
+			return nil, &TLVError{Tag: d.Tag, Name: d.Name, Status: ESME_RINVOPTPARAMVAL,
+				Reason: fmt.Sprintf("%v does not fit a %s value", v, d.Type)}
+		}
+		value = binary.BigEndian.AppendUint32(nil, uint32(n))[4-size:]
+	}
+	if err := d.CheckValue(value); err != nil {
+		return nil, err
+	}
+	tlv := NewTLVParam(d.Tag, value)
+	tlv.def = d
+	return tlv, nil
+}
+
+// toUint64 converts an integer of any type, failing for negative values
+func toUint64(v any) (uint64, bool) {
+	switch x := v.(type) {
+	case uint8:
+		return uint64(x), true
+	case uint16:
+		return uint64(x), true
+	case uint32:
+		return uint64(x), true
+	case uint64:
+		return x, true
+	case uint:
+		return uint64(x), true
+	case int:
+		return uint64(x), x >= 0
+	case int8:
+		return uint64(x), x >= 0
+	case int16:
+		return uint64(x), x >= 0
+	case int32:
+		return uint64(x), x >= 0
+	case int64:
+		return uint64(x), x >= 0
+	}
+	return 0, false
+}
+
+// maxValue returns a validator limiting a 1 octet value
+func maxValue(max uint8) func([]byte) error {
+	return func(value []byte) error {
+		if value[0] > max {
+			return fmt.Errorf("value %d exceeds %d", value[0], max)
+		}
+		return nil
+	}
+}
+
+// nonZero rejects a zero 1 octet value
+func nonZero(value []byte) error {
+	if value[0] == 0 {
+		return fmt.Errorf("value must not be zero")
+	}
+	return nil
+}
+
+var (
+	// PDUs that carry a short message to or from a mobile
+	messagePDUs = []uint32{SUBMIT_SM, SUBMIT_MULTI, DELIVER_SM, DATA_SM}
+
+	// Responses to message submission and delivery
+	messageRespPDUs = []uint32{SUBMIT_SM_RESP, SUBMIT_MULTI_RESP, DELIVER_SM_RESP, DATA_SM_RESP}
+
+	// Responses to bind operations
+	bindRespPDUs = []uint32{BIND_RECEIVER_RESP, BIND_TRANSMITTER_RESP, BIND_TRANSCEIVER_RESP}
+)
+
+// DefaultTLVSchema holds the standard SMPP v3.4 and v5.0 TLV definitions
+var DefaultTLVSchema = newDefaultTLVSchema()
+
+func newDefaultTLVSchema() *TLVSchema {
+	s := NewTLVSchema()
+
+	// Addressing
+	s.Register(&TLVDef{Tag: TLV_DEST_ADDR_SUBUNIT, Name: "dest_addr_subunit", Type: TLV_TYPE_UINT8, Validate: maxValue(4)}).
+		Allow(SMPP_V34, SUBMIT_SM, SUBMIT_MULTI, DATA_SM).Allow(SMPP_V50, DELIVER_SM, BROADCAST_SM)
+	s.Register(&TLVDef{Tag: TLV_DEST_NETWORK_TYPE, Name: "dest_network_type", Type: TLV_TYPE_UINT8}).
+		Allow(SMPP_V34, DATA_SM).Allow(SMPP_V50, SUBMIT_SM, SUBMIT_MULTI, DELIVER_SM)
+	s.Register(&TLVDef{Tag: TLV_DEST_BEARER_TYPE, Name: "dest_bearer_type", Type: TLV_TYPE_UINT8}).
+		Allow(SMPP_V34, DATA_SM).Allow(SMPP_V50, SUBMIT_SM, SUBMIT_MULTI, DELIVER_SM)
+	s.Register(&TLVDef{Tag: TLV_DEST_TELEMATICS_ID, Name: "dest_telematics_id", Type: TLV_TYPE_UINT16}).
+		Allow(SMPP_V34, DATA_SM).Allow(SMPP_V50, SUBMIT_SM, SUBMIT_MULTI, DELIVER_SM)
+	s.Register(&TLVDef{Tag: TLV_SOURCE_ADDR_SUBUNIT, Name: "source_addr_subunit", Type: TLV_TYPE_UINT8, Validate: maxValue(4)}).
+		Allow(SMPP_V34, messagePDUs...)
+	s.Register(&TLVDef{Tag: TLV_SOURCE_NETWORK_TYPE, Name: "source_network_type", Type: TLV_TYPE_UINT8}).
+		Allow(SMPP_V34, DATA_SM).Allow(SMPP_V50, SUBMIT_SM, SUBMIT_MULTI, DELIVER_SM)
+	s.Register(&TLVDef{Tag: TLV_SOURCE_BEARER_TYPE, Name: "source_bearer_type", Type: TLV_TYPE_UINT8}).
+		Allow(SMPP_V34, DATA_SM).Allow(SMPP_V50, SUBMIT_SM, SUBMIT_MULTI, DELIVER_SM)
+	s.Register(&TLVDef{Tag: TLV_SOURCE_TELEMATICS_ID, Name: "source_telematics_id", Type: TLV_TYPE_UINT8}).
+		Allow(SMPP_V34, DATA_SM).Allow(SMPP_V50, SUBMIT_SM, SUBMIT_MULTI, DELIVER_SM)
+	s.Register(&TLVDef{Tag: TLV_SOURCE_SUBADDRESS, Name: "source_subaddress", Type: TLV_TYPE_OCTETS, MinLen: 2, MaxLen: 23}).
+		Allow(SMPP_V34, messagePDUs...)
+	s.Register(&TLVDef{Tag: TLV_DEST_SUBADDRESS, Name: "dest_subaddress", Type: TLV_TYPE_OCTETS, MinLen: 2, MaxLen: 23}).
+		Allow(SMPP_V34, messagePDUs...)
+
+	// Message submission
+	s.Register(&TLVDef{Tag: TLV_QOS_TIME_TO_LIVE, Name: "qos_time_to_live", Type: TLV_TYPE_UINT32}).
+		Allow(SMPP_V34, DATA_SM)
+	s.Register(&TLVDef{Tag: TLV_PAYLOAD_TYPE, Name: "payload_type", Type: TLV_TYPE_UINT8, Validate: maxValue(1)}).
+		Allow(SMPP_V34, messagePDUs...)
+	s.Register(&TLVDef{Tag: TLV_MS_MSG_WAIT_FACILITIES, Name: "ms_msg_wait_facilities", Type: TLV_TYPE_UINT8}).
+		Allow(SMPP_V34, SUBMIT_SM, SUBMIT_MULTI, DATA_SM).Allow(SMPP_V50, DELIVER_SM)
+	s.Register(&TLVDef{Tag: TLV_PRIVACY_INDICATOR, Name: "privacy_indicator", Type: TLV_TYPE_UINT8, Validate: maxValue(3)}).
+		Allow(SMPP_V34, messagePDUs...)
+	s.Register(&TLVDef{Tag: TLV_USER_MESSAGE_REFERENCE, Name: "user_message_reference", Type: TLV_TYPE_UINT16}).
+		Allow(SMPP_V34, messagePDUs...)
+	s.Register(&TLVDef{Tag: TLV_USER_RESPONSE_CODE, Name: "user_response_code", Type: TLV_TYPE_UINT8}).
+		Allow(SMPP_V34, messagePDUs...)
+	s.Register(&TLVDef{Tag: TLV_SOURCE_PORT, Name: "source_port", Type: TLV_TYPE_UINT16}).
+		Allow(SMPP_V34, messagePDUs...)
+	s.Register(&TLVDef{Tag: TLV_DESTINATION_PORT, Name: "destination_port", Type: TLV_TYPE_UINT16}).
+		Allow(SMPP_V34, messagePDUs...)
+	s.Register(&TLVDef{Tag: TLV_SAR_MSG_REF_NUM, Name: "sar_msg_ref_num", Type: TLV_TYPE_UINT16}).
+		Allow(SMPP_V34, messagePDUs...)
+	s.Register(&TLVDef{Tag: TLV_LANGUAGE_INDICATOR, Name: "language_indicator", Type: TLV_TYPE_UINT8}).
+		Allow(SMPP_V34, messagePDUs...).Allow(SMPP_V50, BROADCAST_SM)
+	s.Register(&TLVDef{Tag: TLV_SAR_TOTAL_SEGMENTS, Name: "sar_total_segments", Type: TLV_TYPE_UINT8, Validate: nonZero}).
+		Allow(SMPP_V34, messagePDUs...)
+	s.Register(&TLVDef{Tag: TLV_SAR_SEGMENT_SEQNUM, Name: "sar_segment_seqnum", Type: TLV_TYPE_UINT8, Validate: nonZero}).
+		Allow(SMPP_V34, messagePDUs...)
+	s.Register(&TLVDef{Tag: TLV_CALLBACK_NUM_PRES_IND, Name: "callback_num_pres_ind", Type: TLV_TYPE_UINT8}).
+		Allow(SMPP_V34, messagePDUs...).Allow(SMPP_V50, BROADCAST_SM)
+	s.Register(&TLVDef{Tag: TLV_CALLBACK_NUM_ATAG, Name: "callback_num_atag", Type: TLV_TYPE_OCTETS, MaxLen: 65}).
+		Allow(SMPP_V34, messagePDUs...).Allow(SMPP_V50, BROADCAST_SM)
+	s.Register(&TLVDef{Tag: TLV_NUMBER_OF_MESSAGES, Name: "number_of_messages", Type: TLV_TYPE_UINT8, Validate: maxValue(99)}).
+		Allow(SMPP_V34, SUBMIT_SM, SUBMIT_MULTI, DATA_SM).Allow(SMPP_V50, DELIVER_SM)
+	s.Register(&TLVDef{Tag: TLV_CALLBACK_NUM, Name: "callback_num", Type: TLV_TYPE_OCTETS, MinLen: 4, MaxLen: 19}).
+		Allow(SMPP_V34, messagePDUs...).Allow(SMPP_V50, BROADCAST_SM)
+	s.Register(&TLVDef{Tag: TLV_SET_DPF, Name: "set_dpf", Type: TLV_TYPE_UINT8, Validate: maxValue(1)}).
+		Allow(SMPP_V34, DATA_SM).Allow(SMPP_V50, SUBMIT_SM, SUBMIT_MULTI)
+	s.Register(&TLVDef{Tag: TLV_MESSAGE_PAYLOAD, Name: "message_payload", Type: TLV_TYPE_OCTETS}).
+		Allow(SMPP_V34, messagePDUs...).Allow(SMPP_V50, BROADCAST_SM)
+	s.Register(&TLVDef{Tag: TLV_MORE_MESSAGES_TO_SEND, Name: "more_messages_to_send", Type: TLV_TYPE_UINT8, Validate: maxValue(1)}).
+		Allow(SMPP_V34, messagePDUs...)
+	s.Register(&TLVDef{Tag: TLV_USSD_SERVICE_OP, Name: "ussd_service_op", Type: TLV_TYPE_UINT8}).
+		Allow(SMPP_V34, SUBMIT_SM, DELIVER_SM).Allow(SMPP_V50, SUBMIT_MULTI, DATA_SM)
+	s.Register(&TLVDef{Tag: TLV_BILLING_IDENTIFICATION, Name: "billing_identification", Type: TLV_TYPE_OCTETS, MaxLen: 1024}).
+		Allow(SMPP_V50, messagePDUs...).Allow(SMPP_V50, BROADCAST_SM)
+	s.Register(&TLVDef{Tag: TLV_SOURCE_NETWORK_ID, Name: "source_network_id", Type: TLV_TYPE_CSTRING, MaxLen: 65}).
+		Allow(SMPP_V50, messagePDUs...)
+	s.Register(&TLVDef{Tag: TLV_DEST_NETWORK_ID, Name: "dest_network_id", Type: TLV_TYPE_CSTRING, MaxLen: 65}).
+		Allow(SMPP_V50, messagePDUs...)
+	s.Register(&TLVDef{Tag: TLV_SOURCE_NODE_ID, Name: "source_node_id", Type: TLV_TYPE_OCTETS, MinLen: 6, MaxLen: 6}).
+		Allow(SMPP_V50, messagePDUs...)
+	s.Register(&TLVDef{Tag: TLV_DEST_NODE_ID, Name: "dest_node_id", Type: TLV_TYPE_OCTETS, MinLen: 6, MaxLen: 6}).
+		Allow(SMPP_V50, messagePDUs...)
+	s.Register(&TLVDef{Tag: TLV_DEST_ADDR_NP_RESOLUTION, Name: "dest_addr_np_resolution", Type: TLV_TYPE_UINT8, Validate: maxValue(2)}).
+		Allow(SMPP_V50, messagePDUs...)
+	s.Register(&TLVDef{Tag: TLV_DEST_ADDR_NP_INFORMATION, Name: "dest_addr_np_information", Type: TLV_TYPE_OCTETS, MinLen: 10, MaxLen: 10}).
+		Allow(SMPP_V50, messagePDUs...)
+	s.Register(&TLVDef{Tag: TLV_DEST_ADDR_NP_COUNTRY, Name: "dest_addr_np_country", Type: TLV_TYPE_OCTETS, MinLen: 1, MaxLen: 5}).
+		Allow(SMPP_V50, messagePDUs...)
+
+	// Delivery receipts and message state
+	s.Register(&TLVDef{Tag: TLV_RECEIPTED_MESSAGE_ID, Name: "receipted_message_id", Type: TLV_TYPE_CSTRING, MaxLen: 65}).
+		Allow(SMPP_V34, DELIVER_SM, DATA_SM)
+	s.Register(&TLVDef{Tag: TLV_MESSAGE_STATE, Name: "message_state", Type: TLV_TYPE_UINT8, Validate: maxValue(9)}).
+		Allow(SMPP_V34, DELIVER_SM, DATA_SM).Allow(SMPP_V50, QUERY_BROADCAST_SM_RESP)
+	s.Register(&TLVDef{Tag: TLV_NETWORK_ERROR_CODE, Name: "network_error_code", Type: TLV_TYPE_OCTETS, MinLen: 3, MaxLen: 3}).
+		Allow(SMPP_V34, DELIVER_SM, DATA_SM, DATA_SM_RESP).Allow(SMPP_V50, SUBMIT_SM_RESP, SUBMIT_MULTI_RESP, DELIVER_SM_RESP)
+	s.Register(&TLVDef{Tag: TLV_DELIVERY_FAILURE_REASON, Name: "delivery_failure_reason", Type: TLV_TYPE_UINT8, Validate: maxValue(3)}).
+		Allow(SMPP_V34, DATA_SM_RESP).Allow(SMPP_V50, SUBMIT_SM_RESP, SUBMIT_MULTI_RESP, DELIVER_SM_RESP)
+	s.Register(&TLVDef{Tag: TLV_ADDITIONAL_STATUS_INFO_TEXT, Name: "additional_status_info_text", Type: TLV_TYPE_CSTRING, MaxLen: 256}).
+		Allow(SMPP_V34, DATA_SM_RESP).Allow(SMPP_V50, SUBMIT_SM_RESP, SUBMIT_MULTI_RESP, DELIVER_SM_RESP)
+	s.Register(&TLVDef{Tag: TLV_DPF_RESULT, Name: "dpf_result", Type: TLV_TYPE_UINT8, Validate: maxValue(1)}).
+		Allow(SMPP_V34, DATA_SM_RESP).Allow(SMPP_V50, SUBMIT_SM_RESP, SUBMIT_MULTI_RESP, DELIVER_SM_RESP)
+
+	// Session management
+	s.Register(&TLVDef{Tag: TLV_SC_INTERFACE_VERSION, Name: "sc_interface_version", Type: TLV_TYPE_UINT8}).
+		Allow(SMPP_V34, bindRespPDUs...)
+	s.Register(&TLVDef{Tag: TLV_MS_AVAILABILITY_STATUS, Name: "ms_availability_status", Type: TLV_TYPE_UINT8, Validate: maxValue(2)}).
+		Allow(SMPP_V34, ALERT_NOTIFICATION)
+	s.Register(&TLVDef{Tag: TLV_CONGESTION_STATE, Name: "congestion_state", Type: TLV_TYPE_UINT8, Validate: maxValue(100)}).
+		Allow(SMPP_V50, ENQUIRE_LINK, ENQUIRE_LINK_RESP, BROADCAST_SM_RESP).
+		Allow(SMPP_V50, messageRespPDUs...).Allow(SMPP_V50, bindRespPDUs...)
+
+	// Broadcast (SMPP v5.0)
+	s.Register(&TLVDef{Tag: TLV_BROADCAST_CHANNEL_INDICATOR, Name: "broadcast_channel_indicator", Type: TLV_TYPE_UINT8, Validate: validateBroadcastChannel}).
+		Allow(SMPP_V50, BROADCAST_SM)
+	s.Register(&TLVDef{Tag: TLV_BROADCAST_CONTENT_TYPE, Name: "broadcast_content_type", Type: TLV_TYPE_OCTETS, MinLen: 3, MaxLen: 3, Validate: validateBroadcastContentType}).
+		Allow(SMPP_V50, BROADCAST_SM)
+	s.Register(&TLVDef{Tag: TLV_BROADCAST_CONTENT_TYPE_INFO, Name: "broadcast_content_type_info", Type: TLV_TYPE_OCTETS, MaxLen: 255}).
+		Allow(SMPP_V50, BROADCAST_SM)
+	s.Register(&TLVDef{Tag: TLV_BROADCAST_MESSAGE_CLASS, Name: "broadcast_message_class", Type: TLV_TYPE_UINT8, Validate: maxValue(3)}).
+		Allow(SMPP_V50, BROADCAST_SM)
+	s.Register(&TLVDef{Tag: TLV_BROADCAST_REP_NUM, Name: "broadcast_rep_num", Type: TLV_TYPE_UINT16}).
+		Allow(SMPP_V50, BROADCAST_SM)
+	s.Register(&TLVDef{Tag: TLV_BROADCAST_FREQUENCY_INTERVAL, Name: "broadcast_frequency_interval", Type: TLV_TYPE_OCTETS, MinLen: 3, MaxLen: 3, Validate: validateBroadcastFrequency}).
+		Allow(SMPP_V50, BROADCAST_SM)
+	s.Register(&TLVDef{Tag: TLV_BROADCAST_AREA_IDENTIFIER, Name: "broadcast_area_identifier", Type: TLV_TYPE_OCTETS, MinLen: 1, MaxLen: 101, Validate: validateBroadcastArea}).
+		Allow(SMPP_V50, BROADCAST_SM, BROADCAST_SM_RESP, QUERY_BROADCAST_SM_RESP)
+	s.Register(&TLVDef{Tag: TLV_BROADCAST_ERROR_STATUS, Name: "broadcast_error_status", Type: TLV_TYPE_UINT32}).
+		Allow(SMPP_V50, BROADCAST_SM_RESP)
+	s.Register(&TLVDef{Tag: TLV_BROADCAST_AREA_SUCCESS, Name: "broadcast_area_success", Type: TLV_TYPE_UINT8, Validate: validateBroadcastAreaSuccess}).
+		Allow(SMPP_V50, QUERY_BROADCAST_SM_RESP)
+	s.Register(&TLVDef{Tag: TLV_BROADCAST_END_TIME, Name: "broadcast_end_time", Type: TLV_TYPE_CSTRING, MaxLen: 17}).
+		Allow(SMPP_V50, QUERY_BROADCAST_SM_RESP)
+	s.Register(&TLVDef{Tag: TLV_BROADCAST_SERVICE_GROUP, Name: "broadcast_service_group", Type: TLV_TYPE_OCTETS, MaxLen: 255}).
+		Allow(SMPP_V50, BROADCAST_SM)
+
+	return s
+}
@@ -0,0 +1,323 @@
+package pdu
+
+import "encoding/binary"
+
+// GetUint8 returns the value of a 1 octet integer TLV
+func (l TLVList) GetUint8(tag uint16) (uint8, bool) {
+	tlv := l.Get(tag)
+	if tlv == nil || len(tlv.Value) != 1 {
+		return 0, false
+	}
+	return tlv.Value[0], true
+}
+
+// SetUint8 sets a 1 octet integer TLV
+func (l *TLVList) SetUint8(tag uint16, v uint8) {
+	l.Set(tag, []byte{v})
+}
+
+// GetUint16 returns the value of a 2 octet integer TLV
+func (l TLVList) GetUint16(tag uint16) (uint16, bool) {
+	tlv := l.Get(tag)
+	if tlv == nil || len(tlv.Value) != 2 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint16(tlv.Value), true
+}
+
+// SetUint16 sets a 2 octet integer TLV
+func (l *TLVList) SetUint16(tag uint16, v uint16) {
+	value := make([]byte, 2)
+	binary.BigEndian.PutUint16(value, v)
+	l.Set(tag, value)
+}
+
+// GetUint32 returns the value of a 4 octet integer TLV
+func (l TLVList) GetUint32(tag uint16) (uint32, bool) {
+	tlv := l.Get(tag)
+	if tlv == nil || len(tlv.Value) != 4 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint32(tlv.Value), true
+}
+
+// SetUint32 sets a 4 octet integer TLV
+func (l *TLVList) SetUint32(tag uint16, v uint32) {
+	value := make([]byte, 4)
+	binary.BigEndian.PutUint32(value, v)
+	l.Set(tag, value)
+}
+
+// GetCString returns the value of a C-string TLV without its null terminator
+func (l TLVList) GetCString(tag uint16) (string, bool) {
+	tlv := l.Get(tag)
+	if tlv == nil {
+		return "", false
+	}
+	value := tlv.Value
+	if n := len(value); n > 0 && value[n-1] == 0 {
+		value = value[:n-1]
+	}
+	return string(value), true
+}
+
+// SetCString sets a C-string TLV, adding the null terminator
+func (l *TLVList) SetCString(tag uint16, s string) {
+	value := make([]byte, len(s)+1)
+	copy(value, s)
+	l.Set(tag, value)
+}
+
+// GetOctets returns the raw value of a TLV
+func (l TLVList) GetOctets(tag uint16) ([]byte, bool) {
+	tlv := l.Get(tag)
+	if tlv == nil {
+		return nil, false
+	}
+	return tlv.Value, true
+}
+
+// SetOctets sets the raw value of a TLV
+func (l *TLVList) SetOctets(tag uint16, value []byte) {
+	l.Set(tag, value)
+}
+
+// SARMsgRefNum returns the sar_msg_ref_num TLV
+func (l TLVList) SARMsgRefNum() (uint16, bool) {
+	return l.GetUint16(TLV_SAR_MSG_REF_NUM)
+}
+
+// SetSARMsgRefNum sets the sar_msg_ref_num TLV
+func (l *TLVList) SetSARMsgRefNum(v uint16) {
+	l.SetUint16(TLV_SAR_MSG_REF_NUM, v)
+}
+
+// SARTotalSegments returns the sar_total_segments TLV
+func (l TLVList) SARTotalSegments() (uint8, bool) {
+	return l.GetUint8(TLV_SAR_TOTAL_SEGMENTS)
+}
+
+// SetSARTotalSegments sets the sar_total_segments TLV
+func (l *TLVList) SetSARTotalSegments(v uint8) {
+	l.SetUint8(TLV_SAR_TOTAL_SEGMENTS, v)
+}
+
+// SARSegmentSeqNum returns the sar_segment_seqnum TLV
+func (l TLVList) SARSegmentSeqNum() (uint8, bool) {
+	return l.GetUint8(TLV_SAR_SEGMENT_SEQNUM)
+}
+
+// SetSARSegmentSeqNum sets the sar_segment_seqnum TLV
+func (l *TLVList) SetSARSegmentSeqNum(v uint8) {
+	l.SetUint8(TLV_SAR_SEGMENT_SEQNUM, v)
+}
+
+// SAR returns the segmentation parameters if all three SAR TLVs are present
+func (l TLVList) SAR() (SARParams, bool) {
+	ref, ok1 := l.SARMsgRefNum()
+	total, ok2 := l.SARTotalSegments()
+	seq, ok3 := l.SARSegmentSeqNum()
+	if !ok1 || !ok2 || !ok3 {
+		return SARParams{}, false
+	}
+	return SARParams{RefNum: ref, Total: total, SeqNum: seq}, true
+}
+
+// SetSAR sets all three SAR TLVs
+func (l *TLVList) SetSAR(sar SARParams) {
+	l.SetSARMsgRefNum(sar.RefNum)
+	l.SetSARTotalSegments(sar.Total)
+	l.SetSARSegmentSeqNum(sar.SeqNum)
+}
+
+// MessageState returns the message_state TLV
+func (l TLVList) MessageState() (uint8, bool) {
+	return l.GetUint8(TLV_MESSAGE_STATE)
+}
+
+// SetMessageState sets the message_state TLV
+func (l *TLVList) SetMessageState(v uint8) {
+	l.SetUint8(TLV_MESSAGE_STATE, v)
+}
+
+// ReceiptedMessageID returns the receipted_message_id TLV
+func (l TLVList) ReceiptedMessageID() (string, bool) {
+	return l.GetCString(TLV_RECEIPTED_MESSAGE_ID)
+}
+
+// SetReceiptedMessageID sets the receipted_message_id TLV
+func (l *TLVList) SetReceiptedMessageID(id string) {
+	l.SetCString(TLV_RECEIPTED_MESSAGE_ID, id)
+}
+
+// NetworkErrorCode returns the network type and error code of the
+// network_error_code TLV
+func (l TLVList) NetworkErrorCode() (uint8, uint16, bool) {
+	tlv := l.Get(TLV_NETWORK_ERROR_CODE)
+	if tlv == nil || len(tlv.Value) != 3 {
+		return 0, 0, false
+	}
+	return tlv.Value[0], binary.BigEndian.Uint16(tlv.Value[1:]), true
+}
+
+// SetNetworkErrorCode sets the network_error_code TLV
+func (l *TLVList) SetNetworkErrorCode(networkType uint8, code uint16) {
+	value := []byte{networkType, 0, 0}
+	binary.BigEndian.PutUint16(value[1:], code)
+	l.Set(TLV_NETWORK_ERROR_CODE, value)
+}
+
+// MessagePayload returns the message_payload TLV
+func (l TLVList) MessagePayload() ([]byte, bool) {
+	return l.GetOctets(TLV_MESSAGE_PAYLOAD)
+}
+
+// SetMessagePayload sets the message_payload TLV
+func (l *TLVList) SetMessagePayload(payload []byte) {
+	l.SetOctets(TLV_MESSAGE_PAYLOAD, payload)
+}
+
+// SourcePort returns the source_port TLV
+func (l TLVList) SourcePort() (uint16, bool) {
+	return l.GetUint16(TLV_SOURCE_PORT)
+}
+
+// SetSourcePort sets the source_port TLV
+func (l *TLVList) SetSourcePort(port uint16) {
+	l.SetUint16(TLV_SOURCE_PORT, port)
+}
+
+// DestinationPort returns the destination_port TLV
+func (l TLVList) DestinationPort() (uint16, bool) {
+	return l.GetUint16(TLV_DESTINATION_PORT)
+}
+
+// SetDestinationPort sets the destination_port TLV
+func (l *TLVList) SetDestinationPort(port uint16) {
+	l.SetUint16(TLV_DESTINATION_PORT, port)
+}
+
+// UserMessageReference returns the user_message_reference TLV
+func (l TLVList) UserMessageReference() (uint16, bool) {
+	return l.GetUint16(TLV_USER_MESSAGE_REFERENCE)
+}
+
+// SetUserMessageReference sets the user_message_reference TLV
+func (l *TLVList) SetUserMessageReference(v uint16) {
+	l.SetUint16(TLV_USER_MESSAGE_REFERENCE, v)
+}
+
+// PayloadType returns the payload_type TLV
+func (l TLVList) PayloadType() (uint8, bool) {
+	return l.GetUint8(TLV_PAYLOAD_TYPE)
+}
+
+// SetPayloadType sets the payload_type TLV
+func (l *TLVList) SetPayloadType(v uint8) {
+	l.SetUint8(TLV_PAYLOAD_TYPE, v)
+}
+
+// LanguageIndicator returns the language_indicator TLV
+func (l TLVList) LanguageIndicator() (uint8, bool) {
+	return l.GetUint8(TLV_LANGUAGE_INDICATOR)
+}
+
+// SetLanguageIndicator sets the language_indicator TLV
+func (l *TLVList) SetLanguageIndicator(v uint8) {
+	l.SetUint8(TLV_LANGUAGE_INDICATOR, v)
+}
+
+// MoreMessagesToSend returns the more_messages_to_send TLV
+func (l TLVList) MoreMessagesToSend() (bool, bool) {
+	v, ok := l.GetUint8(TLV_MORE_MESSAGES_TO_SEND)
+	return v == 1, ok
+}
+
+// SetMoreMessagesToSend sets the more_messages_to_send TLV
+func (l *TLVList) SetMoreMessagesToSend(more bool) {
+	var v uint8
+	if more {
+		v = 1
+	}
+	l.SetUint8(TLV_MORE_MESSAGES_TO_SEND, v)
+}
+
+// SCInterfaceVersion returns the sc_interface_version TLV
+func (l TLVList) SCInterfaceVersion() (uint8, bool) {
+	return l.GetUint8(TLV_SC_INTERFACE_VERSION)
+}
+
+// SetSCInterfaceVersion sets the sc_interface_version TLV
+func (l *TLVList) SetSCInterfaceVersion(v uint8) {
+	l.SetUint8(TLV_SC_INTERFACE_VERSION, v)
+}
+
+// MSAvailabilityStatus returns the ms_availability_status TLV
+func (l TLVList) MSAvailabilityStatus() (uint8, bool) {
+	return l.GetUint8(TLV_MS_AVAILABILITY_STATUS)
+}
+
+// SetMSAvailabilityStatus sets the ms_availability_status TLV
+func (l *TLVList) SetMSAvailabilityStatus(v uint8) {
+	l.SetUint8(TLV_MS_AVAILABILITY_STATUS, v)
+}
+
+// DeliveryFailureReason returns the delivery_failure_reason TLV
+func (l TLVList) DeliveryFailureReason() (uint8, bool) {
+	return l.GetUint8(TLV_DELIVERY_FAILURE_REASON)
+}
+
+// SetDeliveryFailureReason sets the delivery_failure_reason TLV
+func (l *TLVList) SetDeliveryFailureReason(v uint8) {
+	l.SetUint8(TLV_DELIVERY_FAILURE_REASON, v)
+}
+
+// AdditionalStatusInfoText returns the additional_status_info_text TLV
+func (l TLVList) AdditionalStatusInfoText() (string, bool) {
+	return l.GetCString(TLV_ADDITIONAL_STATUS_INFO_TEXT)
+}
+
+// SetAdditionalStatusInfoText sets the additional_status_info_text TLV
+func (l *TLVList) SetAdditionalStatusInfoText(text string) {
+	l.SetCString(TLV_ADDITIONAL_STATUS_INFO_TEXT, text)
+}
+
+// CongestionState returns the congestion_state TLV
+func (l TLVList) CongestionState() (uint8, bool) {
+	return l.GetUint8(TLV_CONGESTION_STATE)
+}
+
+// SetCongestionState sets the congestion_state TLV
+func (l *TLVList) SetCongestionState(v uint8) {
+	l.SetUint8(TLV_CONGESTION_STATE, v)
+}
+
+// USSDServiceOp returns the ussd_service_op TLV
+func (l TLVList) USSDServiceOp() (uint8, bool) {
+	return l.GetUint8(TLV_USSD_SERVICE_OP)
+}
+
+// SetUSSDServiceOp sets the ussd_service_op TLV
+func (l *TLVList) SetUSSDServiceOp(v uint8) {
+	l.SetUint8(TLV_USSD_SERVICE_OP, v)
+}
+
+// MSMsgWaitFacilities returns the ms_msg_wait_facilities TLV
+func (l TLVList) MSMsgWaitFacilities() (uint8, bool) {
+	return l.GetUint8(TLV_MS_MSG_WAIT_FACILITIES)
+}
+
+// SetMSMsgWaitFacilities sets the ms_msg_wait_facilities TLV
+func (l *TLVList) SetMSMsgWaitFacilities(v uint8) {
+	l.SetUint8(TLV_MS_MSG_WAIT_FACILITIES, v)
+}
+
+// NumberOfMessages returns the number_of_messages TLV
+func (l TLVList) NumberOfMessages() (uint8, bool) {
+	return l.GetUint8(TLV_NUMBER_OF_MESSAGES)
+}
+
+// SetNumberOfMessages sets the number_of_messages TLV
+func (l *TLVList) SetNumberOfMessages(v uint8) {
+	l.SetUint8(TLV_NUMBER_OF_MESSAGES, v)
+}
@@ -0,0 +1,397 @@
+package pdu
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// UDHElement is an information element of a User Data Header
+type UDHElement interface {
+	// ID returns the information element identifier
+	ID() uint8
+
+	// Value returns the encoded element data without identifier and length
+	Value() []byte
+}
+
+// RawIE is an information element without a typed representation, or one
+// whose data did not match its type
+type RawIE struct {
+	IEI  uint8
+	Data []byte
+}
+
+// ID returns the information element identifier
+func (e *RawIE) ID() uint8 { return e.IEI }
+
+// Value returns the element data
+func (e *RawIE) Value() []byte { return e.Data }
+
+// ConcatIE is a concatenated short message IE with an 8 or 16-bit reference
+type ConcatIE struct {
+	Ref      uint16
+	Total    uint8
+	SeqNum   uint8
+	Ref16Bit bool
+}
+
+// ID returns the information element identifier
+func (e *ConcatIE) ID() uint8 {
+	if e.Ref16Bit {
+		return UDH_IE_CONCAT_16BIT
+	}
+	return UDH_IE_CONCAT_8BIT
+}
+
+// Value returns the element data
+func (e *ConcatIE) Value() []byte {
+	if e.Ref16Bit {
+		return []byte{byte(e.Ref >> 8), byte(e.Ref), e.Total, e.SeqNum}
+	}
+	return []byte{byte(e.Ref), e.Total, e.SeqNum}
+}
+
+// SAR returns the segmentation parameters of the element
+func (e *ConcatIE) SAR() SARParams {
+	return SARParams{RefNum: e.Ref, Total: e.Total, SeqNum: e.SeqNum}
+}
+
+// SpecialSMSIE is a special SMS message indication, e.g. voice mail waiting
+type SpecialSMSIE struct {
+	Store bool  // Store the message after updating the indication
+	Type  uint8 // Message indication type (bits 0-6)
+	Count uint8 // Number of waiting messages
+}
+
+// ID returns the information element identifier
+func (e *SpecialSMSIE) ID() uint8 { return UDH_IE_SPECIAL_SMS }
+
+// Value returns the element data
+func (e *SpecialSMSIE) Value() []byte {
+	b := e.Type & 0x7F
+	if e.Store {
+		b |= 0x80
+	}
+	return []byte{b, e.Count}
+}
+
+// PortIE is an application port addressing IE with 8 or 16-bit ports
+type PortIE struct {
+	DestPort   uint16
+	SourcePort uint16
+	Port16Bit  bool
+}
+
+// ID returns the information element identifier
+func (e *PortIE) ID() uint8 {
+	if e.Port16Bit {
+		return UDH_IE_PORT_16BIT
+	}
+	return UDH_IE_PORT_8BIT
+}
+
+// Value returns the element data
+func (e *PortIE) Value() []byte {
+	if e.Port16Bit {
+		return []byte{byte(e.DestPort >> 8), byte(e.DestPort), byte(e.SourcePort >> 8), byte(e.SourcePort)}
+	}
+	return []byte{byte(e.DestPort), byte(e.SourcePort)}
+}
+
+// WCMPIE carries a Wireless Control Message Protocol message
+type WCMPIE struct {
+	Data []byte
+}
+
+// ID returns the information element identifier
+func (e *WCMPIE) ID() uint8 { return UDH_IE_WIRELESS_CTRL }
+
+// Value returns the element data
+func (e *WCMPIE) Value() []byte { return e.Data }
+
+// TextFormatIE formats a range of the message text
+type TextFormatIE struct {
+	Start    uint8
+	Length   uint8
+	Mode     uint8 // Alignment, font size, bold, italic, underline, strikethrough
+	Color    uint8 // Foreground and background colour, if HasColor
+	HasColor bool
+}
+
+// ID returns the information element identifier
+func (e *TextFormatIE) ID() uint8 { return UDH_IE_TEXT_FORMAT }
+
+// Value returns the element data
+func (e *TextFormatIE) Value() []byte {
+	if e.HasColor {
+		return []byte{e.Start, e.Length, e.Mode, e.Color}
+	}
+	return []byte{e.Start, e.Length, e.Mode}
+}
+
+// PredefinedSoundIE plays one of the predefined EMS sounds
+type PredefinedSoundIE struct {
+	Position uint8 // Position in the text
+	Sound    uint8 // Sound number
+}
+
+// ID returns the information element identifier
+func (e *PredefinedSoundIE) ID() uint8 { return UDH_IE_PREDEFINED_SOUND }
+
+// Value returns the element data
+func (e *PredefinedSoundIE) Value() []byte { return []byte{e.Position, e.Sound} }
+
+// UserPromptIE indicates the number of objects forming a user prompt
+type UserPromptIE struct {
+	Count uint8
+}
+
+// ID returns the information element identifier
+func (e *UserPromptIE) ID() uint8 { return UDH_IE_USER_PROMPT_IND }
+
+// Value returns the element data
+func (e *UserPromptIE) Value() []byte { return []byte{e.Count} }
+
+// ExtendedObjectIE carries an EMS extended object or the first part of one
+// spread over several segments
+type ExtendedObjectIE struct {
+	Ref      uint8
+	Length   uint16 // Length of the whole object
+	Control  uint8
+	Type     uint8
+	Position uint16 // Position in the text
+	Data     []byte
+}
+
+// ID returns the information element identifier
+func (e *ExtendedObjectIE) ID() uint8 { return UDH_IE_EXTENDED_OBJECT }
+
+// Value returns the element data
+func (e *ExtendedObjectIE) Value() []byte {
+	v := make([]byte, 7, 7+len(e.Data))
+	v[0] = e.Ref
+	binary.BigEndian.PutUint16(v[1:], e.Length)
+	v[3] = e.Control
+	v[4] = e.Type
+	binary.BigEndian.PutUint16(v[5:], e.Position)
+	return append(v, e.Data...)
+}
+
+// NationalShiftIE selects a national language locking or single shift table
+type NationalShiftIE struct {
+	Language uint8 // NLI_* constant
+	Locking  bool
+}
+
+// ID returns the information element identifier
+func (e *NationalShiftIE) ID() uint8 {
+	if e.Locking {
+		return UDH_IE_NATIONAL_LOCKING
+	}
+	return UDH_IE_NATIONAL_SINGLE
+}
+
+// Value returns the element data
+func (e *NationalShiftIE) Value() []byte { return []byte{e.Language} }
+
+// parseElement returns the typed element for an identifier, or a RawIE if
+// the identifier is unknown or the data does not match the type
+func parseElement(iei uint8, data []byte) UDHElement {
+	switch {
+	case iei == UDH_IE_CONCAT_8BIT && len(data) == 3:
+		return &ConcatIE{Ref: uint16(data[0]), Total: data[1], SeqNum: data[2]}
+	case iei == UDH_IE_CONCAT_16BIT && len(data) == 4:
+		return &ConcatIE{Ref: binary.BigEndian.Uint16(data), Total: data[2], SeqNum: data[3], Ref16Bit: true}
+	case iei == UDH_IE_SPECIAL_SMS && len(data) == 2:
+		return &SpecialSMSIE{Store: data[0]&0x80 != 0, Type: data[0] & 0x7F, Count: data[1]}
+	case iei == UDH_IE_PORT_8BIT && len(data) == 2:
+		return &PortIE{DestPort: uint16(data[0]), SourcePort: uint16(data[1])}
+	case iei == UDH_IE_PORT_16BIT && len(data) == 4:
+		return &PortIE{DestPort: binary.BigEndian.Uint16(data), SourcePort: binary.BigEndian.Uint16(data[2:]), Port16Bit: true}
+	case iei == UDH_IE_WIRELESS_CTRL:
+		return &WCMPIE{Data: data}
+	case iei == UDH_IE_TEXT_FORMAT && len(data) == 3:
+		return &TextFormatIE{Start: data[0], Length: data[1], Mode: data[2]}
+	case iei == UDH_IE_TEXT_FORMAT && len(data) == 4:
+		return &TextFormatIE{Start: data[0], Length: data[1], Mode: data[2], Color: data[3], HasColor: true}
+	case iei == UDH_IE_PREDEFINED_SOUND && len(data) == 2:
+		return &PredefinedSoundIE{Position: data[0], Sound: data[1]}
+	case iei == UDH_IE_USER_PROMPT_IND && len(data) == 1:
+		return &UserPromptIE{Count: data[0]}
+	case iei == UDH_IE_EXTENDED_OBJECT && len(data) >= 7:
+		return &ExtendedObjectIE{
+			Ref:      data[0],
+			Length:   binary.BigEndian.Uint16(data[1:]),
+			Control:  data[3],
+			Type:     data[4],
+			Position: binary.BigEndian.Uint16(data[5:]),
+			Data:     data[7:],
+		}
+	case iei == UDH_IE_NATIONAL_SINGLE && len(data) == 1:
+		return &NationalShiftIE{Language: data[0]}
+	case iei == UDH_IE_NATIONAL_LOCKING && len(data) == 1:
+		return &NationalShiftIE{Language: data[0], Locking: true}
+	}
+	return &RawIE{IEI: iei, Data: data}
+}
+
+// UDH is a User Data Header, the list of information elements that may
+// precede the text of a short message
+type UDH struct {
+	Elements []UDHElement
+}
+
+// NewUDH creates a UDH holding the given elements
+func NewUDH(elements ...UDHElement) *UDH {
+	return &UDH{Elements: elements}
+}
+
+// ParseUDH parses the UDH at the start of data and returns it together
+// with the user payload that follows it
+func ParseUDH(data []byte) (*UDH, []byte, error) {
+	if len(data) == 0 {
+		return nil, nil, fmt.Errorf("%w: empty user data", ErrInvalidUDH)
+	}
+	end := 1 + int(data[0])
+	if end > len(data) {
+		return nil, nil, fmt.Errorf("%w: length %d exceeds user data", ErrInvalidUDH, data[0])
+	}
+
+	u := &UDH{}
+	for offset := 1; offset < end; {
+		if end-offset < 2 {
+			return nil, nil, fmt.Errorf("%w: truncated element at offset %d", ErrInvalidUDH, offset)
+		}
+		iei, length := data[offset], int(data[offset+1])
+		offset += 2
+		if end-offset < length {
+			return nil, nil, fmt.Errorf("%w: element 0x%02X overruns the header", ErrInvalidUDH, iei)
+		}
+		value := make([]byte, length)
+		copy(value, data[offset:offset+length])
+		u.Elements = append(u.Elements, parseElement(iei, value))
+		offset += length
+	}
+
+	return u, data[end:], nil
+}
+
+// SplitUserData separates a short_message into its UDH and user payload.
+// When esmClass does not have the UDHI flag set there is no UDH and the
+// whole message is payload.
+func SplitUserData(sm []byte, esmClass uint8) (*UDH, []byte, error) {
+	if esmClass&ESM_CLASS_UDHI == 0 {
+		return nil, sm, nil
+	}
+	return ParseUDH(sm)
+}
+
+// Len returns the encoded length of the header including the UDHL octet
+func (u *UDH) Len() int {
+	n := 1
+	for _, e := range u.Elements {
+		n += 2 + len(e.Value())
+	}
+	return n
+}
+
+// Marshal encodes the header including the UDHL octet
+func (u *UDH) Marshal() ([]byte, error) {
+	buf := make([]byte, 1, u.Len())
+	for _, e := range u.Elements {
+		value := e.Value()
+		if len(value) > 0xFF {
+			return nil, fmt.Errorf("%w: element 0x%02X is too long", ErrInvalidUDH, e.ID())
+		}
+		buf = append(buf, e.ID(), byte(len(value)))
+		buf = append(buf, value...)
+	}
+	if len(buf)-1 > 0xFF {
+		return nil, fmt.Errorf("%w: header is too long", ErrInvalidUDH)
+	}
+	buf[0] = byte(len(buf) - 1)
+	return buf, nil
+}
+
+// Prepend returns the header followed by payload
+func (u *UDH) Prepend(payload []byte) ([]byte, error) {
+	buf, err := u.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return append(buf, payload...), nil
+}
+
+// Get returns the first element with the given identifier, or nil
+func (u *UDH) Get(iei uint8) UDHElement {
+	for _, e := range u.Elements {
+		if e.ID() == iei {
+			return e
+		}
+	}
+	return nil
+}
+
+// Add appends an element
+func (u *UDH) Add(e UDHElement) {
+	u.Elements = append(u.Elements, e)
+}
+
+// Remove deletes every element with the given identifier
+func (u *UDH) Remove(iei uint8) {
+	elements := u.Elements[:0]
+	for _, e := range u.Elements {
+		if e.ID() != iei {
+			elements = append(elements, e)
+		}
+	}
+	u.Elements = elements
+}
+
+// Concat returns the concatenation element, 8 or 16-bit
+func (u *UDH) Concat() (*ConcatIE, bool) {
+	for _, e := range u.Elements {
+		if c, ok := e.(*ConcatIE); ok {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// Ports returns the application port addressing element, 8 or 16-bit
+func (u *UDH) Ports() (*PortIE, bool) {
+	for _, e := range u.Elements {
+		if p, ok := e.(*PortIE); ok {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// SpecialSMS returns the special SMS message indication elements, one per
+// indication type
+func (u *UDH) SpecialSMS() []*SpecialSMSIE {
+	var special []*SpecialSMSIE
+	for _, e := range u.Elements {
+		if s, ok := e.(*SpecialSMSIE); ok {
+			special = append(special, s)
+		}
+	}
+	return special
+}
+
+// NationalShifts returns the languages of the national language locking
+// and single shift elements, NLI_DEFAULT when absent
+func (u *UDH) NationalShifts() (uint8, uint8) {
+	var locking, single uint8
+	for _, e := range u.Elements {
+		if n, ok := e.(*NationalShiftIE); ok {
+			if n.Locking {
+				locking = n.Language
+			} else {
+				single = n.Language
+			}
+		}
+	}
+	return locking, single
+}
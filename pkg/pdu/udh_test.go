@@ -0,0 +1,161 @@
+package pdu
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestUDHElements(t *testing.T) {
+	tests := []struct {
+		name    string
+		element UDHElement
+		encoded []byte // Identifier, length and value
+	}{
+		{"concat 8-bit", &ConcatIE{Ref: 0x42, Total: 3, SeqNum: 1}, []byte{0x00, 3, 0x42, 3, 1}},
+		{"concat 16-bit", &ConcatIE{Ref: 0x1234, Total: 2, SeqNum: 2, Ref16Bit: true}, []byte{0x08, 4, 0x12, 0x34, 2, 2}},
+		{"special sms", &SpecialSMSIE{Store: true, Type: 0x01, Count: 5}, []byte{0x01, 2, 0x81, 5}},
+		{"special sms discard", &SpecialSMSIE{Type: 0x00, Count: 0}, []byte{0x01, 2, 0x00, 0}},
+		{"port 8-bit", &PortIE{DestPort: 0xF5, SourcePort: 0xF0}, []byte{0x04, 2, 0xF5, 0xF0}},
+		{"port 16-bit", &PortIE{DestPort: 2948, SourcePort: 9200, Port16Bit: true}, []byte{0x05, 4, 0x0B, 0x84, 0x23, 0xF0}},
+		{"wcmp", &WCMPIE{Data: []byte{1, 2, 3}}, []byte{0x09, 3, 1, 2, 3}},
+		{"text format", &TextFormatIE{Start: 0, Length: 10, Mode: 0x10}, []byte{0x0A, 3, 0, 10, 0x10}},
+		{"text format with colour", &TextFormatIE{Start: 5, Length: 4, Mode: 0x21, Color: 0x3C, HasColor: true}, []byte{0x0A, 4, 5, 4, 0x21, 0x3C}},
+		{"predefined sound", &PredefinedSoundIE{Position: 7, Sound: 3}, []byte{0x0B, 2, 7, 3}},
+		{"user prompt", &UserPromptIE{Count: 2}, []byte{0x13, 1, 2}},
+		{
+			"extended object",
+			&ExtendedObjectIE{Ref: 1, Length: 0x0102, Control: 0x01, Type: 0x09, Position: 0x0010, Data: []byte("obj")},
+			[]byte{0x14, 10, 1, 0x01, 0x02, 0x01, 0x09, 0x00, 0x10, 'o', 'b', 'j'},
+		},
+		{"national single shift", &NationalShiftIE{Language: NLI_TURKISH}, []byte{0x24, 1, NLI_TURKISH}},
+		{"national locking shift", &NationalShiftIE{Language: NLI_PORTUGUESE, Locking: true}, []byte{0x25, 1, NLI_PORTUGUESE}},
+		{"unknown", &RawIE{IEI: 0x70, Data: []byte{0xAA}}, []byte{0x70, 1, 0xAA}},
+		{"mistyped", &RawIE{IEI: UDH_IE_CONCAT_8BIT, Data: []byte{1, 2}}, []byte{0x00, 2, 1, 2}},
+	}
+	for _, tt := range tests {
+		data, err := NewUDH(tt.element).Prepend([]byte("text"))
+		if err != nil {
+			t.Errorf("%s: Prepend: %v", tt.name, err)
+			continue
+		}
+		want := append([]byte{byte(len(tt.encoded))}, tt.encoded...)
+		want = append(want, "text"...)
+		if !bytes.Equal(data, want) {
+			t.Errorf("%s: Prepend = %X, want %X", tt.name, data, want)
+		}
+
+		u, payload, err := ParseUDH(data)
+		if err != nil {
+			t.Errorf("%s: ParseUDH: %v", tt.name, err)
+			continue
+		}
+		if string(payload) != "text" || len(u.Elements) != 1 {
+			t.Errorf("%s: ParseUDH = %d elements, payload %q", tt.name, len(u.Elements), payload)
+			continue
+		}
+		if !reflect.DeepEqual(u.Elements[0], tt.element) {
+			t.Errorf("%s: parsed %#v, want %#v", tt.name, u.Elements[0], tt.element)
+		}
+		if u.Len() != len(want)-len("text") {
+			t.Errorf("%s: Len = %d", tt.name, u.Len())
+		}
+	}
+}
+
+func TestUDHAccessors(t *testing.T) {
+	u := NewUDH(
+		&NationalShiftIE{Language: NLI_SPANISH},
+		&ConcatIE{Ref: 9, Total: 2, SeqNum: 1},
+		&SpecialSMSIE{Type: uint8(MWI_VOICEMAIL), Count: 1},
+		&PortIE{DestPort: 5000, SourcePort: 5001, Port16Bit: true},
+		&SpecialSMSIE{Type: uint8(MWI_FAX), Count: 2},
+		&NationalShiftIE{Language: NLI_TURKISH, Locking: true},
+	)
+	if c, ok := u.Concat(); !ok || c.SAR() != (SARParams{RefNum: 9, Total: 2, SeqNum: 1}) {
+		t.Errorf("Concat = %+v, %v", c, ok)
+	}
+	if p, ok := u.Ports(); !ok || p.DestPort != 5000 {
+		t.Errorf("Ports = %+v, %v", p, ok)
+	}
+	if special := u.SpecialSMS(); len(special) != 2 || special[1].Type != uint8(MWI_FAX) {
+		t.Errorf("SpecialSMS = %+v", special)
+	}
+	if locking, single := u.NationalShifts(); locking != NLI_TURKISH || single != NLI_SPANISH {
+		t.Errorf("NationalShifts = %d, %d", locking, single)
+	}
+
+	u.Remove(UDH_IE_SPECIAL_SMS)
+	if u.Get(UDH_IE_SPECIAL_SMS) != nil || len(u.Elements) != 4 {
+		t.Errorf("after Remove: %d elements", len(u.Elements))
+	}
+	u.Add(&UserPromptIE{Count: 1})
+	if _, ok := u.Get(UDH_IE_USER_PROMPT_IND).(*UserPromptIE); !ok {
+		t.Errorf("Get(0x13) = %v", u.Get(UDH_IE_USER_PROMPT_IND))
+	}
+
+	// An empty header is just the UDHL octet
+	if data, err := NewUDH().Marshal(); err != nil || !bytes.Equal(data, []byte{0}) {
+		t.Errorf("empty Marshal = %X, %v", data, err)
+	}
+}
+
+func TestParseUDHInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"udhl beyond data", []byte{5, 0x00, 3, 1, 2}},
+		{"element beyond udhl", []byte{4, 0x00, 3, 1, 2, 3, 'x'}},
+		{"truncated element", []byte{4, 0x00, 1, 9, 0x24, 'x'}},
+	}
+	for _, tt := range tests {
+		if _, _, err := ParseUDH(tt.data); !errors.Is(err, ErrInvalidUDH) {
+			t.Errorf("%s: ParseUDH error = %v, want ErrInvalidUDH", tt.name, err)
+		}
+	}
+
+	// A header filling the whole user data leaves an empty payload
+	u, payload, err := ParseUDH([]byte{3, 0x24, 1, NLI_SPANISH})
+	if err != nil || len(u.Elements) != 1 || len(payload) != 0 {
+		t.Errorf("header only = %v, %q, %v", u, payload, err)
+	}
+
+	if _, err := NewUDH(&WCMPIE{Data: make([]byte, 256)}).Marshal(); !errors.Is(err, ErrInvalidUDH) {
+		t.Errorf("Marshal of a 256 octet element error = %v", err)
+	}
+	long := NewUDH()
+	for range 52 {
+		long.Add(&ConcatIE{Ref: 1, Total: 2, SeqNum: 1})
+	}
+	if _, err := long.Marshal(); !errors.Is(err, ErrInvalidUDH) {
+		t.Errorf("Marshal of a %d octet header error = %v", long.Len(), err)
+	}
+}
+
+func TestSplitUserData(t *testing.T) {
+	sm := []byte{5, 0x00, 3, 1, 2, 1, 'h', 'i'}
+
+	// Without UDHI the whole short_message is payload, whatever it holds
+	u, payload, err := SplitUserData(sm, 0)
+	if err != nil || u != nil || !bytes.Equal(payload, sm) {
+		t.Errorf("UDHI clear: %v, %X, %v", u, payload, err)
+	}
+	u, payload, err = SplitUserData([]byte{0xFF}, 0)
+	if err != nil || u != nil || !bytes.Equal(payload, []byte{0xFF}) {
+		t.Errorf("UDHI clear with a bad header: %v, %X, %v", u, payload, err)
+	}
+
+	u, payload, err = SplitUserData(sm, ESM_CLASS_UDHI)
+	if err != nil || u == nil || string(payload) != "hi" {
+		t.Fatalf("UDHI set: %v, %q, %v", u, payload, err)
+	}
+	if c, ok := u.Concat(); !ok || c.Total != 2 {
+		t.Errorf("UDHI set: concatenation %+v", c)
+	}
+	if _, _, err := SplitUserData([]byte{0xFF}, ESM_CLASS_UDHI); !errors.Is(err, ErrInvalidUDH) {
+		t.Errorf("UDHI set with a bad header: %v", err)
+	}
+}
@@ -2,7 +2,7 @@ package pdu
 
 // Unbind represents an SMPP unbind PDU
 type Unbind struct {
-	Header *Header
+	Header *Header `json:"header"`
 }
 
 // NewUnbind creates a new Unbind PDU
@@ -12,19 +12,66 @@ func NewUnbind() *Unbind {
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (u *Unbind) Marshal() ([]byte, error) {
-	// Set command length in header
-	u.Header.CommandLength = 16 // Only header, no body
+// EncodedLen returns the size of the encoded PDU in bytes
+func (u *Unbind) EncodedLen() int {
+	return HeaderLength
+}
+
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (u *Unbind) AppendTo(dst []byte) ([]byte, error) {
+	u.Header.CommandLength = uint32(u.EncodedLen())
 	u.Header.CommandID = UNBIND
 
-	// Marshal header
-	return u.Header.Marshal()
+	return u.Header.AppendTo(dst), nil
+}
+
+// Marshal serializes the PDU into bytes
+func (u *Unbind) Marshal() ([]byte, error) {
+	return u.AppendTo(make([]byte, 0, u.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (u *Unbind) Unmarshal(data []byte) error {
-	// Unmarshal header
+	return u.decode(newDecoder(data, false))
+}
+
+// decode reads the PDU fields from dec
+func (u *Unbind) decode(dec *decoder) error {
 	u.Header = &Header{}
-	return u.Header.Unmarshal(data[:16])
+	dec.header(u.Header)
+
+	dec.end()
+	return dec.Err()
+}
+
+// CommandID returns the command_id of the PDU
+func (u *Unbind) CommandID() uint32 {
+	return UNBIND
+}
+
+// GetHeader returns the PDU header
+func (u *Unbind) GetHeader() *Header {
+	return u.Header
+}
+
+// String returns a one-line description of the PDU for trace logs
+func (u *Unbind) String() string {
+	return FormatPDU(u)
+}
+
+// MarshalJSON encodes the PDU as JSON
+func (u *Unbind) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(u)
+}
+
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (u *Unbind) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, u)
+}
+
+// GetResponse creates the unbind_resp answering this PDU
+func (u *Unbind) GetResponse() PDU {
+	resp := NewUnbindResp()
+	resp.Header = responseHeader(u.Header, UNBIND_RESP)
+	return resp
 }
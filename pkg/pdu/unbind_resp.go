@@ -2,7 +2,7 @@ package pdu
 
 // UnbindResp represents an SMPP unbind response PDU
 type UnbindResp struct {
-	Header *Header
+	Header *Header `json:"header"`
 }
 
 // NewUnbindResp creates a new UnbindResp PDU
@@ -12,19 +12,64 @@ func NewUnbindResp() *UnbindResp {
 	}
 }
 
-// Marshal serializes the PDU into bytes
-func (u *UnbindResp) Marshal() ([]byte, error) {
-	// Set command length in header
-	u.Header.CommandLength = 16 // Only header, no body
+// EncodedLen returns the size of the encoded PDU in bytes
+func (u *UnbindResp) EncodedLen() int {
+	return HeaderLength
+}
+
+// AppendTo appends the encoded PDU to dst and returns the extended slice
+func (u *UnbindResp) AppendTo(dst []byte) ([]byte, error) {
+	u.Header.CommandLength = uint32(u.EncodedLen())
 	u.Header.CommandID = UNBIND_RESP
 
-	// Marshal header
-	return u.Header.Marshal()
+	return u.Header.AppendTo(dst), nil
+}
+
+// Marshal serializes the PDU into bytes
+func (u *UnbindResp) Marshal() ([]byte, error) {
+	return u.AppendTo(make([]byte, 0, u.EncodedLen()))
 }
 
 // Unmarshal deserializes the PDU from bytes
 func (u *UnbindResp) Unmarshal(data []byte) error {
-	// Unmarshal header
+	return u.decode(newDecoder(data, false))
+}
+
+// decode reads the PDU fields from dec
+func (u *UnbindResp) decode(dec *decoder) error {
 	u.Header = &Header{}
-	return u.Header.Unmarshal(data[:16])
+	dec.header(u.Header)
+
+	dec.end()
+	return dec.Err()
+}
+
+// CommandID returns the command_id of the PDU
+func (u *UnbindResp) CommandID() uint32 {
+	return UNBIND_RESP
+}
+
+// GetHeader returns the PDU header
+func (u *UnbindResp) GetHeader() *Header {
+	return u.Header
+}
+
+// String returns a one-line description of the PDU for trace logs
+func (u *UnbindResp) String() string {
+	return FormatPDU(u)
+}
+
+// MarshalJSON encodes the PDU as JSON
+func (u *UnbindResp) MarshalJSON() ([]byte, error) {
+	return marshalPDUJSON(u)
+}
+
+// UnmarshalJSON decodes the JSON encoding of the PDU
+func (u *UnbindResp) UnmarshalJSON(data []byte) error {
+	return unmarshalPDUJSON(data, u)
+}
+
+// GetResponse returns nil as unbind_resp has no response PDU
+func (u *UnbindResp) GetResponse() PDU {
+	return nil
 }
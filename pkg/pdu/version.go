@@ -0,0 +1,200 @@
+package pdu
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+var (
+	ErrCommandNotSupported = NewStatusError(ESME_RINVCMDID, "command not supported by interface version")
+	ErrTLVNotSupported     = NewStatusError(ESME_ROPTPARNOTALLWD, "optional parameters not supported by interface version")
+	ErrPayloadTooLong      = NewStatusError(ESME_RINVMSGLEN, "message_payload does not fit into short_message")
+)
+
+// commandVersions holds the commands introduced after SMPP 3.3, mapped to
+// the first interface version that defines them
+var commandVersions = map[uint32]uint32{
+	BIND_TRANSCEIVER:         SMPP_V34,
+	BIND_TRANSCEIVER_RESP:    SMPP_V34,
+	OUTBIND:                  SMPP_V34,
+	ALERT_NOTIFICATION:       SMPP_V34,
+	DATA_SM:                  SMPP_V34,
+	DATA_SM_RESP:             SMPP_V34,
+	BROADCAST_SM:             SMPP_V50,
+	BROADCAST_SM_RESP:        SMPP_V50,
+	QUERY_BROADCAST_SM:       SMPP_V50,
+	QUERY_BROADCAST_SM_RESP:  SMPP_V50,
+	CANCEL_BROADCAST_SM:      SMPP_V50,
+	CANCEL_BROADCAST_SM_RESP: SMPP_V50,
+}
+
+// CommandVersion returns the first interface version that defines a command
+func CommandVersion(commandID uint32) uint32 {
+	if v, ok := commandVersions[commandID]; ok {
+		return v
+	}
+	return SMPP_V33
+}
+
+// checkCommandVersion reports ESME_RINVCMDID for commands a version lacks
+func checkCommandVersion(commandID uint32, version uint32) error {
+	if CommandVersion(commandID) > version {
+		return fmt.Errorf("%w: command 0x%08X in version 0x%02X", ErrCommandNotSupported, commandID, version)
+	}
+	return nil
+}
+
+// NegotiateVersion returns the interface version to use with a peer that
+// announced the given interface_version. Anything below 3.4 is treated as
+// 3.3 and anything above 5.0 as 5.0.
+func NegotiateVersion(version uint32) uint32 {
+	switch {
+	case version < SMPP_V34:
+		return SMPP_V33
+	case version < SMPP_V50:
+		return SMPP_V34
+	default:
+		return SMPP_V50
+	}
+}
+
+// Context carries the negotiated interface version of a session and the
+// registry used for its PDUs. Encoding through a context drops what the
+// peer's version does not understand; decoding rejects it.
+type Context struct {
+	Version  uint32
+	Registry *Registry // nil for DefaultRegistry
+}
+
+// NewContext creates a context for an interface version
+func NewContext(version uint32) *Context {
+	return &Context{
+		Version: NegotiateVersion(version),
+	}
+}
+
+// registry returns the context registry or the default one
+func (c *Context) registry() *Registry {
+	if c.Registry != nil {
+		return c.Registry
+	}
+	return DefaultRegistry
+}
+
+// CheckCommand reports ESME_RINVCMDID for commands the version lacks
+func (c *Context) CheckCommand(commandID uint32) error {
+	return checkCommandVersion(commandID, c.Version)
+}
+
+// AllowsTLV checks if a TLV may be sent in a PDU. SMPP 3.3 has no optional
+// parameters at all; later versions allow known tags according to the TLV
+// schema and pass unknown tags through.
+func (c *Context) AllowsTLV(commandID uint32, tag uint16) bool {
+	if c.Version < SMPP_V34 {
+		return false
+	}
+	schema := c.registry().TLVSchema()
+	if schema == nil {
+		return true
+	}
+	def, ok := schema.Lookup(tag)
+	return !ok || def.AllowedIn(commandID, c.Version)
+}
+
+// FilterTLVs returns the parameters that may be sent in a PDU
+func (c *Context) FilterTLVs(commandID uint32, params TLVList) TLVList {
+	var kept TLVList
+	for _, tlv := range params {
+		if c.AllowsTLV(commandID, tlv.Tag) {
+			kept = append(kept, tlv)
+		}
+	}
+	return kept
+}
+
+// Encode marshals a PDU for the peer, leaving out the optional parameters
+// its version does not allow. For SMPP 3.3 a message_payload is moved into
+// short_message. The PDU itself is not modified.
+func (c *Context) Encode(p PDU) ([]byte, error) {
+	return c.AppendTo(nil, p)
+}
+
+// AppendTo appends a PDU encoded for the peer to dst like Encode
+func (c *Context) AppendTo(dst []byte, p PDU) ([]byte, error) {
+	if err := c.CheckCommand(p.CommandID()); err != nil {
+		return dst, err
+	}
+	if c.Version < SMPP_V34 {
+		var err error
+		if p, err = inlinePayload(p); err != nil {
+			return dst, err
+		}
+	}
+
+	start := len(dst)
+	dst, err := AppendPDU(dst, p)
+	if err != nil {
+		return dst, err
+	}
+
+	carrier, ok := p.(TLVCarrier)
+	if !ok {
+		return dst, nil
+	}
+	params := *carrier.GetTLVParams()
+	kept := c.FilterTLVs(p.CommandID(), params)
+	if len(kept) == len(params) {
+		return dst, nil
+	}
+
+	// Optional parameters always come last, so cut them off and append
+	// the ones that are kept
+	dst = kept.AppendTo(dst[:len(dst)-params.EncodedLen()])
+	binary.BigEndian.PutUint32(dst[start:], uint32(len(dst)-start))
+	return dst, nil
+}
+
+// inlinePayload returns a copy of a PDU with its message_payload moved
+// into short_message, the only place SMPP 3.3 carries a message. A message
+// that does not fit, or a PDU without short_message, cannot be sent.
+func inlinePayload(p PDU) (PDU, error) {
+	carrier, ok := p.(TLVCarrier)
+	if !ok {
+		return p, nil
+	}
+	payload, ok := carrier.GetTLVParams().MessagePayload()
+	if !ok {
+		return p, nil
+	}
+	if len(payload) > SM_MAX_LENGTH {
+		return p, fmt.Errorf("%w: %d octets", ErrPayloadTooLong, len(payload))
+	}
+
+	switch v := p.(type) {
+	case *SubmitSM:
+		if len(v.ShortMessage) == 0 {
+			out := *v
+			out.ShortMessage = payload
+			return &out, nil
+		}
+	case *SubmitMulti:
+		if len(v.ShortMessage) == 0 {
+			out := *v
+			out.ShortMessage = payload
+			return &out, nil
+		}
+	case *DeliverSM:
+		if len(v.ShortMessage) == 0 {
+			out := *v
+			out.ShortMessage = payload
+			return &out, nil
+		}
+	}
+	return p, fmt.Errorf("%w: message_payload in %s", ErrTLVNotSupported, CommandName(p.CommandID()))
+}
+
+// Decode turns a PDU frame received from the peer into its typed PDU,
+// rejecting commands and optional parameters its version does not allow
+func (c *Context) Decode(data []byte) (PDU, error) {
+	return c.registry().DecodeVersion(data, c.Version)
+}
@@ -0,0 +1,214 @@
+package pdu
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestNegotiateVersion(t *testing.T) {
+	tests := []struct {
+		announced, want uint32
+	}{
+		{0x00, SMPP_V33},
+		{0x33, SMPP_V33},
+		{0x34, SMPP_V34},
+		{0x40, SMPP_V34},
+		{0x50, SMPP_V50},
+		{0x51, SMPP_V50},
+	}
+	for _, tt := range tests {
+		if got := NegotiateVersion(tt.announced); got != tt.want {
+			t.Errorf("NegotiateVersion(0x%02X) = 0x%02X, want 0x%02X", tt.announced, got, tt.want)
+		}
+	}
+}
+
+func TestContextFilterTLVs(t *testing.T) {
+	sm := NewDeliverSM()
+	sm.ShortMessage = []byte("hello")
+	sm.TLVParams.SetUserMessageReference(42)                        // SMPP 3.4
+	sm.TLVParams.Set(TLV_BILLING_IDENTIFICATION, []byte{0x01, 'x'}) // SMPP 5.0
+	sm.TLVParams.Set(0x1400, []byte{0x01})                          // Vendor specific
+
+	tests := []struct {
+		version uint32
+		tags    []uint16
+	}{
+		{SMPP_V33, nil},
+		{SMPP_V34, []uint16{TLV_USER_MESSAGE_REFERENCE, 0x1400}},
+		{SMPP_V50, []uint16{TLV_USER_MESSAGE_REFERENCE, TLV_BILLING_IDENTIFICATION, 0x1400}},
+	}
+	for _, tt := range tests {
+		c := NewContext(tt.version)
+		frame, err := c.Encode(sm)
+		if err != nil {
+			t.Fatalf("0x%02X: Encode: %v", tt.version, err)
+		}
+		p, err := c.Decode(frame)
+		if err != nil {
+			t.Fatalf("0x%02X: Decode: %v", tt.version, err)
+		}
+		got := p.(*DeliverSM)
+		var tags []uint16
+		for _, tlv := range got.TLVParams {
+			tags = append(tags, tlv.Tag)
+		}
+		if !slices.Equal(tags, tt.tags) {
+			t.Errorf("0x%02X: sent TLVs %04X, want %04X", tt.version, tags, tt.tags)
+		}
+		if string(got.ShortMessage) != "hello" {
+			t.Errorf("0x%02X: short_message %q", tt.version, got.ShortMessage)
+		}
+	}
+
+	// The PDU itself keeps its parameters
+	if len(sm.TLVParams) != 3 {
+		t.Errorf("Encode modified the PDU: %d TLVs left", len(sm.TLVParams))
+	}
+}
+
+func TestContextCommands(t *testing.T) {
+	tests := []struct {
+		p       PDU
+		version uint32 // First version that has the command
+	}{
+		{NewSubmitSM(), SMPP_V33},
+		{NewEnquireLink(), SMPP_V33},
+		{NewDataSM(), SMPP_V34},
+		{NewBindTransceiver(), SMPP_V34},
+		{NewBroadcastSMResp(), SMPP_V50},
+		{NewQueryBroadcastSM(), SMPP_V50},
+		{NewCancelBroadcastSM(), SMPP_V50},
+	}
+	for _, tt := range tests {
+		name := CommandName(tt.p.CommandID())
+		for _, version := range []uint32{SMPP_V33, SMPP_V34, SMPP_V50} {
+			_, err := NewContext(version).Encode(tt.p)
+			if version >= tt.version {
+				if errors.Is(err, ErrCommandNotSupported) {
+					t.Errorf("%s in 0x%02X: %v", name, version, err)
+				}
+				continue
+			}
+			if status, ok := ErrorStatus(err); !ok || status != ESME_RINVCMDID {
+				t.Errorf("%s in 0x%02X: error %v, want ESME_RINVCMDID", name, version, err)
+			}
+		}
+	}
+
+	// Received frames are checked the same way
+	frame, err := NewEnquireLink().Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewContext(SMPP_V33).Decode(frame); err != nil {
+		t.Errorf("Decode enquire_link in 3.3: %v", err)
+	}
+	frame, err = NewCancelBroadcastSMResp().Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewContext(SMPP_V34).Decode(frame); !errors.Is(err, ErrCommandNotSupported) {
+		t.Errorf("Decode cancel_broadcast_sm_resp in 3.4: %v", err)
+	}
+}
+
+func TestContextDecodeTLVs(t *testing.T) {
+	sm := NewDeliverSM()
+	sm.TLVParams.Set(TLV_BILLING_IDENTIFICATION, []byte{0x01, 'x'})
+	frame, err := sm.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		version uint32
+		status  uint32
+	}{
+		{SMPP_V33, ESME_ROPTPARNOTALLWD},
+		{SMPP_V34, ESME_ROPTPARNOTALLWD},
+		{SMPP_V50, ESME_ROK},
+	} {
+		_, err := NewContext(tt.version).Decode(frame)
+		if tt.status == ESME_ROK {
+			if err != nil {
+				t.Errorf("0x%02X: Decode: %v", tt.version, err)
+			}
+			continue
+		}
+		if status, ok := ErrorStatus(err); !ok || status != tt.status {
+			t.Errorf("0x%02X: Decode error %v, want status 0x%08X", tt.version, err, tt.status)
+		}
+	}
+}
+
+func TestContextPayloadV33(t *testing.T) {
+	payload := bytes.Repeat([]byte{'a'}, SM_MAX_LENGTH)
+	sm := NewDeliverSM()
+	sm.TLVParams.SetMessagePayload(payload)
+	sm.TLVParams.SetUserMessageReference(7)
+
+	// A payload that fits is moved into short_message
+	v33 := NewContext(SMPP_V33)
+	frame, err := v33.Encode(sm)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	p, err := v33.Decode(frame)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	got := p.(*DeliverSM)
+	if !bytes.Equal(got.ShortMessage, payload) || len(got.TLVParams) != 0 {
+		t.Errorf("short_message %d octets with %d TLVs", len(got.ShortMessage), len(got.TLVParams))
+	}
+	if len(sm.ShortMessage) != 0 || !sm.TLVParams.Has(TLV_MESSAGE_PAYLOAD) {
+		t.Error("Encode modified the PDU")
+	}
+
+	// Later versions keep it where it is
+	frame, err = NewContext(SMPP_V34).Encode(sm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, err := Decode(frame); err != nil || len(p.(*DeliverSM).ShortMessage) != 0 {
+		t.Errorf("3.4 deliver_sm %v, %v", p, err)
+	}
+
+	// One that does not fit cannot be sent
+	sm.TLVParams.SetMessagePayload(bytes.Repeat([]byte{'a'}, 300))
+	if _, err := v33.Encode(sm); !errors.Is(err, ErrPayloadTooLong) {
+		t.Errorf("Encode of 300 octets error = %v, want ErrPayloadTooLong", err)
+	} else if status, _ := ErrorStatus(err); status != ESME_RINVMSGLEN {
+		t.Errorf("Encode of 300 octets status 0x%08X", status)
+	}
+
+	// Nor one next to short_message
+	sm.TLVParams.SetMessagePayload([]byte("payload"))
+	sm.ShortMessage = []byte("text")
+	if _, err := v33.Encode(sm); !errors.Is(err, ErrTLVNotSupported) {
+		t.Errorf("Encode with both error = %v, want ErrTLVNotSupported", err)
+	}
+
+	// submit_sm and submit_multi are handled the same way
+	submit := NewSubmitSM()
+	submit.TLVParams.SetMessagePayload([]byte("hello"))
+	multi := NewSubmitMulti()
+	multi.AddDestAddr("447700900123", TON_INTERNATIONAL, NPI_ISDN)
+	multi.TLVParams.SetMessagePayload([]byte("hello"))
+	for _, p := range []PDU{submit, multi} {
+		frame, err := v33.Encode(p)
+		if err != nil {
+			t.Fatalf("%s: Encode: %v", CommandName(p.CommandID()), err)
+		}
+		decoded, err := v33.Decode(frame)
+		if err != nil {
+			t.Fatalf("%s: Decode: %v", CommandName(p.CommandID()), err)
+		}
+		msg, err := MessageFrom(decoded)
+		if err != nil || string(msg.Payload) != "hello" {
+			t.Errorf("%s: message %v, %v", CommandName(p.CommandID()), msg, err)
+		}
+	}
+}
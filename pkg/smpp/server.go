@@ -1,12 +1,12 @@
 package smpp
 
 import (
+	"errors"
 	"fmt"
-	"io"
 	"net"
 	"sync"
 
-	"github.com/tarik/nessmpp/pkg/pdu"
+	"nessmpp/pkg/pdu"
 )
 
 // Server represents an SMPP server
@@ -16,6 +16,15 @@ type Server struct {
 	sessions map[string]*Session
 	mu       sync.RWMutex
 	handlers map[uint32]PDUHandler
+	registry *pdu.Registry
+	maxLen   uint32
+	version  uint32 // Highest interface version spoken
+
+	// Handlers of vendor commands by profile name, tried before handlers
+	profileHandlers map[string]map[uint32]PDUHandler
+
+	submitHandler SubmitHandler
+	dlResolver    DistributionListResolver
 }
 
 // Session represents a client connection
@@ -27,10 +36,21 @@ type Session struct {
 	mu         sync.RWMutex
 	server     *Server
 	sequenceNo uint32
+	reader     *pdu.Reader
+	writer     *pdu.Writer
+	profile    *pdu.Profile // Vendor extensions of the peer, nil for none
 }
 
 // PDUHandler is a function type that handles specific PDU types
-type PDUHandler func(*Session, pdu.Header) error
+type PDUHandler func(*Session, pdu.PDU) error
+
+// SubmitHandler accepts a single message for delivery and returns the
+// assigned message ID, or a non-zero command_status if it was rejected
+type SubmitHandler func(*Session, *pdu.SubmitSM) (string, uint32)
+
+// DistributionListResolver expands a distribution list name into the SME
+// addresses it contains
+type DistributionListResolver func(sess *Session, name string) ([]pdu.DestAddress, error)
 
 // NewServer creates a new SMPP server
 func NewServer(addr string) *Server {
@@ -38,6 +58,11 @@ func NewServer(addr string) *Server {
 		addr:     addr,
 		sessions: make(map[string]*Session),
 		handlers: make(map[uint32]PDUHandler),
+		registry: pdu.DefaultRegistry,
+		maxLen:   pdu.DefaultMaxCommandLength,
+		version:  pdu.SMPP_V50,
+
+		profileHandlers: make(map[string]map[uint32]PDUHandler),
 	}
 
 	// Register default handlers
@@ -66,6 +91,61 @@ func (s *Server) Stop() error {
 	return nil
 }
 
+// SetRegistry sets the registry used to decode incoming PDUs
+func (s *Server) SetRegistry(r *pdu.Registry) {
+	s.registry = r
+}
+
+// SetMaxCommandLength sets the largest PDU accepted from clients
+func (s *Server) SetMaxCommandLength(n uint32) {
+	s.maxLen = n
+}
+
+// SetInterfaceVersion sets the highest interface version the server speaks.
+// Sessions use it until their bind announces a lower one.
+func (s *Server) SetInterfaceVersion(version uint32) {
+	s.version = pdu.NegotiateVersion(version)
+}
+
+// SetSubmitHandler sets the handler that accepts submitted messages
+func (s *Server) SetSubmitHandler(h SubmitHandler) {
+	s.submitHandler = h
+}
+
+// SetDistributionListResolver sets the resolver used for submit_multi
+// destinations given as distribution list names
+func (s *Server) SetDistributionListResolver(r DistributionListResolver) {
+	s.dlResolver = r
+}
+
+// Handle registers the handler for a command ID, replacing any existing one
+func (s *Server) Handle(commandID uint32, handler PDUHandler) {
+	s.handlers[commandID] = handler
+}
+
+// HandleProfile registers the handler for a command ID on sessions using a
+// profile, such as a proprietary command of that vendor. It takes
+// precedence over the handler registered with Handle.
+func (s *Server) HandleProfile(profile *pdu.Profile, commandID uint32, handler PDUHandler) {
+	handlers, ok := s.profileHandlers[profile.Name]
+	if !ok {
+		handlers = make(map[uint32]PDUHandler)
+		s.profileHandlers[profile.Name] = handlers
+	}
+	handlers[commandID] = handler
+}
+
+// handler returns the handler for a command ID on a session
+func (s *Server) handler(sess *Session, commandID uint32) (PDUHandler, bool) {
+	if sess.profile != nil {
+		if h, ok := s.profileHandlers[sess.profile.Name][commandID]; ok {
+			return h, true
+		}
+	}
+	h, ok := s.handlers[commandID]
+	return h, ok
+}
+
 func (s *Server) accept() {
 	for {
 		conn, err := s.listener.Accept()
@@ -74,13 +154,23 @@ func (s *Server) accept() {
 			continue
 		}
 
-		session := &Session{
-			conn:   conn,
-			server: s,
-		}
+		go s.newSession(conn).handle()
+	}
+}
 
-		go session.handle()
+// newSession creates the session of an accepted connection
+func (s *Server) newSession(conn net.Conn) *Session {
+	session := &Session{
+		conn:   conn,
+		server: s,
+		reader: pdu.NewReader(conn),
+		writer: pdu.NewWriter(conn),
 	}
+	session.reader.SetRegistry(s.registry)
+	session.reader.SetMaxCommandLength(s.maxLen)
+	session.writer.SetRegistry(s.registry)
+	session.SetInterfaceVersion(s.version)
+	return session
 }
 
 func (s *Server) registerDefaultHandlers() {
@@ -91,6 +181,7 @@ func (s *Server) registerDefaultHandlers() {
 
 	// Messaging operations
 	s.handlers[pdu.SUBMIT_SM] = handleSubmitSM
+	s.handlers[pdu.SUBMIT_MULTI] = handleSubmitMulti
 	s.handlers[pdu.DELIVER_SM] = handleDeliverSM
 	s.handlers[pdu.DATA_SM] = handleDataSM
 
@@ -117,131 +208,286 @@ func (s *Server) registerDefaultHandlers() {
 func (sess *Session) handle() {
 	defer sess.conn.Close()
 
-	headerBuf := make([]byte, 16)
 	for {
-		// Read PDU header
-		_, err := io.ReadFull(sess.conn, headerBuf)
+		// Read and decode the next PDU
+		p, err := sess.reader.ReadPDU()
 		if err != nil {
-			if err != io.EOF {
+			var frameErr *pdu.FrameError
+			if !errors.As(err, &frameErr) {
 				// TODO: Handle read error
+				return
 			}
-			return
-		}
-
-		// Parse header
-		header := &pdu.Header{}
-		if err := header.Unmarshal(headerBuf); err != nil {
-			// TODO: Send GENERIC_NACK
-			continue
-		}
 
-		// Read PDU body
-		bodyLen := int(header.CommandLength) - 16
-		if bodyLen < 0 {
-			// TODO: Send GENERIC_NACK
+			sess.sendFrameError(frameErr, p)
+			if frameErr.Fatal {
+				return
+			}
 			continue
 		}
 
-		bodyBuf := make([]byte, bodyLen)
-		if bodyLen > 0 {
-			if _, err := io.ReadFull(sess.conn, bodyBuf); err != nil {
-				// TODO: Handle read error
-				continue
-			}
+		// A bind announces the interface version of the ESME, the session
+		// speaks the lower of it and that of the server
+		if version, ok := bindInterfaceVersion(p); ok {
+			sess.SetInterfaceVersion(min(version, sess.server.version))
 		}
 
 		// Handle PDU
-		if handler, ok := sess.server.handlers[header.CommandID]; ok {
-			if err := handler(sess, *header); err != nil {
+		if handler, ok := sess.server.handler(sess, p.CommandID()); ok {
+			if err := handler(sess, p); err != nil {
 				// TODO: Handle error
 			}
 		} else {
-			// TODO: Send GENERIC_NACK for unknown command
+			sess.sendGenericNack(p.GetHeader().SequenceNumber, pdu.ESME_RINVCMDID)
 		}
 	}
 }
 
+// bindInterfaceVersion returns the interface_version of a bind PDU
+func bindInterfaceVersion(p pdu.PDU) (uint32, bool) {
+	switch bind := p.(type) {
+	case *pdu.BindTransmitter:
+		return uint32(bind.InterfaceVersion), true
+	case *pdu.BindReceiver:
+		return uint32(bind.InterfaceVersion), true
+	case *pdu.BindTransceiver:
+		return uint32(bind.InterfaceVersion), true
+	}
+	return 0, false
+}
+
 // Handler implementations
 
-func handleBindTransmitter(sess *Session, header pdu.Header) error {
+func handleBindTransmitter(sess *Session, p pdu.PDU) error {
 	// TODO: Implement bind_transmitter handling
 	return nil
 }
 
-func handleBindReceiver(sess *Session, header pdu.Header) error {
+func handleBindReceiver(sess *Session, p pdu.PDU) error {
 	// TODO: Implement bind_receiver handling
 	return nil
 }
 
-func handleBindTransceiver(sess *Session, header pdu.Header) error {
+func handleBindTransceiver(sess *Session, p pdu.PDU) error {
 	// TODO: Implement bind_transceiver handling
 	return nil
 }
 
-func handleSubmitSM(sess *Session, header pdu.Header) error {
-	// TODO: Implement submit_sm handling
-	return nil
+func handleSubmitSM(sess *Session, p pdu.PDU) error {
+	sm := p.(*pdu.SubmitSM)
+	resp := sm.GetResponse().(*pdu.SubmitSMResp)
+	resp.MessageID, resp.Header.CommandStatus = sess.submit(sm)
+	return sess.sendPDU(resp)
+}
+
+func handleSubmitMulti(sess *Session, p pdu.PDU) error {
+	multi := p.(*pdu.SubmitMulti)
+	resp := multi.GetResponse().(*pdu.SubmitMultiResp)
+
+	// Expand distribution lists into individual SME addresses
+	var dests []pdu.DestAddress
+	for _, dest := range multi.DestAddresses {
+		if !dest.IsDistributionList() {
+			dests = append(dests, dest)
+			continue
+		}
+
+		if sess.server.dlResolver == nil {
+			resp.AddUnsuccessSME(dest.DLName, 0, 0, pdu.ESME_RCNTSUBDL)
+			continue
+		}
+
+		members, err := sess.server.dlResolver(sess, dest.DLName)
+		if err != nil || len(members) == 0 {
+			resp.AddUnsuccessSME(dest.DLName, 0, 0, pdu.ESME_RINVDLNAME)
+			continue
+		}
+		dests = append(dests, members...)
+
+		// Distribution lists count against the same limit as the
+		// destinations listed in the PDU
+		if len(dests) > pdu.SUBMIT_MULTI_MAX_DESTS {
+			resp = multi.GetResponse().(*pdu.SubmitMultiResp)
+			resp.Header.CommandStatus = pdu.ESME_RINVNUMDESTS
+			return sess.sendPDU(resp)
+		}
+	}
+
+	// Fan out into one message per destination
+	submitted := false
+	for _, dest := range dests {
+		messageID, status := sess.submit(multi.SubmitSM(dest))
+		if status != pdu.ESME_ROK {
+			resp.AddUnsuccessSME(dest.DestinationAddr, dest.DestAddrTON, dest.DestAddrNPI, status)
+			continue
+		}
+		if !submitted {
+			resp.MessageID = messageID
+			submitted = true
+		}
+	}
+
+	// Nothing was submitted: the whole request failed
+	if !submitted {
+		resp.Header.CommandStatus = multiFailureStatus(resp.UnsuccessSME)
+	}
+
+	if len(resp.UnsuccessSME) > pdu.SUBMIT_MULTI_MAX_DESTS {
+		resp.UnsuccessSME = resp.UnsuccessSME[:pdu.SUBMIT_MULTI_MAX_DESTS]
+	}
+
+	return sess.sendPDU(resp)
 }
 
-func handleDeliverSM(sess *Session, header pdu.Header) error {
+// multiFailureStatus returns the command_status of a submit_multi none of
+// whose destinations were submitted: the status they share, or
+// ESME_RSUBMITFAIL when they failed for different reasons
+func multiFailureStatus(unsuccess []pdu.UnsuccessSME) uint32 {
+	if len(unsuccess) == 0 {
+		return pdu.ESME_RSUBMITFAIL
+	}
+	status := unsuccess[0].ErrorStatusCode
+	for _, sme := range unsuccess[1:] {
+		if sme.ErrorStatusCode != status {
+			return pdu.ESME_RSUBMITFAIL
+		}
+	}
+	return status
+}
+
+func handleDeliverSM(sess *Session, p pdu.PDU) error {
 	// TODO: Implement deliver_sm handling
 	return nil
 }
 
-func handleDataSM(sess *Session, header pdu.Header) error {
+func handleDataSM(sess *Session, p pdu.PDU) error {
 	// TODO: Implement data_sm handling
 	return nil
 }
 
-func handleQuerySM(sess *Session, header pdu.Header) error {
+func handleQuerySM(sess *Session, p pdu.PDU) error {
 	// TODO: Implement query_sm handling
 	return nil
 }
 
-func handleQueryBroadcastSM(sess *Session, header pdu.Header) error {
+func handleQueryBroadcastSM(sess *Session, p pdu.PDU) error {
 	// TODO: Implement query_broadcast_sm handling
 	return nil
 }
 
-func handleCancelSM(sess *Session, header pdu.Header) error {
+func handleCancelSM(sess *Session, p pdu.PDU) error {
 	// TODO: Implement cancel_sm handling
 	return nil
 }
 
-func handleCancelBroadcastSM(sess *Session, header pdu.Header) error {
+func handleCancelBroadcastSM(sess *Session, p pdu.PDU) error {
 	// TODO: Implement cancel_broadcast_sm handling
 	return nil
 }
 
-func handleReplaceSM(sess *Session, header pdu.Header) error {
+func handleReplaceSM(sess *Session, p pdu.PDU) error {
 	// TODO: Implement replace_sm handling
 	return nil
 }
 
-func handleBroadcastSM(sess *Session, header pdu.Header) error {
+func handleBroadcastSM(sess *Session, p pdu.PDU) error {
 	// TODO: Implement broadcast_sm handling
 	return nil
 }
 
-func handleUnbind(sess *Session, header pdu.Header) error {
+func handleUnbind(sess *Session, p pdu.PDU) error {
 	// TODO: Implement unbind handling
 	return nil
 }
 
-func handleEnquireLink(sess *Session, header pdu.Header) error {
+func handleEnquireLink(sess *Session, p pdu.PDU) error {
 	// TODO: Implement enquire_link handling
 	return nil
 }
 
-func handleGenericNack(sess *Session, header pdu.Header) error {
+func handleGenericNack(sess *Session, p pdu.PDU) error {
 	// TODO: Implement generic_nack handling
 	return nil
 }
 
 // Helper methods for Session
-func (sess *Session) sendPDU(p interface{}) error {
-	// TODO: Implement PDU sending
-	return nil
+
+// SetInterfaceVersion applies the interface_version negotiated at bind time,
+// so that a v3.3 ESME gets no optional parameters or v5.0 only PDUs. It is
+// called with the version of the bind PDU before its handler runs.
+func (sess *Session) SetInterfaceVersion(version uint32) {
+	sess.reader.SetVersion(version)
+	sess.writer.SetVersion(version)
+}
+
+// SetProfile applies the vendor extensions of the peer, e.g. once its
+// system_id is known at bind time, so that its vendor TLVs and commands
+// are decoded and its vendor command handlers are used
+func (sess *Session) SetProfile(profile *pdu.Profile) {
+	sess.profile = profile
+	sess.reader.SetRegistry(profile.Registry())
+	sess.writer.SetRegistry(profile.Registry())
+}
+
+// Profile returns the vendor extensions of the peer, or nil
+func (sess *Session) Profile() *pdu.Profile {
+	return sess.profile
+}
+
+// Registry returns the registry the session's PDUs are read with, that of
+// its profile if it has one. Its FormatPDU and EncodeJSON name the vendor
+// commands of the peer.
+func (sess *Session) Registry() *pdu.Registry {
+	if sess.profile != nil {
+		return sess.profile.Registry()
+	}
+	return sess.server.registry
+}
+
+// Send writes a PDU to the peer. Requests without a sequence number are
+// given the next one of the session.
+func (sess *Session) Send(p pdu.PDU) error {
+	if h := p.GetHeader(); h != nil && h.SequenceNumber == 0 && !pdu.IsResponse(p.CommandID()) {
+		h.SequenceNumber = sess.nextSequenceNumber()
+	}
+	return sess.sendPDU(p)
+}
+
+func (sess *Session) sendPDU(p pdu.PDU) error {
+	return sess.writer.WritePDU(p)
+}
+
+func (sess *Session) sendGenericNack(sequenceNo, status uint32) error {
+	nack := pdu.NewGenericNack()
+	nack.Header.SequenceNumber = sequenceNo
+	nack.SetErrorCode(status)
+	return sess.sendPDU(nack)
+}
+
+// sendFrameError answers a PDU that could not be read or decoded, using the
+// matching response when the command is known and generic_nack otherwise
+func (sess *Session) sendFrameError(frameErr *pdu.FrameError, p pdu.PDU) error {
+	var sequenceNo uint32
+	if frameErr.Header != nil {
+		sequenceNo = frameErr.Header.SequenceNumber
+	}
+
+	if p != nil {
+		if resp := p.GetResponse(); resp != nil {
+			resp.GetHeader().SequenceNumber = sequenceNo
+			resp.GetHeader().CommandStatus = frameErr.Status
+			return sess.sendPDU(resp)
+		}
+	}
+
+	return sess.sendGenericNack(sequenceNo, frameErr.Status)
+}
+
+// submit passes a single message to the server's submit handler
+func (sess *Session) submit(sm *pdu.SubmitSM) (string, uint32) {
+	if sess.server.submitHandler == nil {
+		return "", pdu.ESME_RSUBMITFAIL
+	}
+	return sess.server.submitHandler(sess, sm)
 }
 
 func (sess *Session) nextSequenceNumber() uint32 {
@@ -0,0 +1,199 @@
+package smpp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+
+	"nessmpp/pkg/pdu"
+)
+
+func TestSessionInterfaceVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		server   uint32 // Version of the server, 0 for the default
+		bind     uint8
+		wantTLVs bool
+	}{
+		{"v3.3 bind", 0, 0x33, false},
+		{"pre-3.3 bind", 0, 0x00, false},
+		{"v3.4 bind", 0, 0x34, true},
+		{"v5.0 bind", 0, 0x50, true},
+		{"v3.3 server", pdu.SMPP_V33, 0x50, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer("")
+			if tt.server != 0 {
+				s.SetInterfaceVersion(tt.server)
+			}
+			// Deliver a message with optional parameters as soon as bound
+			s.Handle(pdu.BIND_TRANSMITTER, func(sess *Session, p pdu.PDU) error {
+				resp := p.GetResponse().(*pdu.BindTransmitterResp)
+				resp.TLVParams.SetSCInterfaceVersion(uint8(pdu.SMPP_V50))
+				if err := sess.Send(resp); err != nil {
+					return err
+				}
+				sm := pdu.NewDeliverSM()
+				sm.ShortMessage = []byte("hello")
+				sm.SMLength = uint8(len(sm.ShortMessage))
+				sm.TLVParams.SetUserMessageReference(42)
+				return sess.Send(sm)
+			})
+
+			client, conn := net.Pipe()
+			defer client.Close()
+			go s.newSession(conn).handle()
+
+			bind := pdu.NewBindTransmitter()
+			bind.SystemID = "esme"
+			bind.InterfaceVersion = tt.bind
+			bind.Header.SequenceNumber = 1
+			if err := pdu.NewWriter(client).WritePDU(bind); err != nil {
+				t.Fatalf("WritePDU: %v", err)
+			}
+
+			reader := pdu.NewReader(client)
+			for _, commandID := range []uint32{pdu.BIND_TRANSMITTER_RESP, pdu.DELIVER_SM} {
+				frame, err := reader.ReadFrame()
+				if err != nil {
+					t.Fatalf("ReadFrame: %v", err)
+				}
+				p, err := pdu.Decode(frame)
+				if err != nil {
+					t.Fatalf("Decode: %v", err)
+				}
+				if p.CommandID() != commandID {
+					t.Fatalf("got %s, want %s", pdu.CommandName(p.CommandID()), pdu.CommandName(commandID))
+				}
+				params := *p.(pdu.TLVCarrier).GetTLVParams()
+				if hasTLVs := len(params) > 0; hasTLVs != tt.wantTLVs {
+					t.Errorf("%s sent with TLVs %v, want TLVs %v", pdu.CommandName(commandID), params, tt.wantTLVs)
+				}
+			}
+		})
+	}
+}
+
+func TestSessionSubmitMulti(t *testing.T) {
+	lists := map[string][]string{
+		"staff":  {"447700900001", "447700900002"},
+		"empty":  nil,
+		"broken": {"447700900666"},
+	}
+	// The "huge" list takes the request over the destination limit
+	for i := range pdu.SUBMIT_MULTI_MAX_DESTS {
+		lists["huge"] = append(lists["huge"], fmt.Sprintf("4477009%05d", i))
+	}
+
+	tests := []struct {
+		name      string
+		dests     []string // Names in brackets are distribution lists
+		status    uint32
+		submitted []string
+		unsuccess []pdu.UnsuccessSME
+	}{
+		{
+			name:      "mixed",
+			dests:     []string{"447700900100", "[staff]", "[empty]", "447700900666"},
+			submitted: []string{"447700900100", "447700900001", "447700900002"},
+			unsuccess: []pdu.UnsuccessSME{
+				{DestinationAddr: "empty", ErrorStatusCode: pdu.ESME_RINVDLNAME},
+				{DestAddrTON: pdu.TON_INTERNATIONAL, DestAddrNPI: pdu.NPI_ISDN, DestinationAddr: "447700900666", ErrorStatusCode: pdu.ESME_RINVDSTADR},
+			},
+		},
+		{
+			name:   "all rejected",
+			dests:  []string{"447700900666", "[broken]"},
+			status: pdu.ESME_RINVDSTADR,
+			unsuccess: []pdu.UnsuccessSME{
+				{DestAddrTON: pdu.TON_INTERNATIONAL, DestAddrNPI: pdu.NPI_ISDN, DestinationAddr: "447700900666", ErrorStatusCode: pdu.ESME_RINVDSTADR},
+				{DestAddrTON: pdu.TON_INTERNATIONAL, DestAddrNPI: pdu.NPI_ISDN, DestinationAddr: "447700900666", ErrorStatusCode: pdu.ESME_RINVDSTADR},
+			},
+		},
+		{
+			name:   "failures differ",
+			dests:  []string{"[empty]", "447700900666"},
+			status: pdu.ESME_RSUBMITFAIL,
+			unsuccess: []pdu.UnsuccessSME{
+				{DestinationAddr: "empty", ErrorStatusCode: pdu.ESME_RINVDLNAME},
+				{DestAddrTON: pdu.TON_INTERNATIONAL, DestAddrNPI: pdu.NPI_ISDN, DestinationAddr: "447700900666", ErrorStatusCode: pdu.ESME_RINVDSTADR},
+			},
+		},
+		{
+			name:   "too many members",
+			dests:  []string{"447700900100", "[huge]"},
+			status: pdu.ESME_RINVNUMDESTS,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var submitted []string
+			s := NewServer("")
+			s.SetSubmitHandler(func(sess *Session, sm *pdu.SubmitSM) (string, uint32) {
+				if sm.DestinationAddr == "447700900666" {
+					return "", pdu.ESME_RINVDSTADR
+				}
+				submitted = append(submitted, sm.DestinationAddr)
+				return fmt.Sprintf("id%d", len(submitted)), pdu.ESME_ROK
+			})
+			s.SetDistributionListResolver(func(sess *Session, name string) ([]pdu.DestAddress, error) {
+				members, ok := lists[name]
+				if !ok {
+					return nil, errors.New("unknown list")
+				}
+				var dests []pdu.DestAddress
+				for _, addr := range members {
+					dests = append(dests, pdu.DestAddress{DestAddrTON: pdu.TON_INTERNATIONAL, DestAddrNPI: pdu.NPI_ISDN, DestinationAddr: addr})
+				}
+				return dests, nil
+			})
+
+			client, conn := net.Pipe()
+			defer client.Close()
+			go s.newSession(conn).handle()
+
+			multi := pdu.NewSubmitMulti()
+			multi.Header.SequenceNumber = 3
+			for _, dest := range tt.dests {
+				if name, ok := strings.CutPrefix(dest, "["); ok {
+					multi.AddDistributionList(strings.TrimSuffix(name, "]"))
+				} else {
+					multi.AddDestAddr(dest, pdu.TON_INTERNATIONAL, pdu.NPI_ISDN)
+				}
+			}
+			multi.ShortMessage = []byte("hello")
+			if err := pdu.NewWriter(client).WritePDU(multi); err != nil {
+				t.Fatalf("WritePDU: %v", err)
+			}
+
+			frame, err := pdu.NewReader(client).ReadFrame()
+			if err != nil {
+				t.Fatalf("ReadFrame: %v", err)
+			}
+			p, err := pdu.Decode(frame)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			resp, ok := p.(*pdu.SubmitMultiResp)
+			if !ok || resp.Header.SequenceNumber != 3 {
+				t.Fatalf("got %v", p)
+			}
+			if resp.Header.CommandStatus != tt.status {
+				t.Errorf("command_status 0x%08X, want 0x%08X", resp.Header.CommandStatus, tt.status)
+			}
+			if wantID := tt.status == pdu.ESME_ROK; (resp.MessageID != "") != wantID {
+				t.Errorf("message_id %q", resp.MessageID)
+			}
+			if !slices.Equal(submitted, tt.submitted) {
+				t.Errorf("submitted %v, want %v", submitted, tt.submitted)
+			}
+			if !slices.Equal(resp.UnsuccessSME, tt.unsuccess) {
+				t.Errorf("unsuccess_sme %+v, want %+v", resp.UnsuccessSME, tt.unsuccess)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+// Package ussd runs USSD dialogues over SMPP. The SMSC or USSD gateway
+// passes what the mobile sends in deliver_sm or data_sm PDUs and the
+// application answers with submit_sm or data_sm, each carrying the
+// ussd_service_op TLV that tells where in the dialogue it belongs.
+package ussd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"nessmpp/pkg/pdu"
+)
+
+var (
+	ErrNotUSSD        = pdu.NewStatusError(pdu.ESME_RX_R_APPN, "pdu carries no ussd_service_op")
+	ErrNoDialogue     = pdu.NewStatusError(pdu.ESME_RX_R_APPN, "no open dialogue for the msisdn")
+	ErrUnexpectedOp   = pdu.NewStatusError(pdu.ESME_RX_R_APPN, "ussd_service_op not expected in the dialogue state")
+	ErrDialogueActive = errors.New("a dialogue is already open for the msisdn")
+	ErrTextTooLong    = errors.New("text exceeds the USSD string length")
+)
+
+// USSD string limits
+const (
+	USSD_MAX_OCTETS  int = 160 // Longest USSD string in octets
+	USSD_MAX_SEPTETS int = 182 // Septets of the GSM 7-bit alphabet in USSD_MAX_OCTETS
+)
+
+// USSD_OP_VENDOR_RELEASE is the vendor specific ussd_service_op several
+// gateways use to release a dialogue. Manager.ReleaseOp selects the one of
+// the supplier.
+const USSD_OP_VENDOR_RELEASE uint8 = 0x20
+
+// OpName returns the name of a ussd_service_op value
+func OpName(op uint8) string {
+	switch op {
+	case pdu.USSD_OP_PSSD_INDICATION:
+		return "PSSD indication"
+	case pdu.USSD_OP_PSSR_INDICATION:
+		return "PSSR indication"
+	case pdu.USSD_OP_USSR_REQUEST:
+		return "USSR request"
+	case pdu.USSD_OP_USSN_REQUEST:
+		return "USSN request"
+	case pdu.USSD_OP_PSSD_RESPONSE:
+		return "PSSD response"
+	case pdu.USSD_OP_PSSR_RESPONSE:
+		return "PSSR response"
+	case pdu.USSD_OP_USSR_CONFIRM:
+		return "USSR confirm"
+	case pdu.USSD_OP_USSN_CONFIRM:
+		return "USSN confirm"
+	}
+	return fmt.Sprintf("0x%02X", op)
+}
+
+// State is the state of a dialogue
+type State uint8
+
+// Dialogue states
+const (
+	STATE_WAIT_APPLICATION State = iota // The application owes the mobile an answer
+	STATE_WAIT_MOBILE                   // A request or notification awaits the mobile's answer
+	STATE_RELEASED                      // The dialogue is over
+)
+
+// String returns the name of the state
+func (s State) String() string {
+	switch s {
+	case STATE_WAIT_APPLICATION:
+		return "wait-application"
+	case STATE_WAIT_MOBILE:
+		return "wait-mobile"
+	default:
+		return "released"
+	}
+}
+
+// Reason tells why a dialogue was released
+type Reason uint8
+
+// Release reasons
+const (
+	RELEASE_END      Reason = iota // The application ended it or the mobile confirmed a notification
+	RELEASE_MOBILE                 // The mobile or the network released it
+	RELEASE_TIMEOUT                // The mobile did not answer in time or the dialogue lasted too long
+	RELEASE_REPLACED               // The mobile opened a new dialogue
+	RELEASE_ERROR                  // The answer could not be sent
+)
+
+// String returns the name of the reason
+func (r Reason) String() string {
+	switch r {
+	case RELEASE_END:
+		return "end"
+	case RELEASE_MOBILE:
+		return "mobile"
+	case RELEASE_TIMEOUT:
+		return "timeout"
+	case RELEASE_REPLACED:
+		return "replaced"
+	default:
+		return "error"
+	}
+}
+
+// Dialogue is a USSD dialogue with one mobile
+type Dialogue struct {
+	MSISDN  string    // Address of the mobile
+	Service string    // USSD string that opened the dialogue, e.g. "*123#"
+	State   State     // Current state
+	Started time.Time // When the dialogue was opened
+	Updated time.Time // When the last message was exchanged
+
+	// Values holds what the application keeps between the steps of the
+	// dialogue, such as the position in a menu
+	Values map[string]any
+
+	mobile      pdu.Address // Address of the mobile as received
+	service     pdu.Address // Service code the mobile addressed
+	serviceType string      // service_type of the PDU that opened it
+	dataSM      bool        // Answer with data_sm instead of submit_sm
+	opener      uint8       // ussd_service_op that opened the dialogue
+	lastOp      uint8       // ussd_service_op last sent to the mobile
+	send        Sender      // Where answers go
+}
+
+// endOp returns the ussd_service_op that ends the dialogue with a text:
+// the response to the indication of a dialogue the mobile opened, or the
+// release operation for one the application opened
+func (d *Dialogue) endOp(releaseOp uint8) uint8 {
+	switch d.opener {
+	case pdu.USSD_OP_PSSD_INDICATION:
+		return pdu.USSD_OP_PSSD_RESPONSE
+	case pdu.USSD_OP_PSSR_INDICATION:
+		return pdu.USSD_OP_PSSR_RESPONSE
+	}
+	return releaseOp
+}
+
+// message creates the PDU carrying a USSD string to the mobile
+func (d *Dialogue) message(op uint8, text string) (pdu.PDU, error) {
+	msg, err := encodeText(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var p pdu.PDU
+	var params *pdu.TLVList
+	if d.dataSM {
+		ds := pdu.NewDataSM()
+		ds.ServiceType = d.serviceType
+		ds.SetSource(d.service)
+		ds.SetDestination(d.mobile)
+		p, params = ds, &ds.TLVParams
+	} else {
+		sm := pdu.NewSubmitSM()
+		sm.ServiceType = d.serviceType
+		sm.SetSource(d.service)
+		sm.SetDestination(d.mobile)
+		p, params = sm, &sm.TLVParams
+	}
+	if err := msg.ApplyTo(p); err != nil {
+		return nil, err
+	}
+	params.SetUSSDServiceOp(op)
+	return p, nil
+}
+
+// encodeText encodes a USSD string in the GSM 7-bit alphabet if it can,
+// in UCS2 otherwise. USSD strings carry no UDH, so national language
+// shift tables are not used.
+func encodeText(text string) (*pdu.Message, error) {
+	enc := pdu.SelectTextEncoding(text, &pdu.TextOptions{Languages: []uint8{}})
+	limit := USSD_MAX_OCTETS
+	if enc.IsGSM7() {
+		limit = USSD_MAX_SEPTETS
+	}
+	if enc.Length > limit {
+		return nil, fmt.Errorf("%w: %d of %d", ErrTextTooLong, enc.Length, limit)
+	}
+	return &pdu.Message{DataCoding: enc.DataCoding, Payload: enc.Data}, nil
+}
+
+// Request is a message of the mobile passed to the application
+type Request struct {
+	Op   uint8   // ussd_service_op of the PDU
+	Text string  // Decoded USSD string
+	PDU  pdu.PDU // deliver_sm or data_sm carrying it
+}
+
+// Response is the answer of the application to a Request
+type Response struct {
+	Text string // USSD string shown to the user
+	End  bool   // Whether the dialogue ends after the text
+}
+
+// Continue returns a Response showing text and waiting for the user's
+// answer, such as a menu
+func Continue(text string) Response {
+	return Response{Text: text}
+}
+
+// End returns a Response showing text and ending the dialogue
+func End(text string) Response {
+	return Response{Text: text, End: true}
+}
+
+// Handler answers the requests of mobiles. It is called once per request
+// of a dialogue and never concurrently for the same dialogue.
+type Handler func(d *Dialogue, req *Request) Response
+
+// ReleaseHandler is told about dialogues that were released
+type ReleaseHandler func(d *Dialogue, reason Reason)
+
+// Sender sends a PDU to the SMSC or gateway a dialogue runs over
+type Sender func(p pdu.PDU) error
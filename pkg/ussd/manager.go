@@ -0,0 +1,361 @@
+package ussd
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"nessmpp/pkg/pdu"
+)
+
+// Default dialogue timeouts
+const (
+	DEFAULT_IDLE_TIMEOUT time.Duration = 60 * time.Second  // Time the mobile has to answer
+	DEFAULT_MAX_DURATION time.Duration = 180 * time.Second // Time a dialogue may last
+)
+
+// Manager tracks the USSD dialogues of mobiles by MSISDN, passes their
+// requests to the application's Handler and sends its answers back
+type Manager struct {
+	// IdleTimeout limits how long the mobile may take to answer a request
+	// or notification, MaxDuration how long a dialogue may last. Zero
+	// disables the limit.
+	IdleTimeout time.Duration
+	MaxDuration time.Duration
+
+	// ReleaseOp is the ussd_service_op releasing a dialogue, which the
+	// specification leaves to the vendor
+	ReleaseOp uint8
+
+	// TimeoutText is shown to the user when a dialogue times out
+	TimeoutText string
+
+	// OnRelease, if set, is told about every dialogue that was released
+	OnRelease ReleaseHandler
+
+	handler   Handler
+	now       func() time.Time
+	mu        sync.Mutex
+	dialogues map[string]*Dialogue
+}
+
+// NewManager creates a Manager passing the requests of mobiles to handler
+func NewManager(handler Handler) *Manager {
+	return &Manager{
+		IdleTimeout: DEFAULT_IDLE_TIMEOUT,
+		MaxDuration: DEFAULT_MAX_DURATION,
+		ReleaseOp:   USSD_OP_VENDOR_RELEASE,
+		handler:     handler,
+		now:         time.Now,
+		dialogues:   make(map[string]*Dialogue),
+	}
+}
+
+// Dialogue returns the open dialogue of a mobile
+func (m *Manager) Dialogue(msisdn string) (*Dialogue, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d, ok := m.dialogues[msisdn]
+	return d, ok
+}
+
+// Len returns the number of open dialogues
+func (m *Manager) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.dialogues)
+}
+
+// Op returns the ussd_service_op of a deliver_sm or data_sm
+func Op(p pdu.PDU) (uint8, bool) {
+	switch v := p.(type) {
+	case *pdu.DeliverSM:
+		return v.TLVParams.USSDServiceOp()
+	case *pdu.DataSM:
+		return v.TLVParams.USSDServiceOp()
+	}
+	return 0, false
+}
+
+// Check reports the error HandlePDU would return for a PDU without
+// changing any dialogue, so that the PDU can be acknowledged before the
+// application is asked for an answer
+func (m *Manager) Check(p pdu.PDU) error {
+	op, ok := Op(p)
+	if !ok {
+		return ErrNotUSSD
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.check(op, m.dialogues[p.(pdu.SourceAddressed).Source().Addr])
+}
+
+// check verifies that an operation received from a mobile fits the state
+// of its dialogue, nil if there is none
+func (m *Manager) check(op uint8, d *Dialogue) error {
+	switch op {
+	case pdu.USSD_OP_PSSD_INDICATION, pdu.USSD_OP_PSSR_INDICATION:
+		// Opens a new dialogue, replacing any earlier one
+		return nil
+	case pdu.USSD_OP_USSR_CONFIRM, pdu.USSD_OP_USSN_CONFIRM, m.ReleaseOp:
+		if d == nil {
+			return ErrNoDialogue
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrUnexpectedOp, OpName(op))
+	}
+
+	if op == m.ReleaseOp {
+		return nil
+	}
+	if d.State != STATE_WAIT_MOBILE {
+		return fmt.Errorf("%w: %s in state %s", ErrUnexpectedOp, OpName(op), d.State)
+	}
+	notified := d.lastOp == pdu.USSD_OP_USSN_REQUEST
+	if notified != (op == pdu.USSD_OP_USSN_CONFIRM) {
+		return fmt.Errorf("%w: %s after %s", ErrUnexpectedOp, OpName(op), OpName(d.lastOp))
+	}
+	return nil
+}
+
+// HandlePDU handles a deliver_sm or data_sm from the mobile. Indications
+// open a dialogue, confirms continue it and the release operation ends
+// it; the application's answer goes out through send as a submit_sm, or
+// a data_sm when the request came in one.
+func (m *Manager) HandlePDU(p pdu.PDU, send Sender) error {
+	op, ok := Op(p)
+	if !ok {
+		return ErrNotUSSD
+	}
+	msg, err := pdu.MessageFrom(p)
+	if err != nil {
+		return err
+	}
+	text, err := msg.Text()
+	if err != nil {
+		return err
+	}
+	mobile := p.(pdu.SourceAddressed).Source()
+	now := m.now()
+
+	m.mu.Lock()
+	d := m.dialogues[mobile.Addr]
+	if err := m.check(op, d); err != nil {
+		m.mu.Unlock()
+		return err
+	}
+
+	var replaced *Dialogue
+	switch op {
+	case m.ReleaseOp:
+		delete(m.dialogues, d.MSISDN)
+		m.mu.Unlock()
+		m.released(d, RELEASE_MOBILE)
+		return nil
+	case pdu.USSD_OP_USSN_CONFIRM:
+		delete(m.dialogues, d.MSISDN)
+		m.mu.Unlock()
+		m.released(d, RELEASE_END)
+		return nil
+	case pdu.USSD_OP_PSSD_INDICATION, pdu.USSD_OP_PSSR_INDICATION:
+		replaced = d
+		d = &Dialogue{
+			MSISDN:  mobile.Addr,
+			Service: text,
+			Started: now,
+			Values:  make(map[string]any),
+			mobile:  mobile,
+			opener:  op,
+		}
+		d.service = p.(pdu.DestinationAddressed).Destination()
+		switch v := p.(type) {
+		case *pdu.DeliverSM:
+			d.serviceType = v.ServiceType
+		case *pdu.DataSM:
+			d.serviceType, d.dataSM = v.ServiceType, true
+		}
+		m.dialogues[d.MSISDN] = d
+	}
+	d.State = STATE_WAIT_APPLICATION
+	d.Updated = now
+	d.send = send
+	m.mu.Unlock()
+
+	if replaced != nil {
+		m.released(replaced, RELEASE_REPLACED)
+	}
+	return m.answer(d, m.handler(d, &Request{Op: op, Text: text, PDU: p}))
+}
+
+// answer sends the application's response, continuing the dialogue with
+// a USSR request or ending it. A dialogue that has lasted MaxDuration is
+// ended after the text.
+func (m *Manager) answer(d *Dialogue, resp Response) error {
+	now := m.now()
+	reason := RELEASE_END
+	end := resp.End
+	if !end && m.MaxDuration > 0 && now.Sub(d.Started) >= m.MaxDuration {
+		end, reason = true, RELEASE_TIMEOUT
+	}
+	op := pdu.USSD_OP_USSR_REQUEST
+	if end {
+		op = d.endOp(m.ReleaseOp)
+	}
+	p, err := d.message(op, resp.Text)
+
+	m.mu.Lock()
+	if m.dialogues[d.MSISDN] != d {
+		// Released or replaced meanwhile
+		m.mu.Unlock()
+		return err
+	}
+	if err != nil || end {
+		delete(m.dialogues, d.MSISDN)
+	} else {
+		d.State = STATE_WAIT_MOBILE
+		d.Updated = now
+		d.lastOp = op
+	}
+	m.mu.Unlock()
+
+	if err == nil {
+		err = d.send(p)
+	}
+	if err != nil {
+		m.mu.Lock()
+		if m.dialogues[d.MSISDN] == d {
+			delete(m.dialogues, d.MSISDN)
+		}
+		m.mu.Unlock()
+		m.released(d, RELEASE_ERROR)
+		return err
+	}
+	if end {
+		m.released(d, reason)
+	}
+	return nil
+}
+
+// Push opens a dialogue from the application with a USSR request to a
+// mobile, such as a menu the user did not dial
+func (m *Manager) Push(send Sender, service, mobile pdu.Address, text string) error {
+	return m.open(send, service, mobile, pdu.USSD_OP_USSR_REQUEST, text)
+}
+
+// Notify sends a USSN request to a mobile. The dialogue ends when the
+// mobile confirms it.
+func (m *Manager) Notify(send Sender, service, mobile pdu.Address, text string) error {
+	return m.open(send, service, mobile, pdu.USSD_OP_USSN_REQUEST, text)
+}
+
+// open starts a dialogue from the application
+func (m *Manager) open(send Sender, service, mobile pdu.Address, op uint8, text string) error {
+	now := m.now()
+	d := &Dialogue{
+		MSISDN:      mobile.Addr,
+		State:       STATE_WAIT_MOBILE,
+		Started:     now,
+		Updated:     now,
+		Values:      make(map[string]any),
+		mobile:      mobile,
+		service:     service,
+		serviceType: "USSD",
+		opener:      op,
+		lastOp:      op,
+		send:        send,
+	}
+	p, err := d.message(op, text)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	if _, ok := m.dialogues[d.MSISDN]; ok {
+		m.mu.Unlock()
+		return ErrDialogueActive
+	}
+	m.dialogues[d.MSISDN] = d
+	m.mu.Unlock()
+
+	if err := send(p); err != nil {
+		m.mu.Lock()
+		if m.dialogues[d.MSISDN] == d {
+			delete(m.dialogues, d.MSISDN)
+		}
+		m.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Release ends the open dialogue of a mobile from the application, showing
+// text to the user
+func (m *Manager) Release(msisdn, text string) error {
+	m.mu.Lock()
+	d, ok := m.dialogues[msisdn]
+	if ok {
+		delete(m.dialogues, msisdn)
+	}
+	m.mu.Unlock()
+	if !ok {
+		return ErrNoDialogue
+	}
+
+	p, err := d.message(d.endOp(m.ReleaseOp), text)
+	if err == nil {
+		err = d.send(p)
+	}
+	m.released(d, RELEASE_END)
+	return err
+}
+
+// Expire releases the dialogues whose mobile has not answered within
+// IdleTimeout or that have lasted MaxDuration, telling the mobile with
+// TimeoutText. It returns the number of dialogues released.
+func (m *Manager) Expire() int {
+	now := m.now()
+	var expired []*Dialogue
+	m.mu.Lock()
+	for msisdn, d := range m.dialogues {
+		if d.State != STATE_WAIT_MOBILE {
+			continue
+		}
+		idle := m.IdleTimeout > 0 && now.Sub(d.Updated) >= m.IdleTimeout
+		long := m.MaxDuration > 0 && now.Sub(d.Started) >= m.MaxDuration
+		if idle || long {
+			delete(m.dialogues, msisdn)
+			expired = append(expired, d)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, d := range expired {
+		if p, err := d.message(d.endOp(m.ReleaseOp), m.TimeoutText); err == nil {
+			d.send(p)
+		}
+		m.released(d, RELEASE_TIMEOUT)
+	}
+	return len(expired)
+}
+
+// Run calls Expire every interval until stop is closed
+func (m *Manager) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			m.Expire()
+		}
+	}
+}
+
+// released marks a dialogue as released and tells OnRelease
+func (m *Manager) released(d *Dialogue, reason Reason) {
+	d.State = STATE_RELEASED
+	if m.OnRelease != nil {
+		m.OnRelease(d, reason)
+	}
+}
@@ -0,0 +1,421 @@
+package ussd
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"nessmpp/pkg/pdu"
+)
+
+var (
+	testMobile  = pdu.Address{TON: pdu.TON_INTERNATIONAL, NPI: pdu.NPI_ISDN, Addr: "447700900123"}
+	testService = pdu.Address{Addr: "123"}
+	testStart   = time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+)
+
+// harness runs a Manager on a clock the test moves, recording what it
+// sends and releases
+type harness struct {
+	m        *Manager
+	now      time.Time
+	sent     []pdu.PDU
+	released []string
+}
+
+func newHarness(handler Handler) *harness {
+	h := &harness{now: testStart}
+	h.m = NewManager(handler)
+	h.m.now = func() time.Time { return h.now }
+	h.m.TimeoutText = "Session expired"
+	h.m.OnRelease = func(d *Dialogue, reason Reason) {
+		h.released = append(h.released, fmt.Sprintf("%s %s", d.MSISDN, reason))
+	}
+	return h
+}
+
+// send is the Sender of the harness
+func (h *harness) send(p pdu.PDU) error {
+	h.sent = append(h.sent, p)
+	return nil
+}
+
+// advance moves the clock forward
+func (h *harness) advance(d time.Duration) {
+	h.now = h.now.Add(d)
+}
+
+// mobile passes a deliver_sm from the test mobile to the manager
+func (h *harness) mobile(op uint8, text string) error {
+	sm := pdu.NewDeliverSM()
+	sm.ServiceType = "USSD"
+	sm.SetSource(testMobile)
+	sm.SetDestination(testService)
+	if err := sm.SetMessageText(text, pdu.DATA_CODING_DEFAULT); err != nil {
+		return err
+	}
+	sm.TLVParams.SetUSSDServiceOp(op)
+	return h.m.HandlePDU(sm, h.send)
+}
+
+// last returns the ussd_service_op and text of the last PDU sent
+func (h *harness) last(t *testing.T) (uint8, string) {
+	t.Helper()
+	if len(h.sent) == 0 {
+		t.Fatal("nothing sent")
+	}
+	p := h.sent[len(h.sent)-1]
+	op, ok := p.(pdu.TLVCarrier).GetTLVParams().USSDServiceOp()
+	if !ok {
+		t.Fatalf("%s sent without ussd_service_op", pdu.CommandName(p.CommandID()))
+	}
+	msg, err := pdu.MessageFrom(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := msg.Text()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return op, text
+}
+
+// expectSent checks the last PDU sent
+func (h *harness) expectSent(t *testing.T, op uint8, text string) {
+	t.Helper()
+	gotOp, gotText := h.last(t)
+	if gotOp != op || gotText != text {
+		t.Errorf("sent %s %q, want %s %q", OpName(gotOp), gotText, OpName(op), text)
+	}
+}
+
+// expectState checks the dialogue of the test mobile
+func (h *harness) expectState(t *testing.T, state State) *Dialogue {
+	t.Helper()
+	d, ok := h.m.Dialogue(testMobile.Addr)
+	if !ok {
+		t.Fatalf("no dialogue, want one in state %s", state)
+	}
+	if d.State != state {
+		t.Errorf("dialogue in state %s, want %s", d.State, state)
+	}
+	return d
+}
+
+// menu is a Handler showing a menu and ending the dialogue on a choice
+func menu(d *Dialogue, req *Request) Response {
+	switch req.Op {
+	case pdu.USSD_OP_PSSR_INDICATION, pdu.USSD_OP_PSSD_INDICATION:
+		d.Values["opened"] = req.Text
+		return Continue("1 Balance\n2 Exit")
+	}
+	switch req.Text {
+	case "1":
+		return Continue("Balance 5.00\n0 Back")
+	case "0":
+		return Continue("1 Balance\n2 Exit")
+	}
+	return End(fmt.Sprintf("Goodbye from %s", d.Values["opened"]))
+}
+
+func TestManagerDialogue(t *testing.T) {
+	h := newHarness(menu)
+
+	if err := h.mobile(pdu.USSD_OP_PSSR_INDICATION, "*123#"); err != nil {
+		t.Fatalf("PSSR indication: %v", err)
+	}
+	h.expectSent(t, pdu.USSD_OP_USSR_REQUEST, "1 Balance\n2 Exit")
+	d := h.expectState(t, STATE_WAIT_MOBILE)
+	if d.Service != "*123#" || !d.Started.Equal(testStart) {
+		t.Errorf("dialogue for %q started %v", d.Service, d.Started)
+	}
+	sm := h.sent[0].(*pdu.SubmitSM)
+	if sm.Destination() != testMobile || sm.Source() != testService || sm.ServiceType != "USSD" {
+		t.Errorf("answer from %v to %v service_type %q", sm.Source(), sm.Destination(), sm.ServiceType)
+	}
+
+	h.advance(10 * time.Second)
+	if err := h.mobile(pdu.USSD_OP_USSR_CONFIRM, "1"); err != nil {
+		t.Fatalf("USSR confirm: %v", err)
+	}
+	h.expectSent(t, pdu.USSD_OP_USSR_REQUEST, "Balance 5.00\n0 Back")
+	if d := h.expectState(t, STATE_WAIT_MOBILE); !d.Updated.Equal(testStart.Add(10 * time.Second)) {
+		t.Errorf("dialogue updated %v", d.Updated)
+	}
+
+	h.advance(10 * time.Second)
+	if err := h.mobile(pdu.USSD_OP_USSR_CONFIRM, "2"); err != nil {
+		t.Fatalf("USSR confirm: %v", err)
+	}
+	// The dialogue opened with a PSSR indication ends with its response
+	h.expectSent(t, pdu.USSD_OP_PSSR_RESPONSE, "Goodbye from *123#")
+	if h.m.Len() != 0 || d.State != STATE_RELEASED {
+		t.Errorf("%d dialogues open, released one in state %s", h.m.Len(), d.State)
+	}
+	if want := []string{"447700900123 end"}; !slices.Equal(h.released, want) {
+		t.Errorf("released %q, want %q", h.released, want)
+	}
+
+	// The mobile releases a dialogue with the vendor operation
+	if err := h.mobile(pdu.USSD_OP_PSSR_INDICATION, "*123#"); err != nil {
+		t.Fatal(err)
+	}
+	sent := len(h.sent)
+	if err := h.mobile(USSD_OP_VENDOR_RELEASE, ""); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if h.m.Len() != 0 || len(h.sent) != sent {
+		t.Errorf("%d dialogues open, %d PDUs sent after the release", h.m.Len(), len(h.sent)-sent)
+	}
+	if got := h.released[len(h.released)-1]; got != "447700900123 mobile" {
+		t.Errorf("released %q", got)
+	}
+}
+
+func TestManagerDataSM(t *testing.T) {
+	h := newHarness(menu)
+	ds := pdu.NewDataSM()
+	ds.SetSource(testMobile)
+	ds.SetDestination(testService)
+	ds.TLVParams.SetUSSDServiceOp(pdu.USSD_OP_PSSD_INDICATION)
+	ds.TLVParams.SetMessagePayload([]byte("*100#"))
+	if err := h.m.HandlePDU(ds, h.send); err != nil {
+		t.Fatalf("PSSD indication: %v", err)
+	}
+	answer, ok := h.sent[0].(*pdu.DataSM)
+	if !ok {
+		t.Fatalf("answered with %s, want data_sm", pdu.CommandName(h.sent[0].CommandID()))
+	}
+	if op, _ := answer.TLVParams.USSDServiceOp(); op != pdu.USSD_OP_USSR_REQUEST {
+		t.Errorf("answered with %s", OpName(op))
+	}
+
+	// A dialogue opened with a PSSD indication ends with its response
+	if err := h.m.Release(testMobile.Addr, "Bye"); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if op, _ := h.sent[1].(*pdu.DataSM).TLVParams.USSDServiceOp(); op != pdu.USSD_OP_PSSD_RESPONSE {
+		t.Errorf("released with %s", OpName(op))
+	}
+	if err := h.m.Release(testMobile.Addr, "Bye"); !errors.Is(err, ErrNoDialogue) {
+		t.Errorf("second Release error = %v, want ErrNoDialogue", err)
+	}
+}
+
+func TestManagerNotify(t *testing.T) {
+	h := newHarness(func(d *Dialogue, req *Request) Response {
+		t.Errorf("handler called with %s", OpName(req.Op))
+		return End("")
+	})
+
+	if err := h.m.Notify(h.send, testService, testMobile, "Your balance is low"); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	h.expectSent(t, pdu.USSD_OP_USSN_REQUEST, "Your balance is low")
+	h.expectState(t, STATE_WAIT_MOBILE)
+	if err := h.m.Notify(h.send, testService, testMobile, "Again"); !errors.Is(err, ErrDialogueActive) {
+		t.Errorf("second Notify error = %v, want ErrDialogueActive", err)
+	}
+	if err := h.m.Push(h.send, testService, testMobile, "Menu"); !errors.Is(err, ErrDialogueActive) {
+		t.Errorf("Push error = %v, want ErrDialogueActive", err)
+	}
+
+	// A notification is confirmed, not answered
+	if err := h.mobile(pdu.USSD_OP_USSR_CONFIRM, "1"); !errors.Is(err, ErrUnexpectedOp) {
+		t.Errorf("USSR confirm error = %v, want ErrUnexpectedOp", err)
+	}
+	if err := h.mobile(pdu.USSD_OP_USSN_CONFIRM, ""); err != nil {
+		t.Fatalf("USSN confirm: %v", err)
+	}
+	if h.m.Len() != 0 || len(h.sent) != 1 {
+		t.Errorf("%d dialogues open, %d PDUs sent", h.m.Len(), len(h.sent))
+	}
+	if want := []string{"447700900123 end"}; !slices.Equal(h.released, want) {
+		t.Errorf("released %q, want %q", h.released, want)
+	}
+}
+
+func TestManagerReplace(t *testing.T) {
+	h := newHarness(menu)
+	if err := h.mobile(pdu.USSD_OP_PSSR_INDICATION, "*123#"); err != nil {
+		t.Fatal(err)
+	}
+	first := h.expectState(t, STATE_WAIT_MOBILE)
+	first.Values["step"] = 1
+
+	h.advance(5 * time.Second)
+	if err := h.mobile(pdu.USSD_OP_PSSR_INDICATION, "*124#"); err != nil {
+		t.Fatalf("second PSSR indication: %v", err)
+	}
+	second := h.expectState(t, STATE_WAIT_MOBILE)
+	if second == first || second.Service != "*124#" || !second.Started.Equal(testStart.Add(5*time.Second)) {
+		t.Errorf("dialogue for %q started %v, want a new one for *124#", second.Service, second.Started)
+	}
+	if _, ok := second.Values["step"]; ok {
+		t.Error("the new dialogue kept the values of the old one")
+	}
+	if first.State != STATE_RELEASED || h.m.Len() != 1 {
+		t.Errorf("old dialogue in state %s, %d open", first.State, h.m.Len())
+	}
+	if want := []string{"447700900123 replaced"}; !slices.Equal(h.released, want) {
+		t.Errorf("released %q, want %q", h.released, want)
+	}
+	// Nothing is sent to end the old dialogue, the network already has
+	h.expectSent(t, pdu.USSD_OP_USSR_REQUEST, "1 Balance\n2 Exit")
+	if len(h.sent) != 2 {
+		t.Errorf("%d PDUs sent, want 2", len(h.sent))
+	}
+}
+
+func TestManagerExpire(t *testing.T) {
+	t.Run("idle", func(t *testing.T) {
+		h := newHarness(menu)
+		if err := h.mobile(pdu.USSD_OP_PSSR_INDICATION, "*123#"); err != nil {
+			t.Fatal(err)
+		}
+		h.advance(DEFAULT_IDLE_TIMEOUT - time.Second)
+		if n := h.m.Expire(); n != 0 {
+			t.Fatalf("Expire released %d dialogues before IdleTimeout", n)
+		}
+		h.advance(time.Second)
+		if n := h.m.Expire(); n != 1 {
+			t.Fatalf("Expire released %d dialogues, want 1", n)
+		}
+		h.expectSent(t, pdu.USSD_OP_PSSR_RESPONSE, "Session expired")
+		if want := []string{"447700900123 timeout"}; !slices.Equal(h.released, want) {
+			t.Errorf("released %q, want %q", h.released, want)
+		}
+		if err := h.mobile(pdu.USSD_OP_USSR_CONFIRM, "1"); !errors.Is(err, ErrNoDialogue) {
+			t.Errorf("late answer error = %v, want ErrNoDialogue", err)
+		}
+	})
+
+	t.Run("max duration", func(t *testing.T) {
+		h := newHarness(menu)
+		if err := h.m.Push(h.send, testService, testMobile, "Menu"); err != nil {
+			t.Fatal(err)
+		}
+		// Answers within IdleTimeout keep the dialogue open until it has
+		// lasted MaxDuration
+		for i := 0; i < 3; i++ {
+			h.advance(50 * time.Second)
+			if err := h.mobile(pdu.USSD_OP_USSR_CONFIRM, "1"); err != nil {
+				t.Fatalf("answer %d: %v", i, err)
+			}
+			if n := h.m.Expire(); n != 0 {
+				t.Fatalf("Expire released %d dialogues after %v", n, h.now.Sub(testStart))
+			}
+		}
+		h.advance(DEFAULT_MAX_DURATION - 150*time.Second)
+		if n := h.m.Expire(); n != 1 {
+			t.Fatalf("Expire released %d dialogues, want 1", n)
+		}
+		// The application opened it, so it is released with ReleaseOp
+		h.expectSent(t, USSD_OP_VENDOR_RELEASE, "Session expired")
+		if want := []string{"447700900123 timeout"}; !slices.Equal(h.released, want) {
+			t.Errorf("released %q, want %q", h.released, want)
+		}
+	})
+
+	t.Run("answer after max duration", func(t *testing.T) {
+		h := newHarness(menu)
+		h.m.IdleTimeout = 0
+		if err := h.mobile(pdu.USSD_OP_PSSR_INDICATION, "*123#"); err != nil {
+			t.Fatal(err)
+		}
+		h.advance(DEFAULT_MAX_DURATION)
+		// The answer is shown, but ends the dialogue
+		if err := h.mobile(pdu.USSD_OP_USSR_CONFIRM, "1"); err != nil {
+			t.Fatal(err)
+		}
+		h.expectSent(t, pdu.USSD_OP_PSSR_RESPONSE, "Balance 5.00\n0 Back")
+		if h.m.Len() != 0 {
+			t.Errorf("%d dialogues open", h.m.Len())
+		}
+		if want := []string{"447700900123 timeout"}; !slices.Equal(h.released, want) {
+			t.Errorf("released %q, want %q", h.released, want)
+		}
+	})
+
+	t.Run("waiting for the application", func(t *testing.T) {
+		var h *harness
+		h = newHarness(func(d *Dialogue, req *Request) Response {
+			// The application is slow; the mobile is not to blame
+			h.advance(2 * DEFAULT_MAX_DURATION)
+			if n := h.m.Expire(); n != 0 {
+				t.Errorf("Expire released %d dialogues waiting for the application", n)
+			}
+			return Continue("Menu")
+		})
+		if err := h.mobile(pdu.USSD_OP_PSSR_INDICATION, "*123#"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		h := newHarness(menu)
+		h.m.IdleTimeout, h.m.MaxDuration = 0, 0
+		if err := h.mobile(pdu.USSD_OP_PSSR_INDICATION, "*123#"); err != nil {
+			t.Fatal(err)
+		}
+		h.advance(24 * time.Hour)
+		if n := h.m.Expire(); n != 0 || h.m.Len() != 1 {
+			t.Errorf("Expire released %d dialogues without limits", n)
+		}
+	})
+}
+
+func TestManagerUnexpectedOp(t *testing.T) {
+	h := newHarness(menu)
+
+	tests := []struct {
+		name string
+		open bool // Whether a PSSR indication opened a dialogue first
+		op   uint8
+		err  error
+	}{
+		{"confirm without dialogue", false, pdu.USSD_OP_USSR_CONFIRM, ErrNoDialogue},
+		{"release without dialogue", false, USSD_OP_VENDOR_RELEASE, ErrNoDialogue},
+		{"request from the mobile", false, pdu.USSD_OP_USSR_REQUEST, ErrUnexpectedOp},
+		{"response from the mobile", true, pdu.USSD_OP_PSSR_RESPONSE, ErrUnexpectedOp},
+		{"notify confirm after a request", true, pdu.USSD_OP_USSN_CONFIRM, ErrUnexpectedOp},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h.m.Release(testMobile.Addr, "")
+			if tt.open {
+				if err := h.mobile(pdu.USSD_OP_PSSR_INDICATION, "*123#"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			sent := len(h.sent)
+
+			sm := pdu.NewDeliverSM()
+			sm.SetSource(testMobile)
+			sm.TLVParams.SetUSSDServiceOp(tt.op)
+			if err := h.m.Check(sm); !errors.Is(err, tt.err) {
+				t.Errorf("Check error = %v, want %v", err, tt.err)
+			}
+			err := h.m.HandlePDU(sm, h.send)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("HandlePDU error = %v, want %v", err, tt.err)
+			}
+			if status, ok := pdu.ErrorStatus(err); !ok || status != pdu.ESME_RX_R_APPN {
+				t.Errorf("ErrorStatus = 0x%08X, %v", status, ok)
+			}
+			if len(h.sent) != sent {
+				t.Errorf("%d PDUs sent for a rejected operation", len(h.sent)-sent)
+			}
+			if tt.open {
+				// The dialogue still waits for the mobile's answer
+				h.expectState(t, STATE_WAIT_MOBILE)
+			}
+		})
+	}
+
+	if err := h.m.HandlePDU(pdu.NewDeliverSM(), h.send); !errors.Is(err, ErrNotUSSD) {
+		t.Errorf("HandlePDU without ussd_service_op error = %v, want ErrNotUSSD", err)
+	}
+}
@@ -0,0 +1,40 @@
+package ussd
+
+import (
+	"nessmpp/pkg/pdu"
+	"nessmpp/pkg/smpp"
+)
+
+// ServerHandler returns a handler for the deliver_sm and data_sm PDUs of
+// an smpp.Server. USSD PDUs are acknowledged and passed to the manager,
+// whose answers go back over the same session; anything else is passed to
+// next when it is set, or acknowledged. Register it with
+//
+//	srv.Handle(pdu.DELIVER_SM, m.ServerHandler(nil))
+//	srv.Handle(pdu.DATA_SM, m.ServerHandler(nil))
+func (m *Manager) ServerHandler(next smpp.PDUHandler) smpp.PDUHandler {
+	return func(sess *smpp.Session, p pdu.PDU) error {
+		_, isUSSD := Op(p)
+		if !isUSSD && next != nil {
+			return next(sess, p)
+		}
+		resp := p.GetResponse()
+		if !isUSSD {
+			return sess.Send(resp)
+		}
+
+		if err := m.Check(p); err != nil {
+			status, ok := pdu.ErrorStatus(err)
+			if !ok {
+				status = pdu.ESME_RX_P_APPN
+			}
+			resp.GetHeader().CommandStatus = status
+			sess.Send(resp)
+			return err
+		}
+		if err := sess.Send(resp); err != nil {
+			return err
+		}
+		return m.HandlePDU(p, sess.Send)
+	}
+}
@@ -0,0 +1,205 @@
+// Package wap builds WAP Push messages: WSP push PDUs carrying Service
+// Indication, Service Loading and client provisioning documents, sent as
+// port addressed binary SMS to the WAP Push port of the handset.
+package wap
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
+
+	"nessmpp/pkg/pdu"
+)
+
+// WSP PDU types
+const (
+	WSP_PDU_PUSH           uint8 = 0x06
+	WSP_PDU_CONFIRMED_PUSH uint8 = 0x07
+)
+
+// WSP header and parameter codes
+const (
+	WSP_HEADER_APP_ID uint8 = 0x2F // X-Wap-Application-Id
+	WSP_PARAM_SEC     uint8 = 0x11 // SEC content type parameter
+	WSP_PARAM_MAC     uint8 = 0x12 // MAC content type parameter
+
+	wspLengthQuote    uint8 = 0x1F // Precedes a uintvar length
+	wspShortLengthMax int   = 30   // Longest length in a single octet
+)
+
+// Content types with a well-known WSP code
+const (
+	CONTENT_TYPE_SI           = "application/vnd.wap.sic"
+	CONTENT_TYPE_SL           = "application/vnd.wap.slc"
+	CONTENT_TYPE_PROVISIONING = "application/vnd.wap.connectivity-wbxml"
+	CONTENT_TYPE_WMLC         = "application/vnd.wap.wmlc"
+)
+
+// contentTypes maps content types to their WSP codes
+var contentTypes = map[string]uint8{
+	"*/*":                     0x00,
+	"text/plain":              0x03,
+	"text/x-vCalendar":        0x06,
+	"text/x-vCard":            0x07,
+	CONTENT_TYPE_WMLC:         0x14,
+	"text/vnd.wap.si":         0x2D,
+	CONTENT_TYPE_SI:           0x2E,
+	"text/vnd.wap.sl":         0x2F,
+	CONTENT_TYPE_SL:           0x30,
+	"text/vnd.wap.co":         0x31,
+	"application/vnd.wap.coc": 0x32,
+	"application/vnd.wap.sia": 0x34,
+	CONTENT_TYPE_PROVISIONING: 0x36,
+}
+
+// Push application IDs
+const (
+	APP_ID_ANY       = "x-wap-application:*"
+	APP_ID_PUSH_SIA  = "x-wap-application:push.sia"
+	APP_ID_WML_UA    = "x-wap-application:wml.ua"
+	APP_ID_WTA_UA    = "x-wap-application:wta.ua"
+	APP_ID_MMS_UA    = "x-wap-application:mms.ua"
+	APP_ID_SYNCML    = "x-wap-application:push.syncml"
+	APP_ID_SYNCML_DM = "x-wap-application:syncml.dm"
+)
+
+// applicationIDs maps push application IDs to their registered codes
+var applicationIDs = map[string]uint8{
+	APP_ID_ANY:       0x00,
+	APP_ID_PUSH_SIA:  0x01,
+	APP_ID_WML_UA:    0x02,
+	APP_ID_WTA_UA:    0x03,
+	APP_ID_MMS_UA:    0x04,
+	APP_ID_SYNCML:    0x05,
+	APP_ID_SYNCML_DM: 0x07,
+}
+
+// Security methods of client provisioning documents
+const (
+	SEC_NETWPIN     uint8 = 0x00 // MAC keyed with the IMSI
+	SEC_USERPIN     uint8 = 0x01 // MAC keyed with a PIN given to the user
+	SEC_USERNETWPIN uint8 = 0x02 // MAC keyed with the IMSI and a PIN
+	SEC_USERPINMAC  uint8 = 0x03 // MAC keyed with a PIN derived from the MAC
+)
+
+// Push is a connectionless WSP push PDU
+type Push struct {
+	TID           uint8  // Transaction ID
+	ContentType   string // Media type of the body
+	Params        []byte // Encoded content type parameters
+	ApplicationID string // X-Wap-Application-Id, "" for none
+	Headers       []byte // Further encoded WSP headers
+	Body          []byte
+}
+
+// NewSIPush creates a push of a Service Indication
+func NewSIPush(si *ServiceIndication) (*Push, error) {
+	body, err := si.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return &Push{TID: 0x01, ContentType: CONTENT_TYPE_SI, ApplicationID: APP_ID_WML_UA, Body: body}, nil
+}
+
+// NewSLPush creates a push of a Service Loading
+func NewSLPush(sl *ServiceLoading) (*Push, error) {
+	body, err := sl.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return &Push{TID: 0x01, ContentType: CONTENT_TYPE_SL, ApplicationID: APP_ID_WML_UA, Body: body}, nil
+}
+
+// NewProvisioningPush creates a push of a WBXML encoded client
+// provisioning document, authenticated with the security method and MAC
+// unless mac is empty
+func NewProvisioningPush(doc []byte, sec uint8, mac string) *Push {
+	p := &Push{TID: 0x01, ContentType: CONTENT_TYPE_PROVISIONING, Body: doc}
+	if mac != "" {
+		p.Params = []byte{WSP_PARAM_SEC | 0x80, sec | 0x80, WSP_PARAM_MAC | 0x80}
+		p.Params = append(append(p.Params, mac...), 0)
+	}
+	return p
+}
+
+// ProvisioningMAC returns the MAC of a client provisioning document: the
+// HMAC-SHA1 of the document keyed as the security method requires, in
+// upper case hex
+func ProvisioningMAC(key, doc []byte) string {
+	h := hmac.New(sha1.New, key)
+	h.Write(doc)
+	return strings.ToUpper(hex.EncodeToString(h.Sum(nil)))
+}
+
+// Marshal encodes the push PDU
+func (p *Push) Marshal() []byte {
+	headers := appendContentType(nil, p.ContentType, p.Params)
+	if p.ApplicationID != "" {
+		headers = append(headers, WSP_HEADER_APP_ID|0x80)
+		if id, ok := applicationIDs[p.ApplicationID]; ok {
+			headers = append(headers, id|0x80)
+		} else {
+			headers = appendText(headers, p.ApplicationID)
+		}
+	}
+	headers = append(headers, p.Headers...)
+
+	b := []byte{p.TID, WSP_PDU_PUSH}
+	b = appendUintvar(b, uint32(len(headers)))
+	b = append(b, headers...)
+	return append(b, p.Body...)
+}
+
+// Message returns the push as a binary message from the WSP port to the
+// WAP Push port, to be split with pdu.Segmenter.SubmitSMBinary
+func (p *Push) Message() *pdu.BinaryMessage {
+	return pdu.NewPortMessage(p.Marshal(), pdu.PORT_WAP_PUSH, pdu.PORT_WAP_WSP)
+}
+
+// appendContentType appends a content type by its well-known code or as
+// text, in the general form with a length when it has parameters
+func appendContentType(b []byte, contentType string, params []byte) []byte {
+	var media []byte
+	if code, ok := contentTypes[contentType]; ok {
+		media = []byte{code | 0x80}
+	} else {
+		media = appendText(nil, contentType)
+	}
+	if len(params) == 0 {
+		return append(b, media...)
+	}
+
+	n := len(media) + len(params)
+	if n <= wspShortLengthMax {
+		b = append(b, byte(n))
+	} else {
+		b = append(b, wspLengthQuote)
+		b = appendUintvar(b, uint32(n))
+	}
+	b = append(b, media...)
+	return append(b, params...)
+}
+
+// appendText appends a null terminated text string, quoted when it starts
+// with an octet that would be read as a token
+func appendText(b []byte, s string) []byte {
+	if len(s) > 0 && s[0] >= 0x80 {
+		b = append(b, 0x7F)
+	}
+	b = append(b, s...)
+	return append(b, 0)
+}
+
+// appendUintvar appends a WSP variable length unsigned integer: 7 bits per
+// octet, most significant first, the top bit set on all but the last
+func appendUintvar(b []byte, v uint32) []byte {
+	var tmp [5]byte
+	i := len(tmp) - 1
+	tmp[i] = byte(v & 0x7F)
+	for v >>= 7; v > 0; v >>= 7 {
+		i--
+		tmp[i] = byte(v&0x7F) | 0x80
+	}
+	return append(b, tmp[i:]...)
+}
@@ -0,0 +1,110 @@
+package wap
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"nessmpp/pkg/pdu"
+)
+
+// airUDH returns the UDH a part has on the air interface: its own elements
+// and those the SMSC builds from the SAR and port TLVs
+func airUDH(t *testing.T, sm *pdu.SubmitSM) (*pdu.UDH, []byte) {
+	t.Helper()
+	udh, data, err := pdu.SplitUserData(sm.ShortMessage, sm.ESMClass)
+	if err != nil {
+		t.Fatalf("SplitUserData: %v", err)
+	}
+	if udh == nil {
+		udh = pdu.NewUDH()
+	}
+	if _, ok := udh.Concat(); !ok {
+		if _, ok := sm.TLVParams.SAR(); ok {
+			udh.Add(&pdu.ConcatIE{})
+		}
+	}
+	if _, ok := udh.Ports(); !ok {
+		dest, hasDest := sm.TLVParams.DestinationPort()
+		src, hasSrc := sm.TLVParams.SourcePort()
+		if hasDest || hasSrc {
+			udh.Add(pdu.NewPortIE(dest, src))
+		}
+	}
+	return udh, data
+}
+
+func TestWAPPushSegmentation(t *testing.T) {
+	si := &ServiceIndication{
+		Href: "https://www.example.com/offers/" + strings.Repeat("x", 200),
+		Text: strings.Repeat("Your new offer is waiting. ", 8),
+	}
+	push, err := NewSIPush(si)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := push.Marshal()
+
+	strategies := []pdu.SegmentStrategy{pdu.SEGMENT_UDH_8BIT, pdu.SEGMENT_UDH_16BIT, pdu.SEGMENT_SAR, pdu.SEGMENT_PAYLOAD}
+	for _, strategy := range strategies {
+		tmpl := pdu.NewSubmitSM()
+		tmpl.DestinationAddr = "447700900123"
+		pdus, err := pdu.NewSegmenter(strategy).SubmitSMBinary(tmpl, push.Message())
+		if err != nil {
+			t.Fatalf("strategy %d: SubmitSMBinary: %v", strategy, err)
+		}
+
+		if strategy == pdu.SEGMENT_PAYLOAD {
+			// The SMSC segments message_payload itself
+			payload, _ := pdus[0].TLVParams.MessagePayload()
+			if len(pdus) != 1 || !bytes.Equal(payload, want) {
+				t.Errorf("payload: want the whole push in one message_payload, got %d pdus", len(pdus))
+			}
+			continue
+		}
+
+		if len(pdus) < 3 {
+			t.Fatalf("strategy %d: %d parts, want a multi-part push", strategy, len(pdus))
+		}
+		var got []byte
+		for i, sm := range pdus {
+			udh, data := airUDH(t, sm)
+			if n := udh.Len() + len(data); n > pdu.SM_MAX_USER_DATA {
+				t.Errorf("strategy %d, part %d: %d octets on the air, limit %d", strategy, i+1, n, pdu.SM_MAX_USER_DATA)
+			}
+			ports, ok := udh.Ports()
+			if !ok || ports.DestPort != pdu.PORT_WAP_PUSH || ports.SourcePort != pdu.PORT_WAP_WSP {
+				t.Errorf("strategy %d, part %d: ports %+v, want %d/%d", strategy, i+1, ports, pdu.PORT_WAP_PUSH, pdu.PORT_WAP_WSP)
+			}
+			got = append(got, data...)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("strategy %d: reassembled push differs", strategy)
+		}
+	}
+}
+
+func TestServiceIndicationMarshal(t *testing.T) {
+	// Example of WAP-167 appendix C, with the href prefix tokenized
+	si := &ServiceIndication{
+		Href: "http://www.xyz.com/email/123/abc.wml",
+		ID:   "/123",
+		Text: "You have 4 new emails",
+	}
+	got, err := si.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0x02, 0x05, 0x6A, 0x00, 0x45, 0xC6, 0x0D, 0x03}
+	want = append(want, "xyz\x00"...)
+	want = append(want, 0x85, 0x03)
+	want = append(want, "email/123/abc.wml\x00"...)
+	want = append(want, 0x11, 0x03)
+	want = append(want, "/123\x00"...)
+	want = append(want, 0x01, 0x03)
+	want = append(want, "You have 4 new emails\x00"...)
+	want = append(want, 0x01, 0x01)
+	if !bytes.Equal(got, want) {
+		t.Errorf("Marshal =\n% X\nwant\n% X", got, want)
+	}
+}
@@ -0,0 +1,207 @@
+package wap
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoHref = errors.New("service loading has no href")
+)
+
+// WBXML global tokens and document header values
+const (
+	WBXML_VERSION_1_2  uint8 = 0x02
+	WBXML_PUBLIC_ID_SI uint8 = 0x05 // -//WAPFORUM//DTD SI 1.0//EN
+	WBXML_PUBLIC_ID_SL uint8 = 0x06 // -//WAPFORUM//DTD SL 1.0//EN
+	WBXML_PUBLIC_ID_CP uint8 = 0x0B // -//WAPFORUM//DTD PROV 1.0//EN
+	WBXML_CHARSET_UTF8 uint8 = 0x6A
+
+	wbxmlEnd        uint8 = 0x01
+	wbxmlStrI       uint8 = 0x03
+	wbxmlOpaque     uint8 = 0xC3
+	wbxmlContent    uint8 = 0x40 // Tag flag: the element has content
+	wbxmlAttributes uint8 = 0x80 // Tag flag: the element has attributes
+)
+
+// SIAction is the action attribute of a Service Indication
+type SIAction uint8
+
+// Service Indication actions, the tokens of the action attribute
+const (
+	SI_SIGNAL_MEDIUM SIAction = 0x07 // Default
+	SI_SIGNAL_NONE   SIAction = 0x05 // Store without telling the user
+	SI_SIGNAL_LOW    SIAction = 0x06
+	SI_SIGNAL_HIGH   SIAction = 0x08
+	SI_DELETE        SIAction = 0x09 // Delete the indication with the same si-id
+)
+
+// SLAction is the action attribute of a Service Loading
+type SLAction uint8
+
+// Service Loading actions, the tokens of the action attribute
+const (
+	SL_EXECUTE_LOW  SLAction = 0x05 // Default: load when the user agrees
+	SL_EXECUTE_HIGH SLAction = 0x06 // Load and show at once
+	SL_CACHE        SLAction = 0x07 // Load into the cache
+)
+
+// Service Indication tag and attribute tokens (WAP-167)
+const (
+	siTagSI         uint8 = 0x05
+	siTagIndication uint8 = 0x06
+	siAttrCreated   uint8 = 0x0A
+	siAttrHref      uint8 = 0x0B
+	siAttrExpires   uint8 = 0x10
+	siAttrID        uint8 = 0x11
+)
+
+// Service Loading tag and attribute tokens (WAP-168)
+const (
+	slTagSL    uint8 = 0x05
+	slAttrHref uint8 = 0x08
+)
+
+// hrefPrefix is an href attribute start token with the URL prefix it
+// stands for
+type hrefPrefix struct {
+	prefix string
+	token  uint8
+}
+
+// Longest first, so that the first match is the best
+var (
+	siHrefPrefixes = []hrefPrefix{
+		{"https://www.", 0x0F}, {"http://www.", 0x0D}, {"https://", 0x0E}, {"http://", 0x0C},
+	}
+	slHrefPrefixes = []hrefPrefix{
+		{"https://www.", 0x0C}, {"http://www.", 0x0A}, {"https://", 0x0B}, {"http://", 0x09},
+	}
+)
+
+// hrefValues are the attribute value tokens shared by SI and SL
+var hrefValues = []hrefPrefix{
+	{".com/", 0x85}, {".edu/", 0x86}, {".net/", 0x87}, {".org/", 0x88},
+}
+
+// ServiceIndication tells the user about a service by URL, with a text to
+// show. It is pushed with NewSIPush.
+type ServiceIndication struct {
+	Href    string    // URL of the service
+	Text    string    // Text shown to the user
+	ID      string    // si-id, defaults to Href; a later SI with the same ID replaces it
+	Created time.Time // When the content was created, zero to omit
+	Expires time.Time // When the indication is deleted, zero to omit
+	Action  SIAction  // SI_SIGNAL_MEDIUM unless set
+}
+
+// Marshal encodes the indication as a WBXML document
+func (si *ServiceIndication) Marshal() ([]byte, error) {
+	b := []byte{WBXML_VERSION_1_2, WBXML_PUBLIC_ID_SI, WBXML_CHARSET_UTF8, 0x00}
+	b = append(b, siTagSI|wbxmlContent)
+
+	tag := siTagIndication | wbxmlAttributes
+	if si.Text != "" {
+		tag |= wbxmlContent
+	}
+	b = append(b, tag)
+	if si.Href != "" {
+		b = appendHref(b, si.Href, siAttrHref, siHrefPrefixes)
+	}
+	if si.ID != "" {
+		b = append(b, siAttrID)
+		b = appendInlineString(b, si.ID)
+	}
+	if !si.Created.IsZero() {
+		b = append(b, siAttrCreated)
+		b = appendDate(b, si.Created)
+	}
+	if !si.Expires.IsZero() {
+		b = append(b, siAttrExpires)
+		b = appendDate(b, si.Expires)
+	}
+	if si.Action != 0 {
+		b = append(b, uint8(si.Action))
+	}
+	b = append(b, wbxmlEnd)
+
+	if si.Text != "" {
+		b = appendInlineString(b, si.Text)
+		b = append(b, wbxmlEnd)
+	}
+	return append(b, wbxmlEnd), nil
+}
+
+// ServiceLoading makes the handset load a URL, with or without asking the
+// user. It is pushed with NewSLPush.
+type ServiceLoading struct {
+	Href   string   // URL to load
+	Action SLAction // SL_EXECUTE_LOW unless set
+}
+
+// Marshal encodes the service loading as a WBXML document
+func (sl *ServiceLoading) Marshal() ([]byte, error) {
+	if sl.Href == "" {
+		return nil, ErrNoHref
+	}
+	b := []byte{WBXML_VERSION_1_2, WBXML_PUBLIC_ID_SL, WBXML_CHARSET_UTF8, 0x00}
+	b = append(b, slTagSL|wbxmlAttributes)
+	b = appendHref(b, sl.Href, slAttrHref, slHrefPrefixes)
+	if sl.Action != 0 {
+		b = append(b, uint8(sl.Action))
+	}
+	return append(b, wbxmlEnd), nil
+}
+
+// appendHref appends an href attribute, tokenizing the URL prefix and the
+// well-known domain endings
+func appendHref(b []byte, href string, attr uint8, prefixes []hrefPrefix) []byte {
+	for _, p := range prefixes {
+		if strings.HasPrefix(href, p.prefix) {
+			attr, href = p.token, href[len(p.prefix):]
+			break
+		}
+	}
+	b = append(b, attr)
+
+	for href != "" {
+		i, value := -1, hrefPrefix{}
+		for _, v := range hrefValues {
+			if j := strings.Index(href, v.prefix); j >= 0 && (i < 0 || j < i) {
+				i, value = j, v
+			}
+		}
+		if i < 0 {
+			return appendInlineString(b, href)
+		}
+		if i > 0 {
+			b = appendInlineString(b, href[:i])
+		}
+		b = append(b, value.token)
+		href = href[i+len(value.prefix):]
+	}
+	return b
+}
+
+// appendInlineString appends a null terminated inline string
+func appendInlineString(b []byte, s string) []byte {
+	b = append(b, wbxmlStrI)
+	b = append(b, s...)
+	return append(b, 0)
+}
+
+// appendDate appends a date attribute value as opaque data: the UTC digits
+// of YYYYMMDDhhmmss packed two per octet, without trailing zero octets
+func appendDate(b []byte, t time.Time) []byte {
+	digits := t.UTC().Format("20060102150405")
+	date := make([]byte, len(digits)/2)
+	for i := range date {
+		date[i] = (digits[2*i]-'0')<<4 | (digits[2*i+1] - '0')
+	}
+	for len(date) > 0 && date[len(date)-1] == 0 {
+		date = date[:len(date)-1]
+	}
+	b = append(b, wbxmlOpaque, byte(len(date)))
+	return append(b, date...)
+}